@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -17,12 +18,14 @@ import (
 	"dhis2sync-desktop/internal/crypto"
 	"dhis2sync-desktop/internal/database"
 	"dhis2sync-desktop/internal/models"
+	"dhis2sync-desktop/internal/notifications"
 	"dhis2sync-desktop/internal/services/audit"
 	"dhis2sync-desktop/internal/services/completeness"
 	"dhis2sync-desktop/internal/services/metadata"
 	"dhis2sync-desktop/internal/services/scheduler"
 	"dhis2sync-desktop/internal/services/tracker"
 	"dhis2sync-desktop/internal/services/transfer"
+	"dhis2sync-desktop/internal/writewindow"
 
 	"gorm.io/gorm"
 )
@@ -129,6 +132,35 @@ func (a *App) GetAuditProgress(taskID string) (*audit.AuditProgress, error) {
 	return a.auditService.GetAuditProgress(taskID)
 }
 
+// CancelAudit stops a running audit
+func (a *App) CancelAudit(taskID string) error {
+	return a.auditService.CancelAudit(taskID)
+}
+
+// CreateMissingFromAudit creates the accepted missing org units/COCs from an audit
+// in the destination, copying them from source with saved metadata mappings applied
+func (a *App) CreateMissingFromAudit(taskID string, itemIDs []string) (*metadata.ImportReport, error) {
+	profileID, items, err := a.auditService.GetMissingItems(taskID, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, errors.New("no accepted items match the audit's missing items")
+	}
+
+	ids := make(map[metadata.MetadataType][]string)
+	for _, item := range items {
+		switch item.Type {
+		case "orgUnit":
+			ids[metadata.TypeOrganisationUnits] = append(ids[metadata.TypeOrganisationUnits], item.ID)
+		case "categoryOptionCombo":
+			ids[metadata.TypeCategoryOptionCombos] = append(ids[metadata.TypeCategoryOptionCombos], item.ID)
+		}
+	}
+
+	return a.metadataService.CreateMissing(profileID, ids)
+}
+
 // shutdown is called when the app is closing
 func (a *App) shutdown(ctx context.Context) {
 	log.Println("Application shutting down...")
@@ -152,10 +184,27 @@ func (a *App) shutdown(ctx context.Context) {
 
 // Profile Management Methods
 
-// ListProfiles returns all connection profiles
+// ListProfiles returns all connection profiles, most recently used first
 func (a *App) ListProfiles() ([]models.ConnectionProfile, error) {
+	return a.ListProfilesSorted("recent")
+}
+
+// ListProfilesSorted returns all connection profiles; sortBy "name" orders them
+// alphabetically, "" or "recent" most recently used first
+func (a *App) ListProfilesSorted(sortBy string) ([]models.ConnectionProfile, error) {
+	var order string
+	switch sortBy {
+	case "", "recent":
+		// Never-used profiles (NULL) sort last, then alphabetically
+		order = "last_used_at IS NULL, last_used_at DESC, name ASC"
+	case "name":
+		order = "LOWER(name) ASC"
+	default:
+		return nil, fmt.Errorf("invalid sort %q: must be 'recent' or 'name'", sortBy)
+	}
+
 	var profiles []models.ConnectionProfile
-	if err := a.db.Find(&profiles).Error; err != nil {
+	if err := a.db.Order(order).Find(&profiles).Error; err != nil {
 		return nil, err
 	}
 	return profiles, nil
@@ -179,7 +228,47 @@ func (a *App) CreateProfile(req CreateProfileRequest) error {
 		return errors.New("encryption system not initialized - cannot save profiles")
 	}
 
-	// Encrypt passwords
+	if _, err := audit.ParseNameRules(req.NameMatchRules); err != nil {
+		return fmt.Errorf("invalid name match rules: %w", err)
+	}
+	if _, err := writewindow.Parse(req.WriteWindow); err != nil {
+		return err
+	}
+	if req.RateLimit < 0 {
+		return errors.New("rate limit cannot be negative")
+	}
+	if req.RequestTimeoutSeconds < 0 {
+		return errors.New("request timeout cannot be negative")
+	}
+	if _, err := api.ParseProxyURL(req.ProxyURL); err != nil {
+		return err
+	}
+
+	sourceURL, err := api.NormalizeDHIS2URL(req.SourceURL)
+	if err != nil {
+		return fmt.Errorf("invalid source URL: %w", err)
+	}
+	destURL, err := api.NormalizeDHIS2URL(req.DestURL)
+	if err != nil {
+		return fmt.Errorf("invalid destination URL: %w", err)
+	}
+
+	sourceAuthType, err := normalizeAuthType(req.SourceAuthType)
+	if err != nil {
+		return fmt.Errorf("invalid source authentication: %w", err)
+	}
+	destAuthType, err := normalizeAuthType(req.DestAuthType)
+	if err != nil {
+		return fmt.Errorf("invalid destination authentication: %w", err)
+	}
+	if sourceAuthType == api.AuthToken && req.SourceToken == "" {
+		return errors.New("source token is required for token authentication")
+	}
+	if destAuthType == api.AuthToken && req.DestToken == "" {
+		return errors.New("destination token is required for token authentication")
+	}
+
+	// Encrypt passwords and tokens
 	sourcePasswordEnc, err := crypto.EncryptPassword(req.SourcePassword)
 	if err != nil {
 		return err
@@ -190,15 +279,41 @@ func (a *App) CreateProfile(req CreateProfileRequest) error {
 		return err
 	}
 
+	sourceTokenEnc, err := encryptToken(req.SourceToken)
+	if err != nil {
+		return err
+	}
+
+	destTokenEnc, err := encryptToken(req.DestToken)
+	if err != nil {
+		return err
+	}
+
+	proxyURL, proxyPassword := api.SplitProxyPassword(strings.TrimSpace(req.ProxyURL))
+	proxyPasswordEnc, err := encryptToken(proxyPassword)
+	if err != nil {
+		return err
+	}
+
 	profile := &models.ConnectionProfile{
-		Name:              req.Name,
-		Owner:             req.Owner,
-		SourceURL:         req.SourceURL,
-		SourceUsername:    req.SourceUsername,
-		SourcePasswordEnc: sourcePasswordEnc,
-		DestURL:           req.DestURL,
-		DestUsername:      req.DestUsername,
-		DestPasswordEnc:   destPasswordEnc,
+		Name:                  req.Name,
+		Owner:                 req.Owner,
+		SourceURL:             sourceURL,
+		SourceUsername:        req.SourceUsername,
+		SourcePasswordEnc:     sourcePasswordEnc,
+		DestURL:               destURL,
+		DestUsername:          req.DestUsername,
+		DestPasswordEnc:       destPasswordEnc,
+		NameMatchRules:        req.NameMatchRules,
+		WriteWindow:           req.WriteWindow,
+		SourceAuthType:        sourceAuthType,
+		SourceTokenEnc:        sourceTokenEnc,
+		DestAuthType:          destAuthType,
+		DestTokenEnc:          destTokenEnc,
+		RateLimit:             req.RateLimit,
+		RequestTimeoutSeconds: req.RequestTimeoutSeconds,
+		ProxyURL:              proxyURL,
+		ProxyPasswordEnc:      proxyPasswordEnc,
 	}
 
 	return a.db.Create(profile).Error
@@ -211,14 +326,43 @@ func (a *App) UpdateProfile(profileID string, req CreateProfileRequest) error {
 		return err
 	}
 
+	sourceURL, err := api.NormalizeDHIS2URL(req.SourceURL)
+	if err != nil {
+		return fmt.Errorf("invalid source URL: %w", err)
+	}
+	destURL, err := api.NormalizeDHIS2URL(req.DestURL)
+	if err != nil {
+		return fmt.Errorf("invalid destination URL: %w", err)
+	}
+
 	// Update fields
 	profile.Name = req.Name
 	profile.Owner = req.Owner
-	profile.SourceURL = req.SourceURL
+	profile.SourceURL = sourceURL
 	profile.SourceUsername = req.SourceUsername
-	profile.DestURL = req.DestURL
+	profile.DestURL = destURL
 	profile.DestUsername = req.DestUsername
 
+	if _, err := audit.ParseNameRules(req.NameMatchRules); err != nil {
+		return fmt.Errorf("invalid name match rules: %w", err)
+	}
+	profile.NameMatchRules = req.NameMatchRules
+
+	if _, err := writewindow.Parse(req.WriteWindow); err != nil {
+		return err
+	}
+	profile.WriteWindow = req.WriteWindow
+
+	if req.RateLimit < 0 {
+		return errors.New("rate limit cannot be negative")
+	}
+	if req.RequestTimeoutSeconds < 0 {
+		return errors.New("request timeout cannot be negative")
+	}
+	if _, err := api.ParseProxyURL(req.ProxyURL); err != nil {
+		return err
+	}
+
 	// Encrypt passwords if provided
 	if req.SourcePassword != "" {
 		sourcePasswordEnc, err := crypto.EncryptPassword(req.SourcePassword)
@@ -236,9 +380,533 @@ func (a *App) UpdateProfile(profileID string, req CreateProfileRequest) error {
 		profile.DestPasswordEnc = destPasswordEnc
 	}
 
+	// Tokens, like passwords, are kept unless a new one is provided
+	sourceAuthType, err := normalizeAuthType(req.SourceAuthType)
+	if err != nil {
+		return fmt.Errorf("invalid source authentication: %w", err)
+	}
+	destAuthType, err := normalizeAuthType(req.DestAuthType)
+	if err != nil {
+		return fmt.Errorf("invalid destination authentication: %w", err)
+	}
+	if req.SourceToken != "" {
+		if profile.SourceTokenEnc, err = encryptToken(req.SourceToken); err != nil {
+			return err
+		}
+	}
+	if req.DestToken != "" {
+		if profile.DestTokenEnc, err = encryptToken(req.DestToken); err != nil {
+			return err
+		}
+	}
+	if sourceAuthType == api.AuthToken && profile.SourceTokenEnc == "" {
+		return errors.New("source token is required for token authentication")
+	}
+	if destAuthType == api.AuthToken && profile.DestTokenEnc == "" {
+		return errors.New("destination token is required for token authentication")
+	}
+	profile.SourceAuthType = sourceAuthType
+	profile.DestAuthType = destAuthType
+	profile.RateLimit = req.RateLimit
+	profile.RequestTimeoutSeconds = req.RequestTimeoutSeconds
+
+	// The proxy password, like the others, is kept unless a new one is provided, as
+	// long as the proxy still names a user
+	proxyURL, proxyPassword := api.SplitProxyPassword(strings.TrimSpace(req.ProxyURL))
+	if proxyPassword != "" {
+		if profile.ProxyPasswordEnc, err = encryptToken(proxyPassword); err != nil {
+			return err
+		}
+	} else if !api.ProxyHasUser(proxyURL) {
+		profile.ProxyPasswordEnc = ""
+	}
+	profile.ProxyURL = proxyURL
+
 	return a.db.Save(&profile).Error
 }
 
+// normalizeAuthType validates a profile/connection auth type; empty means basic
+func normalizeAuthType(authType string) (string, error) {
+	switch authType {
+	case "", api.AuthBasic:
+		return api.AuthBasic, nil
+	case api.AuthToken:
+		return api.AuthToken, nil
+	default:
+		return "", fmt.Errorf("unknown auth type %q (expected %q or %q)", authType, api.AuthBasic, api.AuthToken)
+	}
+}
+
+// encryptToken encrypts a personal access token; no token stays empty
+func encryptToken(token string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+	return crypto.EncryptPassword(token)
+}
+
+// RotateEncryptionKey re-encrypts every profile's stored passwords (proxy passwords
+// included) and tokens under a
+// new random key, which then replaces the current key in the keychain. All profiles
+// are updated in one transaction, so a credential that doesn't decrypt leaves every
+// profile and the key as they were. dryRun only checks that every stored credential
+// decrypts with the current key. A key from ENCRYPTION_KEY can't be rotated here, so
+// both fail before any profile is touched.
+func (a *App) RotateEncryptionKey(dryRun bool) (*KeyRotationResponse, error) {
+	oldKey, err := crypto.CurrentKey()
+	if err != nil {
+		return nil, err
+	}
+	if crypto.KeyFromEnvironment() {
+		return nil, crypto.ErrKeyFromEnvironment
+	}
+	newKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &KeyRotationResponse{DryRun: dryRun}
+	rotated := false
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		var profiles []models.ConnectionProfile
+		if err := tx.Find(&profiles).Error; err != nil {
+			return err
+		}
+
+		for i := range profiles {
+			profile := &profiles[i]
+			n, err := reencryptProfile(profile, oldKey, newKey)
+			if err != nil {
+				return err
+			}
+			result.Profiles++
+			result.Credentials += n
+			if dryRun {
+				continue
+			}
+
+			// UpdateColumns leaves UpdatedAt alone; the profile's settings didn't change
+			if err := tx.Model(&models.ConnectionProfile{}).Where("id = ?", profile.ID).UpdateColumns(map[string]interface{}{
+				"source_password_enc": profile.SourcePasswordEnc,
+				"dest_password_enc":   profile.DestPasswordEnc,
+				"source_token_enc":    profile.SourceTokenEnc,
+				"dest_token_enc":      profile.DestTokenEnc,
+				"proxy_password_enc":  profile.ProxyPasswordEnc,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to save profile %q: %w", profile.Name, err)
+			}
+		}
+
+		if dryRun {
+			return nil
+		}
+		if err := crypto.RotateKey(oldKey, newKey); err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	if err != nil {
+		if rotated {
+			// The commit failed after the keychain took the new key, so put the old one back
+			if restoreErr := crypto.RotateKey(newKey, oldKey); restoreErr != nil {
+				log.Printf("✗ Failed to restore the previous encryption key after a failed rotation: %v", restoreErr)
+			}
+		}
+		return nil, fmt.Errorf("key rotation failed, no credentials were changed: %w", err)
+	}
+
+	if !dryRun {
+		// The cached selection holds ciphertexts from before the rotation
+		if a.selectedProfile != nil {
+			if profile, err := a.GetProfile(a.selectedProfile.ID); err == nil {
+				a.selectedProfile = profile
+			}
+		}
+		log.Printf("✓ Encryption key rotated: %d credentials re-encrypted across %d profiles", result.Credentials, result.Profiles)
+	}
+	return result, nil
+}
+
+// reencryptProfile moves a profile's stored passwords and tokens from oldKey to newKey,
+// returning how many it holds
+func reencryptProfile(profile *models.ConnectionProfile, oldKey, newKey []byte) (int, error) {
+	secrets := []struct {
+		name  string
+		value *string
+	}{
+		{"source password", &profile.SourcePasswordEnc},
+		{"destination password", &profile.DestPasswordEnc},
+		{"source token", &profile.SourceTokenEnc},
+		{"destination token", &profile.DestTokenEnc},
+		{"proxy password", &profile.ProxyPasswordEnc},
+	}
+
+	count := 0
+	for _, secret := range secrets {
+		// No token is stored as ""; an empty password still has a ciphertext
+		if *secret.value == "" {
+			continue
+		}
+		reencrypted, err := crypto.Reencrypt(*secret.value, oldKey, newKey)
+		if err != nil {
+			return 0, fmt.Errorf("profile %q %s doesn't decrypt with the current key: %w", profile.Name, secret.name, err)
+		}
+		*secret.value = reencrypted
+		count++
+	}
+	return count, nil
+}
+
+// DuplicateProfile copies a profile, encrypted credentials included, under newName and
+// returns the copy's ID. Usage history isn't copied.
+func (a *App) DuplicateProfile(profileID, newName string) (string, error) {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return "", errors.New("a name is required for the copy")
+	}
+
+	var profile models.ConnectionProfile
+	if err := a.db.Where("id = ?", profileID).First(&profile).Error; err != nil {
+		return "", err
+	}
+
+	// Names are unique, so report a clash before the insert fails on it
+	var count int64
+	if err := a.db.Model(&models.ConnectionProfile{}).Where("name = ?", newName).Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "", fmt.Errorf("a profile named %q already exists", newName)
+	}
+
+	profile.ID = ""
+	profile.Name = newName
+	profile.CreatedAt = time.Time{}
+	profile.UpdatedAt = time.Time{}
+	profile.LastUsedAt = nil
+	if err := a.db.Create(&profile).Error; err != nil {
+		return "", err
+	}
+	return profile.ID, nil
+}
+
+// Profile export file identification, checked by ImportProfiles
+const (
+	profileExportFormat  = "dhis2sync-profiles"
+	profileExportVersion = 1
+)
+
+// minExportPassphraseLength guards exported credentials against a trivially guessed passphrase
+const minExportPassphraseLength = 8
+
+// ExportProfiles serializes profiles (all when profileIDs is empty) as JSON for backup
+// or for ImportProfiles on another machine. Without includeSecrets passwords and tokens
+// are left out, so the file is safe to share; with it they are encrypted under a key
+// derived from passphrase, since the local key never leaves this machine.
+func (a *App) ExportProfiles(profileIDs []string, includeSecrets bool, passphrase string) (string, error) {
+	var profiles []models.ConnectionProfile
+	query := a.db.Order("name ASC")
+	if len(profileIDs) > 0 {
+		query = query.Where("id IN ?", profileIDs)
+	}
+	if err := query.Find(&profiles).Error; err != nil {
+		return "", err
+	}
+	if len(profileIDs) > 0 && len(profiles) != len(profileIDs) {
+		return "", fmt.Errorf("%d of the %d selected profiles were not found", len(profileIDs)-len(profiles), len(profileIDs))
+	}
+
+	export := ProfileExport{
+		Format:     profileExportFormat,
+		Version:    profileExportVersion,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Profiles:   make([]ExportedProfile, 0, len(profiles)),
+	}
+
+	var localKey, exportKey []byte
+	if includeSecrets {
+		if len(passphrase) < minExportPassphraseLength {
+			return "", fmt.Errorf("a passphrase of at least %d characters is required to export credentials", minExportPassphraseLength)
+		}
+		salt, err := crypto.NewSalt()
+		if err != nil {
+			return "", err
+		}
+		if localKey, err = crypto.CurrentKey(); err != nil {
+			return "", err
+		}
+		exportKey = crypto.DeriveKey(passphrase, salt)
+		export.SecretsSalt = base64.StdEncoding.EncodeToString(salt)
+	}
+
+	for _, p := range profiles {
+		exported := ExportedProfile{
+			Name:                  p.Name,
+			Owner:                 p.Owner,
+			SourceURL:             p.SourceURL,
+			SourceUsername:        p.SourceUsername,
+			SourceAuthType:        p.SourceAuthType,
+			DestURL:               p.DestURL,
+			DestUsername:          p.DestUsername,
+			DestAuthType:          p.DestAuthType,
+			NameMatchRules:        p.NameMatchRules,
+			WriteWindow:           p.WriteWindow,
+			RateLimit:             p.RateLimit,
+			RequestTimeoutSeconds: p.RequestTimeoutSeconds,
+			ProxyURL:              p.ProxyURL,
+		}
+		if includeSecrets {
+			secrets := []struct{ local, exported *string }{
+				{&p.SourcePasswordEnc, &exported.SourcePassword},
+				{&p.DestPasswordEnc, &exported.DestPassword},
+				{&p.SourceTokenEnc, &exported.SourceToken},
+				{&p.DestTokenEnc, &exported.DestToken},
+				{&p.ProxyPasswordEnc, &exported.ProxyPassword},
+			}
+			for _, secret := range secrets {
+				if *secret.local == "" {
+					continue
+				}
+				reencrypted, err := crypto.Reencrypt(*secret.local, localKey, exportKey)
+				if err != nil {
+					return "", fmt.Errorf("failed to export credentials of profile %q: %w", p.Name, err)
+				}
+				*secret.exported = reencrypted
+			}
+		}
+		export.Profiles = append(export.Profiles, exported)
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ImportProfiles adds the profiles of an ExportProfiles file, re-encrypting any exported
+// credentials under the local key (passphrase is only needed when the file has them).
+// A profile whose name already exists is merged into it: its settings are replaced and
+// its credentials only where the file carries them. The whole file is validated before
+// anything is saved, and saved in one transaction. Returns the imported profiles' IDs.
+func (a *App) ImportProfiles(data, passphrase string) ([]string, error) {
+	localKey, err := crypto.CurrentKey()
+	if err != nil {
+		return nil, errors.New("encryption system not initialized - cannot save profiles")
+	}
+
+	export, err := parseProfileExport(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var importKey []byte
+	if export.SecretsSalt != "" {
+		salt, err := base64.StdEncoding.DecodeString(export.SecretsSalt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid profile export: bad secrets salt: %w", err)
+		}
+		if passphrase == "" {
+			return nil, errors.New("this export contains credentials; enter the passphrase it was exported with")
+		}
+		importKey = crypto.DeriveKey(passphrase, salt)
+	}
+
+	// Validate and re-encrypt everything before touching the database
+	seen := make(map[string]bool, len(export.Profiles))
+	imported := make([]models.ConnectionProfile, 0, len(export.Profiles))
+	for i, p := range export.Profiles {
+		profile, err := importedProfile(p, importKey, localKey)
+		if err != nil {
+			return nil, fmt.Errorf("profile %d (%q): %w", i+1, p.Name, err)
+		}
+		if seen[profile.Name] {
+			return nil, fmt.Errorf("profile %q appears more than once in the export", profile.Name)
+		}
+		seen[profile.Name] = true
+		imported = append(imported, *profile)
+	}
+
+	ids := make([]string, 0, len(imported))
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		for _, profile := range imported {
+			var existing models.ConnectionProfile
+			err := tx.Where("name = ?", profile.Name).First(&existing).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// Passwords are stored encrypted even when there are none
+				for _, password := range []*string{&profile.SourcePasswordEnc, &profile.DestPasswordEnc} {
+					if *password == "" {
+						if *password, err = crypto.EncryptPassword(""); err != nil {
+							return err
+						}
+					}
+				}
+				if err := tx.Create(&profile).Error; err != nil {
+					return fmt.Errorf("failed to create profile %q: %w", profile.Name, err)
+				}
+				ids = append(ids, profile.ID)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			mergeImportedProfile(&existing, &profile)
+			if err := tx.Save(&existing).Error; err != nil {
+				return fmt.Errorf("failed to update profile %q: %w", profile.Name, err)
+			}
+			ids = append(ids, existing.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✓ Imported %d profiles", len(ids))
+	return ids, nil
+}
+
+// parseProfileExport decodes an ExportProfiles file, rejecting unknown fields, other
+// formats and other layout versions
+func parseProfileExport(data string) (*ProfileExport, error) {
+	var export ProfileExport
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&export); err != nil {
+		return nil, fmt.Errorf("invalid profile export: %w", err)
+	}
+	if export.Format != profileExportFormat {
+		return nil, fmt.Errorf("not a profile export (format %q)", export.Format)
+	}
+	if export.Version != profileExportVersion {
+		return nil, fmt.Errorf("unsupported profile export version %d", export.Version)
+	}
+	return &export, nil
+}
+
+// importedProfile validates an exported profile and builds it with its credentials
+// moved from importKey to localKey; credentials left out of the export stay empty
+func importedProfile(p ExportedProfile, importKey, localKey []byte) (*models.ConnectionProfile, error) {
+	if strings.TrimSpace(p.Name) == "" {
+		return nil, errors.New("name is required")
+	}
+	sourceURL, err := api.NormalizeDHIS2URL(p.SourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source URL: %w", err)
+	}
+	destURL, err := api.NormalizeDHIS2URL(p.DestURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination URL: %w", err)
+	}
+	sourceAuthType, err := normalizeAuthType(p.SourceAuthType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source authentication: %w", err)
+	}
+	destAuthType, err := normalizeAuthType(p.DestAuthType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination authentication: %w", err)
+	}
+	if _, err := audit.ParseNameRules(p.NameMatchRules); err != nil {
+		return nil, fmt.Errorf("invalid name match rules: %w", err)
+	}
+	if _, err := writewindow.Parse(p.WriteWindow); err != nil {
+		return nil, err
+	}
+	if p.RateLimit < 0 {
+		return nil, errors.New("rate limit cannot be negative")
+	}
+	if p.RequestTimeoutSeconds < 0 {
+		return nil, errors.New("request timeout cannot be negative")
+	}
+	if _, err := api.ParseProxyURL(p.ProxyURL); err != nil {
+		return nil, err
+	}
+
+	profile := &models.ConnectionProfile{
+		Name:                  p.Name,
+		Owner:                 p.Owner,
+		SourceURL:             sourceURL,
+		SourceUsername:        p.SourceUsername,
+		SourceAuthType:        sourceAuthType,
+		DestURL:               destURL,
+		DestUsername:          p.DestUsername,
+		DestAuthType:          destAuthType,
+		NameMatchRules:        p.NameMatchRules,
+		WriteWindow:           p.WriteWindow,
+		RateLimit:             p.RateLimit,
+		RequestTimeoutSeconds: p.RequestTimeoutSeconds,
+	}
+
+	// Exports keep the proxy password apart, encrypted like the other credentials
+	proxyURL, proxyPassword := api.SplitProxyPassword(strings.TrimSpace(p.ProxyURL))
+	if proxyPassword != "" {
+		return nil, errors.New("proxy URL must not contain a password; exports carry it as proxy_password")
+	}
+	profile.ProxyURL = proxyURL
+
+	secrets := []struct {
+		name     string
+		exported string
+		target   *string
+	}{
+		{"source password", p.SourcePassword, &profile.SourcePasswordEnc},
+		{"destination password", p.DestPassword, &profile.DestPasswordEnc},
+		{"source token", p.SourceToken, &profile.SourceTokenEnc},
+		{"destination token", p.DestToken, &profile.DestTokenEnc},
+		{"proxy password", p.ProxyPassword, &profile.ProxyPasswordEnc},
+	}
+	for _, secret := range secrets {
+		if secret.exported == "" {
+			continue
+		}
+		if importKey == nil {
+			return nil, fmt.Errorf("%s is present but the export has no secrets salt", secret.name)
+		}
+		reencrypted, err := crypto.Reencrypt(secret.exported, importKey, localKey)
+		if err != nil {
+			return nil, fmt.Errorf("%s doesn't decrypt; check the passphrase: %w", secret.name, err)
+		}
+		*secret.target = reencrypted
+	}
+	return profile, nil
+}
+
+// mergeImportedProfile applies an imported profile to the existing one of the same name:
+// settings are replaced, credentials only where the import has them
+func mergeImportedProfile(existing, imported *models.ConnectionProfile) {
+	existing.Owner = imported.Owner
+	existing.SourceURL = imported.SourceURL
+	existing.SourceUsername = imported.SourceUsername
+	existing.SourceAuthType = imported.SourceAuthType
+	existing.DestURL = imported.DestURL
+	existing.DestUsername = imported.DestUsername
+	existing.DestAuthType = imported.DestAuthType
+	existing.NameMatchRules = imported.NameMatchRules
+	existing.WriteWindow = imported.WriteWindow
+	existing.RateLimit = imported.RateLimit
+	existing.RequestTimeoutSeconds = imported.RequestTimeoutSeconds
+	existing.ProxyURL = imported.ProxyURL
+	if !api.ProxyHasUser(imported.ProxyURL) {
+		// The kept proxy password has no user to go with any more
+		existing.ProxyPasswordEnc = ""
+	}
+
+	secrets := []struct{ existing, imported *string }{
+		{&existing.SourcePasswordEnc, &imported.SourcePasswordEnc},
+		{&existing.DestPasswordEnc, &imported.DestPasswordEnc},
+		{&existing.SourceTokenEnc, &imported.SourceTokenEnc},
+		{&existing.DestTokenEnc, &imported.DestTokenEnc},
+		{&existing.ProxyPasswordEnc, &imported.ProxyPasswordEnc},
+	}
+	for _, secret := range secrets {
+		if *secret.imported != "" {
+			*secret.existing = *secret.imported
+		}
+	}
+}
+
 // DeleteProfile deletes a connection profile
 func (a *App) DeleteProfile(profileID string) error {
 	return a.db.Where("id = ?", profileID).Delete(&models.ConnectionProfile{}).Error
@@ -250,11 +918,20 @@ func (a *App) SelectProfile(profileID string) error {
 	if err := a.db.Where("id = ?", profileID).First(&profile).Error; err != nil {
 		return err
 	}
+	a.touchProfile(profile.ID)
 	a.selectedProfile = &profile
 	log.Printf("Selected profile: %s", profile.Name)
 	return nil
 }
 
+// touchProfile records that a profile was just used. UpdateColumn leaves UpdatedAt alone.
+func (a *App) touchProfile(profileID string) {
+	if err := a.db.Model(&models.ConnectionProfile{}).Where("id = ?", profileID).
+		UpdateColumn("last_used_at", time.Now()).Error; err != nil {
+		log.Printf("⚠ Failed to update last used time for profile %s: %v", profileID, err)
+	}
+}
+
 // GetSelectedProfile returns the currently selected profile
 func (a *App) GetSelectedProfile() (*models.ConnectionProfile, error) {
 	if a.selectedProfile == nil {
@@ -354,7 +1031,38 @@ func (a *App) StartTransfer(req transfer.TransferRequest) (string, error) {
 		return "", fmt.Errorf("validation failed: %w", err)
 	}
 
-	return a.transferService.StartTransfer(req)
+	taskID, err := a.transferService.StartTransfer(req)
+	if err == nil {
+		a.touchProfile(req.ProfileID)
+	}
+	return taskID, err
+}
+
+// ValidateTransfer runs the pre-flight checks for a transfer without moving any data,
+// so the UI can hold back "Start" until the report is valid
+func (a *App) ValidateTransfer(req transfer.TransferRequest) (*transfer.ValidationReport, error) {
+	return a.transferService.ValidateTransfer(req)
+}
+
+// GeneratePeriods lists the DHIS2 period identifiers of a period type between two periods,
+// inclusive, e.g. MONTHLY 202401 to 202412
+func (a *App) GeneratePeriods(periodType, start, end string) ([]string, error) {
+	return transfer.GeneratePeriods(periodType, start, end)
+}
+
+// StartQuickTransfer transfers explicitly listed org units whose IDs match in source
+// and destination, skipping discovery and name matching
+func (a *App) StartQuickTransfer(req transfer.TransferRequest) (string, error) {
+	req.OrgUnitSelectionMode = "selected" // Validates the explicit org unit IDs
+	if err := transfer.ValidateTransferRequest(&req); err != nil {
+		return "", fmt.Errorf("validation failed: %w", err)
+	}
+
+	taskID, err := a.transferService.StartQuickTransfer(req)
+	if err == nil {
+		a.touchProfile(req.ProfileID)
+	}
+	return taskID, err
 }
 
 // GetTransferProgress retrieves transfer progress
@@ -362,6 +1070,11 @@ func (a *App) GetTransferProgress(taskID string) (*transfer.TransferProgress, er
 	return a.transferService.GetTransferProgress(taskID)
 }
 
+// CancelTransfer stops a running transfer; submitted import jobs are left running server-side
+func (a *App) CancelTransfer(taskID string) error {
+	return a.transferService.CancelTransfer(taskID)
+}
+
 // ResolveUnmappedValues handles user's decision on unmapped data values
 // action: "create_mappings", "skip_unmapped", or "cancel"
 // newMappings: map of source element ID → destination element ID (only used for "create_mappings" action)
@@ -384,6 +1097,102 @@ func (a *App) ResolveUnmappedValues(taskID string, action string, newMappings ma
 	}
 }
 
+// ResumeAsyncPolling resumes polling of a transfer's persisted async import jobs after a restart
+func (a *App) ResumeAsyncPolling(taskID string) error {
+	return a.transferService.ResumeAsyncPolling(taskID)
+}
+
+// PreviewTransferByElement totals the values a transfer would send per destination data element, without writing
+func (a *App) PreviewTransferByElement(req transfer.TransferRequest) (*transfer.ElementPreviewResponse, error) {
+	return a.transferService.PreviewByElement(req)
+}
+
+// StartDeleteValues deletes selected data elements' values for a dataset, period and org units
+// (preview with dry_run first; deleting requires confirm)
+func (a *App) StartDeleteValues(req transfer.DeleteValuesRequest) (string, error) {
+	if err := transfer.ValidateDeleteValuesRequest(&req); err != nil {
+		return "", fmt.Errorf("validation failed: %w", err)
+	}
+	return a.transferService.StartDeleteValues(req)
+}
+
+// StageTransfer fetches and maps a transfer's values into the staging table for review instead of importing them
+func (a *App) StageTransfer(req transfer.TransferRequest) (string, error) {
+	if err := transfer.ValidateTransferRequest(&req); err != nil {
+		return "", fmt.Errorf("validation failed: %w", err)
+	}
+
+	stagingID, err := a.transferService.StageTransfer(req)
+	if err == nil {
+		a.touchProfile(req.ProfileID)
+	}
+	return stagingID, err
+}
+
+// GetStagedValues returns one page (1-based) of a staged transfer's values
+func (a *App) GetStagedValues(stagingID string, page, pageSize int) (*transfer.StagedValuesPage, error) {
+	return a.transferService.GetStagedValues(stagingID, page, pageSize)
+}
+
+// UpdateStagedValue corrects a staged value before it is pushed
+func (a *App) UpdateStagedValue(stagingID, valueID, value, comment string) error {
+	return a.transferService.UpdateStagedValue(stagingID, valueID, value, comment)
+}
+
+// RemoveStagedValue drops a staged value so it is not pushed
+func (a *App) RemoveStagedValue(stagingID, valueID string) error {
+	return a.transferService.RemoveStagedValue(stagingID, valueID)
+}
+
+// PushStaged imports a staged transfer's reviewed values and returns the push task ID
+func (a *App) PushStaged(stagingID string) (string, error) {
+	return a.transferService.PushStaged(stagingID)
+}
+
+// CompareDatasets fetches a source and destination dataset side by side and lines up their data elements
+func (a *App) CompareDatasets(profileID, sourceDatasetID, destDatasetID string) (*transfer.DatasetComparison, error) {
+	return a.transferService.CompareDatasets(profileID, sourceDatasetID, destDatasetID)
+}
+
+// AssignDatasetToOrgUnits assigns a destination dataset to org units a transfer skipped as unassigned
+func (a *App) AssignDatasetToOrgUnits(profileID, datasetID string, orgUnitIDs []string) error {
+	return a.transferService.AssignDatasetToOrgUnits(profileID, datasetID, orgUnitIDs)
+}
+
+// ExportUnmatchedOrgUnits saves the transfer's unmatched source org units as CSV or JSON
+func (a *App) ExportUnmatchedOrgUnits(taskID, format string) (string, error) {
+	data, err := a.transferService.ExportUnmatchedOrgUnits(taskID, format)
+	if err != nil {
+		return "", err
+	}
+
+	ext := strings.ToLower(format)
+	savePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           fmt.Sprintf("Save unmatched org units (%s)", strings.ToUpper(ext)),
+		DefaultFilename: fmt.Sprintf("unmatched-orgunits-%s.%s", time.Now().Format("20060102-150405"), ext),
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: strings.ToUpper(ext),
+				Pattern:     fmt.Sprintf("*.%s", ext),
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if savePath == "" {
+		// User cancelled dialog
+		return "", nil
+	}
+
+	if err := os.WriteFile(savePath, []byte(data), 0644); err != nil {
+		return "", err
+	}
+
+	return savePath, nil
+}
+
 // GetOrgUnitTree retrieves org unit hierarchy for transfer selection
 func (a *App) GetOrgUnitTree(profileID, sourceOrDest, rootID string, maxDepth int) (*transfer.OrgUnitTreeResponse, error) {
 	return a.transferService.GetOrgUnitTree(profileID, sourceOrDest, rootID, maxDepth)
@@ -411,8 +1220,15 @@ func (a *App) GetOrgUnitChildren(profileID string, sourceOrDest string, parentID
 
 // GetOrgUnitsByLevelBatch fetches all org units grouped by level in parallel
 // This is MUCH faster than fetching children per-parent for large hierarchies
-func (a *App) GetOrgUnitsByLevelBatch(profileID string, sourceOrDest string, maxLevel int) (map[int][]transfer.OrgUnit, error) {
-	return a.transferService.GetOrgUnitsByLevelBatch(profileID, sourceOrDest, maxLevel)
+// Levels that failed are listed in the result's errors. Pass a batchID to be able to
+// cancel the batch with CancelOrgUnitsByLevelBatch.
+func (a *App) GetOrgUnitsByLevelBatch(profileID string, sourceOrDest string, maxLevel int, batchID string) (*transfer.OrgUnitBatchResult, error) {
+	return a.transferService.GetOrgUnitsByLevelBatch(profileID, sourceOrDest, maxLevel, batchID)
+}
+
+// CancelOrgUnitsByLevelBatch aborts a running GetOrgUnitsByLevelBatch
+func (a *App) CancelOrgUnitsByLevelBatch(batchID string) error {
+	return a.transferService.CancelOrgUnitBatch(batchID)
 }
 
 // Metadata Service Methods
@@ -424,7 +1240,13 @@ func (a *App) GetMetadataSummary(profileID string, types []metadata.MetadataType
 
 // StartMetadataDiff initiates a background metadata comparison
 func (a *App) StartMetadataDiff(profileID string, types []metadata.MetadataType) (string, error) {
-	return a.metadataService.StartDiff(profileID, types)
+	return a.metadataService.StartDiff(profileID, types, false)
+}
+
+// StartMetadataCountsDiff initiates a background metadata comparison that reports
+// per-type counts instead of the full lists
+func (a *App) StartMetadataCountsDiff(profileID string, types []metadata.MetadataType) (string, error) {
+	return a.metadataService.StartDiff(profileID, types, true)
 }
 
 // GetMetadataDiffProgress retrieves metadata diff progress
@@ -432,6 +1254,40 @@ func (a *App) GetMetadataDiffProgress(taskID string) (*metadata.DiffProgress, er
 	return a.metadataService.GetDiffProgress(taskID)
 }
 
+// ExportMetadataDiff saves a completed metadata diff's findings as CSV or JSON
+func (a *App) ExportMetadataDiff(taskID, format string) (string, error) {
+	data, err := a.metadataService.ExportDiffResults(taskID, format)
+	if err != nil {
+		return "", err
+	}
+
+	ext := strings.ToLower(format)
+	savePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           fmt.Sprintf("Save metadata diff (%s)", strings.ToUpper(ext)),
+		DefaultFilename: fmt.Sprintf("metadata-diff-%s.%s", time.Now().Format("20060102-150405"), ext),
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: strings.ToUpper(ext),
+				Pattern:     fmt.Sprintf("*.%s", ext),
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if savePath == "" {
+		// User cancelled dialog
+		return "", nil
+	}
+
+	if err := os.WriteFile(savePath, []byte(data), 0644); err != nil {
+		return "", err
+	}
+
+	return savePath, nil
+}
+
 // SaveMetadataMappings persists metadata mapping pairs
 func (a *App) SaveMetadataMappings(profileID string, pairs []metadata.MappingPair) (*metadata.SaveMappingsResponse, error) {
 	return a.metadataService.SaveMappings(profileID, pairs)
@@ -442,26 +1298,44 @@ func (a *App) GetMetadataMappings(profileID string) map[metadata.MetadataType]ma
 	return a.metadataService.GetMappings(profileID)
 }
 
-// BuildMetadataPayloadPreview generates a metadata import payload preview
-func (a *App) BuildMetadataPayloadPreview(profileID string, types []metadata.MetadataType, mappings map[metadata.MetadataType]map[string]string) (*metadata.PayloadPreviewResponse, error) {
-	return a.metadataService.BuildPayloadPreview(profileID, types, mappings)
+// BuildMetadataPayloadPreview generates a metadata import payload preview; preserveSharing
+// carries the source objects' sharing over, with user groups remapped through the
+// userGroups mappings
+func (a *App) BuildMetadataPayloadPreview(profileID string, types []metadata.MetadataType, mappings map[metadata.MetadataType]map[string]string, preserveSharing bool) (*metadata.PayloadPreviewResponse, error) {
+	return a.metadataService.BuildPayloadPreview(profileID, types, mappings, preserveSharing)
+}
+
+// StartMetadataPayloadBuild builds a metadata import payload preview in the background,
+// reporting progress on metadata:payload:{taskID}
+func (a *App) StartMetadataPayloadBuild(profileID string, types []metadata.MetadataType, mappings map[metadata.MetadataType]map[string]string, preserveSharing bool) (string, error) {
+	return a.metadataService.StartBuildPayload(profileID, types, mappings, preserveSharing)
 }
 
-// MetadataDryRun performs a dry-run metadata import
-func (a *App) MetadataDryRun(profileID string, payload map[metadata.MetadataType][]map[string]interface{}, importStrategy, atomicMode string) (*metadata.ImportReport, error) {
-	return a.metadataService.DryRun(profileID, payload, importStrategy, atomicMode)
+// GetMetadataPayloadBuildProgress retrieves a payload build's progress and, once completed, its preview
+func (a *App) GetMetadataPayloadBuildProgress(taskID string) (*metadata.PayloadProgress, error) {
+	return a.metadataService.GetBuildPayloadProgress(taskID)
 }
 
-// MetadataApply performs an actual metadata import
-func (a *App) MetadataApply(profileID string, payload map[metadata.MetadataType][]map[string]interface{}, importStrategy, atomicMode string) (*metadata.ImportReport, error) {
-	return a.metadataService.Apply(profileID, payload, importStrategy, atomicMode)
+// MetadataDryRun performs a dry-run metadata import; preserveSharing is as for MetadataApply
+func (a *App) MetadataDryRun(profileID string, payload map[metadata.MetadataType][]map[string]interface{}, importStrategy, atomicMode string, preserveSharing bool) (*metadata.ImportReport, error) {
+	return a.metadataService.DryRun(profileID, payload, importStrategy, atomicMode, preserveSharing)
+}
+
+// MetadataApply performs an actual metadata import; pass a previous report's apply ID to resume it.
+// The payload's sharing is only imported with preserveSharing.
+func (a *App) MetadataApply(profileID, applyID string, payload map[metadata.MetadataType][]map[string]interface{}, importStrategy, atomicMode string, preserveSharing bool) (*metadata.ImportReport, error) {
+	return a.metadataService.Apply(profileID, applyID, payload, importStrategy, atomicMode, preserveSharing)
 }
 
 // Completeness Service Methods
 
 // StartCompletenessAssessment initiates a background completeness assessment
 func (a *App) StartCompletenessAssessment(req completeness.AssessmentRequest) (string, error) {
-	return a.completenessService.StartAssessment(req)
+	taskID, err := a.completenessService.StartAssessment(req)
+	if err == nil {
+		a.touchProfile(req.ProfileID)
+	}
+	return taskID, err
 }
 
 // GetCompletenessAssessmentProgress retrieves assessment progress
@@ -469,9 +1343,47 @@ func (a *App) GetCompletenessAssessmentProgress(taskID string) (*completeness.As
 	return a.completenessService.GetAssessmentProgress(taskID)
 }
 
+// StartCompletenessComparison compares completeness on source vs destination for the same request
+func (a *App) StartCompletenessComparison(req completeness.AssessmentRequest) (string, error) {
+	taskID, err := a.completenessService.StartComparison(req)
+	if err == nil {
+		a.touchProfile(req.ProfileID)
+	}
+	return taskID, err
+}
+
+// BuildTransferFromAssessment prepares (without starting) a transfer of the org units a
+// completed source assessment found compliant in every period, for review in the transfer
+// form. threshold <= 0 uses the assessment's own compliance threshold. The destination
+// dataset defaults to the assessed dataset.
+func (a *App) BuildTransferFromAssessment(assessmentTaskID string, threshold float64) (*transfer.TransferRequest, error) {
+	orgUnits, assessed, err := a.completenessService.CompliantOrgUnits(assessmentTaskID, threshold)
+	if err != nil {
+		return nil, err
+	}
+	if len(orgUnits) == 0 {
+		return nil, fmt.Errorf("no org units met the compliance threshold in every assessed period")
+	}
+
+	return &transfer.TransferRequest{
+		ProfileID:            assessed.ProfileID,
+		SourceDatasetID:      assessed.DatasetID,
+		DestDatasetID:        assessed.DatasetID,
+		Periods:              append([]string(nil), assessed.Periods...),
+		OrgUnitSelectionMode: "selected",
+		OrgUnitIDs:           orgUnits,
+	}, nil
+}
+
 // ExportCompletenessResults exports assessment results in JSON or CSV format
 func (a *App) ExportCompletenessResults(taskID, format string, limit int) (string, error) {
-	data, err := a.completenessService.ExportResults(taskID, format, limit)
+	return a.ExportCompletenessResultsWithColumns(taskID, format, limit, nil)
+}
+
+// ExportCompletenessResultsWithColumns exports assessment results like ExportCompletenessResults;
+// columns optionally picks, orders and renames the CSV columns, empty keeps the default layout
+func (a *App) ExportCompletenessResultsWithColumns(taskID, format string, limit int, columns []completeness.CSVColumn) (string, error) {
+	data, err := a.completenessService.ExportResults(taskID, format, limit, columns)
 	if err != nil {
 		return "", err
 	}
@@ -486,10 +1398,18 @@ func (a *App) ExportCompletenessResults(taskID, format string, limit int) (strin
 	}
 
 	ext := strings.ToLower(format)
-	if ext != "json" && ext != "csv" {
+	if ext != "json" && ext != "csv" && ext != "xlsx" {
 		return "", fmt.Errorf("unsupported format: %s", format)
 	}
 
+	content := []byte(data)
+	if ext == "xlsx" {
+		// Workbooks come back base64-encoded
+		if content, err = base64.StdEncoding.DecodeString(data); err != nil {
+			return "", fmt.Errorf("failed to decode workbook: %w", err)
+		}
+	}
+
 	dialogOptions := runtime.SaveDialogOptions{
 		Title:           fmt.Sprintf("Save completeness results (%s)", strings.ToUpper(ext)),
 		DefaultFilename: fmt.Sprintf("completeness-%s.%s", time.Now().Format("20060102-150405"), ext),
@@ -515,7 +1435,7 @@ func (a *App) ExportCompletenessResults(taskID, format string, limit int) (strin
 		return "", nil
 	}
 
-	if err := os.WriteFile(savePath, []byte(data), 0644); err != nil {
+	if err := os.WriteFile(savePath, content, 0644); err != nil {
 		return "", err
 	}
 
@@ -531,6 +1451,11 @@ func (a *App) StartCompletenessBulkAction(req completeness.BulkActionRequest) (s
 	return a.completenessService.StartBulkAction(req)
 }
 
+// RetryFailedCompletenessBulkAction re-attempts only the failed items of a finished bulk action
+func (a *App) RetryFailedCompletenessBulkAction(taskID string) (string, error) {
+	return a.completenessService.RetryFailedBulkAction(taskID)
+}
+
 // GetCompletenessBulkActionProgress retrieves bulk action progress
 func (a *App) GetCompletenessBulkActionProgress(taskID string) (*completeness.BulkActionProgress, error) {
 	return a.completenessService.GetBulkActionProgress(taskID)
@@ -560,6 +1485,16 @@ func (a *App) StartTrackerTransfer(req tracker.TransferRequest) (string, error)
 	return a.trackerService.StartTransfer(req)
 }
 
+// PreviewTrackerTEIs previews a program's tracked entities with their enrollments and events
+func (a *App) PreviewTrackerTEIs(req tracker.PreviewRequest) (*tracker.TEIPreviewResponse, error) {
+	return a.trackerService.PreviewTEIs(req)
+}
+
+// StartTrackerTEITransfer initiates a background tracked entity transfer
+func (a *App) StartTrackerTEITransfer(req tracker.TransferRequest) (string, error) {
+	return a.trackerService.StartTEITransfer(req)
+}
+
 // GetTrackerTransferProgress retrieves transfer progress
 func (a *App) GetTrackerTransferProgress(taskID string) (*tracker.TransferProgress, error) {
 	return a.trackerService.GetTransferProgress(taskID)
@@ -584,6 +1519,30 @@ func (a *App) DeleteScheduledJob(jobID string) error {
 	return a.schedulerService.DeleteJob(jobID)
 }
 
+// ListJobRuns returns a scheduled job's most recent runs, newest first
+func (a *App) ListJobRuns(jobID string, limit int) ([]models.JobRun, error) {
+	return a.schedulerService.ListJobRuns(jobID, limit)
+}
+
+// ====================================================================================
+// NOTIFICATIONS
+// ====================================================================================
+
+// ListNotifications returns task notifications newest first (limit <= 0 means 50)
+func (a *App) ListNotifications(unreadOnly bool, limit int) ([]models.Notification, error) {
+	return notifications.List(a.db, unreadOnly, limit)
+}
+
+// MarkNotificationRead marks a single notification as read
+func (a *App) MarkNotificationRead(notificationID string) error {
+	return notifications.MarkRead(a.db, []string{notificationID})
+}
+
+// MarkAllNotificationsRead marks every unread notification as read
+func (a *App) MarkAllNotificationsRead() error {
+	return notifications.MarkRead(a.db, nil)
+}
+
 // ====================================================================================
 // REQUEST/RESPONSE TYPES
 // ====================================================================================
@@ -608,7 +1567,62 @@ type CreateProfileRequest struct {
 	SourcePassword string `json:"source_password"` // Plain text, will be encrypted
 	DestURL        string `json:"dest_url"`
 	DestUsername   string `json:"dest_username"`
-	DestPassword   string `json:"dest_password"` // Plain text, will be encrypted
+	DestPassword   string `json:"dest_password"`    // Plain text, will be encrypted
+	NameMatchRules string `json:"name_match_rules"` // Optional, one suffix or "re:<regex>" per line
+	WriteWindow    string `json:"write_window"`     // Optional JSON {"start":"18:00","end":"06:00","days":["mon",...]}
+
+	// SourceAuthType/DestAuthType are "basic" (default) or "token"; with "token" the
+	// *Token field holds a personal access token and username/password are not used
+	SourceAuthType string `json:"source_auth_type"`
+	SourceToken    string `json:"source_token"` // Plain text, will be encrypted
+	DestAuthType   string `json:"dest_auth_type"`
+	DestToken      string `json:"dest_token"` // Plain text, will be encrypted
+
+	RateLimit             int    `json:"rate_limit"`              // Optional max requests per second per instance, 0 = unlimited
+	RequestTimeoutSeconds int    `json:"request_timeout_seconds"` // Optional per-request timeout, 0 = default (10 minutes)
+	ProxyURL              string `json:"proxy_url"`               // Optional proxy for both instances, empty uses HTTP(S)_PROXY; its password is stored encrypted
+}
+
+// KeyRotationResponse reports a RotateEncryptionKey run
+type KeyRotationResponse struct {
+	DryRun      bool `json:"dry_run"`
+	Profiles    int  `json:"profiles"`    // Profiles checked or re-encrypted
+	Credentials int  `json:"credentials"` // Stored passwords and tokens among them
+}
+
+// ProfileExport is the file ExportProfiles writes and ImportProfiles reads
+type ProfileExport struct {
+	Format     string `json:"format"`  // Always "dhis2sync-profiles"
+	Version    int    `json:"version"` // File layout version, currently 1
+	ExportedAt string `json:"exported_at"`
+
+	// SecretsSalt is set when credentials are included: they're encrypted under a key
+	// derived from the export passphrase with this (base64) salt
+	SecretsSalt string            `json:"secrets_salt,omitempty"`
+	Profiles    []ExportedProfile `json:"profiles"`
+}
+
+// ExportedProfile is a connection profile as exported; credentials are only present
+// when the export includes them, encrypted under the passphrase key
+type ExportedProfile struct {
+	Name                  string `json:"name"`
+	Owner                 string `json:"owner,omitempty"`
+	SourceURL             string `json:"source_url"`
+	SourceUsername        string `json:"source_username"`
+	SourceAuthType        string `json:"source_auth_type,omitempty"`
+	SourcePassword        string `json:"source_password,omitempty"`
+	SourceToken           string `json:"source_token,omitempty"`
+	DestURL               string `json:"dest_url"`
+	DestUsername          string `json:"dest_username"`
+	DestAuthType          string `json:"dest_auth_type,omitempty"`
+	DestPassword          string `json:"dest_password,omitempty"`
+	DestToken             string `json:"dest_token,omitempty"`
+	ProxyPassword         string `json:"proxy_password,omitempty"`
+	NameMatchRules        string `json:"name_match_rules,omitempty"`
+	WriteWindow           string `json:"write_window,omitempty"`
+	RateLimit             int    `json:"rate_limit,omitempty"`
+	RequestTimeoutSeconds int    `json:"request_timeout_seconds,omitempty"`
+	ProxyURL              string `json:"proxy_url,omitempty"`
 }
 
 // TestConnectionRequest represents a connection test request
@@ -616,23 +1630,86 @@ type TestConnectionRequest struct {
 	URL      string `json:"url"`
 	Username string `json:"username"`
 	Password string `json:"password"`
+	AuthType string `json:"auth_type"` // "basic" (default) or "token"
+	Token    string `json:"token"`     // Personal access token for "token" auth
+	// CompareVersion is the DHIS2 version of the other side of the profile, as reported
+	// by its own test; when set, a large gap to this server's version is warned about
+	CompareVersion string `json:"compare_version,omitempty"`
+	// RequestTimeoutSeconds is the profile's timeout being entered; 0 uses the default
+	RequestTimeoutSeconds int `json:"request_timeout_seconds,omitempty"`
+	// ProxyURL is the profile's proxy being entered; empty uses HTTP(S)_PROXY
+	ProxyURL string `json:"proxy_url,omitempty"`
+}
+
+// maxReleasesApart is how many DHIS2 releases source and destination may be apart
+// before TestConnection warns that metadata and tracker payloads may not carry over
+const maxReleasesApart = 1
+
+// ServerInfo describes the DHIS2 instance a connection test reached
+type ServerInfo struct {
+	Version    string `json:"version"`
+	Revision   string `json:"revision,omitempty"`
+	ServerDate string `json:"server_date,omitempty"`
+	BuildTime  string `json:"build_time,omitempty"`
+	// Major and Minor are parsed from Version for feature gating; zero if it doesn't parse
+	Major int `json:"major"`
+	Minor int `json:"minor"`
 }
 
 // TestConnectionResponse represents the test result
 type TestConnectionResponse struct {
-	Success    bool   `json:"success"`
-	Error      string `json:"error,omitempty"`
-	UserName   string `json:"user_name,omitempty"`
-	ServerInfo string `json:"server_info,omitempty"`
+	Success    bool        `json:"success"`
+	Error      string      `json:"error,omitempty"`
+	UserName   string      `json:"user_name,omitempty"`
+	ServerInfo *ServerInfo `json:"server_info,omitempty"`
+	// DetectedURL is the base URL including the instance's contextPath, set when it
+	// differs from the URL entered; save it on the profile instead
+	DetectedURL string `json:"detected_url,omitempty"`
+	Warning     string `json:"warning,omitempty"`
 }
 
 // TestConnection tests a DHIS2 connection without saving to database
 func (a *App) TestConnection(req TestConnectionRequest) TestConnectionResponse {
-	// Import the API client
-	client := api.NewClient(req.URL, req.Username, req.Password)
+	enteredURL, err := api.NormalizeDHIS2URL(req.URL)
+	if err != nil {
+		return TestConnectionResponse{
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+
+	authType, err := normalizeAuthType(req.AuthType)
+	if err != nil {
+		return TestConnectionResponse{Success: false, Error: err.Error()}
+	}
+	secret := req.Password
+	if authType == api.AuthToken {
+		if req.Token == "" {
+			return TestConnectionResponse{Success: false, Error: "A personal access token is required for token authentication"}
+		}
+		secret = req.Token
+	}
+
+	client := api.NewClientWithAuth(req.URL, authType, req.Username, secret)
+	client.SetTimeoutSeconds(req.RequestTimeoutSeconds)
+	if err := client.SetProxy(req.ProxyURL); err != nil {
+		return TestConnectionResponse{Success: false, Error: err.Error()}
+	}
 
 	// Test connection by calling /api/me.json
 	resp, err := client.Get("api/me.json", nil)
+
+	// A 404 or an HTML page usually means DHIS2 lives under a subpath the URL omits
+	detectedURL := ""
+	if err == nil && (resp.StatusCode() == 404 || (resp.IsSuccess() && !strings.Contains(resp.Header().Get("Content-Type"), "json"))) {
+		if base, detectErr := client.DetectContextPath(); detectErr == nil && base != enteredURL {
+			if retry, retryErr := client.Get("api/me.json", nil); retryErr == nil {
+				resp = retry
+				detectedURL = base
+				log.Printf("Detected DHIS2 context path: %s (entered %s)", base, enteredURL)
+			}
+		}
+	}
 	if err != nil {
 		return TestConnectionResponse{
 			Success: false,
@@ -646,6 +1723,9 @@ func (a *App) TestConnection(req TestConnectionRequest) TestConnectionResponse {
 		switch resp.StatusCode() {
 		case 401:
 			errorMsg = "Invalid credentials (wrong username or password)"
+			if authType == api.AuthToken {
+				errorMsg = "Invalid credentials (token is wrong, expired or not allowed from this address)"
+			}
 		case 404:
 			errorMsg = "Server not found or invalid URL"
 		case 403:
@@ -683,15 +1763,58 @@ func (a *App) TestConnection(req TestConnectionRequest) TestConnectionResponse {
 			userName = "Connected User"
 		}
 
-		return TestConnectionResponse{
+		return withServerInfo(withDetectedURL(TestConnectionResponse{
 			Success:  true,
 			UserName: userName,
-		}
+		}, detectedURL), client, req.CompareVersion)
 	}
 
 	// Connection succeeded but couldn't parse user info
-	return TestConnectionResponse{
+	return withServerInfo(withDetectedURL(TestConnectionResponse{
 		Success:  true,
 		UserName: "Connected User",
+	}, detectedURL), client, req.CompareVersion)
+}
+
+// withServerInfo adds the instance's api/system/info details to a successful test. The
+// credentials already work, so failing to read them only costs a warning.
+func withServerInfo(resp TestConnectionResponse, client *api.Client, compareVersion string) TestConnectionResponse {
+	info, err := client.SystemInfo()
+	if err != nil {
+		log.Printf("⚠ Could not read system info from %s: %v", client.BaseURL(), err)
+		return addWarning(resp, "Could not read the server's DHIS2 version")
+	}
+
+	resp.ServerInfo = &ServerInfo{
+		Version:    info.Version,
+		Revision:   info.Revision,
+		ServerDate: info.ServerDate,
+		BuildTime:  info.BuildTime,
+	}
+	resp.ServerInfo.Major, resp.ServerInfo.Minor, _ = api.ParseVersion(info.Version)
+
+	if compareVersion != "" {
+		if apart, ok := api.ReleasesApart(info.Version, compareVersion); ok && apart > maxReleasesApart {
+			resp = addWarning(resp, fmt.Sprintf("This server runs DHIS2 %s but the other side runs %s; metadata and tracker transfers between releases this far apart may fail", info.Version, compareVersion))
+		}
+	}
+	return resp
+}
+
+// addWarning appends a warning to any the response already carries
+func addWarning(resp TestConnectionResponse, warning string) TestConnectionResponse {
+	if resp.Warning != "" {
+		resp.Warning += "; "
+	}
+	resp.Warning += warning
+	return resp
+}
+
+// withDetectedURL tells the user the connection only worked at a corrected base URL
+func withDetectedURL(resp TestConnectionResponse, detectedURL string) TestConnectionResponse {
+	if detectedURL != "" {
+		resp.DetectedURL = detectedURL
+		resp = addWarning(resp, fmt.Sprintf("DHIS2 was found at %s - use this URL for the profile", detectedURL))
 	}
+	return resp
 }
@@ -4,6 +4,7 @@
  */
 
 import { generatePeriods } from './utils/periods';
+import { toast } from './toast';
 
 window.COMPLETENESS_MOD_LOADED = true;
 
@@ -260,12 +261,31 @@ export class CompletenessModule {
         try {
             console.log('[Completeness] Fetching org units by level (batch)...');
 
+            // A batch still loading for another profile or instance is no longer needed
+            if (this.ouBatchId) {
+                App.CancelOrgUnitsByLevelBatch(this.ouBatchId).catch(() => { });
+            }
+            const batchId = `ou-batch-${Date.now()}`;
+            this.ouBatchId = batchId;
+
             // Use the new batch method - fetches all levels in parallel
-            const orgUnitsByLevel = await App.GetOrgUnitsByLevelBatch(
+            const batch = await App.GetOrgUnitsByLevelBatch(
                 this.app.currentProfile.id,
                 this.currentInstance,
-                10 // max levels
+                10, // max levels
+                batchId
             );
+            if (this.ouBatchId === batchId) {
+                this.ouBatchId = null;
+            }
+            const orgUnitsByLevel = batch?.levels;
+
+            // Levels that failed would otherwise look like levels with no org units
+            const failedLevels = Object.entries(batch?.errors || {});
+            if (failedLevels.length > 0) {
+                console.warn('[Completeness] Some org unit levels failed to load:', batch.errors);
+                toast.warning(`Failed to load org unit level(s) ${failedLevels.map(([level]) => level).join(', ')}: ${failedLevels[0][1]}`);
+            }
 
             if (!orgUnitsByLevel || Object.keys(orgUnitsByLevel).length === 0) {
                 console.warn('[Completeness] No org units returned from batch fetch');
@@ -31,6 +31,7 @@ class DHIS2SyncApp {
         this.completenessPeriods = new Set();
         this.completenessRunning = false;
         this.profileFormStep = 1;
+        this.metadataApplyId = null; // Unfinished metadata apply to resume
 
         // Initialize components
         this.scheduler = new SchedulerManager('scheduler-content');
@@ -550,6 +551,10 @@ class DHIS2SyncApp {
                                                     <i class="bi bi-check2-circle me-1"></i>Apply (After Dry-Run)
                                                 </button>
                                             </div>
+                                            <div class="form-check mt-2">
+                                                <input class="form-check-input" type="checkbox" id="md_preserve_sharing">
+                                                <label class="form-check-label" for="md_preserve_sharing">Preserve source sharing (user groups are remapped; unknown groups are left out)</label>
+                                            </div>
                                         </div>
                                     </div>
                                 </div>
@@ -933,6 +938,7 @@ class DHIS2SyncApp {
             // Fetch profiles to get the selected one
             const profiles = await App.ListProfiles();
             this.currentProfile = profiles.find(p => String(p.id) === String(id));
+            this.metadataApplyId = null;
 
             if (!this.currentProfile) {
                 throw new Error("Selected profile not found in list");
@@ -1965,19 +1971,22 @@ class DHIS2SyncApp {
      */
     async previewMetadataPayload() {
         const scope = Array.from(document.querySelectorAll('#metadata-scope-form input[type="checkbox"]:checked')).map(cb => cb.value);
+        const preserveSharing = document.getElementById('md_preserve_sharing')?.checked || false;
         const progressDiv = document.getElementById('metadata-progress');
 
         progressDiv.innerHTML = '<div class="alert alert-info"><i class="bi bi-eye me-2"></i>Building payload preview...</div>';
 
         try {
-            const preview = await App.BuildMetadataPayloadPreview(this.currentProfile.id, scope, {});
+            const preview = await App.BuildMetadataPayloadPreview(this.currentProfile.id, scope, {}, preserveSharing);
             const summary = JSON.stringify(preview.counts || {}, null, 2);
             const snippet = JSON.stringify(preview.payload || {}, null, 2).slice(0, 4000);
+            const warnings = (preview.warnings || []).map(w => `<div class="alert alert-warning py-1 mb-1">${this.escapeHtml(w)}</div>`).join('');
 
             document.getElementById('metadata-results').innerHTML = `
             <div class="card mt-3" >
                     <div class="card-header"><strong>Payload Preview</strong></div>
                     <div class="card-body">
+                        ${warnings}
                         <div class="mb-2"><strong>Counts by type</strong></div>
                         <pre style="white-space: pre-wrap;">${this.escapeHtml(summary)}</pre>
                         <div class="mb-2"><strong>Payload (truncated)</strong></div>
@@ -1995,13 +2004,14 @@ class DHIS2SyncApp {
      */
     async runMetadataDryRun() {
         const scope = Array.from(document.querySelectorAll('#metadata-scope-form input[type="checkbox"]:checked')).map(cb => cb.value);
+        const preserveSharing = document.getElementById('md_preserve_sharing')?.checked || false;
         const progressDiv = document.getElementById('metadata-progress');
 
         progressDiv.innerHTML = '<div class="alert alert-info"><i class="bi bi-hourglass-split me-2"></i>Running dry-run...</div>';
 
         try {
-            const preview = await App.BuildMetadataPayloadPreview(this.currentProfile.id, scope, {});
-            const report = await App.MetadataDryRun(this.currentProfile.id, scope, preview.payload, {});
+            const preview = await App.BuildMetadataPayloadPreview(this.currentProfile.id, scope, {}, preserveSharing);
+            const report = await App.MetadataDryRun(this.currentProfile.id, preview.payload, '', '', preserveSharing);
             document.getElementById('metadata-results').innerHTML = this.renderImportReport('Dry-Run Import Report', report);
         } catch (error) {
             console.error('Dry-run error:', error);
@@ -2018,13 +2028,16 @@ class DHIS2SyncApp {
         }
 
         const scope = Array.from(document.querySelectorAll('#metadata-scope-form input[type="checkbox"]:checked')).map(cb => cb.value);
+        const preserveSharing = document.getElementById('md_preserve_sharing')?.checked || false;
         const progressDiv = document.getElementById('metadata-progress');
 
         progressDiv.innerHTML = '<div class="alert alert-warning"><i class="bi bi-exclamation-triangle me-2"></i>Applying metadata... This may take a while.</div>';
 
         try {
-            const preview = await App.BuildMetadataPayloadPreview(this.currentProfile.id, scope, {});
-            const report = await App.MetadataApply(this.currentProfile.id, scope, preview.payload, {});
+            const preview = await App.BuildMetadataPayloadPreview(this.currentProfile.id, scope, {}, preserveSharing);
+            // Passing the previous attempt's apply ID resumes it, skipping objects it already imported
+            const report = await App.MetadataApply(this.currentProfile.id, this.metadataApplyId || '', preview.payload, '', '', preserveSharing);
+            this.metadataApplyId = report.status === 'OK' ? null : report.apply_id;
             document.getElementById('metadata-results').innerHTML = this.renderImportReport('Apply Import Report', report);
             toast.success('Metadata applied successfully!');
         } catch (error) {
@@ -0,0 +1,110 @@
+// Package apitest provides a fake DHIS2 server for testing services that talk
+// to the API through api.Client.
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"dhis2sync-desktop/internal/api"
+)
+
+// Server is an httptest.Server that routes requests by path and records what it received
+type Server struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	hits   map[string]int
+	bodies map[string][][]byte
+}
+
+// NewServer starts a server serving routes keyed by URL path ("/api/dataValueSets")
+// or method and path ("POST /api/dataValueSets"). Unknown paths get a 404.
+// The server is closed when the test finishes.
+func NewServer(t *testing.T, routes map[string]http.HandlerFunc) *Server {
+	t.Helper()
+
+	s := &Server{
+		hits:   make(map[string]int),
+		bodies: make(map[string][][]byte),
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body)) // Leave the body readable for handlers
+
+		s.mu.Lock()
+		s.hits[r.URL.Path]++
+		s.bodies[r.URL.Path] = append(s.bodies[r.URL.Path], body)
+		s.mu.Unlock()
+
+		handler, ok := routes[r.Method+" "+r.URL.Path]
+		if !ok {
+			handler, ok = routes[r.URL.Path]
+		}
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Client returns an api.Client pointed at the server
+func (s *Server) Client() *api.Client {
+	return api.NewClient(s.URL, "admin", "district")
+}
+
+// Hits returns how many requests were made to a path
+func (s *Server) Hits(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[path]
+}
+
+// LastBody returns the body of the most recent request to a path, or nil
+func (s *Server) LastBody(path string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bodies := s.bodies[path]
+	if len(bodies) == 0 {
+		return nil
+	}
+	return bodies[len(bodies)-1]
+}
+
+// JSON returns a handler that writes v as a JSON response with the given status
+func JSON(status int, v interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+// Raw returns a handler that writes body verbatim with the given status
+func Raw(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}
+}
+
+// DecodeBody unmarshals a recorded request body into v, failing the test on error
+func DecodeBody(t *testing.T, body []byte, v interface{}) {
+	t.Helper()
+	if body == nil {
+		t.Fatal("apitest: no request body recorded")
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		t.Fatalf("apitest: failed to decode request body %q: %v", string(body), err)
+	}
+}
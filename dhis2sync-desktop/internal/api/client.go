@@ -1,8 +1,11 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -11,34 +14,82 @@ import (
 
 // Client represents a DHIS2 API client
 type Client struct {
-	baseURL   string
-	username  string
-	password  string
-	http      *resty.Client
-	nameCache *lruCache // LRU cache for org unit names (bounded memory)
+	baseURL  string
+	username string
+	password string
+	http     *resty.Client
+	identity string // Who requests are made as, scoping cached org unit names
+
+	maxResponseBytes int          // Buffered body cap, see SetMaxResponseBytes
+	retryPolicy      RetryPolicy  // See SetRetryPolicy
+	limiter          *rateLimiter // Shared by all requests, see SetRateLimit
 }
 
-// NewClient creates a new DHIS2 API client
+// DefaultTimeout bounds each request unless a profile sets its own: slow DHIS2 servers
+// can take several minutes over async operations and large analytics responses
+const DefaultTimeout = 600 * time.Second
+
+// Authentication modes for NewClientWithAuth
+const (
+	AuthBasic = "basic" // Username and password (HTTP Basic)
+	AuthToken = "token" // DHIS2 personal access token, sent as "Authorization: ApiToken <token>"
+)
+
+// NewClient creates a new DHIS2 API client. baseURL is normalized with
+// NormalizeDHIS2URL, so "host/dhis/", "https://host/api" and similar variants work.
 func NewClient(baseURL, username, password string) *Client {
+	client := newClient(baseURL)
+	client.username = username
+	client.password = password
+	client.identity = "user:" + username
+	client.http.SetBasicAuth(username, password)
+	return client
+}
+
+// NewTokenClient creates a DHIS2 API client that authenticates with a personal access token
+func NewTokenClient(baseURL, token string) *Client {
+	client := newClient(baseURL)
+	client.identity = "token:" + tokenFingerprint(token)
+	client.http.SetAuthScheme("ApiToken").SetAuthToken(token)
+	return client
+}
+
+// NewClientWithAuth creates a client for authType (AuthBasic or AuthToken; empty means
+// AuthBasic). secret is the password for basic auth and the token for token auth.
+func NewClientWithAuth(baseURL, authType, username, secret string) *Client {
+	if authType == AuthToken {
+		return NewTokenClient(baseURL, secret)
+	}
+	return NewClient(baseURL, username, secret)
+}
+
+// tokenFingerprint identifies a token without keeping it in cache keys
+func tokenFingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// newClient creates a client without credentials
+func newClient(baseURL string) *Client {
+	if normalized, err := NormalizeDHIS2URL(baseURL); err == nil {
+		baseURL = normalized
+	}
+
 	client := &Client{
-		baseURL:   strings.TrimRight(baseURL, "/"),
-		username:  username,
-		password:  password,
-		nameCache: newLRUCache(10000), // Max 10k org unit names
+		baseURL: strings.TrimRight(baseURL, "/"),
+		limiter: &rateLimiter{},
 	}
 
 	// Configure resty client
 	client.http = resty.New().
 		SetHeader("User-Agent", "python-requests/2.31.0"). // Masquerade as Python to avoid DHIS2 client discrimination
-		SetBasicAuth(username, password).
-		SetTimeout(600 * time.Second). // 10 minutes timeout for slow DHIS2 servers (async operations can take several minutes)
-		SetRetryCount(3).
-		SetRetryWaitTime(500 * time.Millisecond).
-		SetRetryMaxWaitTime(2 * time.Second).
-		AddRetryCondition(func(r *resty.Response, err error) bool {
-			// Retry on 429 (Too Many Requests) and 5xx server errors
-			return r.StatusCode() == 429 || (r.StatusCode() >= 500 && r.StatusCode() <= 504)
+		SetTimeout(DefaultTimeout).
+		AddRetryCondition(client.shouldRetry).
+		OnBeforeRequest(func(_ *resty.Client, r *resty.Request) error {
+			return client.limiter.wait(r.Context())
 		})
+	client.SetRetryPolicy(DefaultRetryPolicy())
+	client.SetMaxResponseBytes(DefaultMaxResponseBytes)
 
 	return client
 }
@@ -52,16 +103,48 @@ func (c *Client) Get(endpoint string, params map[string]string) (*resty.Response
 		req.SetQueryParams(params)
 	}
 
-	return req.Get(url)
+	resp, err := req.Get(url)
+	return c.checkResponseSize(endpoint, resp, err)
+}
+
+// GetWithContext performs a GET request that is aborted when ctx is cancelled or times out
+func (c *Client) GetWithContext(ctx context.Context, endpoint string, params map[string]string) (*resty.Response, error) {
+	url := c.buildURL(endpoint)
+	req := c.http.R().SetContext(ctx)
+
+	if params != nil {
+		req.SetQueryParams(params)
+	}
+
+	resp, err := req.Get(url)
+	return c.checkResponseSize(endpoint, resp, err)
+}
+
+// GetStream performs a GET request without buffering the response body, for large
+// payloads read incrementally. The caller must close the returned response's Body.
+func (c *Client) GetStream(ctx context.Context, endpoint string, params map[string]string) (*http.Response, error) {
+	url := c.buildURL(endpoint)
+	req := c.http.R().SetContext(ctx).SetDoNotParseResponse(true)
+
+	if params != nil {
+		req.SetQueryParams(params)
+	}
+
+	resp, err := req.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	return resp.RawResponse, nil
 }
 
 // Post performs a POST request to the DHIS2 API
 func (c *Client) Post(endpoint string, payload interface{}) (*resty.Response, error) {
 	url := c.buildURL(endpoint)
-	return c.http.R().
+	resp, err := c.http.R().
 		SetHeader("Content-Type", "application/json").
 		SetBody(payload).
 		Post(url)
+	return c.checkResponseSize(endpoint, resp, err)
 }
 
 // Delete performs a DELETE request to the DHIS2 API
@@ -73,56 +156,32 @@ func (c *Client) Delete(endpoint string, params map[string]string) (*resty.Respo
 		req.SetQueryParams(params)
 	}
 
-	return req.Delete(url)
+	resp, err := req.Delete(url)
+	return c.checkResponseSize(endpoint, resp, err)
 }
 
 // Put performs a PUT request to the DHIS2 API
 func (c *Client) Put(endpoint string, payload interface{}) (*resty.Response, error) {
 	url := c.buildURL(endpoint)
-	return c.http.R().
+	resp, err := c.http.R().
 		SetHeader("Content-Type", "application/json").
 		SetBody(payload).
 		Put(url)
+	return c.checkResponseSize(endpoint, resp, err)
 }
 
-// GetOrgUnitName retrieves the name of an organization unit (with caching)
-func (c *Client) GetOrgUnitName(orgUnitID string) string {
-	// Check cache first
-	if name, exists := c.nameCache.Get(orgUnitID); exists {
-		return name
-	}
-
-	// Fetch from API
-	endpoint := fmt.Sprintf("api/organisationUnits/%s.json", orgUnitID)
-	params := map[string]string{"fields": "id,name,displayName"}
-
-	resp, err := c.Get(endpoint, params)
-	if err != nil || !resp.IsSuccess() {
-		// Fallback to ID if fetch fails
-		c.nameCache.Put(orgUnitID, orgUnitID)
-		return orgUnitID
+// Ping checks the instance is reachable via api/system/ping and returns the round-trip time
+func (c *Client) Ping() (time.Duration, error) {
+	start := time.Now()
+	resp, err := c.http.R().Get(c.buildURL("api/system/ping"))
+	latency := time.Since(start)
+	if err != nil {
+		return latency, err
 	}
-
-	var result struct {
-		DisplayName string `json:"displayName"`
-		Name        string `json:"name"`
+	if !resp.IsSuccess() {
+		return latency, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), BodySnippet(resp.Body(), 200))
 	}
-
-	if err := json.Unmarshal(resp.Body(), &result); err != nil {
-		c.nameCache.Put(orgUnitID, orgUnitID)
-		return orgUnitID
-	}
-
-	name := result.DisplayName
-	if name == "" {
-		name = result.Name
-	}
-	if name == "" {
-		name = orgUnitID
-	}
-
-	c.nameCache.Put(orgUnitID, name)
-	return name
+	return latency, nil
 }
 
 // ListPrograms lists tracker programs
@@ -148,7 +207,27 @@ func (c *Client) buildURL(endpoint string) string {
 	return fmt.Sprintf("%s/%s", c.baseURL, endpoint)
 }
 
+// SetTransport replaces the underlying HTTP transport, e.g. to route requests
+// through a test double or a custom dialer
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.http.SetTransport(transport)
+}
+
 // SetTimeout allows customizing the timeout for specific operations
 func (c *Client) SetTimeout(timeout time.Duration) {
 	c.http.SetTimeout(timeout)
 }
+
+// SetTimeoutSeconds applies a profile's request timeout; seconds <= 0 restores DefaultTimeout
+func (c *Client) SetTimeoutSeconds(seconds int) {
+	if seconds <= 0 {
+		c.SetTimeout(DefaultTimeout)
+		return
+	}
+	c.SetTimeout(time.Duration(seconds) * time.Second)
+}
+
+// Timeout returns the client's per-request timeout
+func (c *Client) Timeout() time.Duration {
+	return c.http.GetClient().Timeout
+}
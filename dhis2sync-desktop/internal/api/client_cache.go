@@ -3,11 +3,13 @@ package api
 import (
 	"container/list"
 	"sync"
+	"time"
 )
 
 // lruCache implements a thread-safe LRU (Least Recently Used) cache
 type lruCache struct {
 	capacity int
+	ttl      time.Duration // Entries expire this long after being put; 0 keeps them
 	cache    map[string]*list.Element
 	lru      *list.List
 	mu       sync.RWMutex
@@ -15,14 +17,21 @@ type lruCache struct {
 
 // cacheEntry represents a key-value pair in the cache
 type cacheEntry struct {
-	key   string
-	value string
+	key     string
+	value   string
+	expires time.Time // Zero without a TTL
 }
 
 // newLRUCache creates a new LRU cache with the specified capacity
 func newLRUCache(capacity int) *lruCache {
+	return newTTLCache(capacity, 0)
+}
+
+// newTTLCache creates an LRU cache whose entries also expire ttl after being put
+func newTTLCache(capacity int, ttl time.Duration) *lruCache {
 	return &lruCache{
 		capacity: capacity,
+		ttl:      ttl,
 		cache:    make(map[string]*list.Element),
 		lru:      list.New(),
 	}
@@ -35,9 +44,16 @@ func (c *lruCache) Get(key string) (string, bool) {
 	defer c.mu.Unlock()
 
 	if elem, exists := c.cache[key]; exists {
+		entry := elem.Value.(*cacheEntry)
+		if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+			c.lru.Remove(elem)
+			delete(c.cache, key)
+			return "", false
+		}
+
 		// Move to front (most recently used)
 		c.lru.MoveToFront(elem)
-		return elem.Value.(*cacheEntry).value, true
+		return entry.value, true
 	}
 	return "", false
 }
@@ -47,10 +63,17 @@ func (c *lruCache) Put(key, value string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = time.Now().Add(c.ttl)
+	}
+
 	// If key exists, update and move to front
 	if elem, exists := c.cache[key]; exists {
 		c.lru.MoveToFront(elem)
-		elem.Value.(*cacheEntry).value = value
+		entry := elem.Value.(*cacheEntry)
+		entry.value = value
+		entry.expires = expires
 		return
 	}
 
@@ -64,7 +87,7 @@ func (c *lruCache) Put(key, value string) {
 	}
 
 	// Add new entry
-	entry := &cacheEntry{key: key, value: value}
+	entry := &cacheEntry{key: key, value: value, expires: expires}
 	elem := c.lru.PushFront(entry)
 	c.cache[key] = elem
 }
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientAuth(t *testing.T) {
+	var authorization string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	t.Run("Should use basic auth by default", func(t *testing.T) {
+		_, err := NewClientWithAuth(srv.URL, "", "admin", "district").Get("api/me", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Basic YWRtaW46ZGlzdHJpY3Q=", authorization)
+	})
+
+	t.Run("Should send a personal access token as ApiToken", func(t *testing.T) {
+		_, err := NewClientWithAuth(srv.URL, AuthToken, "ignored", "d2pat_abc123").Get("api/me", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "ApiToken d2pat_abc123", authorization)
+	})
+}
+
+func TestSetTimeoutSeconds(t *testing.T) {
+	t.Run("Should apply a profile's timeout and fall back to the default when unset", func(t *testing.T) {
+		client := NewClient("https://play.dhis2.org", "admin", "district")
+		assert.Equal(t, DefaultTimeout, client.Timeout())
+
+		client.SetTimeoutSeconds(30)
+		assert.Equal(t, 30*time.Second, client.Timeout())
+
+		client.SetTimeoutSeconds(0)
+		assert.Equal(t, DefaultTimeout, client.Timeout())
+	})
+
+	t.Run("Should fail a request that outlasts the timeout", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(1500 * time.Millisecond)
+		}))
+		t.Cleanup(srv.Close)
+
+		client := NewClient(srv.URL, "admin", "district")
+		client.SetRetryPolicy(RetryPolicy{MaxAttempts: 1})
+		client.SetTimeoutSeconds(1)
+
+		_, err := client.Get("api/me.json", nil)
+		assert.Error(t, err)
+	})
+}
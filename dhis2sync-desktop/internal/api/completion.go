@@ -0,0 +1,35 @@
+package api
+
+import "time"
+
+// DefaultStoredBy attributes completion registrations when the user doesn't name anyone
+const DefaultStoredBy = "dhis2sync-desktop"
+
+// CompletionRegistration builds a completeDataSetRegistration. Completed registrations
+// carry a completeDate (default: now's date); storedBy defaults to DefaultStoredBy.
+// Without an attribute option combo DHIS2 registers the default one.
+func CompletionRegistration(datasetID, period, orgUnitID, attributeOptionCombo string, completed bool, completeDate, storedBy string, now time.Time) map[string]interface{} {
+	if storedBy == "" {
+		storedBy = DefaultStoredBy
+	}
+
+	reg := map[string]interface{}{
+		"dataSet":          datasetID,
+		"period":           period,
+		"organisationUnit": orgUnitID,
+		"completed":        completed,
+		"storedBy":         storedBy,
+	}
+	if attributeOptionCombo != "" {
+		reg["attributeOptionCombo"] = attributeOptionCombo
+	}
+
+	if completed {
+		if completeDate == "" {
+			completeDate = now.Format("2006-01-02") // YYYY-MM-DD format
+		}
+		reg["completeDate"] = completeDate
+	}
+
+	return reg
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletionRegistration(t *testing.T) {
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	t.Run("Should carry the requested completeDate and storedBy", func(t *testing.T) {
+		reg := CompletionRegistration("ds1", "202401", "ou1", "", true, "2024-01-31", "backfill", now)
+
+		assert.Equal(t, "ds1", reg["dataSet"])
+		assert.Equal(t, "202401", reg["period"])
+		assert.Equal(t, "ou1", reg["organisationUnit"])
+		assert.Equal(t, true, reg["completed"])
+		assert.Equal(t, "2024-01-31", reg["completeDate"])
+		assert.Equal(t, "backfill", reg["storedBy"])
+		assert.NotContains(t, reg, "attributeOptionCombo", "DHIS2 picks the default combo")
+	})
+
+	t.Run("Should carry the attribute option combo", func(t *testing.T) {
+		reg := CompletionRegistration("ds1", "202401", "ou1", "aocPartner", true, "", "", now)
+
+		assert.Equal(t, "aocPartner", reg["attributeOptionCombo"])
+	})
+
+	t.Run("Should default to today and the tool name", func(t *testing.T) {
+		reg := CompletionRegistration("ds1", "202401", "ou1", "", true, "", "", now)
+
+		assert.Equal(t, "2024-03-10", reg["completeDate"])
+		assert.Equal(t, DefaultStoredBy, reg["storedBy"])
+	})
+
+	t.Run("Should omit completeDate when marking incomplete", func(t *testing.T) {
+		reg := CompletionRegistration("ds1", "202401", "ou1", "", false, "2024-01-31", "", now)
+
+		assert.Equal(t, false, reg["completed"])
+		assert.NotContains(t, reg, "completeDate")
+		assert.Equal(t, DefaultStoredBy, reg["storedBy"])
+	})
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// BaseURL returns the base URL endpoints are built against
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// DetectContextPath finds the base URL the instance serves its API under, for
+// DHIS2 deployed below a subpath (https://host/dhis) when the user entered only
+// the host. Candidates are the configured base URL, where the server root
+// redirects to, and the conventional "/dhis" subpath; the first one answering
+// api/system/info wins, corrected to its reported contextPath when that answers
+// as well. On success the client builds all further endpoints against the
+// detected base URL.
+func (c *Client) DetectContextPath() (string, error) {
+	candidates := []string{c.baseURL}
+	if redirected := c.rootRedirect(); redirected != "" && redirected != c.baseURL {
+		candidates = append(candidates, redirected)
+	}
+	if !strings.HasSuffix(c.baseURL, "/dhis") {
+		candidates = append(candidates, c.baseURL+"/dhis")
+	}
+
+	for _, candidate := range candidates {
+		info, err := c.fetchSystemInfo(candidate)
+		if err != nil {
+			continue
+		}
+		// Prefer the reported contextPath, but only if it answers too: proxies may rewrite paths
+		base := candidate
+		if reported := withContextPath(candidate, info.ContextPath); reported != candidate {
+			if _, err := c.fetchSystemInfo(reported); err == nil {
+				base = reported
+			}
+		}
+		c.baseURL = base
+		return c.baseURL, nil
+	}
+
+	return "", fmt.Errorf("no DHIS2 API found at %s (tried %s)", c.baseURL, strings.Join(candidates, ", "))
+}
+
+// rootRedirect returns the normalized URL the server root redirects to, e.g. the
+// login page below the context path, or "" if it can't be determined
+func (c *Client) rootRedirect() string {
+	resp, err := c.http.R().Get(c.baseURL + "/")
+	if err != nil || resp.RawResponse == nil || resp.RawResponse.Request == nil {
+		return ""
+	}
+
+	final, err := NormalizeDHIS2URL(resp.RawResponse.Request.URL.String())
+	if err != nil {
+		return ""
+	}
+	return final
+}
+
+// fetchSystemInfo requests api/system/info below base, failing unless it answers with DHIS2 JSON
+func (c *Client) fetchSystemInfo(base string) (*SystemInfo, error) {
+	resp, err := c.http.R().Get(base + "/api/system/info.json")
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode())
+	}
+
+	var info SystemInfo
+	if err := DecodeJSON(resp, "api/system/info", &info); err != nil {
+		return nil, err
+	}
+	if info.Version == "" && info.ContextPath == "" {
+		return nil, fmt.Errorf("api/system/info at %s is not a DHIS2 response", base)
+	}
+	return &info, nil
+}
+
+// withContextPath keeps the scheme and host the user reached the server with and
+// takes the path from the reported contextPath, which behind a reverse proxy often
+// names an internal host (http://localhost:8080/dhis)
+func withContextPath(base, contextPath string) string {
+	if contextPath == "" {
+		return base
+	}
+	reported, err := NormalizeDHIS2URL(contextPath)
+	if err != nil {
+		return base
+	}
+	reportedURL, err := url.Parse(reported)
+	if err != nil {
+		return base
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return base
+	}
+
+	return fmt.Sprintf("%s://%s%s", baseURL.Scheme, baseURL.Host, reportedURL.Path)
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSubpathServer serves a DHIS2 API only below /dhis, redirecting the root to the login page
+func newSubpathServer(t *testing.T, reportedContextPath string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			http.Redirect(w, r, "/dhis/dhis-web-commons/security/login.action", http.StatusFound)
+		case "/dhis/dhis-web-commons/security/login.action":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html>login</html>"))
+		case "/dhis/api/system/info.json":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]string{"version": "2.40.3", "contextPath": reportedContextPath})
+		case "/dhis/api/me.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"username":"admin"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDetectContextPath(t *testing.T) {
+	t.Run("Should follow the root redirect to the context path", func(t *testing.T) {
+		srv := newSubpathServer(t, "http://localhost:8080/dhis")
+		client := NewClient(srv.URL, "admin", "district")
+
+		base, err := client.DetectContextPath()
+
+		require.NoError(t, err)
+		assert.Equal(t, srv.URL+"/dhis", base, "Scheme and host should come from the entered URL, not the proxied contextPath")
+		assert.Equal(t, base, client.BaseURL())
+
+		resp, err := client.Get("api/me.json", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode(), "Endpoints should be built against the detected context path")
+	})
+
+	t.Run("Should keep a working candidate when the reported contextPath does not answer", func(t *testing.T) {
+		srv := newSubpathServer(t, "http://localhost:8080/internal")
+		client := NewClient(srv.URL, "admin", "district")
+
+		base, err := client.DetectContextPath()
+
+		require.NoError(t, err)
+		assert.Equal(t, srv.URL+"/dhis", base)
+	})
+
+	t.Run("Should fail when no candidate serves the API", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		defer srv.Close()
+		client := NewClient(srv.URL, "admin", "district")
+
+		_, err := client.DetectContextPath()
+
+		assert.Error(t, err)
+		assert.Equal(t, srv.URL, client.BaseURL(), "A failed detection should leave the base URL alone")
+	})
+}
+
+func TestWithContextPath(t *testing.T) {
+	assert.Equal(t, "https://host/dhis", withContextPath("https://host", "http://localhost:8080/dhis"))
+	assert.Equal(t, "https://host", withContextPath("https://host", ""))
+	assert.Equal(t, "https://host", withContextPath("https://host", "http://localhost:8080"))
+}
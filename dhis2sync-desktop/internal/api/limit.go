@@ -0,0 +1,38 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// DefaultMaxResponseBytes caps buffered response bodies. It is far above any
+// legitimate single response, but stops a runaway query (e.g. children=true at
+// national level) from exhausting memory.
+const DefaultMaxResponseBytes = 512 << 20 // 512 MiB
+
+// ErrResponseTooLarge is returned when a response body exceeds the client's limit;
+// narrow the query (fewer org units or periods) or raise the limit
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// SetMaxResponseBytes changes the largest response body the client will buffer;
+// limit <= 0 removes the cap. GetStream is not limited, as its caller reads
+// incrementally.
+func (c *Client) SetMaxResponseBytes(limit int) {
+	c.maxResponseBytes = limit
+	c.http.SetResponseBodyLimit(limit)
+}
+
+// MaxResponseBytes returns the current response body cap (<= 0 means unlimited)
+func (c *Client) MaxResponseBytes() int {
+	return c.maxResponseBytes
+}
+
+// checkResponseSize turns resty's body limit error into ErrResponseTooLarge naming the endpoint
+func (c *Client) checkResponseSize(endpoint string, resp *resty.Response, err error) (*resty.Response, error) {
+	if err != nil && errors.Is(err, resty.ErrResponseBodyTooLarge) {
+		return resp, fmt.Errorf("%w: %s exceeded %d bytes", ErrResponseTooLarge, endpoint, c.maxResponseBytes)
+	}
+	return resp, err
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxResponseBytes(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"dataValues":[`))
+		w.Write(bytes.Repeat([]byte(`{"value":"1"},`), 1000))
+		w.Write([]byte(`{"value":"1"}]}`))
+	}))
+	defer srv.Close()
+
+	t.Run("Should default to a generous limit", func(t *testing.T) {
+		client := NewClient(srv.URL, "admin", "district")
+
+		assert.Equal(t, DefaultMaxResponseBytes, client.MaxResponseBytes())
+		resp, err := client.Get("api/dataValueSets", nil)
+		require.NoError(t, err)
+		assert.True(t, resp.IsSuccess())
+	})
+
+	t.Run("Should fail with ErrResponseTooLarge past the limit without retrying", func(t *testing.T) {
+		client := NewClient(srv.URL, "admin", "district")
+		client.SetMaxResponseBytes(1024)
+		atomic.StoreInt32(&hits, 0)
+
+		_, err := client.Get("api/dataValueSets", map[string]string{"children": "true"})
+
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrResponseTooLarge))
+		assert.Contains(t, err.Error(), "api/dataValueSets exceeded 1024 bytes")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("Should apply to POST responses too", func(t *testing.T) {
+		client := NewClient(srv.URL, "admin", "district")
+		client.SetMaxResponseBytes(1024)
+
+		_, err := client.Post("api/dataValueSets", map[string]string{})
+
+		assert.ErrorIs(t, err, ErrResponseTooLarge)
+	})
+
+	t.Run("Should allow removing the limit", func(t *testing.T) {
+		client := NewClient(srv.URL, "admin", "district")
+		client.SetMaxResponseBytes(0)
+
+		_, err := client.Get("api/dataValueSets", nil)
+
+		assert.NoError(t, err)
+	})
+}
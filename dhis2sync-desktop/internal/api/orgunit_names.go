@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OrgUnitNameTTL is how long a looked-up org unit name is reused
+const OrgUnitNameTTL = time.Hour
+
+// orgUnitNames is shared by every client, so tasks (each with its own client) and
+// the periods within them resolve a name once. Keys are scoped by server and identity
+// (profile instance, user or token), since displayName follows the user's locale.
+var orgUnitNames = newTTLCache(100000, OrgUnitNameTTL)
+
+// orgUnitNameKey scopes an org unit ID to the client's server and identity
+func (c *Client) orgUnitNameKey(orgUnitID string) string {
+	return c.baseURL + "|" + c.identity + "|" + orgUnitID
+}
+
+// CachedOrgUnitName returns an org unit name looked up through any client of the same
+// server and identity within OrgUnitNameTTL
+func (c *Client) CachedOrgUnitName(orgUnitID string) (string, bool) {
+	return orgUnitNames.Get(c.orgUnitNameKey(orgUnitID))
+}
+
+// CacheOrgUnitName records a name found by another lookup (a batched id:in request,
+// a hierarchy fetch) for GetOrgUnitName and CachedOrgUnitName
+func (c *Client) CacheOrgUnitName(orgUnitID, name string) {
+	if name == "" {
+		return
+	}
+	orgUnitNames.Put(c.orgUnitNameKey(orgUnitID), name)
+}
+
+// ClearOrgUnitNameCache forgets every cached org unit name, e.g. after a metadata
+// import renamed some
+func ClearOrgUnitNameCache() {
+	orgUnitNames.Clear()
+}
+
+// GetOrgUnitName retrieves the name of an organization unit (with caching). Failed
+// lookups fall back to the ID and are not cached, so the next call tries again.
+func (c *Client) GetOrgUnitName(orgUnitID string) string {
+	// Check cache first
+	if name, exists := c.CachedOrgUnitName(orgUnitID); exists {
+		return name
+	}
+
+	// Fetch from API
+	endpoint := fmt.Sprintf("api/organisationUnits/%s.json", orgUnitID)
+	params := map[string]string{"fields": "id,name,displayName"}
+
+	resp, err := c.Get(endpoint, params)
+	if err != nil || !resp.IsSuccess() {
+		// Fallback to ID if fetch fails
+		return orgUnitID
+	}
+
+	var result struct {
+		DisplayName string `json:"displayName"`
+		Name        string `json:"name"`
+	}
+
+	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+		return orgUnitID
+	}
+
+	name := result.DisplayName
+	if name == "" {
+		name = result.Name
+	}
+	if name == "" {
+		return orgUnitID
+	}
+
+	c.CacheOrgUnitName(orgUnitID, name)
+	return name
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOrgUnitName(t *testing.T) {
+	t.Run("Should share looked-up names across clients of the same server", func(t *testing.T) {
+		var lookups int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&lookups, 1)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":"ou1","name":"Clinic","displayName":"Clinic (display)"}`))
+		}))
+		defer srv.Close()
+
+		for i := 0; i < 3; i++ {
+			client := NewClient(srv.URL, "admin", "district")
+			assert.Equal(t, "Clinic (display)", client.GetOrgUnitName("ou1"))
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&lookups))
+
+		other := NewClient(srv.URL, "someone-else", "secret")
+		other.GetOrgUnitName("ou1")
+		assert.Equal(t, int32(2), atomic.LoadInt32(&lookups), "Names are scoped per user")
+
+		NewTokenClient(srv.URL, "d2pat_first").GetOrgUnitName("ou1")
+		NewTokenClient(srv.URL, "d2pat_first").GetOrgUnitName("ou1")
+		assert.Equal(t, int32(3), atomic.LoadInt32(&lookups), "Names are shared by clients of one token")
+		NewTokenClient(srv.URL, "d2pat_second").GetOrgUnitName("ou1")
+		assert.Equal(t, int32(4), atomic.LoadInt32(&lookups), "Names are scoped per token")
+	})
+
+	t.Run("Should not cache failed lookups", func(t *testing.T) {
+		var lookups int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&lookups, 1)
+			http.Error(w, "down", http.StatusNotFound)
+		}))
+		defer srv.Close()
+
+		client := NewClient(srv.URL, "admin", "district")
+		assert.Equal(t, "ou1", client.GetOrgUnitName("ou1"))
+		assert.Equal(t, "ou1", client.GetOrgUnitName("ou1"))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&lookups))
+	})
+}
+
+func TestTTLCache(t *testing.T) {
+	t.Run("Should expire entries after the TTL", func(t *testing.T) {
+		cache := newTTLCache(10, 20*time.Millisecond)
+		cache.Put("a", "1")
+
+		value, ok := cache.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, "1", value)
+
+		time.Sleep(30 * time.Millisecond)
+		_, ok = cache.Get("a")
+		assert.False(t, ok)
+		assert.Equal(t, 0, cache.Len())
+	})
+}
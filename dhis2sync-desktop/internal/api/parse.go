@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// bodySnippetLimit is how much of an unparseable response body is kept in errors
+const bodySnippetLimit = 500
+
+// ParseError reports a response that could not be decoded, with enough context
+// (endpoint, status and the start of the body) to diagnose DHIS2 schema changes
+// or HTML error pages returned in place of JSON
+type ParseError struct {
+	Endpoint   string
+	StatusCode int
+	Snippet    string
+	Err        error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s (HTTP %d): %v; response body: %q", e.Endpoint, e.StatusCode, e.Err, e.Snippet)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// BodySnippet returns at most limit bytes of body, cut on a rune boundary and
+// marked when truncated
+func BodySnippet(body []byte, limit int) string {
+	if len(body) <= limit {
+		return string(body)
+	}
+
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s... (%d more bytes)", body[:cut], len(body)-cut)
+}
+
+// DecodeJSON unmarshals a response body into v, returning a *ParseError with the
+// endpoint and a body snippet on failure
+func DecodeJSON(resp *resty.Response, endpoint string, v interface{}) error {
+	body := resp.Body()
+	if err := json.Unmarshal(body, v); err != nil {
+		return &ParseError{
+			Endpoint:   endpoint,
+			StatusCode: resp.StatusCode(),
+			Snippet:    BodySnippet(body, bodySnippetLimit),
+			Err:        err,
+		}
+	}
+	return nil
+}
+
+// ParseJSON decodes a response body into a new T, see DecodeJSON
+func ParseJSON[T any](resp *resty.Response, endpoint string) (T, error) {
+	var result T
+	err := DecodeJSON(resp, endpoint, &result)
+	return result, err
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodySnippet(t *testing.T) {
+	t.Run("Should keep short bodies intact", func(t *testing.T) {
+		assert.Equal(t, `{"a":1}`, BodySnippet([]byte(`{"a":1}`), 100))
+	})
+
+	t.Run("Should truncate long bodies and report the remainder", func(t *testing.T) {
+		out := BodySnippet([]byte(strings.Repeat("x", 50)), 10)
+
+		assert.Equal(t, "xxxxxxxxxx... (40 more bytes)", out)
+	})
+
+	t.Run("Should not split a multi-byte character", func(t *testing.T) {
+		out := BodySnippet([]byte("abcé"), 4) // é is 2 bytes starting at index 3
+
+		assert.True(t, strings.HasPrefix(out, "abc..."))
+	})
+}
+
+func TestParseJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/ok" {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]string{"id": "abc"})
+			return
+		}
+		w.Write([]byte("<html><body>Login required</body></html>"))
+	}))
+	defer srv.Close()
+	client := NewClient(srv.URL, "admin", "district")
+
+	t.Run("Should decode a JSON response", func(t *testing.T) {
+		resp, err := client.Get("api/ok", nil)
+		require.NoError(t, err)
+
+		result, err := ParseJSON[struct {
+			ID string `json:"id"`
+		}](resp, "api/ok")
+
+		require.NoError(t, err)
+		assert.Equal(t, "abc", result.ID)
+	})
+
+	t.Run("Should report endpoint, status and body snippet on failure", func(t *testing.T) {
+		resp, err := client.Get("api/html", nil)
+		require.NoError(t, err)
+
+		_, err = ParseJSON[map[string]interface{}](resp, "api/html")
+
+		require.Error(t, err)
+		var parseErr *ParseError
+		require.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "api/html", parseErr.Endpoint)
+		assert.Equal(t, http.StatusOK, parseErr.StatusCode)
+		assert.Contains(t, err.Error(), "Login required")
+		assert.Contains(t, err.Error(), "api/html")
+	})
+}
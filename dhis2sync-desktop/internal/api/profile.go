@@ -0,0 +1,44 @@
+package api
+
+import (
+	"fmt"
+
+	"dhis2sync-desktop/internal/crypto"
+	"dhis2sync-desktop/internal/models"
+)
+
+// NewProfileClient creates a client for a profile's "source" or destination instance
+// with its stored credentials decrypted and its rate limit, request timeout and proxy
+// applied. Services build every client for a profile through it. Clients of the same
+// profile instance share one rate limiter, so RateLimit caps them together.
+func NewProfileClient(profile *models.ConnectionProfile, instance string) (*Client, error) {
+	url, username, authType, encSecret := profile.Credentials(instance)
+
+	// Decrypt the password or token
+	secret, err := crypto.DecryptPassword(encSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credentials: %w", err)
+	}
+
+	client := NewClientWithAuth(url, authType, username, secret)
+	if profile.ID != "" {
+		client.identity = "profile:" + profile.ID + "|" + instance
+		client.limiter = profileLimiter(profile.ID, instance, profile.RateLimit)
+	} else {
+		client.SetRateLimit(profile.RateLimit)
+	}
+	client.SetTimeoutSeconds(profile.RequestTimeoutSeconds)
+
+	proxyURL := profile.ProxyURL
+	if profile.ProxyPasswordEnc != "" {
+		proxyPassword, err := crypto.DecryptPassword(profile.ProxyPasswordEnc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt proxy password: %w", err)
+		}
+		proxyURL = ProxyWithPassword(proxyURL, proxyPassword)
+	}
+	if err := client.SetProxy(proxyURL); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
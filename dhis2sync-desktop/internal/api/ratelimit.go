@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket shared by every request of a client: rps tokens are
+// added per second, up to a burst of rps. A zero rate lets requests through unthrottled.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rps    float64
+	tokens float64 // Negative when requests are queued for future tokens
+	last   time.Time
+}
+
+// SetRateLimit throttles the client to rps requests per second across all goroutines
+// using it, retries included; rps <= 0 removes the limit
+func (c *Client) SetRateLimit(rps int) {
+	c.limiter.setRate(rps)
+}
+
+// RateLimit returns the client's requests-per-second limit (0 means unlimited)
+func (c *Client) RateLimit() int {
+	c.limiter.mu.Lock()
+	defer c.limiter.mu.Unlock()
+	return int(c.limiter.rps)
+}
+
+// profileLimiters holds one limiter per profile instance, so every client built for it
+// (a task often has several) draws from the same bucket and the profile's RateLimit
+// holds across them
+var profileLimiters = struct {
+	mu       sync.Mutex
+	limiters map[string]*rateLimiter
+}{limiters: make(map[string]*rateLimiter)}
+
+// profileLimiter returns the shared limiter of a profile's "source" or destination
+// instance, set to rps
+func profileLimiter(profileID, instance string, rps int) *rateLimiter {
+	if instance != "source" {
+		instance = "dest"
+	}
+	key := profileID + "|" + instance
+
+	profileLimiters.mu.Lock()
+	limiter, exists := profileLimiters.limiters[key]
+	if !exists {
+		limiter = &rateLimiter{}
+		profileLimiters.limiters[key] = limiter
+	}
+	profileLimiters.mu.Unlock()
+
+	// Resetting an unchanged rate would hand out a fresh burst to every new client
+	limiter.mu.Lock()
+	changed := !exists || int(limiter.rps) != rps
+	limiter.mu.Unlock()
+	if changed {
+		limiter.setRate(rps)
+	}
+	return limiter
+}
+
+func (l *rateLimiter) setRate(rps int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if rps < 0 {
+		rps = 0
+	}
+	l.rps = float64(rps)
+	l.tokens = l.rps
+	l.last = time.Now()
+}
+
+// wait blocks until the request may be sent, or ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	if l.rps == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rps
+	if l.tokens > l.rps {
+		l.tokens = l.rps
+	}
+	l.last = now
+
+	// Take a token now, borrowing against the future if none is left
+	l.tokens--
+	if l.tokens >= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	delay := time.Duration(-l.tokens / l.rps * float64(time.Second))
+	l.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give the unused token back
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
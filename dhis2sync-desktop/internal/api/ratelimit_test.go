@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	t.Run("Should throttle concurrent requests across goroutines", func(t *testing.T) {
+		client := NewClient(srv.URL, "admin", "district")
+		client.SetRateLimit(20)
+
+		// 20 go out as the initial burst, the next 10 need half a second of tokens
+		start := time.Now()
+		var wg sync.WaitGroup
+		for i := 0; i < 30; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := client.Get("api/me", nil)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.GreaterOrEqual(t, time.Since(start), 450*time.Millisecond)
+		assert.Equal(t, 20, client.RateLimit())
+	})
+
+	t.Run("Should not throttle without a limit", func(t *testing.T) {
+		client := NewClient(srv.URL, "admin", "district")
+
+		start := time.Now()
+		for i := 0; i < 30; i++ {
+			_, err := client.Get("api/me", nil)
+			require.NoError(t, err)
+		}
+		assert.Less(t, time.Since(start), 450*time.Millisecond)
+	})
+
+	t.Run("Should stop waiting when the context is cancelled", func(t *testing.T) {
+		client := NewClient(srv.URL, "admin", "district")
+		client.SetRateLimit(1)
+		_, err := client.Get("api/me", nil) // Uses the only token
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err = client.GetWithContext(ctx, "api/me", nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// RetryPolicy controls how the client transparently retries failed requests.
+// Waits grow exponentially (with jitter) from BaseDelay up to MaxDelay.
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts per request including the first; 1 disables retries
+	BaseDelay   time.Duration // Wait before the first retry
+	MaxDelay    time.Duration // Longest wait between attempts
+	StatusCodes []int         // Retryable HTTP statuses; network errors are always retryable
+
+	// RetryNonIdempotent also retries POST and PATCH, which can apply a write (e.g. a
+	// data value import) twice when the first attempt reached the server
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy retries GET, HEAD, PUT and DELETE up to 3 attempts on network
+// errors, 429 and 5xx gateway/server errors; POSTs are not retried
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		StatusCodes: []int{http.StatusTooManyRequests, 500, 502, 503, 504},
+	}
+}
+
+// SetRetryPolicy replaces the client's retry policy
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	c.retryPolicy = policy
+	c.http.SetRetryCount(policy.MaxAttempts - 1).
+		SetRetryWaitTime(policy.BaseDelay).
+		SetRetryMaxWaitTime(policy.MaxDelay)
+}
+
+// RetryPolicy returns the client's current retry policy
+func (c *Client) RetryPolicy() RetryPolicy {
+	return c.retryPolicy
+}
+
+// SetRetryCount changes how often failed requests are retried transparently, keeping
+// the rest of the policy; 0 disables it for callers that retry themselves
+func (c *Client) SetRetryCount(count int) {
+	policy := c.retryPolicy
+	policy.MaxAttempts = count + 1
+	c.SetRetryPolicy(policy)
+}
+
+// shouldRetry is the resty retry condition applying the client's policy
+func (c *Client) shouldRetry(resp *resty.Response, err error) bool {
+	if resp == nil || resp.Request == nil {
+		return false
+	}
+
+	switch resp.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		if !c.retryPolicy.RetryNonIdempotent {
+			return false
+		}
+	}
+
+	if err != nil {
+		return !errors.Is(err, resty.ErrResponseBodyTooLarge)
+	}
+	for _, code := range c.retryPolicy.StatusCodes {
+		if resp.StatusCode() == code {
+			return true
+		}
+	}
+	return false
+}
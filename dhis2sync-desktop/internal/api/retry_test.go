@@ -0,0 +1,120 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	// failing answers 502 until the given number of requests have been served
+	failing := func(failures int32, hits *int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(hits, 1) <= failures {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+		}))
+	}
+	fastPolicy := func() RetryPolicy {
+		policy := DefaultRetryPolicy()
+		policy.BaseDelay = time.Millisecond
+		policy.MaxDelay = 5 * time.Millisecond
+		return policy
+	}
+
+	t.Run("Should retry GETs on transient server errors", func(t *testing.T) {
+		var hits int32
+		srv := failing(2, &hits)
+		defer srv.Close()
+
+		client := NewClient(srv.URL, "admin", "district")
+		client.SetRetryPolicy(fastPolicy())
+
+		resp, err := client.Get("api/me", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("Should give up after MaxAttempts", func(t *testing.T) {
+		var hits int32
+		srv := failing(10, &hits)
+		defer srv.Close()
+
+		client := NewClient(srv.URL, "admin", "district")
+		client.SetRetryPolicy(fastPolicy())
+
+		resp, err := client.Get("api/me", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode())
+		assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("Should not retry POSTs by default", func(t *testing.T) {
+		var hits int32
+		srv := failing(1, &hits)
+		defer srv.Close()
+
+		client := NewClient(srv.URL, "admin", "district")
+		client.SetRetryPolicy(fastPolicy())
+
+		resp, err := client.Post("api/dataValueSets", map[string]string{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode())
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("Should retry POSTs when opted in", func(t *testing.T) {
+		var hits int32
+		srv := failing(1, &hits)
+		defer srv.Close()
+
+		policy := fastPolicy()
+		policy.RetryNonIdempotent = true
+		client := NewClient(srv.URL, "admin", "district")
+		client.SetRetryPolicy(policy)
+
+		resp, err := client.Post("api/dataValueSets", map[string]string{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("Should not retry statuses outside the policy", func(t *testing.T) {
+		var hits int32
+		srv := failing(1, &hits)
+		defer srv.Close()
+
+		policy := fastPolicy()
+		policy.StatusCodes = []int{http.StatusServiceUnavailable}
+		client := NewClient(srv.URL, "admin", "district")
+		client.SetRetryPolicy(policy)
+
+		resp, err := client.Get("api/me", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode())
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("Should disable retries with SetRetryCount(0)", func(t *testing.T) {
+		var hits int32
+		srv := failing(1, &hits)
+		defer srv.Close()
+
+		client := NewClient(srv.URL, "admin", "district")
+		client.SetRetryCount(0)
+
+		_, err := client.Get("api/me", nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+		assert.Equal(t, 1, client.RetryPolicy().MaxAttempts)
+	})
+}
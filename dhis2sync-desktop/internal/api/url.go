@@ -0,0 +1,53 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// NormalizeDHIS2URL turns a pasted DHIS2 address into the instance base URL that
+// endpoints are appended to. It adds a missing scheme, drops query strings and
+// fragments, trailing slashes, an "/api" suffix (with anything after it, e.g.
+// "/api/40/me") and web app paths ("/dhis-web-dashboard/..."), keeping any
+// context path such as "/dhis".
+func NormalizeDHIS2URL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("URL is empty")
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid URL %q: scheme must be http or https", raw)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid URL %q: missing host", raw)
+	}
+
+	// Cut at the first API or web app segment; whatever precedes it is the context path
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	kept := []string{}
+	for _, seg := range segments {
+		if seg == "" {
+			continue
+		}
+		if seg == "api" || strings.HasPrefix(seg, "dhis-web-") {
+			break
+		}
+		kept = append(kept, seg)
+	}
+
+	path := ""
+	if len(kept) > 0 {
+		path = "/" + strings.Join(kept, "/")
+	}
+
+	return fmt.Sprintf("%s://%s%s", strings.ToLower(u.Scheme), u.Host, path), nil
+}
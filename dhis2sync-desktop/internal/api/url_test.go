@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDHIS2URL(t *testing.T) {
+	cases := map[string]string{
+		"https://play.dhis2.org":                                "https://play.dhis2.org",
+		"https://play.dhis2.org/":                               "https://play.dhis2.org",
+		"  https://play.dhis2.org//  ":                          "https://play.dhis2.org",
+		"play.dhis2.org/dhis":                                   "https://play.dhis2.org/dhis",
+		"https://play.dhis2.org/api":                            "https://play.dhis2.org",
+		"https://play.dhis2.org/api/":                           "https://play.dhis2.org",
+		"https://play.dhis2.org/dhis/api/40/me.json?fields=id":  "https://play.dhis2.org/dhis",
+		"https://host/dhis/dhis-web-dashboard/index.html#/":     "https://host/dhis",
+		"HTTP://localhost:8080/dhis-web-commons/security/login": "http://localhost:8080",
+		"https://host/hmis/dhis/api/apps/dashboard/index.html":  "https://host/hmis/dhis",
+		"https://host:8443/dhis?redirect=/dhis-web-dashboard":   "https://host:8443/dhis",
+	}
+	for input, expected := range cases {
+		got, err := NormalizeDHIS2URL(input)
+		require.NoError(t, err, "input %q", input)
+		assert.Equal(t, expected, got, "input %q", input)
+	}
+
+	t.Run("Should reject unusable URLs", func(t *testing.T) {
+		for _, input := range []string{"", "   ", "ftp://host/dhis", "https:///api"} {
+			_, err := NormalizeDHIS2URL(input)
+			assert.Error(t, err, "input %q", input)
+		}
+	})
+}
+
+func TestNewClientNormalizesBaseURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dhis/api/me.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"username":"admin"}`))
+	}))
+	defer srv.Close()
+
+	variants := []string{
+		srv.URL + "/dhis",
+		srv.URL + "/dhis/",
+		srv.URL + "/dhis/api",
+		srv.URL + "/dhis/api/",
+		srv.URL + "/dhis/dhis-web-dashboard/",
+	}
+
+	for _, baseURL := range variants {
+		resp, err := NewClient(baseURL, "admin", "district").Get("api/me.json", nil)
+		require.NoError(t, err, "base URL %q", baseURL)
+		assert.Equal(t, http.StatusOK, resp.StatusCode(), "base URL %q", baseURL)
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SystemInfo is the subset of api/system/info used to locate and describe an instance
+type SystemInfo struct {
+	ContextPath string `json:"contextPath"`
+	Version     string `json:"version"`
+	Revision    string `json:"revision"`
+	ServerDate  string `json:"serverDate"`
+	BuildTime   string `json:"buildTime"`
+}
+
+// SystemInfo returns what the instance reports in api/system/info
+func (c *Client) SystemInfo() (*SystemInfo, error) {
+	return c.fetchSystemInfo(c.baseURL)
+}
+
+// ServerVersion returns the DHIS2 version the instance reports in api/system/info,
+// e.g. "2.40.3" or "2.41-SNAPSHOT"
+func (c *Client) ServerVersion() (string, error) {
+	info, err := c.fetchSystemInfo(c.baseURL)
+	if err != nil {
+		return "", err
+	}
+	return info.Version, nil
+}
+
+// ParseVersion extracts the major and minor numbers of a DHIS2 version string
+func ParseVersion(version string) (major, minor int, ok bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+ "); i >= 0 {
+		version = version[:i]
+	}
+
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, errMajor := strconv.Atoi(parts[0])
+	minor, errMinor := strconv.Atoi(parts[1])
+	if errMajor != nil || errMinor != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// VersionAtLeast reports whether version is major.minor or newer; unparseable versions are not
+func VersionAtLeast(version string, major, minor int) bool {
+	gotMajor, gotMinor, ok := ParseVersion(version)
+	if !ok {
+		return false
+	}
+	return gotMajor > major || (gotMajor == major && gotMinor >= minor)
+}
+
+// ReleasesApart counts the DHIS2 releases between two versions, e.g. 2 for 2.39.1 and
+// 2.41.0. Releases numbered without the leading "2." (v42) continue the 2.x series.
+func ReleasesApart(a, b string) (int, bool) {
+	releaseA, okA := release(a)
+	releaseB, okB := release(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	if releaseA > releaseB {
+		return releaseA - releaseB, true
+	}
+	return releaseB - releaseA, true
+}
+
+// release numbers a version's DHIS2 release: 40 for 2.40.3 and 42 for 42.0.0
+func release(version string) (int, bool) {
+	major, minor, ok := ParseVersion(version)
+	if !ok {
+		return 0, false
+	}
+	if major == 2 {
+		return minor, true
+	}
+	return major, true
+}
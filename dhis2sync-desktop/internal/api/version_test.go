@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemInfo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/system/info.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"version":"2.40.3","revision":"8a8ad2b","serverDate":"2024-05-02T09:15:00.000","buildTime":"2024-03-11T12:00:00.000","contextPath":"http://localhost:8080"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Run("Should return the version and build details", func(t *testing.T) {
+		info, err := NewClient(srv.URL, "admin", "district").SystemInfo()
+
+		require.NoError(t, err)
+		assert.Equal(t, "2.40.3", info.Version)
+		assert.Equal(t, "8a8ad2b", info.Revision)
+		assert.Equal(t, "2024-05-02T09:15:00.000", info.ServerDate)
+		assert.Equal(t, "2024-03-11T12:00:00.000", info.BuildTime)
+	})
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	t.Run("Should compare major and minor numbers", func(t *testing.T) {
+		assert.True(t, VersionAtLeast("2.40.3", 2, 40))
+		assert.True(t, VersionAtLeast("2.41-SNAPSHOT", 2, 40))
+		assert.True(t, VersionAtLeast("v42.0.0", 2, 40))
+		assert.False(t, VersionAtLeast("2.39.6", 2, 40))
+		assert.False(t, VersionAtLeast("2.9", 2, 40))
+	})
+
+	t.Run("Should treat unparseable versions as older", func(t *testing.T) {
+		assert.False(t, VersionAtLeast("", 2, 40))
+		assert.False(t, VersionAtLeast("unknown", 2, 40))
+		assert.False(t, VersionAtLeast("2", 2, 40))
+	})
+}
+
+func TestReleasesApart(t *testing.T) {
+	t.Run("Should count releases across both numbering schemes", func(t *testing.T) {
+		apart, ok := ReleasesApart("2.39.1", "2.41.0")
+		assert.True(t, ok)
+		assert.Equal(t, 2, apart)
+
+		apart, ok = ReleasesApart("42.0.0", "2.40.3")
+		assert.True(t, ok)
+		assert.Equal(t, 2, apart)
+
+		apart, ok = ReleasesApart("2.40.3", "2.40-SNAPSHOT")
+		assert.True(t, ok)
+		assert.Equal(t, 0, apart)
+	})
+
+	t.Run("Should not compare unparseable versions", func(t *testing.T) {
+		_, ok := ReleasesApart("unknown", "2.40.3")
+		assert.False(t, ok)
+	})
+}
@@ -14,7 +14,10 @@ import (
 	"golang.org/x/crypto/pbkdf2"
 )
 
-var encryptionKey []byte
+var (
+	encryptionKey []byte
+	keyFromEnv    bool // Key derived from ENCRYPTION_KEY rather than kept in the keychain
+)
 
 // InitEncryption initializes the encryption key from environment variable or keystore
 // Priority:
@@ -25,19 +28,9 @@ func InitEncryption() error {
 	// Try environment variable first (development/testing)
 	keyString := os.Getenv("ENCRYPTION_KEY")
 	if keyString != "" {
-		// Use PBKDF2 for proper key derivation
 		// Version-specific salt to allow future key rotation
-		salt := []byte("dhis2sync-v1-2024")
-		iterations := 100000 // OWASP recommended minimum
-		keyLength := 32      // AES-256
-
-		encryptionKey = pbkdf2.Key(
-			[]byte(keyString),
-			salt,
-			iterations,
-			keyLength,
-			sha256.New,
-		)
+		encryptionKey = DeriveKey(keyString, []byte("dhis2sync-v1-2024"))
+		keyFromEnv = true
 		return nil
 	}
 
@@ -48,9 +41,27 @@ func InitEncryption() error {
 	}
 
 	encryptionKey = key
+	keyFromEnv = false
 	return nil
 }
 
+// keyDerivationIterations is the PBKDF2 work factor for DeriveKey
+const keyDerivationIterations = 100000 // OWASP recommended minimum
+
+// DeriveKey derives a 32-byte AES-256 key from a passphrase with PBKDF2-SHA256
+func DeriveKey(passphrase string, salt []byte) []byte {
+	return pbkdf2.Key([]byte(passphrase), salt, keyDerivationIterations, 32, sha256.New)
+}
+
+// NewSalt returns a random salt for DeriveKey
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return salt, nil
+}
+
 // IsInitialized checks if encryption has been initialized
 func IsInitialized() bool {
 	return len(encryptionKey) > 0
@@ -62,8 +73,21 @@ func Encrypt(plaintext string) (string, error) {
 	if len(encryptionKey) == 0 {
 		return "", errors.New("encryption not initialized")
 	}
+	return EncryptWithKey(encryptionKey, plaintext)
+}
 
-	block, err := aes.NewCipher(encryptionKey)
+// Decrypt decrypts base64-encoded ciphertext using AES-256-GCM
+// Returns plaintext string
+func Decrypt(ciphertextB64 string) (string, error) {
+	if len(encryptionKey) == 0 {
+		return "", errors.New("encryption not initialized")
+	}
+	return DecryptWithKey(encryptionKey, ciphertextB64)
+}
+
+// EncryptWithKey is Encrypt with an explicit key, e.g. one from DeriveKey
+func EncryptWithKey(key []byte, plaintext string) (string, error) {
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", fmt.Errorf("failed to create cipher: %w", err)
 	}
@@ -86,20 +110,15 @@ func Encrypt(plaintext string) (string, error) {
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
-// Decrypt decrypts base64-encoded ciphertext using AES-256-GCM
-// Returns plaintext string
-func Decrypt(ciphertextB64 string) (string, error) {
-	if len(encryptionKey) == 0 {
-		return "", errors.New("encryption not initialized")
-	}
-
+// DecryptWithKey is Decrypt with an explicit key
+func DecryptWithKey(key []byte, ciphertextB64 string) (string, error) {
 	// Decode from base64
 	ciphertext, err := base64.StdEncoding.DecodeString(ciphertextB64)
 	if err != nil {
 		return "", fmt.Errorf("failed to decode base64: %w", err)
 	}
 
-	block, err := aes.NewCipher(encryptionKey)
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", fmt.Errorf("failed to create cipher: %w", err)
 	}
@@ -225,3 +225,33 @@ func TestDecryptWithoutInitialization(t *testing.T) {
 		encryptionKey = oldKey
 	})
 }
+
+func TestDeriveKey(t *testing.T) {
+	t.Run("Should derive the same key from the same passphrase and salt", func(t *testing.T) {
+		salt, err := NewSalt()
+		require.NoError(t, err)
+
+		key := DeriveKey("correct horse battery staple", salt)
+		assert.Len(t, key, 32)
+		assert.Equal(t, key, DeriveKey("correct horse battery staple", salt))
+
+		otherSalt, err := NewSalt()
+		require.NoError(t, err)
+		assert.NotEqual(t, key, DeriveKey("correct horse battery staple", otherSalt))
+	})
+
+	t.Run("Should only decrypt with the passphrase's key", func(t *testing.T) {
+		salt, err := NewSalt()
+		require.NoError(t, err)
+
+		encrypted, err := EncryptWithKey(DeriveKey("backup passphrase", salt), "district-password")
+		require.NoError(t, err)
+
+		plaintext, err := DecryptWithKey(DeriveKey("backup passphrase", salt), encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "district-password", plaintext)
+
+		_, err = DecryptWithKey(DeriveKey("wrong passphrase", salt), encrypted)
+		assert.Error(t, err)
+	})
+}
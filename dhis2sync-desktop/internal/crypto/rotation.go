@@ -0,0 +1,73 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+// ErrKeyFromEnvironment is returned by RotateKey when the key is derived from
+// ENCRYPTION_KEY, which only the environment can change
+var ErrKeyFromEnvironment = errors.New("encryption key comes from ENCRYPTION_KEY; change the variable to rotate it")
+
+// CurrentKey returns a copy of the active encryption key
+func CurrentKey() ([]byte, error) {
+	if len(encryptionKey) == 0 {
+		return nil, errors.New("encryption not initialized")
+	}
+	return append([]byte(nil), encryptionKey...), nil
+}
+
+// KeyFromEnvironment reports whether the active key is derived from ENCRYPTION_KEY,
+// in which case RotateKey can't replace it
+func KeyFromEnvironment() bool {
+	return keyFromEnv
+}
+
+// GenerateKey returns a new random 32-byte AES-256 key
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate random key: %w", err)
+	}
+	return key, nil
+}
+
+// Reencrypt decrypts ciphertext with oldKey and encrypts the plaintext with newKey
+func Reencrypt(ciphertextB64 string, oldKey, newKey []byte) (string, error) {
+	plaintext, err := DecryptWithKey(oldKey, ciphertextB64)
+	if err != nil {
+		return "", err
+	}
+	return EncryptWithKey(newKey, plaintext)
+}
+
+// RotateKey makes newKey the active key and stores it in the keychain in place of
+// oldKey, which must be the active key. Ciphertexts made with oldKey no longer decrypt
+// afterwards, so re-encrypt them with Reencrypt first; rotating back with the keys
+// swapped undoes a rotation whose re-encrypted data couldn't be saved.
+func RotateKey(oldKey, newKey []byte) error {
+	if len(encryptionKey) == 0 {
+		return errors.New("encryption not initialized")
+	}
+	if keyFromEnv {
+		return ErrKeyFromEnvironment
+	}
+	if subtle.ConstantTimeCompare(oldKey, encryptionKey) != 1 {
+		return errors.New("old key is not the active encryption key")
+	}
+	if len(newKey) != 32 {
+		return fmt.Errorf("new key must be 32 bytes, got %d", len(newKey))
+	}
+
+	// Unlike at startup, a key that can't be stored would be lost on restart along with
+	// every password encrypted under it
+	if err := keyring.Set(keystoreService, keystoreUser, string(newKey)); err != nil {
+		return fmt.Errorf("failed to store new key in keychain: %w", err)
+	}
+	encryptionKey = append([]byte(nil), newKey...)
+	return nil
+}
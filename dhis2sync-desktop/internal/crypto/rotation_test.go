@@ -0,0 +1,80 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+func TestReencrypt(t *testing.T) {
+	t.Run("Should move a ciphertext to the new key", func(t *testing.T) {
+		oldKey, err := CurrentKey()
+		require.NoError(t, err)
+		newKey, err := GenerateKey()
+		require.NoError(t, err)
+
+		encrypted, err := Encrypt("district-password")
+		require.NoError(t, err)
+
+		reencrypted, err := Reencrypt(encrypted, oldKey, newKey)
+		require.NoError(t, err)
+
+		_, err = Decrypt(reencrypted)
+		assert.Error(t, err, "The active key is still the old one")
+		plaintext, err := DecryptWithKey(newKey, reencrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "district-password", plaintext)
+	})
+
+	t.Run("Should fail when the old key doesn't match", func(t *testing.T) {
+		wrongKey, err := GenerateKey()
+		require.NoError(t, err)
+		encrypted, err := Encrypt("district-password")
+		require.NoError(t, err)
+
+		_, err = Reencrypt(encrypted, wrongKey, wrongKey)
+		assert.ErrorContains(t, err, "failed to decrypt")
+	})
+}
+
+func TestRotateKey(t *testing.T) {
+	keyring.MockInit()
+	original, originalFromEnv := encryptionKey, keyFromEnv
+	defer func() { encryptionKey, keyFromEnv = original, originalFromEnv }()
+
+	t.Run("Should refuse a key derived from ENCRYPTION_KEY", func(t *testing.T) {
+		keyFromEnv = true
+		newKey, err := GenerateKey()
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, RotateKey(original, newKey), ErrKeyFromEnvironment)
+	})
+
+	t.Run("Should store the new key and make it active", func(t *testing.T) {
+		keyFromEnv = false
+		encryptionKey = original
+		newKey, err := GenerateKey()
+		require.NoError(t, err)
+
+		require.NoError(t, RotateKey(original, newKey))
+
+		active, err := CurrentKey()
+		require.NoError(t, err)
+		assert.Equal(t, newKey, active)
+		stored, err := GenerateOrLoadKey()
+		require.NoError(t, err)
+		assert.Equal(t, newKey, stored)
+	})
+
+	t.Run("Should refuse an old key that isn't active", func(t *testing.T) {
+		keyFromEnv = false
+		encryptionKey = original
+		other, err := GenerateKey()
+		require.NoError(t, err)
+
+		assert.ErrorContains(t, RotateKey(other, other), "not the active encryption key")
+		assert.Equal(t, original, encryptionKey)
+	})
+}
@@ -132,6 +132,13 @@ func AutoMigrate(db *gorm.DB) error {
 		&models.ConnectionProfile{},
 		&models.ScheduledJob{},
 		&models.TaskProgress{},
+		&models.AsyncImportJob{},
+		&models.Notification{},
+		&models.MetadataImportOutcome{},
+		&models.StagedTransfer{},
+		&models.StagedDataValue{},
+		&models.JobRun{},
+		&models.CompletenessResult{},
 	)
 }
 
@@ -0,0 +1,31 @@
+// Package events emits task progress to the frontend. Each service keeps its own
+// per-task channel ("transfer:{id}", "metadata:{id}", ...) for existing listeners,
+// and every update is mirrored on ProgressChannel so one listener can follow all tasks.
+package events
+
+import (
+	"context"
+	"maps"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ProgressChannel carries progress for every task type, tagged with task_type
+const ProgressChannel = "task:progress"
+
+// emit is swapped in tests; the Wails runtime fatals without an app context
+var emit = runtime.EventsEmit
+
+// EmitProgress emits payload on the task's own channel and on ProgressChannel with
+// task_type added. The caller's payload is not modified.
+func EmitProgress(ctx context.Context, channel, taskType string, payload map[string]interface{}) {
+	if ctx == nil {
+		return // Services built without an app context (tests) have no frontend to notify
+	}
+	emit(ctx, channel, payload)
+
+	unified := make(map[string]interface{}, len(payload)+1)
+	maps.Copy(unified, payload)
+	unified["task_type"] = taskType
+	emit(ctx, ProgressChannel, unified)
+}
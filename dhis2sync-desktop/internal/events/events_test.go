@@ -0,0 +1,47 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitProgress(t *testing.T) {
+	t.Run("Should emit on the task channel and the unified channel", func(t *testing.T) {
+		type emitted struct {
+			channel string
+			payload map[string]interface{}
+		}
+		var got []emitted
+
+		original := emit
+		emit = func(ctx context.Context, name string, data ...interface{}) {
+			got = append(got, emitted{channel: name, payload: data[0].(map[string]interface{})})
+		}
+		defer func() { emit = original }()
+
+		payload := map[string]interface{}{"task_id": "t1", "status": "running", "progress": 40}
+		EmitProgress(context.Background(), "transfer:t1", "transfer", payload)
+
+		require.Len(t, got, 2)
+		assert.Equal(t, "transfer:t1", got[0].channel)
+		assert.NotContains(t, got[0].payload, "task_type", "The per-task payload should be unchanged")
+
+		assert.Equal(t, ProgressChannel, got[1].channel)
+		assert.Equal(t, "transfer", got[1].payload["task_type"])
+		assert.Equal(t, "t1", got[1].payload["task_id"])
+		assert.Equal(t, 40, got[1].payload["progress"])
+	})
+	t.Run("Should skip emitting without an app context", func(t *testing.T) {
+		called := false
+		original := emit
+		emit = func(ctx context.Context, name string, data ...interface{}) { called = true }
+		defer func() { emit = original }()
+
+		EmitProgress(nil, "transfer:t1", "transfer", map[string]interface{}{"task_id": "t1"})
+
+		assert.False(t, called)
+	})
+}
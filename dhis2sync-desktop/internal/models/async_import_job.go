@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AsyncImportJob records a DHIS2 async import job submitted by a transfer task,
+// so polling can be resumed after an app restart instead of losing the result
+type AsyncImportJob struct {
+	ID          string    `gorm:"primaryKey" json:"id"`
+	TaskID      string    `gorm:"not null;index;column:task_id" json:"task_id"`
+	ProfileID   string    `gorm:"not null;column:profile_id" json:"profile_id"`
+	JobID       string    `gorm:"not null;column:job_id" json:"job_id"` // DHIS2 job ID
+	JobType     string    `gorm:"column:job_type" json:"job_type"`      // DHIS2 task category, e.g. DATAVALUE_IMPORT
+	ChunkNum    int       `gorm:"column:chunk_num" json:"chunk_num"`
+	TotalChunks int       `gorm:"column:total_chunks" json:"total_chunks"`
+	NumValues   int       `gorm:"column:num_values" json:"num_values"`
+	Status      string    `gorm:"not null;default:pending" json:"status"` // pending, completed, failed
+	Summary     string    `gorm:"type:text" json:"summary"`               // JSON ImportSummary once completed
+	Error       string    `gorm:"type:text" json:"error"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating record
+func (j *AsyncImportJob) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == "" {
+		j.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for GORM
+func (AsyncImportJob) TableName() string {
+	return "async_import_jobs"
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// CompletenessResult is a finished completeness assessment or comparison, kept so its
+// results can still be read and exported after the app restarts; its ID is the task ID
+type CompletenessResult struct {
+	ID               string    `gorm:"primaryKey" json:"id"`
+	ProfileID        string    `gorm:"index;column:profile_id" json:"profile_id"`
+	Status           string    `gorm:"not null" json:"status"` // completed, error
+	Progress         int       `json:"progress"`
+	Messages         string    `gorm:"type:text" json:"messages"`                                   // JSON array of strings
+	Results          string    `gorm:"type:text" json:"results"`                                    // JSON AssessmentResult
+	Comparison       string    `gorm:"type:text" json:"comparison"`                                 // JSON ComparisonResult
+	Request          string    `gorm:"type:text" json:"request"`                                    // JSON AssessmentRequest
+	PeriodCompliance string    `gorm:"type:text;column:period_compliance" json:"period_compliance"` // JSON period -> orgUnitID -> percentage
+	CompletedAt      int64     `gorm:"column:completed_at" json:"completed_at"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (CompletenessResult) TableName() string {
+	return "completeness_results"
+}
@@ -17,9 +17,56 @@ type ConnectionProfile struct {
 	SourcePasswordEnc string    `gorm:"not null;column:source_password_enc" json:"-"` // Encrypted, never expose in JSON
 	DestURL           string    `gorm:"not null;column:dest_url" json:"dest_url"`
 	DestUsername      string    `gorm:"not null;column:dest_username" json:"dest_username"`
-	DestPasswordEnc   string    `gorm:"not null;column:dest_password_enc" json:"-"` // Encrypted, never expose in JSON
+	DestPasswordEnc   string    `gorm:"not null;column:dest_password_enc" json:"-"`                // Encrypted, never expose in JSON
+	NameMatchRules    string    `gorm:"type:text;column:name_match_rules" json:"name_match_rules"` // Suffixes or "re:<regex>" stripped before fuzzy name matching, one per line
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
+
+	// LastUsedAt is set on selection and whenever a transfer/assessment starts; nil if never used
+	LastUsedAt *time.Time `gorm:"index;column:last_used_at" json:"last_used_at,omitempty"`
+
+	// WriteWindow is a JSON {"start","end","days"} span of local time in which transfers,
+	// bulk completion and metadata imports may write to the destination; empty allows any time
+	WriteWindow string `gorm:"type:text;column:write_window" json:"write_window,omitempty"`
+
+	// SourceAuthType/DestAuthType are "basic" (username + password) or "token" (a personal
+	// access token kept in the *TokenEnc field); empty means basic for older profiles
+	SourceAuthType string `gorm:"column:source_auth_type;default:basic" json:"source_auth_type"`
+	SourceTokenEnc string `gorm:"column:source_token_enc" json:"-"` // Encrypted, never expose in JSON
+	DestAuthType   string `gorm:"column:dest_auth_type;default:basic" json:"dest_auth_type"`
+	DestTokenEnc   string `gorm:"column:dest_token_enc" json:"-"` // Encrypted, never expose in JSON
+
+	// RateLimit caps the requests to each of this profile's instances, across all its
+	// API clients, at this many per second to spare small servers; 0 is unlimited
+	RateLimit int `gorm:"column:rate_limit;default:0" json:"rate_limit"`
+
+	// RequestTimeoutSeconds bounds each request of the API clients built for this profile,
+	// long for slow links and short to fail fast on a LAN; 0 uses the client default
+	RequestTimeoutSeconds int `gorm:"column:request_timeout_seconds;default:0" json:"request_timeout_seconds"`
+
+	// ProxyURL routes this profile's requests through an HTTP(S) or SOCKS5 proxy, e.g.
+	// "http://user@proxy.moh.local:3128"; empty uses HTTP_PROXY/HTTPS_PROXY/NO_PROXY.
+	// It never holds a password: that is kept in ProxyPasswordEnc.
+	ProxyURL         string `gorm:"column:proxy_url" json:"proxy_url,omitempty"`
+	ProxyPasswordEnc string `gorm:"column:proxy_password_enc" json:"-"` // Encrypted, never expose in JSON
+}
+
+// Credentials returns the URL, username, auth type and encrypted secret (the token for
+// token auth, otherwise the password) of the "source" or destination instance
+func (cp *ConnectionProfile) Credentials(sourceOrDest string) (url, username, authType, secretEnc string) {
+	if sourceOrDest == "source" {
+		url, username, authType, secretEnc = cp.SourceURL, cp.SourceUsername, cp.SourceAuthType, cp.SourcePasswordEnc
+		if authType == "token" {
+			secretEnc = cp.SourceTokenEnc
+		}
+		return
+	}
+
+	url, username, authType, secretEnc = cp.DestURL, cp.DestUsername, cp.DestAuthType, cp.DestPasswordEnc
+	if authType == "token" {
+		secretEnc = cp.DestTokenEnc
+	}
+	return
 }
 
 // BeforeCreate hook to generate UUID before creating record
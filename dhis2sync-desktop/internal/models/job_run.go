@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JobRun records one execution of a scheduled job for the job's run history
+type JobRun struct {
+	ID         string     `gorm:"primaryKey" json:"id"`
+	JobID      string     `gorm:"not null;index;column:job_id" json:"job_id"`
+	TaskID     string     `gorm:"column:task_id" json:"task_id,omitempty"` // Task started by the run, if any
+	StartedAt  time.Time  `gorm:"not null;index;column:started_at" json:"started_at"`
+	FinishedAt *time.Time `gorm:"column:finished_at" json:"finished_at"`
+	Status     string     `gorm:"not null;default:running" json:"status"` // running, completed, partial, skipped, error
+	Summary    string     `gorm:"type:text" json:"summary"`
+	Error      string     `gorm:"type:text" json:"error,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID before creating record
+func (r *JobRun) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for GORM
+func (JobRun) TableName() string {
+	return "job_runs"
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MetadataImportOutcome records a metadata object a resumable apply imported
+// successfully, so retrying that apply after a partial failure sends only the rest
+type MetadataImportOutcome struct {
+	ID         string    `gorm:"primaryKey" json:"id"`
+	ApplyID    string    `gorm:"not null;index:idx_apply_object,unique;column:apply_id" json:"apply_id"`
+	ProfileID  string    `gorm:"not null;column:profile_id" json:"profile_id"`
+	ObjectType string    `gorm:"not null;index:idx_apply_object,unique;column:object_type" json:"object_type"` // e.g. dataElements
+	UID        string    `gorm:"not null;index:idx_apply_object,unique;column:uid" json:"uid"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating record
+func (o *MetadataImportOutcome) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == "" {
+		o.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for GORM
+func (MetadataImportOutcome) TableName() string {
+	return "metadata_import_outcomes"
+}
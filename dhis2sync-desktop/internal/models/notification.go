@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Notification is a persisted inbox entry summarizing how a background task ended,
+// so the user sees it even after navigating away from the task's screen
+type Notification struct {
+	ID        string    `gorm:"primaryKey" json:"id"`
+	TaskID    string    `gorm:"index;column:task_id" json:"task_id"`
+	TaskType  string    `gorm:"column:task_type" json:"task_type"` // transfer, completeness, bulk_completeness, metadata, tracker, audit, healthcheck
+	Type      string    `gorm:"not null" json:"type"`              // success, error, warning
+	Title     string    `gorm:"not null" json:"title"`
+	Body      string    `gorm:"type:text" json:"body"`
+	Read      bool      `gorm:"not null;default:false;index" json:"read"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating record
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == "" {
+		n.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for GORM
+func (Notification) TableName() string {
+	return "notifications"
+}
@@ -20,6 +20,9 @@ type ScheduledJob struct {
 	NextRunAt   *time.Time `gorm:"column:next_run_at" json:"next_run_at"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// LastSuccessAt is when the last fully successful run started (delta-sync transfers chain off it)
+	LastSuccessAt *time.Time `gorm:"column:last_success_at" json:"last_success_at,omitempty"`
 }
 
 // BeforeCreate hook to generate UUID before creating record
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StagedTransfer is a transfer whose mapped values were written to the staging table
+// for review instead of being imported; its ID is the staging ID
+type StagedTransfer struct {
+	ID            string    `gorm:"primaryKey" json:"id"`
+	ProfileID     string    `gorm:"not null;index;column:profile_id" json:"profile_id"`
+	DestDatasetID string    `gorm:"column:dest_dataset_id" json:"dest_dataset_id"`
+	Request       string    `gorm:"type:text" json:"request"`               // JSON TransferRequest used for staging and pushing
+	Status        string    `gorm:"not null;default:staging" json:"status"` // staging, staged, pushing, pushed, error
+	ValueCount    int       `gorm:"column:value_count" json:"value_count"`
+	PushTaskID    string    `gorm:"column:push_task_id" json:"push_task_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (StagedTransfer) TableName() string {
+	return "staged_transfers"
+}
+
+// StagedDataValue is one destination-ready data value held for review before pushing
+type StagedDataValue struct {
+	ID                   string    `gorm:"primaryKey" json:"id"`
+	StagingID            string    `gorm:"not null;index;column:staging_id" json:"staging_id"`
+	DataElement          string    `gorm:"not null;column:data_element" json:"data_element"`
+	Period               string    `gorm:"not null" json:"period"`
+	OrgUnit              string    `gorm:"not null;column:org_unit" json:"org_unit"`
+	CategoryOptionCombo  string    `gorm:"column:category_option_combo" json:"category_option_combo"`
+	AttributeOptionCombo string    `gorm:"column:attribute_option_combo" json:"attribute_option_combo"`
+	Value                string    `gorm:"type:text" json:"value"`
+	Comment              string    `gorm:"type:text" json:"comment"`
+	FollowUp             bool      `gorm:"column:follow_up" json:"follow_up"`
+	Edited               bool      `json:"edited"` // Changed by the reviewer after staging
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating record
+func (v *StagedDataValue) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == "" {
+		v.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for GORM
+func (StagedDataValue) TableName() string {
+	return "staged_data_values"
+}
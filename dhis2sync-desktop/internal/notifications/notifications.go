@@ -0,0 +1,111 @@
+// Package notifications records an inbox entry whenever a background task finishes,
+// fails or needs the user's attention.
+package notifications
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+
+	"dhis2sync-desktop/internal/database"
+	"dhis2sync-desktop/internal/models"
+)
+
+// getDB is swapped in tests
+var getDB = database.GetDB
+
+// taskLabels names task types in notification titles
+var taskLabels = map[string]string{
+	"transfer":          "Transfer",
+	"completeness":      "Completeness assessment",
+	"bulk_completeness": "Bulk completeness action",
+	"metadata":          "Metadata comparison",
+	"metadata_payload":  "Metadata payload build",
+	"tracker":           "Tracker transfer",
+	"audit":             "Audit",
+	"healthcheck":       "Healthcheck",
+}
+
+// IsNotable reports whether a task moving into status deserves a notification
+func IsNotable(status string) bool {
+	switch status {
+	case "completed", "error", "awaiting_user_decision", "stalled":
+		return true
+	}
+	return false
+}
+
+// TaskFinished records a notification for a task that reached status, with body
+// usually the task's final progress message. Other statuses are ignored, as is a
+// missing database (e.g. in tests).
+func TaskFinished(taskID, taskType, status, body string) {
+	if !IsNotable(status) {
+		return
+	}
+	db := getDB()
+	if db == nil {
+		return
+	}
+
+	if _, err := create(db, taskID, taskType, status, body); err != nil {
+		log.Printf("⚠ Failed to record notification for task %s: %v", taskID, err)
+	}
+}
+
+// create builds and saves the notification for a task status
+func create(db *gorm.DB, taskID, taskType, status, body string) (*models.Notification, error) {
+	label := taskLabels[taskType]
+	if label == "" {
+		label = "Task"
+	}
+
+	n := &models.Notification{
+		TaskID:   taskID,
+		TaskType: taskType,
+		Body:     body,
+	}
+	switch status {
+	case "completed":
+		n.Type = "success"
+		n.Title = fmt.Sprintf("%s completed", label)
+	case "error":
+		n.Type = "error"
+		n.Title = fmt.Sprintf("%s failed", label)
+	default:
+		n.Type = "warning"
+		n.Title = fmt.Sprintf("%s needs attention", label)
+	}
+
+	if err := db.Create(n).Error; err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// List returns notifications newest first, optionally only unread ones
+func List(db *gorm.DB, unreadOnly bool, limit int) ([]models.Notification, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := db.Order("created_at DESC").Limit(limit)
+	if unreadOnly {
+		query = query.Where("read = ?", false)
+	}
+
+	var notifications []models.Notification
+	if err := query.Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// MarkRead marks the given notifications read; no IDs marks all of them
+func MarkRead(db *gorm.DB, ids []string) error {
+	query := db.Model(&models.Notification{}).Where("read = ?", false)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	return query.Update("read", true).Error
+}
@@ -0,0 +1,72 @@
+package notifications
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"dhis2sync-desktop/internal/models"
+)
+
+func TestTaskFinished(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Notification{}))
+
+	original := getDB
+	getDB = func() *gorm.DB { return db }
+	defer func() { getDB = original }()
+
+	t.Run("Should record terminal statuses only", func(t *testing.T) {
+		TaskFinished("task-1", "transfer", "running", "Fetching data")
+		TaskFinished("task-1", "transfer", "completed", "Transfer complete: 120 values imported")
+		TaskFinished("task-2", "completeness", "error", "Assessment failed: HTTP 500")
+		TaskFinished("task-3", "unknown", "awaiting_user_decision", "Unmapped values found")
+
+		var all []models.Notification
+		require.NoError(t, db.Order("created_at ASC").Find(&all).Error)
+		require.Len(t, all, 3)
+
+		assert.Equal(t, "task-1", all[0].TaskID)
+		assert.Equal(t, "success", all[0].Type)
+		assert.Equal(t, "Transfer completed", all[0].Title)
+		assert.Equal(t, "Transfer complete: 120 values imported", all[0].Body)
+		assert.False(t, all[0].Read)
+
+		assert.Equal(t, "error", all[1].Type)
+		assert.Equal(t, "Completeness assessment failed", all[1].Title)
+
+		assert.Equal(t, "warning", all[2].Type)
+		assert.Equal(t, "Task needs attention", all[2].Title)
+	})
+
+	t.Run("Should list unread and mark read", func(t *testing.T) {
+		unread, err := List(db, true, 0)
+		require.NoError(t, err)
+		require.Len(t, unread, 3)
+
+		require.NoError(t, MarkRead(db, []string{unread[0].ID}))
+		unread, err = List(db, true, 0)
+		require.NoError(t, err)
+		assert.Len(t, unread, 2)
+
+		require.NoError(t, MarkRead(db, nil))
+		unread, err = List(db, true, 0)
+		require.NoError(t, err)
+		assert.Empty(t, unread)
+
+		all, err := List(db, false, 2)
+		require.NoError(t, err)
+		assert.Len(t, all, 2, "Limit should cap the result")
+	})
+
+	t.Run("Should do nothing without a database", func(t *testing.T) {
+		getDB = func() *gorm.DB { return nil }
+		assert.NotPanics(t, func() {
+			TaskFinished("task-4", "transfer", "completed", "done")
+		})
+	})
+}
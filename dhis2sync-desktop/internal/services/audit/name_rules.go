@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultNameMatchRules are applied when a profile has no rules configured
+const DefaultNameMatchRules = " P.S\n Primary School"
+
+// NameRule strips part of an org unit name before fuzzy matching.
+// Either Suffix (case-insensitive) or Pattern is set.
+type NameRule struct {
+	Suffix  string
+	Pattern *regexp.Regexp
+}
+
+// ParseNameRules parses one rule per line: a plain suffix such as " Health Centre",
+// or "re:<regex>" whose matches are removed. Blank lines and lines starting with
+// '#' are ignored. An empty spec yields DefaultNameMatchRules.
+func ParseNameRules(spec string) ([]NameRule, error) {
+	if strings.TrimSpace(spec) == "" {
+		spec = DefaultNameMatchRules
+	}
+
+	rules := []NameRule{}
+	for i, line := range strings.Split(spec, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "re:") {
+			re, err := regexp.Compile(strings.TrimPrefix(line, "re:"))
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i+1, err)
+			}
+			rules = append(rules, NameRule{Pattern: re})
+			continue
+		}
+
+		rules = append(rules, NameRule{Suffix: line})
+	}
+
+	return rules, nil
+}
+
+// ApplyNameRules applies every rule in order and trims surrounding whitespace
+func ApplyNameRules(name string, rules []NameRule) string {
+	cleaned := name
+	for _, rule := range rules {
+		if rule.Pattern != nil {
+			cleaned = rule.Pattern.ReplaceAllString(cleaned, "")
+			continue
+		}
+		if len(cleaned) >= len(rule.Suffix) && strings.EqualFold(cleaned[len(cleaned)-len(rule.Suffix):], rule.Suffix) {
+			cleaned = cleaned[:len(cleaned)-len(rule.Suffix)]
+		}
+	}
+	return strings.TrimSpace(cleaned)
+}
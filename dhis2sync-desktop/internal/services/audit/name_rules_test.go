@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"dhis2sync-desktop/internal/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNameRules(t *testing.T) {
+	t.Run("Should fall back to default rules when empty", func(t *testing.T) {
+		rules, err := ParseNameRules("")
+
+		require.NoError(t, err)
+		assert.Len(t, rules, 2)
+		assert.Equal(t, "St Mary", ApplyNameRules("St Mary Primary School", rules))
+	})
+
+	t.Run("Should parse suffix and regex rules, skipping blanks and comments", func(t *testing.T) {
+		rules, err := ParseNameRules(" Health Centre\n\n# clinics\nre:\\s+HC\\s*(II|III|IV)$\n")
+
+		require.NoError(t, err)
+		require.Len(t, rules, 2)
+		assert.Equal(t, " Health Centre", rules[0].Suffix)
+		assert.NotNil(t, rules[1].Pattern)
+	})
+
+	t.Run("Should reject an invalid regex", func(t *testing.T) {
+		_, err := ParseNameRules("re:([unclosed")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestApplyNameRules(t *testing.T) {
+	rules, err := ParseNameRules(" Health Centre\n CHC\n Dispensary\nre:\\s+HC\\s*(II|III|IV)$")
+	require.NoError(t, err)
+
+	cases := map[string]string{
+		"Kasese Health Centre": "Kasese",
+		"Kasese health centre": "Kasese",
+		"Bwera CHC":            "Bwera",
+		"Mpondwe Dispensary":   "Mpondwe",
+		"Rwesande HC III":      "Rwesande",
+		"Kagando Hospital":     "Kagando Hospital",
+	}
+	for input, expected := range cases {
+		assert.Equal(t, expected, ApplyNameRules(input, rules), "input %q", input)
+	}
+}
+
+func TestFindBestMatchWithNameRules(t *testing.T) {
+	destNames := []string{"Kasese", "Bwera", "Rwesande"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		term := strings.ToLower(strings.TrimPrefix(r.URL.Query().Get("filter"), "name:ilike:"))
+		items := []map[string]string{}
+		for i, name := range destNames {
+			if strings.Contains(strings.ToLower(name), term) {
+				items = append(items, map[string]string{"id": string(rune('a' + i)), "name": name})
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"organisationUnits": items})
+	}))
+	defer srv.Close()
+
+	service := NewService(context.Background())
+	client := api.NewClient(srv.URL, "admin", "district")
+	sources := []string{"Kasese Health Centre", "Bwera CHC", "Rwesande HC III"}
+
+	countMatches := func(rules []NameRule) int {
+		matched := 0
+		for _, name := range sources {
+			suggestion, err := service.findBestMatch(client, "organisationUnits", name, rules)
+			require.NoError(t, err)
+			if suggestion != nil {
+				matched++
+			}
+		}
+		return matched
+	}
+
+	defaults, err := ParseNameRules("")
+	require.NoError(t, err)
+	configured, err := ParseNameRules(" Health Centre\n CHC\nre:\\s+HC\\s*(II|III|IV)$")
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, countMatches(defaults), "School suffixes should not help health facilities")
+	assert.Equal(t, 3, countMatches(configured), "Configured suffixes should match every facility")
+}
@@ -0,0 +1,174 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dhis2sync-desktop/internal/api"
+)
+
+// Data issue types (DataIssue.IssueType)
+const (
+	IssueInvalidEmail      = "invalid_email"
+	IssueInvalidPhone      = "invalid_phone"
+	IssueInvalidNumber     = "invalid_number"
+	IssueInvalidInteger    = "invalid_integer"
+	IssueNotPositive       = "not_positive_integer"
+	IssueNegative          = "negative_integer"
+	IssueInvalidPercentage = "invalid_percentage"
+	IssueInvalidBoolean    = "invalid_boolean"
+	IssueBlankText         = "blank_text"
+	IssueTextTooLong       = "text_too_long"
+)
+
+// maxTextLength is the longest value DHIS2 stores for TEXT and LONG_TEXT elements
+const maxTextLength = 50000
+
+var (
+	// emailPattern is deliberately loose: one @, no spaces, a dot in the domain
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	// phonePattern mirrors the DHIS2 PHONE_NUMBER validation
+	phonePattern = regexp.MustCompile(`^[0-9+()#./\s-]{6,50}$`)
+)
+
+// ValueIssue reports what is wrong with a value for its data element's value type,
+// or "" when it is acceptable (or the type isn't checked)
+func ValueIssue(valueType, value string) string {
+	switch valueType {
+	case "EMAIL":
+		if !emailPattern.MatchString(value) {
+			return IssueInvalidEmail
+		}
+	case "PHONE_NUMBER":
+		if !phonePattern.MatchString(value) {
+			return IssueInvalidPhone
+		}
+	case "NUMBER", "UNIT_INTERVAL":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return IssueInvalidNumber
+		}
+	case "PERCENTAGE":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || n < 0 || n > 100 {
+			return IssueInvalidPercentage
+		}
+	case "INTEGER":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return IssueInvalidInteger
+		}
+	case "INTEGER_POSITIVE":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return IssueInvalidInteger
+		}
+		if n <= 0 {
+			return IssueNotPositive
+		}
+	case "INTEGER_ZERO_OR_POSITIVE":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return IssueInvalidInteger
+		}
+		if n < 0 {
+			return IssueNegative
+		}
+	case "BOOLEAN":
+		if value != "true" && value != "false" {
+			return IssueInvalidBoolean
+		}
+	case "TRUE_ONLY":
+		if value != "true" {
+			return IssueInvalidBoolean
+		}
+	case "TEXT", "LONG_TEXT":
+		if strings.TrimSpace(value) == "" {
+			return IssueBlankText
+		}
+		if len([]rune(value)) > maxTextLength {
+			return IssueTextTooLong
+		}
+	}
+	return ""
+}
+
+// FetchValueTypes maps each of a dataset's data elements to its value type
+func FetchValueTypes(client *api.Client, datasetID string) (map[string]string, error) {
+	resp, err := client.Get(fmt.Sprintf("api/dataSets/%s", datasetID), map[string]string{
+		"fields": "dataSetElements[dataElement[id,valueType]]",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), api.BodySnippet(resp.Body(), 200))
+	}
+
+	var dataSet struct {
+		DataSetElements []struct {
+			DataElement struct {
+				ID        string `json:"id"`
+				ValueType string `json:"valueType"`
+			} `json:"dataElement"`
+		} `json:"dataSetElements"`
+	}
+	if err := json.Unmarshal(resp.Body(), &dataSet); err != nil {
+		return nil, fmt.Errorf("failed to parse dataset %s: %w", datasetID, err)
+	}
+
+	valueTypes := make(map[string]string, len(dataSet.DataSetElements))
+	for _, dse := range dataSet.DataSetElements {
+		valueTypes[dse.DataElement.ID] = dse.DataElement.ValueType
+	}
+	return valueTypes, nil
+}
+
+// issueCollector counts offending values per data element and issue type
+type issueCollector struct {
+	valueTypes map[string]string
+	issues     map[string]*DataIssue // "dataElement:issueType" -> issue
+}
+
+func newIssueCollector(valueTypes map[string]string) *issueCollector {
+	return &issueCollector{valueTypes: valueTypes, issues: make(map[string]*DataIssue)}
+}
+
+// check records the value if it is invalid for its data element; the first offending
+// value of each kind is kept as the example
+func (c *issueCollector) check(dataElement, value string) {
+	issueType := ValueIssue(c.valueTypes[dataElement], value)
+	if issueType == "" {
+		return
+	}
+
+	key := dataElement + ":" + issueType
+	issue, ok := c.issues[key]
+	if !ok {
+		issue = &DataIssue{DataElementID: dataElement, Value: value, IssueType: issueType}
+		c.issues[key] = issue
+	}
+	issue.Count++
+}
+
+// list returns the issues, most offending values first
+func (c *issueCollector) list() []DataIssue {
+	issues := make([]DataIssue, 0, len(c.issues))
+	for _, issue := range c.issues {
+		issues = append(issues, *issue)
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Count != issues[j].Count {
+			return issues[i].Count > issues[j].Count
+		}
+		if issues[i].DataElementID != issues[j].DataElementID {
+			return issues[i].DataElementID < issues[j].DataElementID
+		}
+		return issues[i].IssueType < issues[j].IssueType
+	})
+	return issues
+}
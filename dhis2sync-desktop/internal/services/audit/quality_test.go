@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"net/http"
+	"testing"
+
+	"dhis2sync-desktop/internal/api/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueIssue(t *testing.T) {
+	cases := []struct {
+		valueType, value, issue string
+	}{
+		{"EMAIL", "nurse@clinic.org", ""},
+		{"EMAIL", "nurse.clinic.org", IssueInvalidEmail},
+		{"PHONE_NUMBER", "+256 (0)772-123456", ""},
+		{"PHONE_NUMBER", "0772", IssueInvalidPhone},
+		{"PHONE_NUMBER", "call me", IssueInvalidPhone},
+		{"NUMBER", "12.5", ""},
+		{"NUMBER", "12,5", IssueInvalidNumber},
+		{"INTEGER", "-3", ""},
+		{"INTEGER", "3.0", IssueInvalidInteger},
+		{"INTEGER_POSITIVE", "0", IssueNotPositive},
+		{"INTEGER_ZERO_OR_POSITIVE", "-1", IssueNegative},
+		{"PERCENTAGE", "101", IssueInvalidPercentage},
+		{"BOOLEAN", "yes", IssueInvalidBoolean},
+		{"TEXT", "   ", IssueBlankText},
+		{"TEXT", "fine", ""},
+		{"DATE", "whatever", ""},
+		{"", "unknown element", ""},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.issue, ValueIssue(c.valueType, c.value), "%s %q", c.valueType, c.value)
+	}
+}
+
+func TestIssueCollector(t *testing.T) {
+	t.Run("Should count offending values per element and issue type", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/dataSets/ds1": apitest.Raw(http.StatusOK, `{"dataSetElements":[
+				{"dataElement":{"id":"dePhone","valueType":"PHONE_NUMBER"}},
+				{"dataElement":{"id":"deCount","valueType":"INTEGER_POSITIVE"}}
+			]}`),
+		})
+		valueTypes, err := FetchValueTypes(srv.Client(), "ds1")
+		require.NoError(t, err)
+
+		issues := newIssueCollector(valueTypes)
+		issues.check("dePhone", "12")
+		issues.check("dePhone", "0772 123456")
+		issues.check("dePhone", "n/a")
+		issues.check("deCount", "-2")
+		issues.check("deOther", "anything")
+
+		assert.Equal(t, []DataIssue{
+			{DataElementID: "dePhone", Value: "12", IssueType: IssueInvalidPhone, Count: 2},
+			{DataElementID: "deCount", Value: "-2", IssueType: IssueNotPositive, Count: 1},
+		}, issues.list())
+	})
+}
@@ -0,0 +1,139 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"dhis2sync-desktop/internal/api"
+)
+
+// resolveConcurrency bounds the parallel source/destination lookups while resolving missing items
+const resolveConcurrency = 8
+
+// resolveConcurrently runs resolve for every item with at most resolveConcurrency
+// in flight. Each call gets its own element of items, so results are merged in place
+// without locking. Items not yet started when ctx is cancelled are skipped and
+// ctx.Err() is returned.
+func resolveConcurrently(ctx context.Context, items []MissingItem, resolve func(ctx context.Context, item *MissingItem)) error {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	workers := resolveConcurrency
+	if len(items) < workers {
+		workers = len(items)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				resolve(ctx, &items[i])
+			}
+		}()
+	}
+
+feed:
+	for i := range items {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// fetchSourceName looks up an item's name in the source; "" when it can't be read
+func fetchSourceName(ctx context.Context, client *api.Client, resource, id string) string {
+	resp, err := client.GetWithContext(ctx, fmt.Sprintf("api/%s/%s", resource, id), map[string]string{"fields": "name"})
+	if err != nil || !resp.IsSuccess() {
+		return ""
+	}
+
+	var nameResp struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(resp.Body(), &nameResp); err != nil {
+		return ""
+	}
+	return nameResp.Name
+}
+
+// nameItems fills in the items' names from the source with batched id:in lookups,
+// falling back to one lookup per item if a batch can't be read
+func (s *Service) nameItems(ctx context.Context, client *api.Client, resource string, items []MissingItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Org unit names may already be in the shared cache
+	orgUnits := resource == "organisationUnits"
+	ids := make([]string, 0, len(items))
+	for i, item := range items {
+		if orgUnits {
+			if name, ok := client.CachedOrgUnitName(item.ID); ok {
+				items[i].Name = name
+				continue
+			}
+		}
+		ids = append(ids, item.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	names, err := s.fetchNames(client, resource, ids)
+	if err != nil {
+		return resolveConcurrently(ctx, items, func(ctx context.Context, item *MissingItem) {
+			if item.Name == "" {
+				item.Name = fetchSourceName(ctx, client, resource, item.ID)
+			}
+		})
+	}
+	for i := range items {
+		if name, ok := names[items[i].ID]; ok {
+			items[i].Name = name
+			if orgUnits {
+				client.CacheOrgUnitName(items[i].ID, name)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveMissingOrgUnits names missing org units from the source and suggests
+// destination matches by name
+func (s *Service) resolveMissingOrgUnits(ctx context.Context, sourceClient, destClient *api.Client, items []MissingItem, rules []NameRule) error {
+	if err := s.nameItems(ctx, sourceClient, "organisationUnits", items); err != nil {
+		return err
+	}
+	return resolveConcurrently(ctx, items, func(ctx context.Context, item *MissingItem) {
+		if item.Name == "" || ctx.Err() != nil {
+			return
+		}
+		if suggestion, _ := s.findBestMatch(destClient, "organisationUnits", item.Name, rules); suggestion != nil {
+			item.Suggestion = suggestion
+		}
+	})
+}
+
+// resolveMissingCOCs names missing category option combos from the source and suggests
+// destination matches by structure, sharing lookups through cache
+func (s *Service) resolveMissingCOCs(ctx context.Context, sourceClient, destClient *api.Client, items []MissingItem, cache *cocResolveCache) error {
+	if err := s.nameItems(ctx, sourceClient, "categoryOptionCombos", items); err != nil {
+		return err
+	}
+	return resolveConcurrently(ctx, items, func(ctx context.Context, item *MissingItem) {
+		if item.Name == "" || ctx.Err() != nil {
+			return
+		}
+		if suggestion, _ := resolveCOCByStructure(sourceClient, destClient, item.ID, item.Name, cache); suggestion != nil {
+			item.Suggestion = suggestion
+		}
+	})
+}
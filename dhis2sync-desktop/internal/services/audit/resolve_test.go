@@ -0,0 +1,190 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"dhis2sync-desktop/internal/api"
+	"dhis2sync-desktop/internal/api/apitest"
+	"dhis2sync-desktop/internal/crypto"
+	"dhis2sync-desktop/internal/database"
+	"dhis2sync-desktop/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newNamingServer serves a name for every org unit, singly or by id:in, and a destination
+// match for every name search
+func newNamingServer(t *testing.T, inFlight, peak *int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(inFlight, 1)
+		defer atomic.AddInt32(inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(peak)
+			if current <= seen || atomic.CompareAndSwapInt32(peak, seen, current) {
+				break
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if id := strings.TrimPrefix(r.URL.Path, "/api/organisationUnits/"); id != r.URL.Path {
+			json.NewEncoder(w).Encode(map[string]string{"name": "Facility " + id})
+			return
+		}
+
+		filter := r.URL.Query().Get("filter")
+		if ids := strings.TrimPrefix(filter, "id:in:["); ids != filter {
+			named := []map[string]string{}
+			for _, id := range strings.Split(strings.TrimSuffix(ids, "]"), ",") {
+				named = append(named, map[string]string{"id": id, "name": "Facility " + id})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"organisationUnits": named})
+			return
+		}
+
+		name := strings.TrimPrefix(filter, "name:ilike:")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"organisationUnits": []map[string]string{{"id": "dest-" + strings.TrimPrefix(name, "Facility "), "name": name}},
+		})
+	}))
+}
+
+func TestResolveMissingOrgUnits(t *testing.T) {
+	rules, err := ParseNameRules("")
+	require.NoError(t, err)
+
+	t.Run("Should name and suggest every item with bounded concurrency", func(t *testing.T) {
+		var inFlight, peak int32
+		srv := newNamingServer(t, &inFlight, &peak)
+		defer srv.Close()
+
+		items := make([]MissingItem, 200)
+		for i := range items {
+			items[i] = MissingItem{ID: fmt.Sprintf("OU%03d", i), Type: "organisationUnit"}
+		}
+
+		service := NewService(context.Background())
+		client := api.NewClient(srv.URL, "admin", "district")
+		require.NoError(t, service.resolveMissingOrgUnits(context.Background(), client, client, items, rules))
+
+		for i, item := range items {
+			id := fmt.Sprintf("OU%03d", i)
+			assert.Equal(t, id, item.ID)
+			assert.Equal(t, "Facility "+id, item.Name)
+			require.NotNil(t, item.Suggestion, "Item %s should have a suggestion", id)
+			assert.Equal(t, "dest-"+id, item.Suggestion.ID)
+		}
+		assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(resolveConcurrency))
+	})
+
+	t.Run("Should look up source names in batches", func(t *testing.T) {
+		var batches, single int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, "/api/organisationUnits/") {
+				atomic.AddInt32(&single, 1)
+			} else if strings.HasPrefix(r.URL.Query().Get("filter"), "id:in:") {
+				atomic.AddInt32(&batches, 1)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"organisationUnits": []map[string]string{}})
+		}))
+		defer srv.Close()
+
+		items := make([]MissingItem, 250)
+		for i := range items {
+			items[i] = MissingItem{ID: fmt.Sprintf("OU%03d", i), Type: "organisationUnit"}
+		}
+
+		service := NewService(context.Background())
+		client := api.NewClient(srv.URL, "admin", "district")
+		require.NoError(t, service.resolveMissingOrgUnits(context.Background(), client, client, items, rules))
+
+		assert.Equal(t, int32(3), atomic.LoadInt32(&batches))
+		assert.Zero(t, atomic.LoadInt32(&single))
+	})
+
+	t.Run("Should skip remaining items once cancelled", func(t *testing.T) {
+		var inFlight, peak int32
+		srv := newNamingServer(t, &inFlight, &peak)
+		defer srv.Close()
+
+		items := make([]MissingItem, 50)
+		for i := range items {
+			items[i] = MissingItem{ID: fmt.Sprintf("OU%03d", i), Type: "organisationUnit"}
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		service := NewService(context.Background())
+		client := api.NewClient(srv.URL, "admin", "district")
+		err := service.resolveMissingOrgUnits(ctx, client, client, items, rules)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		for _, item := range items {
+			assert.Nil(t, item.Suggestion)
+		}
+	})
+}
+
+func TestCancelAudit(t *testing.T) {
+	t.Run("Should reject tasks that are not running", func(t *testing.T) {
+		service := NewService(context.Background())
+		assert.Error(t, service.CancelAudit("missing"))
+	})
+
+	t.Run("Should leave an audit cancelled mid-run cancelled", func(t *testing.T) {
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		require.NoError(t, err)
+		require.NoError(t, db.AutoMigrate(&models.ConnectionProfile{}, &models.Notification{}))
+		previous := database.DB
+		database.DB = db
+		defer func() { database.DB = previous }()
+
+		t.Setenv("ENCRYPTION_KEY", "test-key")
+		require.NoError(t, crypto.InitEncryption())
+		password, err := crypto.EncryptPassword("district")
+		require.NoError(t, err)
+
+		service := NewService(nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		service.taskStore["audit-1"] = &AuditProgress{TaskID: "audit-1", Status: "starting"}
+		service.cancels["audit-1"] = cancel
+
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/me.json":      apitest.JSON(http.StatusOK, map[string]interface{}{"organisationUnits": []map[string]string{{"id": "root"}}}),
+			"/api/dataSets/ds1": apitest.JSON(http.StatusOK, map[string]interface{}{"dataSetElements": []interface{}{}}),
+			"/api/dataValueSets": func(w http.ResponseWriter, r *http.Request) {
+				// The user cancels while the source scan is in flight
+				require.NoError(t, service.CancelAudit("audit-1"))
+				apitest.JSON(http.StatusOK, map[string]interface{}{
+					"dataValues": []map[string]string{{"orgUnit": "ou1", "categoryOptionCombo": "coc1", "dataElement": "de1", "value": "5"}},
+				})(w, r)
+			},
+		})
+		profile := models.ConnectionProfile{
+			ID: "profile-1", Name: "National", Owner: "admin",
+			SourceURL: srv.URL, SourceUsername: "admin", SourcePasswordEnc: password,
+			DestURL: srv.URL, DestUsername: "admin", DestPasswordEnc: password,
+		}
+		require.NoError(t, db.Create(&profile).Error)
+
+		service.performAudit(ctx, "audit-1", "profile-1", "ds1", []string{"202401"})
+
+		progress, err := service.GetAuditProgress("audit-1")
+		require.NoError(t, err)
+		assert.Equal(t, "cancelled", progress.Status)
+		assert.Nil(t, progress.Results)
+		assert.Zero(t, srv.Hits("/api/organisationUnits"), "No destination checks run after cancelling")
+	})
+}
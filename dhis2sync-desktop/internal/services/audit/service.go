@@ -3,11 +3,13 @@ package audit
 import (
 	"context"
 	"dhis2sync-desktop/internal/api"
-	"dhis2sync-desktop/internal/crypto"
 	"dhis2sync-desktop/internal/database"
+	"dhis2sync-desktop/internal/events"
 	"dhis2sync-desktop/internal/models"
+	"dhis2sync-desktop/internal/notifications"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
@@ -19,6 +21,7 @@ type Service struct {
 	ctx       context.Context
 	taskStore map[string]*AuditProgress
 	taskMu    sync.RWMutex
+	cancels   map[string]context.CancelFunc // Running audits, guarded by taskMu
 }
 
 // NewService creates a new Audit service
@@ -26,16 +29,18 @@ func NewService(ctx context.Context) *Service {
 	return &Service{
 		ctx:       ctx,
 		taskStore: make(map[string]*AuditProgress),
+		cancels:   make(map[string]context.CancelFunc),
 	}
 }
 
 // AuditProgress tracks the progress of an audit task
 type AuditProgress struct {
-	TaskID   string       `json:"task_id"`
-	Status   string       `json:"status"` // "running", "completed", "failed"
-	Progress int          `json:"progress"`
-	Messages []string     `json:"messages"`
-	Results  *AuditResult `json:"results,omitempty"`
+	TaskID    string       `json:"task_id"`
+	ProfileID string       `json:"profile_id"`
+	Status    string       `json:"status"` // "running", "completed", "failed"
+	Progress  int          `json:"progress"`
+	Messages  []string     `json:"messages"`
+	Results   *AuditResult `json:"results,omitempty"`
 }
 
 // AuditResult contains the findings of the audit
@@ -58,10 +63,11 @@ type MatchSuggestion struct {
 	Score int    `json:"score"` // Confidence score (0-100)
 }
 
+// DataIssue counts a data element's source values that are invalid for its value type
 type DataIssue struct {
 	DataElementID string `json:"data_element_id"`
-	Value         string `json:"value"`
-	IssueType     string `json:"issue_type"` // "invalid_email", "invalid_phone"
+	Value         string `json:"value"`      // An example offending value
+	IssueType     string `json:"issue_type"` // "invalid_email", "invalid_phone", ... (Issue* constants)
 	Count         int    `json:"count"`
 }
 
@@ -70,21 +76,57 @@ func (s *Service) StartAudit(profileID string, datasetID string, periods []strin
 	taskID := "audit-" + uuid.New().String()
 
 	progress := &AuditProgress{
-		TaskID:   taskID,
-		Status:   "starting",
-		Progress: 0,
-		Messages: []string{"Initializing audit..."},
+		TaskID:    taskID,
+		ProfileID: profileID,
+		Status:    "starting",
+		Progress:  0,
+		Messages:  []string{"Initializing audit..."},
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	s.taskMu.Lock()
 	s.taskStore[taskID] = progress
+	s.cancels[taskID] = cancel
 	s.taskMu.Unlock()
 
-	go s.performAudit(taskID, profileID, datasetID, periods)
+	// Emit initial state for frontend
+	s.emitAuditEvent(taskID)
+
+	go func() {
+		defer s.finishAudit(taskID)
+		s.performAudit(ctx, taskID, profileID, datasetID, periods)
+	}()
 
 	return taskID, nil
 }
 
+// CancelAudit stops a running audit; lookups already in flight finish, the rest are skipped
+func (s *Service) CancelAudit(taskID string) error {
+	s.taskMu.Lock()
+	cancel, running := s.cancels[taskID]
+	s.taskMu.Unlock()
+
+	if !running {
+		return fmt.Errorf("audit %s is not running", taskID)
+	}
+	cancel()
+	s.updateProgress(taskID, "cancelled", 0, "Audit cancelled")
+	return nil
+}
+
+// finishAudit releases a finished audit's context
+func (s *Service) finishAudit(taskID string) {
+	s.taskMu.Lock()
+	cancel, ok := s.cancels[taskID]
+	delete(s.cancels, taskID)
+	s.taskMu.Unlock()
+
+	if ok {
+		cancel()
+	}
+}
+
 // GetAuditProgress retrieves progress
 func (s *Service) GetAuditProgress(taskID string) (*AuditProgress, error) {
 	s.taskMu.RLock()
@@ -96,7 +138,39 @@ func (s *Service) GetAuditProgress(taskID string) (*AuditProgress, error) {
 	return nil, fmt.Errorf("task not found")
 }
 
-func (s *Service) performAudit(taskID, profileID, datasetID string, periods []string) {
+// GetMissingItems returns the audit's missing items whose IDs were accepted by
+// the operator, along with the profile the audit ran against. IDs that the
+// audit did not report as missing are ignored.
+func (s *Service) GetMissingItems(taskID string, acceptedIDs []string) (string, []MissingItem, error) {
+	s.taskMu.RLock()
+	defer s.taskMu.RUnlock()
+
+	progress, ok := s.taskStore[taskID]
+	if !ok {
+		return "", nil, fmt.Errorf("task not found")
+	}
+	if progress.Status != "completed" || progress.Results == nil {
+		return "", nil, fmt.Errorf("audit not completed or no results available")
+	}
+
+	accepted := make(map[string]bool, len(acceptedIDs))
+	for _, id := range acceptedIDs {
+		accepted[id] = true
+	}
+
+	items := []MissingItem{}
+	for _, group := range [][]MissingItem{progress.Results.MissingOrgUnits, progress.Results.MissingCOCs} {
+		for _, item := range group {
+			if accepted[item.ID] {
+				items = append(items, item)
+			}
+		}
+	}
+
+	return progress.ProfileID, items, nil
+}
+
+func (s *Service) performAudit(ctx context.Context, taskID, profileID, datasetID string, periods []string) {
 	defer func() {
 		if r := recover(); r != nil {
 			s.updateProgress(taskID, "failed", 0, fmt.Sprintf("Panic during audit: %v", r))
@@ -113,20 +187,18 @@ func (s *Service) performAudit(taskID, profileID, datasetID string, periods []st
 		return
 	}
 
-	// Decrypt passwords and create clients
-	sourcePwd, err := crypto.DecryptPassword(profile.SourcePasswordEnc)
+	// Decrypt credentials and create clients
+	sourceClient, err := api.NewProfileClient(&profile, "source")
 	if err != nil {
-		s.updateProgress(taskID, "failed", 0, fmt.Sprintf("Failed to decrypt source password: %v", err))
+		s.updateProgress(taskID, "failed", 0, fmt.Sprintf("Failed to decrypt source credentials: %v", err))
 		return
 	}
-	sourceClient := api.NewClient(profile.SourceURL, profile.SourceUsername, sourcePwd)
 
-	destPwd, err := crypto.DecryptPassword(profile.DestPasswordEnc)
+	destClient, err := api.NewProfileClient(&profile, "dest")
 	if err != nil {
-		s.updateProgress(taskID, "failed", 0, fmt.Sprintf("Failed to decrypt destination password: %v", err))
+		s.updateProgress(taskID, "failed", 0, fmt.Sprintf("Failed to decrypt destination credentials: %v", err))
 		return
 	}
-	destClient := api.NewClient(profile.DestURL, profile.DestUsername, destPwd)
 
 	// 1. Scan Source for unique OUs and COCs
 	s.updateProgress(taskID, "running", 15, "Scanning source data...")
@@ -161,8 +233,18 @@ func (s *Service) performAudit(taskID, profileID, datasetID string, periods []st
 	}
 	rootOU := meResp.OrganisationUnits[0].ID
 
+	// Values are checked against their data element's value type while scanning
+	valueTypes, err := FetchValueTypes(sourceClient, datasetID)
+	if err != nil {
+		s.updateProgress(taskID, "running", 15, fmt.Sprintf("⚠ Skipping data quality checks, could not read value types: %v", err))
+	}
+	issues := newIssueCollector(valueTypes)
+
 	totalPeriods := len(periods)
 	for i, period := range periods {
+		if ctx.Err() != nil {
+			return
+		}
 		progress := 15 + (20 * i / totalPeriods)
 		s.updateProgress(taskID, "running", progress, fmt.Sprintf("Scanning period %s...", period))
 
@@ -197,11 +279,14 @@ func (s *Service) performAudit(taskID, profileID, datasetID string, periods []st
 			for _, dv := range dataValueSet.DataValues {
 				uniqueOUs[dv.OrgUnit] = true
 				uniqueCOCs[dv.CategoryOptionCombo] = true
-				// TODO: Check for data quality issues here
+				issues.check(dv.DataElement, dv.Value)
 			}
 		}
 	}
 
+	if ctx.Err() != nil {
+		return // Cancelled; CancelAudit already reported it
+	}
 	s.updateProgress(taskID, "running", 35, fmt.Sprintf("Found %d unique OrgUnits and %d COCs", len(uniqueOUs), len(uniqueCOCs)))
 
 	// 2. Check Destination for existence
@@ -224,6 +309,10 @@ func (s *Service) performAudit(taskID, profileID, datasetID string, periods []st
 		return
 	}
 
+	if ctx.Err() != nil {
+		return
+	}
+
 	// Check COCs
 	foundCOCs, err := s.checkExistence(destClient, "categoryOptionCombos", cocIDs)
 	if err != nil {
@@ -246,76 +335,134 @@ func (s *Service) performAudit(taskID, profileID, datasetID string, periods []st
 		}
 	}
 
+	if ctx.Err() != nil {
+		return
+	}
 	s.updateProgress(taskID, "running", 60, fmt.Sprintf("Found %d missing OUs and %d missing COCs", len(missingOUs), len(missingCOCs)))
 
 	// 3. Perform Fuzzy/Structural matching for missing items
 	s.updateProgress(taskID, "running", 70, "Attempting to resolve missing items...")
 
 	// Resolve OUs
-	for i, item := range missingOUs {
-		// Fetch Source Name
-		// We need to fetch it from Source API because we only have ID
-		var srcName string
-		resp, err := sourceClient.Get(fmt.Sprintf("api/organisationUnits/%s?fields=name", item.ID), nil)
-		if err == nil && resp.IsSuccess() {
-			var nameResp struct {
-				Name string `json:"name"`
-			}
-			if err := json.Unmarshal(resp.Body(), &nameResp); err == nil {
-				srcName = nameResp.Name
-				missingOUs[i].Name = srcName
-			}
-		}
-
-		if srcName != "" {
-			suggestion, _ := s.findBestMatch(destClient, "organisationUnits", srcName)
-			if suggestion != nil {
-				missingOUs[i].Suggestion = suggestion
-			}
-		}
+	nameRules, err := ParseNameRules(profile.NameMatchRules)
+	if err != nil {
+		s.updateProgress(taskID, "running", 70, fmt.Sprintf("⚠ Ignoring invalid name match rules: %v", err))
+		nameRules, _ = ParseNameRules("")
+	}
+	if err := s.resolveMissingOrgUnits(ctx, sourceClient, destClient, missingOUs, nameRules); err != nil {
+		return // Cancelled; CancelAudit already reported it
 	}
 
-	// Resolve COCs
-	for i, item := range missingCOCs {
-		// Fetch Source Name
-		var srcName string
-		resp, err := sourceClient.Get(fmt.Sprintf("api/categoryOptionCombos/%s?fields=name", item.ID), nil)
-		if err == nil && resp.IsSuccess() {
-			var nameResp struct {
-				Name string `json:"name"`
-			}
-			if err := json.Unmarshal(resp.Body(), &nameResp); err == nil {
-				srcName = nameResp.Name
-				missingCOCs[i].Name = srcName
-			}
-		}
+	// Resolve COCs, sharing option and structure lookups across the whole audit
+	cocCache := newCOCResolveCache()
+	if err := s.resolveMissingCOCs(ctx, sourceClient, destClient, missingCOCs, cocCache); err != nil {
+		return
+	}
 
-		if srcName != "" {
-			suggestion, _ := s.resolveCOCByStructure(sourceClient, destClient, item.ID, srcName)
-			if suggestion != nil {
-				missingCOCs[i].Suggestion = suggestion
-			}
-		}
+	if ctx.Err() != nil {
+		return
 	}
 
 	// Save results
 	result := &AuditResult{
 		MissingOrgUnits: missingOUs,
 		MissingCOCs:     missingCOCs,
-		// DataIssues: ...
+		DataIssues:      issues.list(),
 	}
 
+	completed := false
 	s.taskMu.Lock()
-	if p, ok := s.taskStore[taskID]; ok {
+	if p, ok := s.taskStore[taskID]; ok && nextAuditStatus(p.Status, "completed") == "completed" {
 		p.Results = result
 		p.Status = "completed"
 		p.Progress = 100
 		p.Messages = append(p.Messages, "Audit complete")
+		completed = true
 	}
 	s.taskMu.Unlock()
+
+	s.emitAuditEvent(taskID)
+	if !completed {
+		return
+	}
+
+	notifications.TaskFinished(taskID, "audit", "completed",
+		fmt.Sprintf("Audit complete: %d missing org units, %d missing category option combos, %d data quality issues",
+			len(missingOUs), len(missingCOCs), len(result.DataIssues)))
+}
+
+// cocResolveCache memoizes structural COC resolution for a single audit run so
+// disaggregations sharing category options are not re-resolved per COC.
+type cocResolveCache struct {
+	mu         sync.Mutex
+	optionIDs  map[string]string           // source option name -> dest option ID ("" when missing)
+	signatures map[string]*MatchSuggestion // sorted dest option IDs -> dest COC (nil when no match)
 }
 
-func (s *Service) resolveCOCByStructure(sourceClient, destClient *api.Client, srcID, srcName string) (*MatchSuggestion, error) {
+func newCOCResolveCache() *cocResolveCache {
+	return &cocResolveCache{
+		optionIDs:  make(map[string]string),
+		signatures: make(map[string]*MatchSuggestion),
+	}
+}
+
+func (c *cocResolveCache) getOption(name string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id, ok := c.optionIDs[name]
+	return id, ok
+}
+
+func (c *cocResolveCache) putOption(name, id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.optionIDs[name] = id
+}
+
+func (c *cocResolveCache) getSignature(sig string) (*MatchSuggestion, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	match, ok := c.signatures[sig]
+	return match, ok
+}
+
+func (c *cocResolveCache) putSignature(sig string, match *MatchSuggestion) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.signatures[sig] = match
+}
+
+// COCMatcher matches source category option combos to destination combos with the same
+// category options, sharing option and structure lookups across calls. Safe for concurrent use.
+type COCMatcher struct {
+	source, dest *api.Client
+	cache        *cocResolveCache
+}
+
+// NewCOCMatcher creates a matcher between a source and a destination instance
+func NewCOCMatcher(source, dest *api.Client) *COCMatcher {
+	return &COCMatcher{source: source, dest: dest, cache: newCOCResolveCache()}
+}
+
+// Match returns the destination COC matching a source COC, or nil when there is none
+func (m *COCMatcher) Match(srcID string) (*MatchSuggestion, error) {
+	return resolveCOCByStructure(m.source, m.dest, srcID, "", m.cache)
+}
+
+// cocSignature builds an order-independent key for a set of option IDs
+func cocSignature(optionIDs []string) string {
+	sorted := append([]string(nil), optionIDs...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// resolveCOCByStructure finds the destination COC with the same category options (matched
+// by name) as a source COC; nil when an option or the combination is missing
+func resolveCOCByStructure(sourceClient, destClient *api.Client, srcID, srcName string, cache *cocResolveCache) (*MatchSuggestion, error) {
+	if cache == nil {
+		cache = newCOCResolveCache()
+	}
+
 	// 1. Get Source Options
 	resp, err := sourceClient.Get(fmt.Sprintf("api/categoryOptionCombos/%s?fields=categoryOptions[name]", srcID), nil)
 	if err != nil || !resp.IsSuccess() {
@@ -327,7 +474,7 @@ func (s *Service) resolveCOCByStructure(sourceClient, destClient *api.Client, sr
 			Name string `json:"name"`
 		} `json:"categoryOptions"`
 	}
-	if err := json.Unmarshal(resp.Body(), &srcResp); err != nil {
+	if err := api.DecodeJSON(resp, fmt.Sprintf("api/categoryOptionCombos/%s?fields=categoryOptions[name]", srcID), &srcResp); err != nil {
 		return nil, err
 	}
 
@@ -338,6 +485,14 @@ func (s *Service) resolveCOCByStructure(sourceClient, destClient *api.Client, sr
 	// 2. Find Target Options
 	targetOptIDs := make([]string, 0, len(srcResp.CategoryOptions))
 	for _, opt := range srcResp.CategoryOptions {
+		if id, ok := cache.getOption(opt.Name); ok {
+			if id == "" {
+				return nil, nil // Option previously found missing in target
+			}
+			targetOptIDs = append(targetOptIDs, id)
+			continue
+		}
+
 		// Search by name
 		params := map[string]string{
 			"filter": fmt.Sprintf("name:eq:%s", opt.Name),
@@ -353,13 +508,15 @@ func (s *Service) resolveCOCByStructure(sourceClient, destClient *api.Client, sr
 				ID string `json:"id"`
 			} `json:"categoryOptions"`
 		}
-		if err := json.Unmarshal(resp.Body(), &targetResp); err != nil {
+		if err := api.DecodeJSON(resp, "api/categoryOptions", &targetResp); err != nil {
 			return nil, err
 		}
 
 		if len(targetResp.CategoryOptions) > 0 {
+			cache.putOption(opt.Name, targetResp.CategoryOptions[0].ID)
 			targetOptIDs = append(targetOptIDs, targetResp.CategoryOptions[0].ID)
 		} else {
+			cache.putOption(opt.Name, "")
 			return nil, nil // Option not found
 		}
 	}
@@ -369,6 +526,15 @@ func (s *Service) resolveCOCByStructure(sourceClient, destClient *api.Client, sr
 		return nil, nil
 	}
 
+	signature := cocSignature(targetOptIDs)
+	if match, ok := cache.getSignature(signature); ok {
+		if match == nil {
+			return nil, nil
+		}
+		suggestion := *match
+		return &suggestion, nil
+	}
+
 	firstOpt := targetOptIDs[0]
 	// Filter COCs that contain the first option
 	params := map[string]string{
@@ -389,7 +555,7 @@ func (s *Service) resolveCOCByStructure(sourceClient, destClient *api.Client, sr
 			} `json:"categoryOptions"`
 		} `json:"categoryOptionCombos"`
 	}
-	if err := json.Unmarshal(resp.Body(), &cocResp); err != nil {
+	if err := api.DecodeJSON(resp, "api/categoryOptionCombos", &cocResp); err != nil {
 		return nil, err
 	}
 
@@ -411,19 +577,50 @@ func (s *Service) resolveCOCByStructure(sourceClient, destClient *api.Client, sr
 			}
 		}
 		if match {
-			return &MatchSuggestion{
+			suggestion := &MatchSuggestion{
 				ID:    coc.ID,
 				Name:  coc.Name,
 				Score: 100, // Structural match is high confidence
-			}, nil
+			}
+			cache.putSignature(signature, suggestion)
+			return suggestion, nil
 		}
 	}
 
+	cache.putSignature(signature, nil)
 	return nil, nil
 }
 
 func (s *Service) checkExistence(client *api.Client, resource string, ids []string) (map[string]bool, error) {
-	found := make(map[string]bool)
+	items, err := s.lookupItems(client, resource, ids, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(items))
+	for _, item := range items {
+		found[item.ID] = true
+	}
+	return found, nil
+}
+
+// fetchNames maps the ids that exist on the server to their names
+func (s *Service) fetchNames(client *api.Client, resource string, ids []string) (map[string]string, error) {
+	items, err := s.lookupItems(client, resource, ids, "id,name")
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(items))
+	for _, item := range items {
+		names[item.ID] = item.Name
+	}
+	return names, nil
+}
+
+// lookupItems fetches the ids that exist on the server in id:in chunks of 100
+func (s *Service) lookupItems(client *api.Client, resource string, ids []string, fields string) ([]idItem, error) {
+	var found []idItem
 	chunkSize := 100
 
 	for i := 0; i < len(ids); i += chunkSize {
@@ -433,45 +630,121 @@ func (s *Service) checkExistence(client *api.Client, resource string, ids []stri
 		}
 		chunk := ids[i:end]
 
-		// DHIS2 filter: id:in:[id1,id2,...]
-		filter := fmt.Sprintf("id:in:[%s]", strings.Join(chunk, ","))
-		params := map[string]string{
-			"filter": filter,
-			"fields": "id",
-			"paging": "false",
+		existing, err := s.fetchExistingItems(client, resource, chunk, fields)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, existing...)
+	}
+	return found, nil
+}
+
+// fetchExistingItems returns the subset of ids that exist on the server.
+// paging=false is requested, but some servers (or proxies) still return a
+// pager-wrapped page; in that case the remaining pages are followed so a
+// truncated response never shows up as false "missing" findings.
+func (s *Service) fetchExistingItems(client *api.Client, resource string, ids []string, fields string) ([]idItem, error) {
+	// DHIS2 filter: id:in:[id1,id2,...]
+	params := map[string]string{
+		"filter": fmt.Sprintf("id:in:[%s]", strings.Join(ids, ",")),
+		"fields": fields,
+		"paging": "false",
+	}
+
+	var existing []idItem
+	for page := 1; ; page++ {
+		if page > 1 {
+			params["paging"] = "true"
+			params["page"] = fmt.Sprintf("%d", page)
 		}
 
 		resp, err := client.Get(fmt.Sprintf("api/%s", resource), params)
 		if err != nil {
 			return nil, err
 		}
-
-		// Parse generic response
-		// { "organisationUnits": [ {"id": "..."} ] }
-		var result map[string][]struct {
-			ID string `json:"id"`
+		if !resp.IsSuccess() {
+			return nil, fmt.Errorf("existence check for %s failed: HTTP %d", resource, resp.StatusCode())
 		}
-		if err := json.Unmarshal(resp.Body(), &result); err != nil {
+
+		items, pager, err := parseItemPage(resp.Body(), resource)
+		if err != nil {
 			return nil, err
 		}
+		existing = append(existing, items...)
 
-		// The key in JSON matches the resource name usually, but let's be safe
-		// Actually, DHIS2 returns the resource name as key.
-		// e.g. "organisationUnits", "categoryOptionCombos"
-		if items, ok := result[resource]; ok {
-			for _, item := range items {
-				found[item.ID] = true
-			}
+		// Unpaged response, last page, or nothing more to fetch
+		if pager == nil || pager.PageCount <= page || len(items) == 0 || len(existing) >= len(ids) {
+			break
+		}
+		if pager.PageSize > 0 {
+			params["pageSize"] = fmt.Sprintf("%d", pager.PageSize)
 		}
 	}
-	return found, nil
+
+	if len(existing) > len(ids) {
+		return nil, fmt.Errorf("existence check for %s returned %d items for %d ids", resource, len(existing), len(ids))
+	}
+
+	return existing, nil
+}
+
+// idPager mirrors the DHIS2 pager block returned with paged collections
+type idPager struct {
+	Page      int `json:"page"`
+	PageCount int `json:"pageCount"`
+	PageSize  int `json:"pageSize"`
+	Total     int `json:"total"`
+}
+
+// idItem is an entry of a collection fetched by id (Name only when requested)
+type idItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// parseItemPage extracts the items under the resource key and the optional pager.
+// Parses defensively: unknown keys are ignored and a missing collection yields no items.
+func parseItemPage(body []byte, resource string) ([]idItem, *idPager, error) {
+	// { "pager": {...}, "organisationUnits": [ {"id": "..."} ] }
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s response: %w", resource, err)
+	}
+
+	var pager *idPager
+	if rawPager, ok := raw["pager"]; ok {
+		pager = &idPager{}
+		if err := json.Unmarshal(rawPager, pager); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s pager: %w", resource, err)
+		}
+	}
+
+	rawItems, ok := raw[resource]
+	if !ok {
+		return nil, pager, nil
+	}
+
+	var items []idItem
+	if err := json.Unmarshal(rawItems, &items); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", resource, err)
+	}
+
+	kept := make([]idItem, 0, len(items))
+	for _, item := range items {
+		if item.ID != "" {
+			kept = append(kept, item)
+		}
+	}
+	return kept, pager, nil
 }
 
-func (s *Service) findBestMatch(client *api.Client, resource, name string) (*MatchSuggestion, error) {
+func (s *Service) findBestMatch(client *api.Client, resource, name string, rules []NameRule) (*MatchSuggestion, error) {
 	// Simple fuzzy search using 'ilike'
-	// Strip common suffixes for better matching
-	cleanName := strings.TrimSuffix(name, " P.S")
-	cleanName = strings.TrimSuffix(cleanName, " Primary School")
+	// Strip configured suffixes for better matching
+	cleanName := ApplyNameRules(name, rules)
+	if cleanName == "" {
+		cleanName = name
+	}
 
 	params := map[string]string{
 		"filter": fmt.Sprintf("name:ilike:%s", cleanName),
@@ -488,7 +761,7 @@ func (s *Service) findBestMatch(client *api.Client, resource, name string) (*Mat
 		ID   string `json:"id"`
 		Name string `json:"name"`
 	}
-	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+	if err := api.DecodeJSON(resp, fmt.Sprintf("api/%s", resource), &result); err != nil {
 		return nil, err
 	}
 
@@ -506,13 +779,60 @@ func (s *Service) findBestMatch(client *api.Client, resource, name string) (*Mat
 
 func (s *Service) updateProgress(taskID, status string, progress int, msg string) {
 	s.taskMu.Lock()
-	defer s.taskMu.Unlock()
-
+	statusChanged := false
 	if p, ok := s.taskStore[taskID]; ok {
-		p.Status = status
-		p.Progress = progress
+		next := nextAuditStatus(p.Status, status)
+		statusChanged = p.Status != next
+		if next == status {
+			p.Progress = progress
+		}
+		p.Status = next
 		if msg != "" {
 			p.Messages = append(p.Messages, msg)
 		}
 	}
+	s.taskMu.Unlock()
+
+	go s.emitAuditEvent(taskID)
+	if statusChanged {
+		notifications.TaskFinished(taskID, "audit", status, msg)
+	}
+}
+
+// nextAuditStatus returns the status an audit moves to when an update asks for status.
+// Final statuses stick: a phase still finishing can't resurrect a cancelled audit.
+func nextAuditStatus(current, status string) string {
+	switch current {
+	case "cancelled", "completed", "failed":
+		return current
+	}
+	return status
+}
+
+// emitAuditEvent pushes an audit's current state to the frontend on "audit:{taskID}"
+func (s *Service) emitAuditEvent(taskID string) {
+	s.taskMu.RLock()
+	progress, exists := s.taskStore[taskID]
+	if !exists {
+		s.taskMu.RUnlock()
+		return
+	}
+
+	payload := map[string]interface{}{
+		"task_id":  taskID,
+		"status":   progress.Status,
+		"progress": progress.Progress,
+		"messages": append([]string(nil), progress.Messages...),
+	}
+
+	if len(progress.Messages) > 0 {
+		payload["message"] = progress.Messages[len(progress.Messages)-1]
+	}
+
+	if progress.Results != nil {
+		payload["results"] = progress.Results
+	}
+	s.taskMu.RUnlock()
+
+	events.EmitProgress(s.ctx, fmt.Sprintf("audit:%s", taskID), "audit", payload)
 }
@@ -0,0 +1,216 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"dhis2sync-desktop/internal/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPagingServer returns a server that ignores paging=false and always pages
+// id:in filter results at pageSize, like a proxy enforcing a default page size.
+func newPagingServer(t *testing.T, resource string, existing map[string]bool, pageSize int, requests *int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+
+		filter := r.URL.Query().Get("filter")
+		filter = strings.TrimSuffix(strings.TrimPrefix(filter, "id:in:["), "]")
+
+		matched := []map[string]string{}
+		for _, id := range strings.Split(filter, ",") {
+			if existing[id] {
+				matched = append(matched, map[string]string{"id": id})
+			}
+		}
+
+		page := 1
+		if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+			page = p
+		}
+		pageCount := (len(matched) + pageSize - 1) / pageSize
+		start := (page - 1) * pageSize
+		end := start + pageSize
+		if start > len(matched) {
+			start = len(matched)
+		}
+		if end > len(matched) {
+			end = len(matched)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"pager": map[string]int{
+				"page":      page,
+				"pageCount": pageCount,
+				"pageSize":  pageSize,
+				"total":     len(matched),
+			},
+			resource: matched[start:end],
+		})
+	}))
+}
+
+func TestCheckExistence(t *testing.T) {
+	service := NewService(context.Background())
+
+	t.Run("Should follow pages when server ignores paging=false", func(t *testing.T) {
+		ids := make([]string, 100)
+		existing := make(map[string]bool)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("ou%09d", i)
+			existing[ids[i]] = true
+		}
+
+		var requests int32
+		srv := newPagingServer(t, "organisationUnits", existing, 50, &requests)
+		defer srv.Close()
+
+		client := api.NewClient(srv.URL, "admin", "district")
+		found, err := service.checkExistence(client, "organisationUnits", ids)
+
+		require.NoError(t, err)
+		assert.Len(t, found, 100, "No ids should be dropped by server-side paging")
+		for _, id := range ids {
+			assert.True(t, found[id], "Expected %s to be found", id)
+		}
+		assert.Equal(t, int32(2), atomic.LoadInt32(&requests), "Should fetch both pages of the chunk")
+	})
+
+	t.Run("Should only report ids that exist across multiple chunks", func(t *testing.T) {
+		ids := make([]string, 250)
+		existing := make(map[string]bool)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("coc%08d", i)
+			if i%2 == 0 {
+				existing[ids[i]] = true
+			}
+		}
+
+		var requests int32
+		srv := newPagingServer(t, "categoryOptionCombos", existing, 50, &requests)
+		defer srv.Close()
+
+		client := api.NewClient(srv.URL, "admin", "district")
+		found, err := service.checkExistence(client, "categoryOptionCombos", ids)
+
+		require.NoError(t, err)
+		assert.Len(t, found, 125)
+		assert.True(t, found["coc00000000"])
+		assert.False(t, found["coc00000001"])
+	})
+
+	t.Run("Should parse an unpaged response", func(t *testing.T) {
+		items, pager, err := parseItemPage([]byte(`{"organisationUnits":[{"id":"a","name":"Clinic A"},{"id":"b"}]}`), "organisationUnits")
+
+		require.NoError(t, err)
+		assert.Nil(t, pager)
+		assert.Equal(t, []idItem{{ID: "a", Name: "Clinic A"}, {ID: "b"}}, items)
+	})
+
+	t.Run("Should tolerate a response without the resource key", func(t *testing.T) {
+		items, pager, err := parseItemPage([]byte(`{"pager":{"page":1,"pageCount":0}}`), "organisationUnits")
+
+		require.NoError(t, err)
+		assert.Empty(t, items)
+		require.NotNil(t, pager)
+		assert.Equal(t, 1, pager.Page)
+	})
+}
+
+// newCOCServer serves source COC options and destination option/COC lookups,
+// counting destination lookups so cache hits can be asserted.
+func newCOCServer(t *testing.T, destLookups *int32) *httptest.Server {
+	t.Helper()
+	sourceOptions := map[string][]string{
+		"srcMaleU5":   {"Male", "<5"},
+		"srcFemaleU5": {"Female", "<5"},
+		"srcMaleU5b":  {"<5", "Male"}, // Duplicate structure, different UID
+	}
+	destOptions := map[string]string{"Male": "dMale", "Female": "dFemale", "<5": "dU5"}
+	destCOCs := []map[string]interface{}{
+		{"id": "dCocMaleU5", "name": "Male, <5", "categoryOptions": []map[string]string{{"id": "dMale"}, {"id": "dU5"}}},
+		{"id": "dCocFemaleU5", "name": "Female, <5", "categoryOptions": []map[string]string{{"id": "dFemale"}, {"id": "dU5"}}},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/api/categoryOptionCombos/"):
+			id := strings.TrimPrefix(r.URL.Path, "/api/categoryOptionCombos/")
+			opts := []map[string]string{}
+			for _, name := range sourceOptions[id] {
+				opts = append(opts, map[string]string{"name": name})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"categoryOptions": opts})
+		case r.URL.Path == "/api/categoryOptions":
+			atomic.AddInt32(destLookups, 1)
+			name := strings.TrimPrefix(r.URL.Query().Get("filter"), "name:eq:")
+			opts := []map[string]string{}
+			if id, ok := destOptions[name]; ok {
+				opts = append(opts, map[string]string{"id": id})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"categoryOptions": opts})
+		case r.URL.Path == "/api/categoryOptionCombos":
+			atomic.AddInt32(destLookups, 1)
+			json.NewEncoder(w).Encode(map[string]interface{}{"categoryOptionCombos": destCOCs})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestResolveCOCByStructureCache(t *testing.T) {
+	t.Run("Should reuse option and structure lookups across COCs", func(t *testing.T) {
+		var destLookups int32
+		srv := newCOCServer(t, &destLookups)
+		defer srv.Close()
+		client := api.NewClient(srv.URL, "admin", "district")
+		cache := newCOCResolveCache()
+
+		first, err := resolveCOCByStructure(client, client, "srcMaleU5", "Male, <5", cache)
+		require.NoError(t, err)
+		require.NotNil(t, first)
+		assert.Equal(t, "dCocMaleU5", first.ID)
+		firstCalls := atomic.LoadInt32(&destLookups)
+		assert.Equal(t, int32(3), firstCalls, "2 option lookups + 1 COC lookup")
+
+		// Same structure under another UID: fully served from cache
+		dup, err := resolveCOCByStructure(client, client, "srcMaleU5b", "<5, Male", cache)
+		require.NoError(t, err)
+		require.NotNil(t, dup)
+		assert.Equal(t, "dCocMaleU5", dup.ID)
+		assert.Equal(t, firstCalls, atomic.LoadInt32(&destLookups), "Repeated structure should not hit the destination")
+
+		// Shares "<5": only "Female" and the new structure are looked up
+		female, err := resolveCOCByStructure(client, client, "srcFemaleU5", "Female, <5", cache)
+		require.NoError(t, err)
+		require.NotNil(t, female)
+		assert.Equal(t, "dCocFemaleU5", female.ID)
+		assert.Equal(t, firstCalls+2, atomic.LoadInt32(&destLookups), "Shared option should come from cache")
+	})
+
+	t.Run("Should make more calls without a shared cache", func(t *testing.T) {
+		var destLookups int32
+		srv := newCOCServer(t, &destLookups)
+		defer srv.Close()
+		client := api.NewClient(srv.URL, "admin", "district")
+
+		for _, id := range []string{"srcMaleU5", "srcMaleU5b", "srcFemaleU5"} {
+			_, err := resolveCOCByStructure(client, client, id, "", nil)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, int32(9), atomic.LoadInt32(&destLookups))
+	})
+}
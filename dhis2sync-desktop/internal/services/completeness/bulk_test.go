@@ -0,0 +1,150 @@
+package completeness
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"dhis2sync-desktop/internal/api/apitest"
+	"dhis2sync-desktop/internal/crypto"
+	"dhis2sync-desktop/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPerformBulkAction(t *testing.T) {
+	var inFlight, peak int32
+
+	srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+		"POST /api/completeDataSetRegistrations": func(w http.ResponseWriter, r *http.Request) {
+			current := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				seen := atomic.LoadInt32(&peak)
+				if current <= seen || atomic.CompareAndSwapInt32(&peak, seen, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+
+			var payload struct {
+				Registrations []map[string]interface{} `json:"completeDataSetRegistrations"`
+			}
+			json.NewDecoder(r.Body).Decode(&payload)
+			if ou, _ := payload.Registrations[0]["organisationUnit"].(string); strings.HasPrefix(ou, "bad") {
+				apitest.JSON(http.StatusConflict, map[string]string{"message": "period locked"})(w, r)
+				return
+			}
+			apitest.JSON(http.StatusOK, map[string]string{"status": "OK"})(w, r)
+		},
+	})
+
+	items := []BulkActionFailure{}
+	for i := 0; i < 40; i++ {
+		ou := fmt.Sprintf("ou%d", i)
+		if i%5 == 0 {
+			ou = fmt.Sprintf("bad%d", i)
+		}
+		items = append(items, BulkActionFailure{OrgUnit: ou, Period: "202401"})
+	}
+
+	t.Run("Should account for every item under concurrency", func(t *testing.T) {
+		t.Setenv("ENCRYPTION_KEY", "test-key")
+		require.NoError(t, crypto.InitEncryption())
+		password, err := crypto.EncryptPassword("district")
+		require.NoError(t, err)
+		profile := &models.ConnectionProfile{SourceURL: srv.URL, SourceUsername: "admin", SourcePasswordEnc: password}
+
+		service := NewService(nil, nil)
+		service.bulkActionStore["task"] = &BulkActionProgress{TaskID: "task", Status: "starting", Results: &BulkActionResult{Action: "complete"}}
+		req := BulkActionRequest{Instance: "source", Action: "complete", DatasetID: "ds1", Concurrency: 4}
+
+		service.performBulkAction("task", profile, req, items)
+
+		progress := service.bulkActionStore["task"]
+		assert.Equal(t, "completed", progress.Status)
+		assert.Equal(t, 100, progress.Progress)
+		assert.NotZero(t, progress.CompletedAt)
+		assert.Equal(t, 40, progress.Results.TotalProcessed)
+		assert.Len(t, progress.Results.Successful, 32)
+		assert.Len(t, progress.Results.Failed, 8)
+		assert.Len(t, progress.Results.FailedItems, 8)
+		assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(4), "Concurrency should stay within the configured bound")
+		assert.Equal(t, 40, srv.Hits("/api/completeDataSetRegistrations"), "Rejected registrations should not be retried")
+	})
+}
+
+func TestRunBulkRegistrationsRetry(t *testing.T) {
+	original := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	defer func() { retryBaseDelay = original }()
+
+	t.Run("Should count a registration that succeeds on retry exactly once", func(t *testing.T) {
+		var calls int32
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/completeDataSetRegistrations": func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					apitest.Raw(http.StatusBadGateway, "upstream unavailable")(w, r)
+					return
+				}
+				apitest.JSON(http.StatusOK, map[string]string{"status": "OK"})(w, r)
+			},
+		})
+
+		client := srv.Client()
+		client.SetRetryCount(0)
+
+		var mu sync.Mutex
+		result := &BulkActionResult{}
+		var messages []string
+		req := BulkActionRequest{Action: "complete", DatasetID: "ds1"}
+		items := []BulkActionFailure{{OrgUnit: "ou1", Period: "202401"}}
+
+		runBulkRegistrations(client, "task", req, items, func(_ string, msg string) {
+			mu.Lock()
+			defer mu.Unlock()
+			messages = append(messages, msg)
+		}, func(item BulkActionFailure, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				result.Successful = append(result.Successful, item.OrgUnit+":"+item.Period)
+			} else {
+				result.Failed = append(result.Failed, item.OrgUnit+":"+item.Period)
+			}
+			result.TotalProcessed++
+		})
+
+		assert.Equal(t, []string{"ou1:202401"}, result.Successful)
+		assert.Empty(t, result.Failed)
+		assert.Equal(t, 1, result.TotalProcessed)
+		assert.Equal(t, 2, srv.Hits("/api/completeDataSetRegistrations"))
+		require.Len(t, messages, 2)
+		assert.Contains(t, messages[0], "ou1:202401 ⚠ Attempt 1/3 failed")
+		assert.Contains(t, messages[1], "succeeded on retry 2/3")
+	})
+
+	t.Run("Should fail after exhausting retries on persistent server errors", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/completeDataSetRegistrations": apitest.Raw(http.StatusServiceUnavailable, "maintenance"),
+		})
+
+		client := srv.Client()
+		client.SetRetryCount(0)
+
+		var finalErr error
+		req := BulkActionRequest{Action: "complete", DatasetID: "ds1"}
+		runBulkRegistrations(client, "task", req, []BulkActionFailure{{OrgUnit: "ou1", Period: "202401"}},
+			func(string, string) {}, func(_ BulkActionFailure, err error) { finalErr = err })
+
+		require.Error(t, finalErr)
+		assert.Contains(t, finalErr.Error(), "503")
+		assert.Equal(t, bulkMaxAttempts, srv.Hits("/api/completeDataSetRegistrations"))
+	})
+}
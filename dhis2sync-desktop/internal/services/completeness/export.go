@@ -0,0 +1,88 @@
+package completeness
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CSVColumn selects a field for the completeness CSV export and names its header
+type CSVColumn struct {
+	Field  string `json:"field"`            // One of csvFields
+	Header string `json:"header,omitempty"` // Defaults to the field name
+}
+
+// csvFields lists the exportable fields of an org unit's compliance info
+var csvFields = []string{
+	"orgUnitId", "name", "compliance_percentage", "elements_present", "elements_required",
+	"weighted_present", "weighted_required", "missing_elements", "missing_critical", "has_data", "total_entries",
+}
+
+// csvValue renders one field of an org unit's compliance info
+func csvValue(field, ouID string, info *OrgUnitComplianceInfo) string {
+	switch field {
+	case "orgUnitId":
+		return ouID
+	case "name":
+		return info.Name
+	case "compliance_percentage":
+		return fmt.Sprintf("%.1f", info.CompliancePercentage)
+	case "elements_present":
+		return fmt.Sprintf("%d", info.ElementsPresent)
+	case "elements_required":
+		return fmt.Sprintf("%d", info.ElementsRequired)
+	case "weighted_present":
+		return fmt.Sprintf("%g", info.WeightedPresent)
+	case "weighted_required":
+		return fmt.Sprintf("%g", info.WeightedRequired)
+	case "missing_elements":
+		return strings.Join(info.MissingElements, ";")
+	case "missing_critical":
+		return strings.Join(info.MissingCritical, ";")
+	case "has_data":
+		return fmt.Sprintf("%t", info.HasData)
+	case "total_entries":
+		return fmt.Sprintf("%d", info.TotalEntries)
+	}
+	return ""
+}
+
+// defaultCSVColumns is the export layout when no columns are configured
+var defaultCSVColumns = []CSVColumn{
+	{Field: "orgUnitId"},
+	{Field: "name"},
+	{Field: "compliance_percentage"},
+	{Field: "elements_present"},
+	{Field: "elements_required"},
+}
+
+// resolveCSVColumns validates configured columns, falling back to the default layout,
+// and returns the header row
+func resolveCSVColumns(columns []CSVColumn) ([]CSVColumn, []string, error) {
+	if len(columns) == 0 {
+		columns = defaultCSVColumns
+	}
+
+	headers := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if !slices.Contains(csvFields, col.Field) {
+			return nil, nil, fmt.Errorf("unknown export field %q (valid: %s)", col.Field, strings.Join(csvFields, ", "))
+		}
+		header := col.Header
+		if header == "" {
+			header = col.Field
+		}
+		headers = append(headers, header)
+	}
+
+	return columns, headers, nil
+}
+
+// csvRow renders one org unit's values in column order
+func csvRow(columns []CSVColumn, ouID string, info *OrgUnitComplianceInfo) []string {
+	row := make([]string, 0, len(columns))
+	for _, col := range columns {
+		row = append(row, csvValue(col.Field, ouID, info))
+	}
+	return row
+}
@@ -0,0 +1,134 @@
+package completeness
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportResultsColumns(t *testing.T) {
+	s := &Service{assessmentStore: map[string]*AssessmentProgress{
+		"task": {
+			Status: "completed",
+			Results: &AssessmentResult{ComplianceDetails: map[string]*OrgUnitComplianceInfo{
+				"ou1": {ID: "ou1", Name: "Clinic, North", CompliancePercentage: 87.5, ElementsPresent: 7, ElementsRequired: 8, MissingElements: []string{"deA", "deB"}},
+			}},
+		},
+	}}
+
+	t.Run("Should keep the default columns", func(t *testing.T) {
+		out, err := s.ExportResults("task", "csv", 0, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, "orgUnitId,name,compliance_percentage,elements_present,elements_required\nou1,\"Clinic, North\",87.5,7,8\n", out)
+	})
+
+	t.Run("Should rename and reorder configured columns", func(t *testing.T) {
+		out, err := s.ExportResults("task", "csv", 0, []CSVColumn{
+			{Field: "compliance_percentage", Header: "Score"},
+			{Field: "orgUnitId", Header: "OU_UID"},
+			{Field: "missing_elements"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Score,OU_UID,missing_elements\n87.5,ou1,deA;deB\n", out)
+	})
+
+	t.Run("Should append the reporting rate as a second table", func(t *testing.T) {
+		s := &Service{assessmentStore: map[string]*AssessmentProgress{
+			"task": {
+				Status: "completed",
+				Results: &AssessmentResult{
+					ComplianceDetails: map[string]*OrgUnitComplianceInfo{"ou1": {Name: "Clinic"}},
+					ReportingRate: &ReportingRate{
+						Basis:          ReportingBasisData,
+						ReportingCount: ReportingCount{Expected: 4, Actual: 3, Rate: 75},
+						ByPeriod: map[string]*ReportingCount{
+							"202402": {Expected: 2, Actual: 1, Rate: 50},
+							"202401": {Expected: 2, Actual: 2, Rate: 100},
+						},
+					},
+				},
+			},
+		}}
+
+		out, err := s.ExportResults("task", "csv", 0, []CSVColumn{{Field: "orgUnitId"}})
+
+		require.NoError(t, err)
+		assert.Equal(t, "orgUnitId\nou1\n\n"+
+			"reporting_period,reporting_basis,expected_reports,actual_reports,reporting_rate\n"+
+			"all,data,4,3,75.0\n202401,data,2,2,100.0\n202402,data,2,1,50.0\n", out)
+	})
+
+	t.Run("Should reject unknown fields", func(t *testing.T) {
+		_, err := s.ExportResults("task", "csv", 0, []CSVColumn{{Field: "district"}})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown export field "district"`)
+		assert.Contains(t, err.Error(), "compliance_percentage")
+	})
+}
+
+func TestExportResultsXLSX(t *testing.T) {
+	s := &Service{assessmentStore: map[string]*AssessmentProgress{
+		"task": {
+			Status: "completed",
+			Results: &AssessmentResult{TotalCompliant: 1, TotalNonCompliant: 1, ComplianceDetails: map[string]*OrgUnitComplianceInfo{
+				"ou2": {ID: "ou2", Name: "Clinic <South>", CompliancePercentage: 50, ElementsPresent: 4, ElementsRequired: 8, MissingElements: []string{"deA", "deB"}},
+				"ou1": {ID: "ou1", Name: "Clinic North", CompliancePercentage: 87.5, ElementsPresent: 7, ElementsRequired: 8},
+			}},
+			request: &AssessmentRequest{ComplianceThreshold: 80, Periods: []string{"202401", "202402"}},
+		},
+	}}
+
+	readSheets := func(t *testing.T, out string) map[string]string {
+		data, err := base64.StdEncoding.DecodeString(out)
+		require.NoError(t, err)
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+
+		parts := map[string]string{}
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(rc)
+			rc.Close()
+			require.NoError(t, err)
+			parts[f.Name] = string(content)
+		}
+		return parts
+	}
+
+	t.Run("Should build summary and detail sheets", func(t *testing.T) {
+		out, err := s.ExportResults("task", "xlsx", 0, nil)
+		require.NoError(t, err)
+
+		parts := readSheets(t, out)
+		assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Summary" sheetId="1" r:id="rId1"/><sheet name="Details" sheetId="2" r:id="rId2"/>`)
+
+		summary := parts["xl/worksheets/sheet1.xml"]
+		assert.Contains(t, summary, `<c r="B2"><v>1</v></c>`)
+		assert.Contains(t, summary, `<c r="B5"><v>80</v></c>`)
+		assert.Contains(t, summary, "202401, 202402")
+
+		details := parts["xl/worksheets/sheet2.xml"]
+		assert.Contains(t, details, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">ou1</t></is></c>`)
+		assert.Contains(t, details, `<c r="C2"><v>87.5</v></c>`)
+		assert.Contains(t, details, "Clinic &lt;South&gt;")
+		assert.Contains(t, details, "deA;deB")
+	})
+
+	t.Run("Should cap the detail rows at the limit", func(t *testing.T) {
+		out, err := s.ExportResults("task", "xlsx", 1, nil)
+		require.NoError(t, err)
+
+		details := readSheets(t, out)["xl/worksheets/sheet2.xml"]
+		assert.Contains(t, details, "ou1")
+		assert.NotContains(t, details, "ou2")
+	})
+}
@@ -0,0 +1,109 @@
+package completeness
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"dhis2sync-desktop/internal/models"
+)
+
+// saveAssessment stores a finished assessment in the completeness_results table so it
+// outlives the in-memory store; failures only cost the durable copy
+func (s *Service) saveAssessment(taskID string) {
+	if s.db == nil {
+		return
+	}
+
+	s.assessmentMu.RLock()
+	p, exists := s.assessmentStore[taskID]
+	if !exists {
+		s.assessmentMu.RUnlock()
+		return
+	}
+	record := models.CompletenessResult{
+		ID:          p.TaskID,
+		ProfileID:   p.ProfileID,
+		Status:      p.Status,
+		Progress:    p.Progress,
+		CompletedAt: p.CompletedAt,
+	}
+	var err error
+	for _, f := range []struct {
+		dst *string
+		v   interface{}
+	}{
+		{&record.Messages, p.Messages},
+		{&record.Results, p.Results},
+		{&record.Comparison, p.Comparison},
+		{&record.Request, p.request},
+		{&record.PeriodCompliance, p.periodCompliance},
+	} {
+		var data []byte
+		if data, err = json.Marshal(f.v); err != nil {
+			break
+		}
+		*f.dst = string(data)
+	}
+	s.assessmentMu.RUnlock()
+
+	if err != nil {
+		log.Printf("WARNING: Failed to encode assessment %s: %v", taskID, err)
+		return
+	}
+	if err := s.db.Save(&record).Error; err != nil {
+		log.Printf("WARNING: Failed to save assessment %s: %v", taskID, err)
+	}
+}
+
+// loadAssessment rebuilds a finished assessment from the completeness_results table
+func (s *Service) loadAssessment(taskID string) (*AssessmentProgress, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("task not found: %s", taskID)
+	}
+
+	var record models.CompletenessResult
+	if err := s.db.Where("id = ?", taskID).First(&record).Error; err != nil {
+		return nil, fmt.Errorf("task not found: %s", taskID)
+	}
+
+	p := &AssessmentProgress{
+		TaskID:      record.ID,
+		ProfileID:   record.ProfileID,
+		Status:      record.Status,
+		Progress:    record.Progress,
+		CompletedAt: record.CompletedAt,
+	}
+	for _, f := range []struct {
+		data string
+		dst  interface{}
+	}{
+		{record.Messages, &p.Messages},
+		{record.Results, &p.Results},
+		{record.Comparison, &p.Comparison},
+		{record.Request, &p.request},
+		{record.PeriodCompliance, &p.periodCompliance},
+	} {
+		if f.data == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(f.data), f.dst); err != nil {
+			return nil, fmt.Errorf("failed to decode saved assessment %s: %w", taskID, err)
+		}
+	}
+
+	return p, nil
+}
+
+// lookupAssessment finds an assessment in memory, falling back to the saved results
+// of assessments from earlier sessions
+func (s *Service) lookupAssessment(taskID string) (*AssessmentProgress, error) {
+	s.assessmentMu.RLock()
+	p, exists := s.assessmentStore[taskID]
+	s.assessmentMu.RUnlock()
+
+	if exists {
+		return p, nil
+	}
+	return s.loadAssessment(taskID)
+}
@@ -0,0 +1,71 @@
+package completeness
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"dhis2sync-desktop/internal/models"
+)
+
+func TestPersistedAssessments(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.CompletenessResult{}))
+
+	first := NewService(db, nil)
+	first.assessmentStore["task"] = &AssessmentProgress{
+		TaskID:      "task",
+		ProfileID:   "p1",
+		Status:      "completed",
+		Progress:    100,
+		Messages:    []string{"Assessment complete"},
+		CompletedAt: 1700000000,
+		Results: &AssessmentResult{TotalCompliant: 1, ComplianceDetails: map[string]*OrgUnitComplianceInfo{
+			"ou1": {ID: "ou1", Name: "Clinic", CompliancePercentage: 100, ElementsPresent: 2, ElementsRequired: 2},
+		}},
+		request:          &AssessmentRequest{Instance: "source", Periods: []string{"202401"}, ComplianceThreshold: 80},
+		periodCompliance: map[string]map[string]float64{"202401": {"ou1": 100}},
+	}
+	first.saveAssessment("task")
+
+	// A fresh service stands in for the app after a restart
+	restarted := NewService(db, nil)
+
+	t.Run("Should load a finished assessment from an earlier session", func(t *testing.T) {
+		progress, err := restarted.GetAssessmentProgress("task")
+
+		require.NoError(t, err)
+		assert.Equal(t, "completed", progress.Status)
+		assert.Equal(t, "p1", progress.ProfileID)
+		assert.Equal(t, []string{"Assessment complete"}, progress.Messages)
+		assert.Equal(t, int64(1700000000), progress.CompletedAt)
+		require.NotNil(t, progress.Results)
+		assert.Equal(t, "Clinic", progress.Results.ComplianceDetails["ou1"].Name)
+	})
+
+	t.Run("Should export saved results", func(t *testing.T) {
+		out, err := restarted.ExportResults("task", "csv", 0, nil)
+
+		require.NoError(t, err)
+		assert.Contains(t, out, "ou1,Clinic,100.0,2,2")
+	})
+
+	t.Run("Should keep per-period compliance for follow-up transfers", func(t *testing.T) {
+		orgUnits, req, err := restarted.CompliantOrgUnits("task", 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ou1"}, orgUnits)
+		assert.Equal(t, []string{"202401"}, req.Periods)
+	})
+
+	t.Run("Should report unknown tasks as not found", func(t *testing.T) {
+		_, err := restarted.GetAssessmentProgress("missing")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "task not found")
+	})
+}
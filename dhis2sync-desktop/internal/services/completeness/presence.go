@@ -0,0 +1,73 @@
+package completeness
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Presence rules decide whether a reported value counts an element as present
+const (
+	PresenceNonEmpty = "non_empty" // Any non-empty value (default)
+	PresenceNonZero  = "non_zero"  // Non-empty and not numerically zero
+	PresencePattern  = "pattern"   // Value matches AssessmentRequest.PresencePattern
+)
+
+// newPresenceCheck builds the value test for a presence rule
+func newPresenceCheck(rule, pattern string) (func(value string) bool, error) {
+	switch rule {
+	case "", PresenceNonEmpty:
+		return func(value string) bool {
+			return strings.TrimSpace(value) != ""
+		}, nil
+
+	case PresenceNonZero:
+		return func(value string) bool {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return false
+			}
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				return f != 0
+			}
+			return true // Non-numeric values (text, dates) are reports in their own right
+		}, nil
+
+	case PresencePattern:
+		if pattern == "" {
+			return nil, fmt.Errorf("presence rule %q requires a presence_pattern", rule)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid presence pattern %q: %w", pattern, err)
+		}
+		return func(value string) bool {
+			return re.MatchString(strings.TrimSpace(value))
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown presence rule: %s", rule)
+}
+
+// buildOrgUnitData indexes which data elements each org unit reported, counting
+// only values accepted by present. Returns orgUnitID -> dataElementID -> true.
+func buildOrgUnitData(dataValues []interface{}, present func(value string) bool) map[string]map[string]bool {
+	orgUnitData := make(map[string]map[string]bool)
+
+	for _, dv := range dataValues {
+		dvMap, _ := dv.(map[string]interface{})
+		ouID, _ := dvMap["orgUnit"].(string)
+		deID, _ := dvMap["dataElement"].(string)
+		value, _ := dvMap["value"].(string)
+
+		if ouID != "" && deID != "" && present(value) {
+			if orgUnitData[ouID] == nil {
+				orgUnitData[ouID] = make(map[string]bool)
+			}
+			orgUnitData[ouID][deID] = true
+		}
+	}
+
+	return orgUnitData
+}
@@ -0,0 +1,156 @@
+package completeness
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+
+	"dhis2sync-desktop/internal/api"
+)
+
+// Reporting rate bases decide what counts as an org unit's report for a period
+const (
+	ReportingBasisData     = "data"     // Any value reported, per the presence rule (default)
+	ReportingBasisComplete = "complete" // Dataset marked complete
+)
+
+// validateReportingBasis checks AssessmentRequest.ReportingRateBasis
+func validateReportingBasis(basis string) error {
+	switch basis {
+	case "", ReportingBasisData, ReportingBasisComplete:
+		return nil
+	}
+	return fmt.Errorf("unknown reporting rate basis %q (valid: %s, %s)", basis, ReportingBasisData, ReportingBasisComplete)
+}
+
+// fetchAssignedOrgUnits lists the org units the dataset is assigned to, i.e. expected to report
+func fetchAssignedOrgUnits(client *api.Client, datasetID string) (map[string]bool, error) {
+	endpoint := fmt.Sprintf("/api/dataSets/%s", datasetID)
+	resp, err := client.Get(endpoint, map[string]string{"fields": "organisationUnits[id]"})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), api.BodySnippet(resp.Body(), 200))
+	}
+
+	var dataset struct {
+		OrganisationUnits []struct {
+			ID string `json:"id"`
+		} `json:"organisationUnits"`
+	}
+	if err := api.DecodeJSON(resp, endpoint, &dataset); err != nil {
+		return nil, err
+	}
+
+	assigned := make(map[string]bool, len(dataset.OrganisationUnits))
+	for _, ou := range dataset.OrganisationUnits {
+		assigned[ou.ID] = true
+	}
+	return assigned, nil
+}
+
+// fetchCompletedOrgUnits lists the org units under parents whose dataset is marked complete
+// for the period. Registrations without a "completed" flag (older DHIS2) count as complete.
+func fetchCompletedOrgUnits(client *api.Client, datasetID, period string, parents []string) (map[string]bool, error) {
+	// orgUnit repeats, which the params map can't express
+	query := url.Values{"dataSet": {datasetID}, "period": {period}, "orgUnit": parents, "children": {"true"}}
+	resp, err := client.Get("/api/completeDataSetRegistrations?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), api.BodySnippet(resp.Body(), 200))
+	}
+
+	var result struct {
+		Registrations []struct {
+			OrganisationUnit string `json:"organisationUnit"`
+			Completed        *bool  `json:"completed"`
+		} `json:"completeDataSetRegistrations"`
+	}
+	if err := api.DecodeJSON(resp, "/api/completeDataSetRegistrations", &result); err != nil {
+		return nil, err
+	}
+
+	completed := make(map[string]bool, len(result.Registrations))
+	for _, reg := range result.Registrations {
+		if reg.Completed == nil || *reg.Completed {
+			completed[reg.OrganisationUnit] = true
+		}
+	}
+	return completed, nil
+}
+
+// countReports tallies one period's reports over the assessed org units: assigned units
+// are expected, and they report when they have data or, if completed is set, when their
+// dataset is marked complete
+func countReports(details map[string]*OrgUnitComplianceInfo, assigned, completed map[string]bool) ReportingCount {
+	var count ReportingCount
+	for ouID, info := range details {
+		if !assigned[ouID] {
+			continue
+		}
+		count.Expected++
+		if completed != nil && completed[ouID] || completed == nil && info.HasData {
+			count.Actual++
+		}
+	}
+	count.setRate()
+	return count
+}
+
+// add folds another count into c
+func (c *ReportingCount) add(other ReportingCount) {
+	c.Expected += other.Expected
+	c.Actual += other.Actual
+	c.setRate()
+}
+
+func (c *ReportingCount) setRate() {
+	c.Rate = 0
+	if c.Expected > 0 {
+		c.Rate = float64(c.Actual) / float64(c.Expected) * 100
+	}
+}
+
+// reportingRateTable lays out a reporting rate for export: a header, the overall rate
+// ("all") and then each period in order
+func reportingRateTable(rate *ReportingRate) [][]interface{} {
+	row := func(period string, c ReportingCount) []interface{} {
+		return []interface{}{period, rate.Basis, c.Expected, c.Actual, c.Rate}
+	}
+
+	table := [][]interface{}{
+		{"reporting_period", "reporting_basis", "expected_reports", "actual_reports", "reporting_rate"},
+		row("all", rate.ReportingCount),
+	}
+
+	periods := make([]string, 0, len(rate.ByPeriod))
+	for period := range rate.ByPeriod {
+		periods = append(periods, period)
+	}
+	sort.Strings(periods)
+	for _, period := range periods {
+		table = append(table, row(period, *rate.ByPeriod[period]))
+	}
+	return table
+}
+
+// reportingRateCSV renders reportingRateTable rows as CSV records
+func reportingRateCSV(rate *ReportingRate) [][]string {
+	var records [][]string
+	for _, row := range reportingRateTable(rate) {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			switch v := cell.(type) {
+			case float64:
+				record[i] = fmt.Sprintf("%.1f", v)
+			default:
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
@@ -0,0 +1,75 @@
+package completeness
+
+import (
+	"net/http"
+	"testing"
+
+	"dhis2sync-desktop/internal/api/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountReports(t *testing.T) {
+	details := map[string]*OrgUnitComplianceInfo{
+		"ouDistrict": {HasData: true},  // Not assigned: never expected
+		"ouClinicA":  {HasData: true},  // Reported data
+		"ouClinicB":  {HasData: false}, // Marked complete without data
+		"ouClinicC":  {HasData: false},
+	}
+	assigned := map[string]bool{"ouClinicA": true, "ouClinicB": true, "ouClinicC": true, "ouElsewhere": true}
+
+	t.Run("Should count assigned org units with data by default", func(t *testing.T) {
+		count := countReports(details, assigned, nil)
+
+		assert.Equal(t, 3, count.Expected, "Assigned units outside the assessed subtree aren't expected")
+		assert.Equal(t, 1, count.Actual)
+		assert.InDelta(t, 33.33, count.Rate, 0.01)
+	})
+
+	t.Run("Should count completed registrations under the complete basis", func(t *testing.T) {
+		count := countReports(details, assigned, map[string]bool{"ouClinicB": true, "ouDistrict": true})
+
+		assert.Equal(t, 3, count.Expected)
+		assert.Equal(t, 1, count.Actual)
+	})
+
+	t.Run("Should total periods", func(t *testing.T) {
+		var total ReportingCount
+		total.add(ReportingCount{Expected: 3, Actual: 3})
+		total.add(ReportingCount{Expected: 3, Actual: 0})
+
+		assert.Equal(t, ReportingCount{Expected: 6, Actual: 3, Rate: 50}, total)
+	})
+}
+
+func TestFetchCompletedOrgUnits(t *testing.T) {
+	t.Run("Should query the subtree and skip incomplete registrations", func(t *testing.T) {
+		var query map[string][]string
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/completeDataSetRegistrations": func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.Query()
+				apitest.JSON(http.StatusOK, map[string]interface{}{
+					"completeDataSetRegistrations": []map[string]interface{}{
+						{"organisationUnit": "ouClinicA", "completed": true},
+						{"organisationUnit": "ouClinicB", "completed": false},
+						{"organisationUnit": "ouClinicC"},
+					},
+				})(w, r)
+			},
+		})
+
+		completed, err := fetchCompletedOrgUnits(srv.Client(), "ds1", "202401", []string{"ouParent1", "ouParent2"})
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"ouClinicA": true, "ouClinicC": true}, completed)
+		assert.Equal(t, []string{"ouParent1", "ouParent2"}, query["orgUnit"])
+		assert.Equal(t, []string{"true"}, query["children"])
+	})
+}
+
+func TestValidateReportingBasis(t *testing.T) {
+	assert.NoError(t, validateReportingBasis(""))
+	assert.NoError(t, validateReportingBasis(ReportingBasisComplete))
+	assert.ErrorContains(t, validateReportingBasis("submitted"), `unknown reporting rate basis "submitted"`)
+}
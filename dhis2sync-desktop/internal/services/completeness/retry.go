@@ -0,0 +1,62 @@
+package completeness
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// fetchMaxAttempts is the number of attempts made for each DHIS2 read during an assessment
+const fetchMaxAttempts = 3
+
+// retryBaseDelay scales the backoff between attempts (overridden in tests)
+var retryBaseDelay = 500 * time.Millisecond
+
+// permanentError marks a failure that retrying can't fix, such as a 409 for a
+// locked period; retryWithBackoff returns it without further attempts
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// retryWithBackoff executes an operation with exponential backoff retry logic.
+// Mirrors the transfer service's helper so a transient network blip doesn't
+// abort the assessment of an entire hierarchy.
+func retryWithBackoff(taskID string, operation func() error, maxAttempts int, taskLogger func(taskID, msg string)) error {
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err := operation()
+		if err == nil {
+			if attempt > 1 && taskLogger != nil {
+				taskLogger(taskID, fmt.Sprintf("✓ Operation succeeded on retry %d/%d", attempt, maxAttempts))
+			}
+			return nil
+		}
+
+		lastErr = err
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+
+		// Don't sleep after last attempt
+		if attempt < maxAttempts {
+			backoffDuration := retryBaseDelay * time.Duration(attempt*attempt) // 500ms, 2s, 4.5s
+			if taskLogger != nil {
+				taskLogger(taskID, fmt.Sprintf("⚠ Attempt %d/%d failed: %v (retrying in %v)", attempt, maxAttempts, err, backoffDuration))
+			}
+			log.Printf("Task %s: Retry %d/%d after %v: %v", taskID, attempt, maxAttempts, backoffDuration, err)
+			time.Sleep(backoffDuration)
+		} else {
+			if taskLogger != nil {
+				taskLogger(taskID, fmt.Sprintf("✗ All %d attempts failed: %v", maxAttempts, err))
+			}
+			log.Printf("Task %s: All %d attempts failed: %v", taskID, maxAttempts, err)
+		}
+	}
+	return fmt.Errorf("failed after %d attempts: %w", maxAttempts, lastErr)
+}
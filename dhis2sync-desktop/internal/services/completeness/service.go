@@ -2,21 +2,26 @@ package completeness
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/go-resty/resty/v2"
 	"github.com/google/uuid"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 	"gorm.io/gorm"
 
 	"dhis2sync-desktop/internal/api"
-	"dhis2sync-desktop/internal/crypto"
+	"dhis2sync-desktop/internal/events"
 	"dhis2sync-desktop/internal/models"
+	"dhis2sync-desktop/internal/notifications"
+	"dhis2sync-desktop/internal/writewindow"
 )
 
 // Service handles completeness assessment operations
@@ -41,6 +46,13 @@ func NewService(db *gorm.DB, ctx context.Context) *Service {
 
 // StartAssessment initiates a background completeness assessment
 func (s *Service) StartAssessment(req AssessmentRequest) (string, error) {
+	if _, err := newPresenceCheck(req.PresenceRule, req.PresencePattern); err != nil {
+		return "", err
+	}
+	if err := validateReportingBasis(req.ReportingRateBasis); err != nil {
+		return "", err
+	}
+
 	profile, err := s.getProfile(req.ProfileID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get profile: %w", err)
@@ -53,6 +65,9 @@ func (s *Service) StartAssessment(req AssessmentRequest) (string, error) {
 		Status:    "starting",
 		Progress:  0,
 		Messages:  []string{"Starting completeness assessment..."},
+
+		request:          &req,
+		periodCompliance: make(map[string]map[string]float64),
 	}
 
 	s.assessmentMu.Lock()
@@ -68,27 +83,19 @@ func (s *Service) StartAssessment(req AssessmentRequest) (string, error) {
 	return taskID, nil
 }
 
-// GetAssessmentProgress retrieves assessment progress
+// GetAssessmentProgress retrieves assessment progress, including finished assessments
+// from earlier sessions
 func (s *Service) GetAssessmentProgress(taskID string) (*AssessmentProgress, error) {
-	s.assessmentMu.RLock()
-	defer s.assessmentMu.RUnlock()
-
-	progress, exists := s.assessmentStore[taskID]
-	if !exists {
-		return nil, fmt.Errorf("task not found: %s", taskID)
-	}
-
-	return progress, nil
+	return s.lookupAssessment(taskID)
 }
 
-// ExportResults exports assessment results in JSON or CSV format
-func (s *Service) ExportResults(taskID, format string, limit int) (string, error) {
-	s.assessmentMu.RLock()
-	progress, exists := s.assessmentStore[taskID]
-	s.assessmentMu.RUnlock()
-
-	if !exists {
-		return "", fmt.Errorf("task not found: %s", taskID)
+// ExportResults exports assessment results in JSON, CSV or XLSX format; columns configures
+// the CSV layout. A reporting rate, when computed, follows the CSV org unit rows as a
+// second table and gets its own XLSX sheet. XLSX workbooks are returned base64-encoded.
+func (s *Service) ExportResults(taskID, format string, limit int, columns []CSVColumn) (string, error) {
+	progress, err := s.lookupAssessment(taskID)
+	if err != nil {
+		return "", err
 	}
 
 	if progress.Status != "completed" || progress.Results == nil {
@@ -106,30 +113,43 @@ func (s *Service) ExportResults(taskID, format string, limit int) (string, error
 	}
 
 	if format == "csv" {
+		cols, headers, err := resolveCSVColumns(columns)
+		if err != nil {
+			return "", err
+		}
+
 		var buf strings.Builder
 		writer := csv.NewWriter(&buf)
 
-		writer.Write([]string{"orgUnitId", "name", "compliance_percentage", "elements_present", "elements_required"})
+		writer.Write(headers)
 
 		count := 0
 		for ouID, info := range results.ComplianceDetails {
 			if limit > 0 && count >= limit {
 				break
 			}
-			writer.Write([]string{
-				ouID,
-				info.Name,
-				fmt.Sprintf("%.1f", info.CompliancePercentage),
-				fmt.Sprintf("%d", info.ElementsPresent),
-				fmt.Sprintf("%d", info.ElementsRequired),
-			})
+			writer.Write(csvRow(cols, ouID, info))
 			count++
 		}
 
+		// The reporting rate follows the org units as its own table after a blank line
+		if results.ReportingRate != nil {
+			writer.Write([]string{""})
+			writer.WriteAll(reportingRateCSV(results.ReportingRate))
+		}
+
 		writer.Flush()
 		return buf.String(), writer.Error()
 	}
 
+	if format == "xlsx" {
+		data, err := resultsWorkbook(results, progress.request, limit)
+		if err != nil {
+			return "", fmt.Errorf("failed to build workbook: %w", err)
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+
 	return "", fmt.Errorf("unsupported format: %s", format)
 }
 
@@ -138,11 +158,19 @@ func (s *Service) StartBulkAction(req BulkActionRequest) (string, error) {
 	if req.Action != "complete" && req.Action != "incomplete" {
 		return "", fmt.Errorf("action must be 'complete' or 'incomplete'")
 	}
+	if req.CompleteDate != "" {
+		if _, err := time.Parse("2006-01-02", req.CompleteDate); err != nil {
+			return "", fmt.Errorf("complete_date must be in YYYY-MM-DD format: %w", err)
+		}
+	}
 
 	profile, err := s.getProfile(req.ProfileID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get profile: %w", err)
 	}
+	if err := writewindow.Check(profile, time.Now()); err != nil {
+		return "", err
+	}
 
 	taskID := uuid.New().String()
 	progress := &BulkActionProgress{
@@ -155,17 +183,142 @@ func (s *Service) StartBulkAction(req BulkActionRequest) (string, error) {
 			Successful: []string{},
 			Failed:     []string{},
 		},
+		request: req,
 	}
 
 	s.bulkActionMu.Lock()
 	s.bulkActionStore[taskID] = progress
 	s.bulkActionMu.Unlock()
 
-	go s.performBulkAction(taskID, profile, req)
+	items := make([]BulkActionFailure, 0, len(req.OrgUnits)*len(req.Periods))
+	for _, ouID := range req.OrgUnits {
+		for _, period := range req.Periods {
+			items = append(items, BulkActionFailure{OrgUnit: ouID, Period: period})
+		}
+	}
+
+	go s.performBulkAction(taskID, profile, req, items)
 
 	return taskID, nil
 }
 
+// RetryFailedBulkAction re-attempts only the failed registrations of a finished bulk
+// action under a new task. When it completes, its outcomes are merged back into the
+// original task's results.
+func (s *Service) RetryFailedBulkAction(taskID string) (string, error) {
+	s.bulkActionMu.RLock()
+	original, exists := s.bulkActionStore[taskID]
+	var status string
+	var req BulkActionRequest
+	var failures []BulkActionFailure
+	if exists {
+		status = original.Status
+		req = original.request
+		failures = failedBulkItems(original.Results)
+	}
+	s.bulkActionMu.RUnlock()
+
+	if !exists {
+		return "", fmt.Errorf("task not found: %s", taskID)
+	}
+	if status != "completed" {
+		return "", fmt.Errorf("bulk action is not finished (current status: %s)", status)
+	}
+	if len(failures) == 0 {
+		return "", fmt.Errorf("bulk action has no failed items to retry")
+	}
+
+	profile, err := s.getProfile(req.ProfileID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	retryID := uuid.New().String()
+	progress := &BulkActionProgress{
+		TaskID:   retryID,
+		Status:   "starting",
+		Progress: 0,
+		Messages: []string{fmt.Sprintf("Retrying %d failed %s registrations...", len(failures), req.Action)},
+		Results: &BulkActionResult{
+			Action:     req.Action,
+			Successful: []string{},
+			Failed:     []string{},
+		},
+		RetryOf: taskID,
+		request: req,
+	}
+
+	s.bulkActionMu.Lock()
+	s.bulkActionStore[retryID] = progress
+	s.bulkActionMu.Unlock()
+
+	go s.performBulkAction(retryID, profile, req, failures)
+
+	return retryID, nil
+}
+
+// failedBulkItems returns the failed (orgUnit, period) pairs of a bulk action,
+// falling back to parsing the "orgUnitID:period - error" strings when structured
+// failures weren't recorded
+func failedBulkItems(results *BulkActionResult) []BulkActionFailure {
+	if results == nil {
+		return nil
+	}
+	if len(results.FailedItems) > 0 {
+		return append([]BulkActionFailure(nil), results.FailedItems...)
+	}
+
+	items := []BulkActionFailure{}
+	for _, entry := range results.Failed {
+		key, errMsg, _ := strings.Cut(entry, " - ")
+		ouID, period, ok := strings.Cut(key, ":")
+		if !ok || ouID == "" || period == "" {
+			continue
+		}
+		items = append(items, BulkActionFailure{OrgUnit: ouID, Period: period, Error: errMsg})
+	}
+	return items
+}
+
+// mergeRetryResults folds a retry's outcomes into the original results: retried
+// items that succeeded move to Successful, the rest keep their latest error
+func mergeRetryResults(original, retry *BulkActionResult) {
+	if original == nil || retry == nil {
+		return
+	}
+
+	stillFailed := make(map[string]BulkActionFailure, len(retry.FailedItems))
+	for _, f := range retry.FailedItems {
+		stillFailed[f.OrgUnit+":"+f.Period] = f
+	}
+
+	failed := []string{}
+	failedItems := []BulkActionFailure{}
+	for _, f := range failedBulkItems(original) {
+		key := f.OrgUnit + ":" + f.Period
+		if latest, ok := stillFailed[key]; ok {
+			f = latest
+		} else if containsString(retry.Successful, key) {
+			original.Successful = append(original.Successful, key)
+			continue
+		}
+		failed = append(failed, fmt.Sprintf("%s - %s", key, f.Error))
+		failedItems = append(failedItems, f)
+	}
+
+	original.Failed = failed
+	original.FailedItems = failedItems
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // GetBulkActionProgress retrieves bulk action progress
 func (s *Service) GetBulkActionProgress(taskID string) (*BulkActionProgress, error) {
 	s.bulkActionMu.RLock()
@@ -188,24 +341,7 @@ func (s *Service) getProfile(profileID string) (*models.ConnectionProfile, error
 }
 
 func (s *Service) getAPIClient(profile *models.ConnectionProfile, instance string) (*api.Client, error) {
-	var url, username, encPassword string
-
-	if instance == "source" {
-		url = profile.SourceURL
-		username = profile.SourceUsername
-		encPassword = profile.SourcePasswordEnc
-	} else {
-		url = profile.DestURL
-		username = profile.DestUsername
-		encPassword = profile.DestPasswordEnc
-	}
-
-	password, err := crypto.DecryptPassword(encPassword)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt password: %w", err)
-	}
-
-	return api.NewClient(url, username, password), nil
+	return api.NewProfileClient(profile, instance)
 }
 
 func (s *Service) performAssessment(taskID string, profile *models.ConnectionProfile, req AssessmentRequest) {
@@ -234,27 +370,59 @@ func (s *Service) performAssessment(taskID string, profile *models.ConnectionPro
 		requiredElements = elements
 	}
 
+	present, err := newPresenceCheck(req.PresenceRule, req.PresencePattern)
+	if err != nil {
+		s.updateProgress(taskID, "error", 0, err.Error())
+		return
+	}
+
 	results := &AssessmentResult{
 		Hierarchy:         make(map[string]*HierarchyResult),
 		ComplianceDetails: make(map[string]*OrgUnitComplianceInfo),
 	}
 
+	// The reporting rate counts only org units the dataset is assigned to
+	basis := req.ReportingRateBasis
+	if basis == "" {
+		basis = ReportingBasisData
+	}
+	assigned, err := fetchAssignedOrgUnits(client, req.DatasetID)
+	if err != nil {
+		s.appendMessage(taskID, fmt.Sprintf("⚠ Could not load dataset assignments, assessing every org unit and skipping the reporting rate: %v", err))
+	} else {
+		results.ReportingRate = &ReportingRate{Basis: basis, ByPeriod: make(map[string]*ReportingCount)}
+	}
+
+	// Unassigned org units aren't expected to report, so by default they aren't assessed
+	universe := assigned
+	if !req.onlyAssignedOrgUnits() {
+		universe = nil
+	}
+
 	total := len(req.Periods)
 	for i, period := range req.Periods {
 		s.appendMessage(taskID, fmt.Sprintf("Assessing %s (%d/%d)...", period, i+1, total))
 
-		periodResults := s.assessPeriod(client, req.ParentOrgUnits, period, req.DatasetID,
-			requiredElements, req.ComplianceThreshold, req.IncludeParents)
+		periodResults := s.assessPeriod(taskID, client, req.ParentOrgUnits, period, req.DatasetID, universe,
+			requiredElements, req.ElementWeights, req.CriticalElements, present, req.ComplianceThreshold, req.IncludeParents)
 
-		results.TotalCompliant += periodResults.TotalCompliant
-		results.TotalNonCompliant += periodResults.TotalNonCompliant
-		results.TotalErrors += periodResults.TotalErrors
+		mergeResults(results, periodResults)
+		s.recordPeriodCompliance(taskID, period, periodResults)
 
-		for k, v := range periodResults.Hierarchy {
-			results.Hierarchy[k] = v
-		}
-		for k, v := range periodResults.ComplianceDetails {
-			results.ComplianceDetails[k] = v
+		if results.ReportingRate != nil {
+			var completed map[string]bool
+			if basis == ReportingBasisComplete {
+				completed, err = fetchCompletedOrgUnits(client, req.DatasetID, period, req.ParentOrgUnits)
+			}
+			if err != nil {
+				// A rate missing some periods' completions would understate reporting
+				s.appendMessage(taskID, fmt.Sprintf("⚠ Could not load completions for %s, reporting rate skipped: %v", period, err))
+				results.ReportingRate = nil
+			} else {
+				count := countReports(periodResults.ComplianceDetails, assigned, completed)
+				results.ReportingRate.ByPeriod[period] = &count
+				results.ReportingRate.add(count)
+			}
 		}
 
 		progress := 10 + int(85*float64(i+1)/float64(total))
@@ -273,8 +441,287 @@ func (s *Service) performAssessment(taskID string, profile *models.ConnectionPro
 	s.updateProgress(taskID, "completed", 100, "Assessment complete")
 }
 
-func (s *Service) assessPeriod(client *api.Client, parentOrgUnits []string, period,
-	datasetID string, requiredElements []string, threshold int, includeParents bool) *AssessmentResult {
+// StartComparison runs the same assessment against source and destination and
+// compares compliance per org unit, flagging units where the destination lags
+// behind the source (typically a transfer gap). req.Instance is ignored.
+func (s *Service) StartComparison(req AssessmentRequest) (string, error) {
+	if _, err := newPresenceCheck(req.PresenceRule, req.PresencePattern); err != nil {
+		return "", err
+	}
+
+	profile, err := s.getProfile(req.ProfileID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	taskID := uuid.New().String()
+	progress := &AssessmentProgress{
+		TaskID:    taskID,
+		ProfileID: req.ProfileID,
+		Status:    "starting",
+		Progress:  0,
+		Messages:  []string{"Starting source vs destination completeness comparison..."},
+	}
+
+	s.assessmentMu.Lock()
+	s.assessmentStore[taskID] = progress
+	s.assessmentMu.Unlock()
+
+	s.emitAssessmentEvent(taskID)
+
+	go s.performComparison(taskID, profile, req)
+
+	return taskID, nil
+}
+
+func (s *Service) performComparison(taskID string, profile *models.ConnectionProfile, req AssessmentRequest) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.updateProgress(taskID, "error", 0, fmt.Sprintf("Panic: %v", r))
+		}
+	}()
+
+	s.updateProgress(taskID, "running", 5, "Creating API clients...")
+
+	sourceClient, err := s.getAPIClient(profile, "source")
+	if err != nil {
+		s.updateProgress(taskID, "error", 0, fmt.Sprintf("Failed to create source client: %v", err))
+		return
+	}
+	destClient, err := s.getAPIClient(profile, "dest")
+	if err != nil {
+		s.updateProgress(taskID, "error", 0, fmt.Sprintf("Failed to create destination client: %v", err))
+		return
+	}
+
+	requiredElements := req.RequiredElements
+	if len(requiredElements) == 0 {
+		s.updateProgress(taskID, "running", 10, "Fetching dataset elements from source...")
+		elements, err := s.fetchDatasetElements(sourceClient, req.DatasetID)
+		if err != nil {
+			s.updateProgress(taskID, "error", 0, fmt.Sprintf("Failed to fetch elements: %v", err))
+			return
+		}
+		requiredElements = elements
+	}
+
+	present, err := newPresenceCheck(req.PresenceRule, req.PresencePattern)
+	if err != nil {
+		s.updateProgress(taskID, "error", 0, err.Error())
+		return
+	}
+
+	var sourceAssigned, destAssigned map[string]bool
+	if req.onlyAssignedOrgUnits() {
+		sourceAssigned = s.loadAssignedOrgUnits(taskID, sourceClient, req.DatasetID, "source")
+		destAssigned = s.loadAssignedOrgUnits(taskID, destClient, req.DatasetID, "destination")
+	}
+
+	comparison := &ComparisonResult{
+		Source:   &AssessmentResult{Hierarchy: make(map[string]*HierarchyResult), ComplianceDetails: make(map[string]*OrgUnitComplianceInfo)},
+		Dest:     &AssessmentResult{Hierarchy: make(map[string]*HierarchyResult), ComplianceDetails: make(map[string]*OrgUnitComplianceInfo)},
+		OrgUnits: make(map[string]*OrgUnitComparison),
+	}
+
+	total := len(req.Periods)
+	for i, period := range req.Periods {
+		s.appendMessage(taskID, fmt.Sprintf("Comparing %s (%d/%d)...", period, i+1, total))
+
+		sourceResults := s.assessPeriod(taskID, sourceClient, req.ParentOrgUnits, period, req.DatasetID, sourceAssigned,
+			requiredElements, req.ElementWeights, req.CriticalElements, present, req.ComplianceThreshold, req.IncludeParents)
+		destResults := s.assessPeriod(taskID, destClient, req.ParentOrgUnits, period, req.DatasetID, destAssigned,
+			requiredElements, req.ElementWeights, req.CriticalElements, present, req.ComplianceThreshold, req.IncludeParents)
+
+		mergeResults(comparison.Source, sourceResults)
+		mergeResults(comparison.Dest, destResults)
+
+		for key, cmp := range compareDetails(period, requiredElements, sourceResults.ComplianceDetails, destResults.ComplianceDetails) {
+			comparison.OrgUnits[key] = cmp
+			if cmp.Gap {
+				comparison.TotalGaps++
+			}
+		}
+
+		progress := 10 + int(85*float64(i+1)/float64(total))
+		s.updateProgress(taskID, "running", progress, "")
+	}
+
+	s.assessmentMu.Lock()
+	if p, exists := s.assessmentStore[taskID]; exists {
+		p.Comparison = comparison
+		p.CompletedAt = time.Now().Unix()
+	}
+	s.assessmentMu.Unlock()
+
+	s.updateProgress(taskID, "completed", 100,
+		fmt.Sprintf("Comparison complete: %d org unit(s) less complete on destination", comparison.TotalGaps))
+}
+
+// compareDetails pairs source and destination compliance for a period, keyed by "orgUnitID:period".
+// Org units present on only one side are compared against zero compliance.
+func compareDetails(period string, requiredElements []string, source, dest map[string]*OrgUnitComplianceInfo) map[string]*OrgUnitComparison {
+	out := make(map[string]*OrgUnitComparison)
+
+	get := func(ouID string) *OrgUnitComparison {
+		key := fmt.Sprintf("%s:%s", ouID, period)
+		cmp, ok := out[key]
+		if !ok {
+			cmp = &OrgUnitComparison{ID: ouID, Period: period}
+			out[key] = cmp
+		}
+		return cmp
+	}
+
+	for ouID, info := range source {
+		cmp := get(ouID)
+		cmp.Name = info.Name
+		cmp.SourceCompliance = info.CompliancePercentage
+		cmp.SourcePresent = info.ElementsPresent
+	}
+	for ouID, info := range dest {
+		cmp := get(ouID)
+		if cmp.Name == "" {
+			cmp.Name = info.Name
+		}
+		cmp.DestCompliance = info.CompliancePercentage
+		cmp.DestPresent = info.ElementsPresent
+	}
+
+	for _, cmp := range out {
+		cmp.Delta = cmp.DestCompliance - cmp.SourceCompliance
+		cmp.Gap = cmp.DestCompliance < cmp.SourceCompliance
+		cmp.MissingInDest = missingInDest(requiredElements, source[cmp.ID], dest[cmp.ID])
+	}
+
+	return out
+}
+
+// missingInDest lists the required elements the source reported that the destination
+// lacks, in required order; a side with no info has reported nothing
+func missingInDest(requiredElements []string, source, dest *OrgUnitComplianceInfo) []string {
+	missing := []string{}
+	if source == nil {
+		return missing
+	}
+
+	sourceMissing := make(map[string]bool, len(source.MissingElements))
+	for _, deID := range source.MissingElements {
+		sourceMissing[deID] = true
+	}
+	destMissing := make(map[string]bool)
+	if dest != nil {
+		for _, deID := range dest.MissingElements {
+			destMissing[deID] = true
+		}
+	}
+
+	for _, deID := range requiredElements {
+		if sourceMissing[deID] {
+			continue
+		}
+		if dest == nil || destMissing[deID] {
+			missing = append(missing, deID)
+		}
+	}
+	return missing
+}
+
+// recordPeriodCompliance keeps each org unit's compliance for one period; the merged
+// results only hold the last period's details
+func (s *Service) recordPeriodCompliance(taskID, period string, results *AssessmentResult) {
+	s.assessmentMu.Lock()
+	defer s.assessmentMu.Unlock()
+
+	p, exists := s.assessmentStore[taskID]
+	if !exists || p.periodCompliance == nil {
+		return
+	}
+
+	compliance := make(map[string]float64, len(results.ComplianceDetails))
+	for ouID, info := range results.ComplianceDetails {
+		// Left out, a unit missing critical elements never qualifies in CompliantOrgUnits
+		if len(info.MissingCritical) > 0 {
+			continue
+		}
+		compliance[ouID] = info.CompliancePercentage
+	}
+	p.periodCompliance[period] = compliance
+}
+
+// CompliantOrgUnits returns the org units of a completed source assessment that
+// reached threshold percent compliance in every assessed period, sorted, together
+// with the assessment's request. threshold <= 0 uses the assessment's own
+// ComplianceThreshold.
+func (s *Service) CompliantOrgUnits(taskID string, threshold float64) ([]string, *AssessmentRequest, error) {
+	p, err := s.lookupAssessment(taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.assessmentMu.RLock()
+	defer s.assessmentMu.RUnlock()
+
+	if p.Status != "completed" || p.request == nil {
+		return nil, nil, fmt.Errorf("assessment %s has not completed (status: %s)", taskID, p.Status)
+	}
+	if p.request.Instance != "source" {
+		return nil, nil, fmt.Errorf("assessment %s checked the %q instance; transfers need a source assessment", taskID, p.request.Instance)
+	}
+
+	if threshold <= 0 {
+		threshold = float64(p.request.ComplianceThreshold)
+	}
+
+	return compliantInAllPeriods(p.periodCompliance, p.request.Periods, threshold), p.request, nil
+}
+
+// compliantInAllPeriods selects org units at or above threshold in each of periods.
+// An org unit missing from a period's results doesn't qualify.
+func compliantInAllPeriods(periodCompliance map[string]map[string]float64, periods []string, threshold float64) []string {
+	if len(periods) == 0 {
+		return []string{}
+	}
+
+	compliant := []string{}
+	for ouID, pct := range periodCompliance[periods[0]] {
+		if pct < threshold {
+			continue
+		}
+		ok := true
+		for _, period := range periods[1:] {
+			if other, found := periodCompliance[period][ouID]; !found || other < threshold {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			compliant = append(compliant, ouID)
+		}
+	}
+
+	sort.Strings(compliant)
+	return compliant
+}
+
+// mergeResults folds a single period's results into the running totals
+func mergeResults(into, from *AssessmentResult) {
+	into.TotalCompliant += from.TotalCompliant
+	into.TotalNonCompliant += from.TotalNonCompliant
+	into.TotalErrors += from.TotalErrors
+
+	for k, v := range from.Hierarchy {
+		into.Hierarchy[k] = v
+	}
+	for k, v := range from.ComplianceDetails {
+		into.ComplianceDetails[k] = v
+	}
+}
+
+// assessPeriod scores the org units under each parent for one period. With assigned set,
+// only org units in it are assessed; nil assesses the whole subtree.
+func (s *Service) assessPeriod(taskID string, client *api.Client, parentOrgUnits []string, period,
+	datasetID string, assigned map[string]bool, requiredElements []string, weights map[string]float64, critical []string, present func(value string) bool,
+	threshold int, includeParents bool) *AssessmentResult {
 
 	results := &AssessmentResult{
 		Hierarchy:         make(map[string]*HierarchyResult),
@@ -287,7 +734,12 @@ func (s *Service) assessPeriod(client *api.Client, parentOrgUnits []string, peri
 		// Step 1: Fetch the full organisation unit hierarchy (universe of units)
 		// We use the 'path:like' filter to get the parent and all its descendants
 		log.Printf("Fetching hierarchy for parent: %s (%s)", parentName, parentOU)
-		orgUnits, err := s.fetchOrgUnitHierarchy(client, parentOU)
+		var orgUnits []models.OrganisationUnit
+		err := retryWithBackoff(taskID, func() error {
+			var fetchErr error
+			orgUnits, fetchErr = s.fetchOrgUnitHierarchy(client, parentOU)
+			return fetchErr
+		}, fetchMaxAttempts, s.appendMessage)
 		if err != nil {
 			log.Printf("Error fetching hierarchy: %v", err)
 			results.TotalErrors++
@@ -298,12 +750,23 @@ func (s *Service) assessPeriod(client *api.Client, parentOrgUnits []string, peri
 
 		// Step 2: Fetch data values for the entire subtree
 		log.Printf("Fetching data values for parent: %s", parentName)
-		resp, err := client.Get("/api/dataValueSets", map[string]string{
-			"dataSet":  datasetID,
-			"orgUnit":  parentOU,
-			"period":   period,
-			"children": "true",
-		})
+		var resp *resty.Response
+		err = retryWithBackoff(taskID, func() error {
+			var fetchErr error
+			resp, fetchErr = client.Get("/api/dataValueSets", map[string]string{
+				"dataSet":  datasetID,
+				"orgUnit":  parentOU,
+				"period":   period,
+				"children": "true",
+			})
+			if fetchErr != nil {
+				return fetchErr
+			}
+			if !resp.IsSuccess() {
+				return fmt.Errorf("HTTP %d: %s", resp.StatusCode(), resp.String())
+			}
+			return nil
+		}, fetchMaxAttempts, s.appendMessage)
 
 		if err != nil {
 			log.Printf("Error fetching data values: %v", err)
@@ -318,63 +781,25 @@ func (s *Service) assessPeriod(client *api.Client, parentOrgUnits []string, peri
 		dataValues, _ := data["dataValues"].([]interface{})
 		log.Printf("Fetched %d data values for %s", len(dataValues), parentName)
 
-		// Map: OrgUnitID -> DataElementID -> Exists
-		orgUnitData := make(map[string]map[string]bool)
-
-		for _, dv := range dataValues {
-			dvMap, _ := dv.(map[string]interface{})
-			ouID, _ := dvMap["orgUnit"].(string)
-			deID, _ := dvMap["dataElement"].(string)
-			value, _ := dvMap["value"].(string)
-
-			if ouID != "" && deID != "" && value != "" {
-				if orgUnitData[ouID] == nil {
-					orgUnitData[ouID] = make(map[string]bool)
-				}
-				orgUnitData[ouID][deID] = true
-			}
-		}
+		// Map: OrgUnitID -> DataElementID -> Exists (per the request's presence rule)
+		orgUnitData := buildOrgUnitData(dataValues, present)
 
 		compliantUnits := []*OrgUnitComplianceInfo{}
 		nonCompliantUnits := []*OrgUnitComplianceInfo{}
 
-		// Step 3: Iterate over ALL organisation units in the hierarchy
-		for _, ou := range orgUnits {
-			// Skip parent if requested
-			if ou.ID == parentOU && !includeParents {
-				continue
-			}
-
+		// Step 3: Iterate over the organisation units expected to report
+		for _, ou := range assessedOrgUnits(orgUnits, parentOU, includeParents, assigned) {
 			// Check if this unit has data
 			elementsWithData := orgUnitData[ou.ID]
 
-			presentCount := 0
-			if elementsWithData != nil {
-				for _, de := range requiredElements {
-					if elementsWithData[de] {
-						presentCount++
-					}
-				}
-			}
-
-			compliancePercentage := 0.0
-			if len(requiredElements) > 0 {
-				compliancePercentage = float64(presentCount) / float64(len(requiredElements)) * 100
-			}
-
-			info := &OrgUnitComplianceInfo{
-				ID:                   ou.ID,
-				Name:                 ou.Name,
-				CompliancePercentage: compliancePercentage,
-				ElementsPresent:      presentCount,
-				ElementsRequired:     len(requiredElements),
-				HasData:              elementsWithData != nil && len(elementsWithData) > 0,
-				TotalEntries:         len(elementsWithData),
-			}
+			info := computeCompliance(elementsWithData, requiredElements, weights)
+			info.ID = ou.ID
+			info.Name = ou.Name
+			info.MissingCritical = missingCriticalElements(elementsWithData, critical)
 
 			results.ComplianceDetails[ou.ID] = info
 
-			if compliancePercentage >= float64(threshold) {
+			if isCompliant(info, threshold) {
 				compliantUnits = append(compliantUnits, info)
 				results.TotalCompliant++
 			} else {
@@ -397,6 +822,81 @@ func (s *Service) assessPeriod(client *api.Client, parentOrgUnits []string, peri
 	return results
 }
 
+// assessedOrgUnits picks the subtree's org units to assess: the parent only if requested,
+// and only assigned units when assigned is set
+func assessedOrgUnits(orgUnits []models.OrganisationUnit, parentOU string, includeParents bool, assigned map[string]bool) []models.OrganisationUnit {
+	assessed := make([]models.OrganisationUnit, 0, len(orgUnits))
+	for _, ou := range orgUnits {
+		if ou.ID == parentOU && !includeParents {
+			continue
+		}
+		if assigned != nil && !assigned[ou.ID] {
+			continue
+		}
+		assessed = append(assessed, ou)
+	}
+	return assessed
+}
+
+// loadAssignedOrgUnits fetches the dataset's assignments on one instance; when they can't
+// be read it warns and returns nil so every org unit is assessed
+func (s *Service) loadAssignedOrgUnits(taskID string, client *api.Client, datasetID, instance string) map[string]bool {
+	assigned, err := fetchAssignedOrgUnits(client, datasetID)
+	if err != nil {
+		s.appendMessage(taskID, fmt.Sprintf("⚠ Could not load %s dataset assignments, assessing every org unit: %v", instance, err))
+		return nil
+	}
+	return assigned
+}
+
+// computeCompliance scores an org unit's reported elements against the required set.
+// Each required element contributes its weight (default 1) when present, and the
+// compliance percentage is weighted-present over weighted-required.
+func computeCompliance(elementsWithData map[string]bool, requiredElements []string, weights map[string]float64) *OrgUnitComplianceInfo {
+	info := &OrgUnitComplianceInfo{
+		ElementsRequired: len(requiredElements),
+		HasData:          len(elementsWithData) > 0,
+		TotalEntries:     len(elementsWithData),
+		MissingElements:  []string{},
+	}
+
+	for _, de := range requiredElements {
+		weight := 1.0
+		if w, ok := weights[de]; ok && w >= 0 {
+			weight = w
+		}
+		info.WeightedRequired += weight
+		if elementsWithData[de] {
+			info.ElementsPresent++
+			info.WeightedPresent += weight
+		} else {
+			info.MissingElements = append(info.MissingElements, de)
+		}
+	}
+
+	if info.WeightedRequired > 0 {
+		info.CompliancePercentage = info.WeightedPresent / info.WeightedRequired * 100
+	}
+
+	return info
+}
+
+// missingCriticalElements lists the critical elements an org unit didn't report
+func missingCriticalElements(elementsWithData map[string]bool, critical []string) []string {
+	var missing []string
+	for _, de := range critical {
+		if !elementsWithData[de] {
+			missing = append(missing, de)
+		}
+	}
+	return missing
+}
+
+// isCompliant decides compliance: at or above threshold percent with every critical element reported
+func isCompliant(info *OrgUnitComplianceInfo, threshold int) bool {
+	return info.CompliancePercentage >= float64(threshold) && len(info.MissingCritical) == 0
+}
+
 // fetchOrgUnitHierarchy fetches the parent org unit and all its descendants
 func (s *Service) fetchOrgUnitHierarchy(client *api.Client, parentID string) ([]models.OrganisationUnit, error) {
 	// Fetch ID, Name, and Level for the subtree
@@ -409,19 +909,27 @@ func (s *Service) fetchOrgUnitHierarchy(client *api.Client, parentID string) ([]
 	if err != nil {
 		return nil, err
 	}
+	if !resp.IsSuccess() {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), resp.String())
+	}
 
 	var result struct {
 		OrganisationUnits []models.OrganisationUnit `json:"organisationUnits"`
 	}
 
-	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+	if err := api.DecodeJSON(resp, "/api/organisationUnits", &result); err != nil {
 		return nil, fmt.Errorf("failed to parse org units: %w", err)
 	}
 
+	// Later periods and tasks can name these units without asking again
+	for _, ou := range result.OrganisationUnits {
+		client.CacheOrgUnitName(ou.ID, ou.Name)
+	}
+
 	return result.OrganisationUnits, nil
 }
 
-func (s *Service) performBulkAction(taskID string, profile *models.ConnectionProfile, req BulkActionRequest) {
+func (s *Service) performBulkAction(taskID string, profile *models.ConnectionProfile, req BulkActionRequest, items []BulkActionFailure) {
 	defer func() {
 		if r := recover(); r != nil {
 			s.updateBulkProgress(taskID, "error", 0, fmt.Sprintf("Panic: %v", r))
@@ -434,58 +942,128 @@ func (s *Service) performBulkAction(taskID string, profile *models.ConnectionPro
 		return
 	}
 
-	totalSteps := len(req.OrgUnits) * len(req.Periods)
-	processed := 0
+	// runBulkRegistrations retries transient failures itself and reports each attempt
+	client.SetRetryCount(0)
 
-	for _, ouID := range req.OrgUnits {
-		for _, period := range req.Periods {
-			key := fmt.Sprintf("%s:%s", ouID, period)
-
-			payload := map[string]interface{}{
-				"completeDataSetRegistrations": []map[string]interface{}{
-					{
-						"dataSet":          req.DatasetID,
-						"period":           period,
-						"organisationUnit": ouID,
-						"completed":        req.Action == "complete",
-					},
-				},
-			}
+	totalSteps := len(items)
+	processed := 0
 
-			_, err := client.Post("/api/completeDataSetRegistrations", payload)
+	runBulkRegistrations(client, taskID, req, items, s.appendBulkMessage, func(item BulkActionFailure, err error) {
+		key := fmt.Sprintf("%s:%s", item.OrgUnit, item.Period)
 
-			s.bulkActionMu.Lock()
-			if p, exists := s.bulkActionStore[taskID]; exists && p.Results != nil {
-				if err == nil {
-					p.Results.Successful = append(p.Results.Successful, key)
-				} else {
-					p.Results.Failed = append(p.Results.Failed, fmt.Sprintf("%s - %s", key, err.Error()))
-				}
-				p.Results.TotalProcessed++
+		s.bulkActionMu.Lock()
+		processed++
+		progress := int(float64(processed) / float64(totalSteps) * 100)
+		if p, exists := s.bulkActionStore[taskID]; exists && p.Results != nil {
+			if err == nil {
+				p.Results.Successful = append(p.Results.Successful, key)
+			} else {
+				p.Results.Failed = append(p.Results.Failed, fmt.Sprintf("%s - %s", key, err.Error()))
+				p.Results.FailedItems = append(p.Results.FailedItems, BulkActionFailure{OrgUnit: item.OrgUnit, Period: item.Period, Error: err.Error()})
 			}
-			s.bulkActionMu.Unlock()
-
-			processed++
-			progress := int(float64(processed) / float64(totalSteps) * 100)
-			s.updateBulkProgress(taskID, "running", progress, "")
-			time.Sleep(10 * time.Millisecond)
+			p.Results.TotalProcessed++
 		}
-	}
+		s.bulkActionMu.Unlock()
+
+		s.updateBulkProgress(taskID, "running", progress, "")
+	})
 
 	s.bulkActionMu.Lock()
 	if p, exists := s.bulkActionStore[taskID]; exists {
 		p.CompletedAt = time.Now().Unix()
+		if p.RetryOf != "" {
+			if original, ok := s.bulkActionStore[p.RetryOf]; ok {
+				mergeRetryResults(original.Results, p.Results)
+			}
+		}
 	}
 	s.bulkActionMu.Unlock()
 
 	s.updateBulkProgress(taskID, "completed", 100, "Bulk action complete")
 
-	runtime.EventsEmit(s.ctx, fmt.Sprintf("bulk-action:%s", taskID), map[string]interface{}{
+	events.EmitProgress(s.ctx, fmt.Sprintf("bulk-action:%s", taskID), "bulk_completeness", map[string]interface{}{
 		"task_id": taskID,
 		"status":  "completed",
 	})
 }
 
+// Bounds for concurrent registration POSTs in bulk actions
+const (
+	defaultBulkConcurrency = 4
+	maxBulkConcurrency     = 10
+)
+
+// bulkRequestDelay paces each worker between registration POSTs, keeping bulk actions
+// gentle on servers whose profile sets no rate limit
+const bulkRequestDelay = 10 * time.Millisecond
+
+// bulkMaxAttempts is the number of attempts per registration; (un)completing is
+// idempotent, so repeating a POST whose response was lost is safe
+const bulkMaxAttempts = 3
+
+// runBulkRegistrations POSTs one registration per item with bounded parallelism,
+// retrying transient failures. onResult is called exactly once per item, with the
+// final outcome, from worker goroutines and must be safe for concurrent use.
+// Retry attempts are reported through logMessage.
+func runBulkRegistrations(client *api.Client, taskID string, req BulkActionRequest, items []BulkActionFailure, logMessage func(taskID, msg string), onResult func(item BulkActionFailure, err error)) {
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency
+	}
+	if concurrency > maxBulkConcurrency {
+		concurrency = maxBulkConcurrency
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for _, item := range items {
+		sem <- struct{}{} // Acquire
+		wg.Add(1)
+
+		go func(item BulkActionFailure) {
+			defer wg.Done()
+			defer func() { <-sem }() // Release
+
+			payload := map[string]interface{}{
+				"completeDataSetRegistrations": []map[string]interface{}{
+					api.CompletionRegistration(req.DatasetID, item.Period, item.OrgUnit, "", req.Action == "complete", req.CompleteDate, req.StoredBy, time.Now()),
+				},
+			}
+
+			logItem := func(taskID, msg string) {
+				logMessage(taskID, fmt.Sprintf("%s:%s %s", item.OrgUnit, item.Period, msg))
+			}
+			err := retryWithBackoff(taskID, func() error {
+				return postRegistration(client, payload)
+			}, bulkMaxAttempts, logItem)
+			onResult(item, err)
+			time.Sleep(bulkRequestDelay)
+		}(item)
+	}
+
+	wg.Wait()
+}
+
+// postRegistration sends one registration payload. Client errors other than 408 and
+// 429 (e.g. a locked period) are permanent and not retried.
+func postRegistration(client *api.Client, payload map[string]interface{}) error {
+	resp, err := client.Post("/api/completeDataSetRegistrations", payload)
+	if err != nil {
+		return err
+	}
+	if resp.IsSuccess() {
+		return nil
+	}
+
+	err = fmt.Errorf("HTTP %d: %s", resp.StatusCode(), resp.String())
+	status := resp.StatusCode()
+	if status >= 400 && status < 500 && status != http.StatusRequestTimeout && status != http.StatusTooManyRequests {
+		return &permanentError{err: err}
+	}
+	return err
+}
+
 func (s *Service) fetchDatasetElements(client *api.Client, datasetID string) ([]string, error) {
 	resp, err := client.Get(fmt.Sprintf("/api/dataSets/%s.json", datasetID), map[string]string{
 		"fields": "dataSetElements[dataElement[id]]",
@@ -513,10 +1091,10 @@ func (s *Service) fetchDatasetElements(client *api.Client, datasetID string) ([]
 
 func (s *Service) updateProgress(taskID, status string, progress int, message string) {
 	s.assessmentMu.Lock()
-	defer s.assessmentMu.Unlock()
-
 	updated := false
+	statusChanged := false
 	if p, exists := s.assessmentStore[taskID]; exists {
+		statusChanged = p.Status != status
 		p.Status = status
 		p.Progress = progress
 		if message != "" {
@@ -524,10 +1102,27 @@ func (s *Service) updateProgress(taskID, status string, progress int, message st
 		}
 		updated = true
 	}
+	s.assessmentMu.Unlock()
 
 	if updated {
 		go s.emitAssessmentEvent(taskID)
 	}
+	if statusChanged && (status == "completed" || status == "error") {
+		s.saveAssessment(taskID)
+	}
+	if statusChanged {
+		notifications.TaskFinished(taskID, "completeness", status, message)
+	}
+}
+
+// appendBulkMessage adds a message to a bulk action's log without changing its status
+func (s *Service) appendBulkMessage(taskID, message string) {
+	s.bulkActionMu.Lock()
+	defer s.bulkActionMu.Unlock()
+
+	if p, exists := s.bulkActionStore[taskID]; exists {
+		p.Messages = append(p.Messages, message)
+	}
 }
 
 func (s *Service) appendMessage(taskID, message string) {
@@ -572,18 +1167,23 @@ func (s *Service) emitAssessmentEvent(taskID string) {
 		payload["completed_at"] = progress.CompletedAt
 	}
 
-	runtime.EventsEmit(s.ctx, fmt.Sprintf("assessment:%s", taskID), payload)
+	events.EmitProgress(s.ctx, fmt.Sprintf("assessment:%s", taskID), "completeness", payload)
 }
 
 func (s *Service) updateBulkProgress(taskID, status string, progress int, message string) {
 	s.bulkActionMu.Lock()
-	defer s.bulkActionMu.Unlock()
-
-	if p, exists := s.bulkActionStore[taskID]; exists {
+	statusChanged := false
+	if p, ok := s.bulkActionStore[taskID]; ok {
+		statusChanged = p.Status != status
 		p.Status = status
 		p.Progress = progress
 		if message != "" {
 			p.Messages = append(p.Messages, message)
 		}
 	}
+	s.bulkActionMu.Unlock()
+
+	if statusChanged {
+		notifications.TaskFinished(taskID, "bulk_completeness", status, message)
+	}
 }
@@ -0,0 +1,323 @@
+package completeness
+
+import (
+	"testing"
+
+	"dhis2sync-desktop/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeCompliance(t *testing.T) {
+	required := []string{"deCore", "deOpt1", "deOpt2", "deOpt3"}
+
+	t.Run("Should weight all elements equally by default", func(t *testing.T) {
+		data := map[string]bool{"deCore": true, "deOpt1": true}
+
+		info := computeCompliance(data, required, nil)
+
+		assert.Equal(t, 2, info.ElementsPresent)
+		assert.Equal(t, 4, info.ElementsRequired)
+		assert.Equal(t, 2.0, info.WeightedPresent)
+		assert.Equal(t, 4.0, info.WeightedRequired)
+		assert.InDelta(t, 50.0, info.CompliancePercentage, 0.001)
+		assert.Equal(t, []string{"deOpt2", "deOpt3"}, info.MissingElements)
+	})
+
+	t.Run("Should let a heavily weighted element tip compliance", func(t *testing.T) {
+		weights := map[string]float64{"deCore": 6}
+		threshold := 70.0
+
+		// Only the core element reported: 1/4 unweighted, 6/9 weighted
+		coreOnly := computeCompliance(map[string]bool{"deCore": true}, required, weights)
+		assert.Equal(t, 1, coreOnly.ElementsPresent)
+		assert.InDelta(t, 66.67, coreOnly.CompliancePercentage, 0.01)
+		assert.Less(t, coreOnly.CompliancePercentage, threshold)
+
+		// Core plus one optional: 2/4 unweighted (50%), 7/9 weighted (77.8%)
+		coreAndOne := computeCompliance(map[string]bool{"deCore": true, "deOpt1": true}, required, weights)
+		assert.Equal(t, 2, coreAndOne.ElementsPresent)
+		assert.InDelta(t, 77.78, coreAndOne.CompliancePercentage, 0.01)
+		assert.GreaterOrEqual(t, coreAndOne.CompliancePercentage, threshold,
+			"Weighted core element should make the unit compliant")
+
+		// All optionals but no core: 3/4 unweighted (75%), 3/9 weighted (33.3%)
+		noCore := computeCompliance(map[string]bool{"deOpt1": true, "deOpt2": true, "deOpt3": true}, required, weights)
+		assert.Equal(t, 3, noCore.ElementsPresent)
+		assert.InDelta(t, 33.33, noCore.CompliancePercentage, 0.01)
+		assert.Less(t, noCore.CompliancePercentage, threshold,
+			"Missing the weighted core element should make the unit non-compliant")
+	})
+
+	t.Run("Should ignore weights for elements that are not required", func(t *testing.T) {
+		weights := map[string]float64{"deOther": 100}
+
+		info := computeCompliance(map[string]bool{"deCore": true, "deOther": true}, required, weights)
+
+		assert.Equal(t, 4.0, info.WeightedRequired)
+		assert.InDelta(t, 25.0, info.CompliancePercentage, 0.001)
+		assert.Equal(t, 2, info.TotalEntries)
+	})
+
+	t.Run("Should report zero compliance for a unit with no data", func(t *testing.T) {
+		info := computeCompliance(nil, required, nil)
+
+		assert.False(t, info.HasData)
+		assert.Equal(t, 0, info.ElementsPresent)
+		assert.Equal(t, 0.0, info.CompliancePercentage)
+	})
+}
+
+func TestCompareDetails(t *testing.T) {
+	required := []string{"deA", "deB", "deC", "deD"}
+
+	t.Run("Should flag org units less complete on destination", func(t *testing.T) {
+		source := map[string]*OrgUnitComplianceInfo{
+			"ou1": {ID: "ou1", Name: "Clinic A", CompliancePercentage: 100, ElementsPresent: 4, MissingElements: []string{}},
+			"ou2": {ID: "ou2", Name: "Clinic B", CompliancePercentage: 50, ElementsPresent: 2, MissingElements: []string{"deC", "deD"}},
+		}
+		dest := map[string]*OrgUnitComplianceInfo{
+			"ou1": {ID: "ou1", Name: "Clinic A", CompliancePercentage: 75, ElementsPresent: 3, MissingElements: []string{"deB"}},
+			"ou2": {ID: "ou2", Name: "Clinic B", CompliancePercentage: 50, ElementsPresent: 2, MissingElements: []string{"deA", "deB"}},
+		}
+
+		out := compareDetails("202401", required, source, dest)
+
+		assert.Len(t, out, 2)
+		assert.True(t, out["ou1:202401"].Gap)
+		assert.InDelta(t, -25.0, out["ou1:202401"].Delta, 0.001)
+		assert.Equal(t, 4, out["ou1:202401"].SourcePresent)
+		assert.Equal(t, 3, out["ou1:202401"].DestPresent)
+		assert.Equal(t, []string{"deB"}, out["ou1:202401"].MissingInDest)
+		assert.False(t, out["ou2:202401"].Gap)
+		assert.Equal(t, []string{"deA", "deB"}, out["ou2:202401"].MissingInDest,
+			"Same compliance, but different elements reported")
+	})
+
+	t.Run("Should treat org units missing on destination as gaps", func(t *testing.T) {
+		source := map[string]*OrgUnitComplianceInfo{
+			"ou1": {ID: "ou1", Name: "Clinic A", CompliancePercentage: 75, MissingElements: []string{"deD"}},
+		}
+
+		out := compareDetails("202401", required, source, map[string]*OrgUnitComplianceInfo{})
+
+		assert.True(t, out["ou1:202401"].Gap)
+		assert.Equal(t, "Clinic A", out["ou1:202401"].Name)
+		assert.Equal(t, 0.0, out["ou1:202401"].DestCompliance)
+		assert.Equal(t, []string{"deA", "deB", "deC"}, out["ou1:202401"].MissingInDest)
+	})
+}
+
+func TestPresenceRules(t *testing.T) {
+	required := []string{"deA", "deB", "deC", "deD"}
+	dataValues := []interface{}{
+		// Facility reporting all zeros
+		map[string]interface{}{"orgUnit": "ouZero", "dataElement": "deA", "value": "0"},
+		map[string]interface{}{"orgUnit": "ouZero", "dataElement": "deB", "value": "0"},
+		map[string]interface{}{"orgUnit": "ouZero", "dataElement": "deC", "value": "0.0"},
+		map[string]interface{}{"orgUnit": "ouZero", "dataElement": "deD", "value": "0"},
+		// Facility with mixed values
+		map[string]interface{}{"orgUnit": "ouMixed", "dataElement": "deA", "value": "12"},
+		map[string]interface{}{"orgUnit": "ouMixed", "dataElement": "deB", "value": "0"},
+		map[string]interface{}{"orgUnit": "ouMixed", "dataElement": "deC", "value": "NA"},
+		map[string]interface{}{"orgUnit": "ouMixed", "dataElement": "deD", "value": ""},
+	}
+
+	score := func(t *testing.T, rule, pattern, ouID string) float64 {
+		t.Helper()
+		present, err := newPresenceCheck(rule, pattern)
+		require.NoError(t, err)
+		orgUnitData := buildOrgUnitData(dataValues, present)
+		return computeCompliance(orgUnitData[ouID], required, nil).CompliancePercentage
+	}
+
+	t.Run("Should count zeros as reported by default", func(t *testing.T) {
+		assert.InDelta(t, 100.0, score(t, "", "", "ouZero"), 0.001)
+		assert.InDelta(t, 75.0, score(t, PresenceNonEmpty, "", "ouMixed"), 0.001)
+	})
+
+	t.Run("Should treat zeros as absent under non_zero", func(t *testing.T) {
+		assert.InDelta(t, 0.0, score(t, PresenceNonZero, "", "ouZero"), 0.001)
+		assert.InDelta(t, 50.0, score(t, PresenceNonZero, "", "ouMixed"), 0.001,
+			"Non-numeric text still counts as reported")
+	})
+
+	t.Run("Should only count values matching the pattern", func(t *testing.T) {
+		pattern := `^[1-9][0-9]*$`
+		assert.InDelta(t, 0.0, score(t, PresencePattern, pattern, "ouZero"), 0.001)
+		assert.InDelta(t, 25.0, score(t, PresencePattern, pattern, "ouMixed"), 0.001)
+	})
+
+	t.Run("Should reject invalid rule configuration", func(t *testing.T) {
+		_, err := newPresenceCheck(PresencePattern, "")
+		assert.Error(t, err)
+
+		_, err = newPresenceCheck(PresencePattern, "([unclosed")
+		assert.Error(t, err)
+
+		_, err = newPresenceCheck("sometimes", "")
+		assert.Error(t, err)
+	})
+}
+
+func TestFailedBulkItems(t *testing.T) {
+	t.Run("Should use structured failures when recorded", func(t *testing.T) {
+		results := &BulkActionResult{
+			Failed:      []string{"ou1:202401 - timeout"},
+			FailedItems: []BulkActionFailure{{OrgUnit: "ou1", Period: "202401", Error: "timeout"}},
+		}
+
+		items := failedBulkItems(results)
+
+		assert.Equal(t, []BulkActionFailure{{OrgUnit: "ou1", Period: "202401", Error: "timeout"}}, items)
+	})
+
+	t.Run("Should reconstruct failures from stored failure strings", func(t *testing.T) {
+		results := &BulkActionResult{
+			Failed: []string{
+				"ou1:202401 - Post \"http://x/api/completeDataSetRegistrations\": EOF",
+				"ou2:2024W05 - HTTP 409: period locked",
+				"garbage",
+			},
+		}
+
+		items := failedBulkItems(results)
+
+		require.Len(t, items, 2)
+		assert.Equal(t, "ou1", items[0].OrgUnit)
+		assert.Equal(t, "202401", items[0].Period)
+		assert.Contains(t, items[0].Error, "EOF")
+		assert.Equal(t, BulkActionFailure{OrgUnit: "ou2", Period: "2024W05", Error: "HTTP 409: period locked"}, items[1])
+	})
+
+	t.Run("Should merge retry outcomes into the original results", func(t *testing.T) {
+		original := &BulkActionResult{
+			Successful: []string{"ou0:202401"},
+			Failed:     []string{"ou1:202401 - timeout", "ou2:202401 - timeout"},
+		}
+		retry := &BulkActionResult{
+			Successful:  []string{"ou1:202401"},
+			Failed:      []string{"ou2:202401 - HTTP 409: locked"},
+			FailedItems: []BulkActionFailure{{OrgUnit: "ou2", Period: "202401", Error: "HTTP 409: locked"}},
+		}
+
+		mergeRetryResults(original, retry)
+
+		assert.Equal(t, []string{"ou0:202401", "ou1:202401"}, original.Successful)
+		assert.Equal(t, []string{"ou2:202401 - HTTP 409: locked"}, original.Failed)
+		require.Len(t, original.FailedItems, 1)
+		assert.Equal(t, "ou2", original.FailedItems[0].OrgUnit)
+	})
+}
+
+func TestCriticalElements(t *testing.T) {
+	required := []string{"deCore", "deOpt1", "deOpt2", "deOpt3"}
+	data := map[string]bool{"deOpt1": true, "deOpt2": true, "deOpt3": true}
+
+	t.Run("Should make a unit missing a critical element non-compliant", func(t *testing.T) {
+		info := computeCompliance(data, required, nil)
+		info.MissingCritical = missingCriticalElements(data, []string{"deCore"})
+
+		assert.InDelta(t, 75.0, info.CompliancePercentage, 0.001)
+		assert.Equal(t, []string{"deCore"}, info.MissingCritical)
+		assert.False(t, isCompliant(info, 70))
+	})
+
+	t.Run("Should fall back to the threshold without critical elements", func(t *testing.T) {
+		info := computeCompliance(data, required, nil)
+		info.MissingCritical = missingCriticalElements(data, nil)
+
+		assert.Empty(t, info.MissingCritical)
+		assert.True(t, isCompliant(info, 70))
+		assert.False(t, isCompliant(info, 80))
+	})
+
+	t.Run("Should keep units missing critical elements out of the compliant set", func(t *testing.T) {
+		s := &Service{assessmentStore: map[string]*AssessmentProgress{
+			"task": {periodCompliance: map[string]map[string]float64{}},
+		}}
+
+		s.recordPeriodCompliance("task", "202401", &AssessmentResult{ComplianceDetails: map[string]*OrgUnitComplianceInfo{
+			"ouA": {CompliancePercentage: 100},
+			"ouB": {CompliancePercentage: 95, MissingCritical: []string{"deCore"}},
+		}})
+
+		assert.Equal(t, map[string]float64{"ouA": 100}, s.assessmentStore["task"].periodCompliance["202401"])
+	})
+}
+
+func TestCompliantOrgUnits(t *testing.T) {
+	periodCompliance := map[string]map[string]float64{
+		"202401": {"ouA": 100, "ouB": 90, "ouC": 40, "ouD": 85},
+		"202402": {"ouA": 95, "ouB": 70, "ouC": 100},
+	}
+
+	t.Run("Should require the threshold in every period", func(t *testing.T) {
+		got := compliantInAllPeriods(periodCompliance, []string{"202401", "202402"}, 80)
+
+		assert.Equal(t, []string{"ouA"}, got, "ouB dips in 202402, ouC in 202401 and ouD has no 202402 result")
+	})
+
+	t.Run("Should use the assessment threshold by default and reject unfinished tasks", func(t *testing.T) {
+		s := &Service{assessmentStore: map[string]*AssessmentProgress{
+			"done": {
+				Status:           "completed",
+				request:          &AssessmentRequest{Instance: "source", Periods: []string{"202401", "202402"}, ComplianceThreshold: 70},
+				periodCompliance: periodCompliance,
+			},
+			"running": {Status: "running", request: &AssessmentRequest{Instance: "source"}},
+			"dest": {
+				Status:  "completed",
+				request: &AssessmentRequest{Instance: "dest"},
+			},
+		}}
+
+		got, req, err := s.CompliantOrgUnits("done", 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ouA", "ouB"}, got)
+		assert.Equal(t, []string{"202401", "202402"}, req.Periods)
+
+		got, _, err = s.CompliantOrgUnits("done", 95)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ouA"}, got)
+
+		_, _, err = s.CompliantOrgUnits("running", 0)
+		assert.Error(t, err)
+		_, _, err = s.CompliantOrgUnits("dest", 0)
+		assert.Error(t, err)
+		_, _, err = s.CompliantOrgUnits("missing", 0)
+		assert.Error(t, err)
+	})
+}
+
+func TestAssessedOrgUnits(t *testing.T) {
+	orgUnits := []models.OrganisationUnit{
+		{ID: "ouParent"}, {ID: "ouClinicA"}, {ID: "ouClinicB"}, {ID: "ouOffice"},
+	}
+	ids := func(units []models.OrganisationUnit) []string {
+		out := []string{}
+		for _, ou := range units {
+			out = append(out, ou.ID)
+		}
+		return out
+	}
+
+	t.Run("Should only assess assigned org units", func(t *testing.T) {
+		assigned := map[string]bool{"ouClinicA": true, "ouClinicB": true}
+
+		assert.Equal(t, []string{"ouClinicA", "ouClinicB"}, ids(assessedOrgUnits(orgUnits, "ouParent", true, assigned)))
+	})
+
+	t.Run("Should assess the whole subtree without assignments", func(t *testing.T) {
+		assert.Equal(t, []string{"ouClinicA", "ouClinicB", "ouOffice"}, ids(assessedOrgUnits(orgUnits, "ouParent", false, nil)))
+		assert.Len(t, assessedOrgUnits(orgUnits, "ouParent", true, nil), 4)
+	})
+
+	t.Run("Should default to assigned org units only", func(t *testing.T) {
+		off := false
+
+		assert.True(t, (&AssessmentRequest{}).onlyAssignedOrgUnits())
+		assert.False(t, (&AssessmentRequest{OnlyAssignedOrgUnits: &off}).onlyAssignedOrgUnits())
+	})
+}
@@ -10,6 +10,27 @@ type AssessmentRequest struct {
 	RequiredElements    []string `json:"required_elements"`    // If empty, uses all dataset elements
 	ComplianceThreshold int      `json:"compliance_threshold"` // 0-100 percentage
 	IncludeParents      bool     `json:"include_parents"`      // Include parent OUs in assessment
+	// ElementWeights optionally weights required elements (dataElementID -> weight).
+	// Elements without a weight count as 1.
+	ElementWeights map[string]float64 `json:"element_weights,omitempty"`
+	// CriticalElements must all be reported for an org unit to be compliant, whatever
+	// its overall percentage
+	CriticalElements []string `json:"critical_elements,omitempty"`
+	// PresenceRule decides when a value counts as reported: "non_empty" (default),
+	// "non_zero" (treat "0" as not reported) or "pattern" (value matches PresencePattern).
+	PresenceRule    string `json:"presence_rule,omitempty"`
+	PresencePattern string `json:"presence_pattern,omitempty"`
+	// ReportingRateBasis decides what counts as a report in the reporting rate: "data"
+	// (default, any value per the presence rule) or "complete" (dataset marked complete)
+	ReportingRateBasis string `json:"reporting_rate_basis,omitempty"`
+	// OnlyAssignedOrgUnits limits the assessment to org units the dataset is assigned to
+	// (default true); false assesses every org unit in the parents' subtrees
+	OnlyAssignedOrgUnits *bool `json:"only_assigned_org_units,omitempty"`
+}
+
+// onlyAssignedOrgUnits resolves OnlyAssignedOrgUnits' default
+func (r *AssessmentRequest) onlyAssignedOrgUnits() bool {
+	return r.OnlyAssignedOrgUnits == nil || *r.OnlyAssignedOrgUnits
 }
 
 // AssessmentProgress tracks the progress of a completeness assessment task
@@ -20,7 +41,11 @@ type AssessmentProgress struct {
 	Progress    int               `json:"progress"` // 0-100
 	Messages    []string          `json:"messages"`
 	Results     *AssessmentResult `json:"results,omitempty"`
+	Comparison  *ComparisonResult `json:"comparison,omitempty"`   // Set for source vs destination comparisons
 	CompletedAt int64             `json:"completed_at,omitempty"` // Unix timestamp
+
+	request          *AssessmentRequest            // What was assessed, for building follow-up transfers
+	periodCompliance map[string]map[string]float64 // period -> orgUnitID -> compliance percentage
 }
 
 // AssessmentResult contains the overall assessment results
@@ -30,6 +55,22 @@ type AssessmentResult struct {
 	TotalErrors       int                               `json:"total_errors"`
 	Hierarchy         map[string]*HierarchyResult       `json:"hierarchy"`          // parentOrgUnitID -> results
 	ComplianceDetails map[string]*OrgUnitComplianceInfo `json:"compliance_details"` // orgUnitID -> compliance info
+	ReportingRate     *ReportingRate                    `json:"reporting_rate,omitempty"`
+}
+
+// ReportingRate is DHIS2's classic reporting rate over the assessed subtrees: one report
+// is expected per org unit assigned to the dataset per period
+type ReportingRate struct {
+	Basis string `json:"basis"` // "data" or "complete"
+	ReportingCount
+	ByPeriod map[string]*ReportingCount `json:"by_period"`
+}
+
+// ReportingCount is the expected and actual reports behind a reporting rate
+type ReportingCount struct {
+	Expected int     `json:"expected"`
+	Actual   int     `json:"actual"`
+	Rate     float64 `json:"rate"` // Actual / Expected percentage
 }
 
 // HierarchyResult contains compliance results for a parent org unit hierarchy
@@ -49,11 +90,37 @@ type OrgUnitComplianceInfo struct {
 	CompliancePercentage float64  `json:"compliance_percentage"`
 	ElementsPresent      int      `json:"elements_present"`
 	ElementsRequired     int      `json:"elements_required"`
+	WeightedPresent      float64  `json:"weighted_present"`
+	WeightedRequired     float64  `json:"weighted_required"`
 	MissingElements      []string `json:"missing_elements"`
+	MissingCritical      []string `json:"missing_critical,omitempty"` // Critical elements not reported; non-compliant if any
 	HasData              bool     `json:"has_data"`
 	TotalEntries         int      `json:"total_entries"` // Total data elements with values
 }
 
+// ComparisonResult contains side-by-side source and destination assessments
+type ComparisonResult struct {
+	Source    *AssessmentResult             `json:"source"`
+	Dest      *AssessmentResult             `json:"dest"`
+	OrgUnits  map[string]*OrgUnitComparison `json:"org_units"`  // "orgUnitID:period" -> comparison
+	TotalGaps int                           `json:"total_gaps"` // Org units where dest is less complete than source
+}
+
+// OrgUnitComparison compares an org unit's compliance on source and destination for one period
+type OrgUnitComparison struct {
+	ID               string  `json:"id"`
+	Name             string  `json:"name"`
+	Period           string  `json:"period"`
+	SourceCompliance float64 `json:"source_compliance"`
+	DestCompliance   float64 `json:"dest_compliance"`
+	SourcePresent    int     `json:"source_present"`
+	DestPresent      int     `json:"dest_present"`
+	Delta            float64 `json:"delta"` // dest - source percentage points
+	Gap              bool    `json:"gap"`   // Dest is less complete than source
+	// MissingInDest lists required elements reported on source but not on destination
+	MissingInDest []string `json:"missing_in_dest"`
+}
+
 // ExportRequest represents a request to export assessment results
 type ExportRequest struct {
 	TaskID string `json:"task_id"`
@@ -69,6 +136,12 @@ type BulkActionRequest struct {
 	OrgUnits  []string `json:"org_units"`
 	DatasetID string   `json:"dataset_id"`
 	Periods   []string `json:"periods"`
+	// CompleteDate (YYYY-MM-DD) and StoredBy are recorded on "complete" registrations,
+	// e.g. for historical backfills. Default to today and the tool name.
+	CompleteDate string `json:"complete_date,omitempty"`
+	StoredBy     string `json:"stored_by,omitempty"`
+	// Concurrency bounds parallel registration POSTs (default 4, max 10)
+	Concurrency int `json:"concurrency,omitempty"`
 }
 
 // BulkActionProgress tracks bulk action progress
@@ -79,6 +152,9 @@ type BulkActionProgress struct {
 	Messages    []string          `json:"messages"`
 	Results     *BulkActionResult `json:"results,omitempty"`
 	CompletedAt int64             `json:"completed_at,omitempty"`
+	RetryOf     string            `json:"retry_of,omitempty"` // Task whose failures this task re-attempts
+
+	request BulkActionRequest // Original request, kept for RetryFailedBulkAction
 }
 
 // BulkActionResult contains results of bulk complete/incomplete action
@@ -87,4 +163,14 @@ type BulkActionResult struct {
 	TotalProcessed int      `json:"total_processed"`
 	Successful     []string `json:"successful"` // "orgUnitID:period" format
 	Failed         []string `json:"failed"`     // "orgUnitID:period - error" format
+
+	// FailedItems holds the same failures structurally so they can be retried
+	FailedItems []BulkActionFailure `json:"failed_items,omitempty"`
+}
+
+// BulkActionFailure is a single registration that failed during a bulk action
+type BulkActionFailure struct {
+	OrgUnit string `json:"org_unit"`
+	Period  string `json:"period"`
+	Error   string `json:"error"`
 }
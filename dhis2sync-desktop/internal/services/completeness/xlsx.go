@@ -0,0 +1,174 @@
+package completeness
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// xlsxSheet is a worksheet for writeXLSX. Cells are strings, ints or float64s;
+// numbers are stored as numbers so spreadsheets can sort and sum them.
+type xlsxSheet struct {
+	Name string
+	Rows [][]interface{}
+}
+
+const (
+	xlsxMainNS = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	xlsxRelNS  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	xlsxPkgNS  = "http://schemas.openxmlformats.org/package/2006/relationships"
+	xmlHeader  = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+)
+
+// writeXLSX builds a minimal Office Open XML workbook with inline strings, which
+// Excel, LibreOffice and Google Sheets all open
+func writeXLSX(sheets []xlsxSheet) ([]byte, error) {
+	var contentTypes, workbook, workbookRels strings.Builder
+
+	contentTypes.WriteString(xmlHeader + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	workbook.WriteString(xmlHeader + `<workbook xmlns="` + xlsxMainNS + `" xmlns:r="` + xlsxRelNS + `"><sheets>`)
+	workbookRels.WriteString(xmlHeader + `<Relationships xmlns="` + xlsxPkgNS + `">`)
+
+	files := map[string]string{}
+	order := []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"}
+
+	for i, sheet := range sheets {
+		n := i + 1
+		path := fmt.Sprintf("xl/worksheets/sheet%d.xml", n)
+		fmt.Fprintf(&contentTypes, `<Override PartName="/%s" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, path)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(sheetName(sheet.Name)), n, n)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="%s/worksheet" Target="worksheets/sheet%d.xml"/>`, n, xlsxRelNS, n)
+
+		files[path] = worksheetXML(sheet.Rows)
+		order = append(order, path)
+	}
+
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	workbookRels.WriteString(`</Relationships>`)
+
+	files["[Content_Types].xml"] = contentTypes.String()
+	files["_rels/.rels"] = xmlHeader + `<Relationships xmlns="` + xlsxPkgNS + `">` +
+		`<Relationship Id="rId1" Type="` + xlsxRelNS + `/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	files["xl/workbook.xml"] = workbook.String()
+	files["xl/_rels/workbook.xml.rels"] = workbookRels.String()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range order {
+		w, err := zw.Create(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if _, err := w.Write([]byte(files[name])); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish workbook: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// worksheetXML renders rows as a worksheet
+func worksheetXML(rows [][]interface{}) string {
+	var b strings.Builder
+	b.WriteString(xmlHeader + `<worksheet xmlns="` + xlsxMainNS + `"><sheetData>`)
+
+	for r, row := range rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			ref := columnName(c) + strconv.Itoa(r+1)
+			switch v := cell.(type) {
+			case int:
+				fmt.Fprintf(&b, `<c r="%s"><v>%d</v></c>`, ref, v)
+			case float64:
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(v, 'f', -1, 64))
+			default:
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlEscape(fmt.Sprint(v)))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// columnName turns a zero-based column index into its letters (0 -> A, 26 -> AA)
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// sheetName trims a sheet name to Excel's rules: at most 31 characters, none of []:*?/\
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	return name
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// resultsWorkbook builds a Summary sheet (totals, threshold, periods) and a Details
+// sheet with one row per org unit, sorted by ID; limit caps the detail rows when > 0
+func resultsWorkbook(results *AssessmentResult, req *AssessmentRequest, limit int) ([]byte, error) {
+	summary := [][]interface{}{
+		{"Metric", "Value"},
+		{"Compliant", results.TotalCompliant},
+		{"Non-compliant", results.TotalNonCompliant},
+		{"Errors", results.TotalErrors},
+	}
+	if req != nil {
+		summary = append(summary,
+			[]interface{}{"Compliance threshold (%)", req.ComplianceThreshold},
+			[]interface{}{"Periods assessed", strings.Join(req.Periods, ", ")},
+		)
+	}
+
+	ouIDs := make([]string, 0, len(results.ComplianceDetails))
+	for ouID := range results.ComplianceDetails {
+		ouIDs = append(ouIDs, ouID)
+	}
+	sort.Strings(ouIDs)
+	if limit > 0 && len(ouIDs) > limit {
+		ouIDs = ouIDs[:limit]
+	}
+
+	details := [][]interface{}{{"Org Unit ID", "Org Unit Name", "Compliance %", "Elements Present", "Elements Required", "Missing Elements"}}
+	for _, ouID := range ouIDs {
+		info := results.ComplianceDetails[ouID]
+		details = append(details, []interface{}{
+			ouID, info.Name, info.CompliancePercentage, info.ElementsPresent, info.ElementsRequired,
+			strings.Join(info.MissingElements, ";"),
+		})
+	}
+
+	sheets := []xlsxSheet{{Name: "Summary", Rows: summary}, {Name: "Details", Rows: details}}
+	if results.ReportingRate != nil {
+		sheets = append(sheets, xlsxSheet{Name: "Reporting Rate", Rows: reportingRateTable(results.ReportingRate)})
+	}
+	return writeXLSX(sheets)
+}
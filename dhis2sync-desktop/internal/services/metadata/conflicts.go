@@ -0,0 +1,39 @@
+package metadata
+
+import "strings"
+
+// StatusConflicts is the ImportReport status for an import DHIS2 rejected with
+// HTTP 409; the UI should show Conflicts for review before retrying
+const StatusConflicts = "conflicts"
+
+// markConflicts relabels a 409 report as StatusConflicts and flattens its object
+// error reports into Conflicts. A 409 without object reports (older DHIS2 versions
+// send only a message) yields a single conflict carrying that message.
+func markConflicts(report *ImportReport) {
+	report.Status = StatusConflicts
+	report.Conflicts = flattenConflicts(report)
+	if len(report.Conflicts) == 0 && report.Message != "" {
+		report.Conflicts = []ImportConflict{{Message: report.Message}}
+	}
+	report.Message = "Conflicts — review required"
+}
+
+// flattenConflicts lists every object error in a report, tagged with its type and UID
+func flattenConflicts(report *ImportReport) []ImportConflict {
+	conflicts := []ImportConflict{}
+	for _, tr := range report.TypeReports {
+		klass := tr.Type[strings.LastIndex(tr.Type, ".")+1:]
+		for _, obj := range tr.Objects {
+			for _, e := range obj.ErrorReports {
+				conflicts = append(conflicts, ImportConflict{
+					Type:      klass,
+					UID:       obj.UID,
+					Property:  e.ErrorProperty,
+					ErrorCode: e.ErrorCode,
+					Message:   e.Message,
+				})
+			}
+		}
+	}
+	return conflicts
+}
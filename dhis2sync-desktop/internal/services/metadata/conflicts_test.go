@@ -0,0 +1,111 @@
+package metadata
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"dhis2sync-desktop/internal/api/apitest"
+	"dhis2sync-desktop/internal/models"
+)
+
+// sample409 is a DHIS2 2.40 response to an atomic import with a missing reference
+const sample409 = `{
+  "httpStatus": "Conflict",
+  "httpStatusCode": 409,
+  "status": "ERROR",
+  "message": "One or more errors occurred, please see full details in import report.",
+  "response": {
+    "responseType": "ImportReport",
+    "status": "ERROR",
+    "stats": {"created": 0, "updated": 0, "deleted": 0, "ignored": 2, "total": 2},
+    "typeReports": [{
+      "klass": "org.hisp.dhis.dataelement.DataElement",
+      "stats": {"created": 0, "updated": 0, "deleted": 0, "ignored": 2, "total": 2},
+      "objectReports": [{
+        "klass": "org.hisp.dhis.dataelement.DataElement",
+        "index": 0,
+        "uid": "deAAAAAAAA1",
+        "errorReports": [{
+          "message": "Invalid reference [ccMissing01] (CategoryCombo) on object ANC 1st visit [deAAAAAAAA1] (DataElement) for association ` + "`categoryCombo`" + `",
+          "mainKlass": "org.hisp.dhis.dataelement.DataElement",
+          "errorKlass": "org.hisp.dhis.category.CategoryCombo",
+          "errorProperty": "categoryCombo",
+          "errorCode": "E5002"
+        }]
+      }]
+    }]
+  }
+}`
+
+func TestApplyConflicts(t *testing.T) {
+	newService := func(t *testing.T) *Service {
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		require.NoError(t, err)
+		require.NoError(t, db.AutoMigrate(&models.MetadataImportOutcome{}))
+		return &Service{db: db}
+	}
+	payload := map[MetadataType][]map[string]interface{}{
+		TypeDataElements: {{"id": "deAAAAAAAA1", "name": "ANC 1st visit"}, {"id": "deBBBBBBBB2", "name": "ANC 2nd visit"}},
+	}
+
+	t.Run("Should flatten a 409 into conflicts for review", func(t *testing.T) {
+		s := newService(t)
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/metadata": apitest.Raw(http.StatusConflict, sample409),
+		})
+
+		report := s.applyResumable(srv.Client(), "profile-1", "apply-1", payload, "CREATE_AND_UPDATE", "ALL")
+
+		assert.Equal(t, StatusConflicts, report.Status)
+		assert.Equal(t, "Conflicts — review required", report.Message)
+		assert.Empty(t, report.Error)
+		require.Len(t, report.Conflicts, 1)
+		conflict := report.Conflicts[0]
+		assert.Equal(t, "DataElement", conflict.Type)
+		assert.Equal(t, "deAAAAAAAA1", conflict.UID)
+		assert.Equal(t, "categoryCombo", conflict.Property)
+		assert.Equal(t, "E5002", conflict.ErrorCode)
+		assert.Contains(t, conflict.Message, "ccMissing01")
+
+		done, err := s.loadImported("apply-1")
+		require.NoError(t, err)
+		assert.Empty(t, done, "An atomic import that conflicts imports nothing")
+	})
+
+	t.Run("Should keep a bare 409 message as the conflict", func(t *testing.T) {
+		s := newService(t)
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/metadata": apitest.Raw(http.StatusConflict, `{"httpStatus":"Conflict","status":"ERROR","message":"Object references are invalid"}`),
+		})
+
+		report := s.applyResumable(srv.Client(), "profile-1", "apply-1", payload, "CREATE_AND_UPDATE", "ALL")
+
+		assert.Equal(t, StatusConflicts, report.Status)
+		require.Len(t, report.Conflicts, 1)
+		assert.Equal(t, "Object references are invalid", report.Conflicts[0].Message)
+	})
+
+	t.Run("Should report a server error distinctly", func(t *testing.T) {
+		s := newService(t)
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/metadata": apitest.Raw(http.StatusInternalServerError, `{"httpStatus":"Internal Server Error","status":"ERROR","message":"NullPointerException"}`),
+		})
+		client := srv.Client()
+		client.SetRetryCount(0)
+
+		report := s.applyResumable(client, "profile-1", "apply-1", payload, "CREATE_AND_UPDATE", "NONE")
+
+		assert.Equal(t, "error", report.Status)
+		assert.Contains(t, report.Error, "HTTP 500")
+		assert.Empty(t, report.Conflicts)
+
+		done, err := s.loadImported("apply-1")
+		require.NoError(t, err)
+		assert.Empty(t, done)
+	})
+}
@@ -0,0 +1,244 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"dhis2sync-desktop/internal/api"
+)
+
+// importOrder lists metadata types so every type comes after the types it references.
+// DHIS2 resolves references within an atomic import only to objects it has already
+// seen, so a data element listed before its category combo fails the whole payload.
+var importOrder = []MetadataType{
+	TypeCategoryOptions,
+	TypeCategories,
+	TypeCategoryCombos,
+	TypeCategoryOptionCombos,
+	TypeOptionSets,
+	TypeOptions,
+	TypeDataElements,
+	TypeDataElementGroups,
+	TypeDataElementGroupSets,
+	TypeOrganisationUnits,
+	TypeDataSets,
+	TypeSections,
+	TypeIndicatorTypes,
+	TypeIndicators,
+	TypeOptionGroups,
+	TypeValidationRules,
+	TypeProgramIndicators,
+}
+
+// orderTypes returns types in importOrder, followed by any types it doesn't list in
+// their given order
+func orderTypes(types []MetadataType) []MetadataType {
+	rank := make(map[MetadataType]int, len(importOrder))
+	for i, t := range importOrder {
+		rank[t] = i
+	}
+
+	ordered := append([]MetadataType(nil), types...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		ri, iKnown := rank[ordered[i]]
+		rj, jKnown := rank[ordered[j]]
+		if iKnown && jKnown {
+			return ri < rj
+		}
+		return iKnown && !jKnown
+	})
+	return ordered
+}
+
+// orderedPayload marshals an import payload with its types in importOrder;
+// encoding/json would write the map keys alphabetically
+type orderedPayload map[MetadataType][]map[string]interface{}
+
+func (p orderedPayload) MarshalJSON() ([]byte, error) {
+	types := make([]MetadataType, 0, len(p))
+	for t := range p {
+		types = append(types, t)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, t := range orderTypes(types) {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(string(t))
+		if err != nil {
+			return nil, err
+		}
+		items, err := json.Marshal(p[t])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(items)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// itemReferences lists the objects a payload item references, by the UIDs it carries
+func itemReferences(objType MetadataType, item map[string]interface{}) []metadataObject {
+	var refs []metadataObject
+	add := func(t MetadataType, v interface{}) {
+		for _, uid := range refIDs(v) {
+			refs = append(refs, metadataObject{Type: t, UID: uid})
+		}
+	}
+	addOperands := func(v interface{}) {
+		for _, op := range refMaps(v) {
+			add(TypeDataElements, op["dataElement"])
+			add(TypeCategoryOptionCombos, op["categoryOptionCombo"])
+		}
+	}
+	addExpressions := func(program bool, exprs ...interface{}) {
+		for _, expr := range exprs {
+			if e, ok := expr.(string); ok {
+				refs = append(refs, expressionReferences(e, program)...)
+			}
+		}
+	}
+
+	switch objType {
+	case TypeCategories:
+		add(TypeCategoryOptions, item["categoryOptions"])
+	case TypeCategoryCombos:
+		add(TypeCategories, item["categories"])
+	case TypeCategoryOptionCombos:
+		add(TypeCategoryCombos, item["categoryCombo"])
+		add(TypeCategoryOptions, item["categoryOptions"])
+	case TypeDataElements:
+		add(TypeCategoryCombos, item["categoryCombo"])
+		add(TypeOptionSets, item["optionSet"])
+	case TypeDataElementGroups:
+		add(TypeDataElements, item["dataElements"])
+	case TypeDataElementGroupSets:
+		add(TypeDataElementGroups, item["dataElementGroups"])
+	case TypeDataSets:
+		add(TypeCategoryCombos, item["categoryCombo"])
+		for _, dse := range refMaps(item["dataSetElements"]) {
+			add(TypeDataElements, dse["dataElement"])
+			add(TypeCategoryCombos, dse["categoryCombo"])
+		}
+		addOperands(item["compulsoryDataElementOperands"])
+	case TypeSections:
+		add(TypeDataSets, item["dataSet"])
+		add(TypeDataElements, item["dataElements"])
+		addOperands(item["greyedFields"])
+	case TypeOrganisationUnits:
+		add(TypeOrganisationUnits, item["parent"])
+	case TypeIndicators:
+		add(TypeIndicatorTypes, item["indicatorType"])
+		addExpressions(false, item["numerator"], item["denominator"])
+	case TypeOptionGroups:
+		add(TypeOptionSets, item["optionSet"])
+	case TypeValidationRules:
+		for _, side := range refMaps([]interface{}{item["leftSide"], item["rightSide"]}) {
+			addExpressions(false, side["expression"])
+		}
+	case TypeProgramIndicators:
+		addExpressions(true, item["expression"], item["filter"])
+	}
+	return refs
+}
+
+// refMaps reads a reference or list of references, whether built here or decoded from JSON
+func refMaps(v interface{}) []map[string]interface{} {
+	switch ref := v.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{ref}
+	case []map[string]interface{}:
+		return ref
+	case []interface{}:
+		maps := make([]map[string]interface{}, 0, len(ref))
+		for _, r := range ref {
+			if m, ok := r.(map[string]interface{}); ok {
+				maps = append(maps, m)
+			}
+		}
+		return maps
+	}
+	return nil
+}
+
+// refIDs lists the ids of a reference or list of references
+func refIDs(v interface{}) []string {
+	var ids []string
+	for _, ref := range refMaps(v) {
+		if id := getStringOr(ref, "id", ""); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// addMissingDependencies brings into the payload the source objects its items reference
+// that are neither in the destination nor already in the payload, and in turn whatever
+// those reference. References are to destination UIDs after mapping, so a mapped
+// reference always resolves in the destination; an unmapped one is a source UID.
+func (s *Service) addMissingDependencies(payload map[MetadataType][]map[string]interface{}, sourceClient *api.Client, destHas func(t MetadataType, uid string) bool, mappings map[MetadataType]map[string]string, preserveSharing bool) {
+	inPayload := make(map[metadataObject]bool)
+	var pending []metadataObject
+	for t, items := range payload {
+		for _, item := range items {
+			inPayload[metadataObject{Type: t, UID: getStringOr(item, "id", "")}] = true
+			pending = append(pending, itemReferences(t, item)...)
+		}
+	}
+
+	for len(pending) > 0 {
+		ref := pending[0]
+		pending = pending[1:]
+		if inPayload[ref] || destHas(ref.Type, ref.UID) {
+			continue
+		}
+		inPayload[ref] = true
+
+		full := s.fetchFullItem(sourceClient, ref.Type, ref.UID)
+		// Every instance has its own default category objects; those must be mapped, as
+		// importing the source's would clash with the destination's by name
+		if full == nil || getStringOr(full, "name", "") == "default" {
+			continue
+		}
+		if minimal := s.buildPayloadItem(ref.Type, full, mappings, preserveSharing); minimal != nil {
+			payload[ref.Type] = append(payload[ref.Type], minimal)
+			pending = append(pending, itemReferences(ref.Type, minimal)...)
+		}
+	}
+}
+
+// sortOrgUnitsByLevel orders the payload's org units parent before child, by their
+// depth below the nearest ancestor outside the payload
+func sortOrgUnitsByLevel(payload map[MetadataType][]map[string]interface{}) {
+	orgUnits := payload[TypeOrganisationUnits]
+	if len(orgUnits) < 2 {
+		return
+	}
+
+	inPayload := make(map[string]bool, len(orgUnits))
+	parents := make(map[string]string, len(orgUnits))
+	for _, ou := range orgUnits {
+		id := getStringOr(ou, "id", "")
+		inPayload[id] = true
+		if ids := refIDs(ou["parent"]); len(ids) > 0 {
+			parents[id] = ids[0]
+		}
+	}
+
+	depths := make(map[string]int, len(orgUnits))
+	for id := range inPayload {
+		// Bounded by the payload size in case of a parent cycle
+		for parent := parents[id]; inPayload[parent] && depths[id] < len(orgUnits); parent = parents[parent] {
+			depths[id]++
+		}
+	}
+	sort.SliceStable(orgUnits, func(i, j int) bool {
+		return depths[getStringOr(orgUnits[i], "id", "")] < depths[getStringOr(orgUnits[j], "id", "")]
+	})
+}
@@ -0,0 +1,136 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"dhis2sync-desktop/internal/api/apitest"
+)
+
+func TestBuildPayloadDependencies(t *testing.T) {
+	s := &Service{}
+	list := func(objType MetadataType, items ...map[string]interface{}) http.HandlerFunc {
+		if items == nil {
+			items = []map[string]interface{}{}
+		}
+		return apitest.JSON(http.StatusOK, map[string]interface{}{string(objType): items})
+	}
+
+	source := apitest.NewServer(t, map[string]http.HandlerFunc{
+		"/api/dataElements.json": list(TypeDataElements, map[string]interface{}{
+			"id": "deMalaria01", "displayName": "Malaria cases", "categoryCombo": map[string]string{"id": "ccAgeSex001"},
+		}),
+		"/api/dataElements/deMalaria01.json": apitest.JSON(http.StatusOK, map[string]interface{}{
+			"id": "deMalaria01", "name": "Malaria cases", "shortName": "Malaria", "valueType": "INTEGER",
+			"categoryCombo": map[string]string{"id": "ccAgeSex001"},
+		}),
+		"/api/categoryCombos/ccAgeSex001.json": apitest.JSON(http.StatusOK, map[string]interface{}{
+			"id": "ccAgeSex001", "name": "Age and sex",
+			"categories": []map[string]string{{"id": "catAge0001"}, {"id": "catSex0001"}},
+		}),
+		"/api/categories/catAge0001.json": apitest.JSON(http.StatusOK, map[string]interface{}{
+			"id": "catAge0001", "name": "Age", "categoryOptions": []map[string]string{{"id": "coUnder5001"}},
+		}),
+		"/api/categoryOptions/coUnder5001.json": apitest.JSON(http.StatusOK, map[string]interface{}{
+			"id": "coUnder5001", "name": "Under 5",
+		}),
+	})
+	// The destination already has the sex category but not the combo or the age category
+	dest := apitest.NewServer(t, map[string]http.HandlerFunc{
+		"/api/dataElements.json":    list(TypeDataElements),
+		"/api/categoryCombos.json":  list(TypeCategoryCombos),
+		"/api/categories.json":      list(TypeCategories, map[string]interface{}{"id": "catSex0001", "displayName": "Sex"}),
+		"/api/categoryOptions.json": list(TypeCategoryOptions),
+	})
+
+	t.Run("Should bring in a missing category combo and what it references", func(t *testing.T) {
+		payload, _ := s.buildPayloadForTypes([]MetadataType{TypeDataElements}, source.Client(), dest.Client(), nil, false, nil)
+
+		require.Len(t, payload[TypeDataElements], 1)
+		assert.Equal(t, map[string]interface{}{"id": "ccAgeSex001"}, payload[TypeDataElements][0]["categoryCombo"])
+		require.Len(t, payload[TypeCategoryCombos], 1)
+		assert.Equal(t, "ccAgeSex001", payload[TypeCategoryCombos][0]["id"])
+		assert.Equal(t, []map[string]interface{}{{"id": "catAge0001"}, {"id": "catSex0001"}}, payload[TypeCategoryCombos][0]["categories"])
+		require.Len(t, payload[TypeCategories], 1, "the destination's sex category is referenced, not imported")
+		assert.Equal(t, "catAge0001", payload[TypeCategories][0]["id"])
+		require.Len(t, payload[TypeCategoryOptions], 1)
+		assert.Equal(t, "coUnder5001", payload[TypeCategoryOptions][0]["id"])
+	})
+
+	t.Run("Should serialize the combo before the data element that uses it", func(t *testing.T) {
+		payload, _ := s.buildPayloadForTypes([]MetadataType{TypeDataElements}, source.Client(), dest.Client(), nil, false, nil)
+
+		body, err := json.Marshal(orderedPayload(payload))
+		require.NoError(t, err)
+
+		text := string(body)
+		order := []string{`"categoryOptions"`, `"categories"`, `"categoryCombos"`, `"dataElements"`}
+		for i := 1; i < len(order); i++ {
+			assert.Less(t, strings.Index(text, order[i-1]), strings.Index(text, order[i]), "%s before %s", order[i-1], order[i])
+		}
+	})
+
+	t.Run("Should report progress fetching each type's missing objects", func(t *testing.T) {
+		var calls []string
+		s.buildPayloadForTypes([]MetadataType{TypeDataElements, TypeCategoryCombos}, source.Client(), dest.Client(), nil, false, func(t MetadataType, done, total int) {
+			calls = append(calls, fmt.Sprintf("%s %d/%d", t, done, total))
+		})
+
+		// The combo is missing from the destination but not listed in the source summary,
+		// so it's only brought in as a dependency
+		assert.Equal(t, []string{"categoryCombos 0/0", "dataElements 0/1", "dataElements 1/1"}, calls)
+	})
+
+	t.Run("Should leave out references the mappings resolve", func(t *testing.T) {
+		mappings := map[MetadataType]map[string]string{TypeCategoryCombos: {"ccAgeSex001": "ccDestAge01"}}
+		// Mapped onto an object the destination has, so nothing needs importing
+		destHas := func(t MetadataType, uid string) bool { return uid == "ccDestAge01" }
+
+		payload := map[MetadataType][]map[string]interface{}{
+			TypeDataElements: {s.buildMinimalItem(TypeDataElements, map[string]interface{}{
+				"id": "deMalaria01", "name": "Malaria cases", "categoryCombo": map[string]interface{}{"id": "ccAgeSex001"},
+			}, mappings)},
+		}
+		s.addMissingDependencies(payload, source.Client(), destHas, mappings, false)
+
+		assert.Len(t, payload, 1)
+	})
+}
+
+func TestOrderTypes(t *testing.T) {
+	t.Run("Should put referenced types first and unknown types last", func(t *testing.T) {
+		ordered := orderTypes([]MetadataType{TypeDataSets, "indicators", TypeDataElements, TypeCategoryOptionCombos, TypeCategoryCombos, TypeCategoryOptions})
+
+		assert.Equal(t, []MetadataType{TypeCategoryOptions, TypeCategoryCombos, TypeCategoryOptionCombos, TypeDataElements, TypeDataSets, "indicators"}, ordered)
+	})
+}
+
+func TestSortOrgUnitsByLevel(t *testing.T) {
+	t.Run("Should put parents before their children", func(t *testing.T) {
+		ou := func(id, parent string) map[string]interface{} {
+			return map[string]interface{}{"id": id, "parent": map[string]interface{}{"id": parent}}
+		}
+		payload := map[MetadataType][]map[string]interface{}{
+			TypeOrganisationUnits: {
+				ou("ouFacility1", "ouChiefdom1"),
+				ou("ouChiefdom1", "ouDistrict1"),
+				ou("ouOtherHF01", "ouExisting1"),
+				ou("ouDistrict1", "ouExisting1"),
+			},
+		}
+
+		sortOrgUnitsByLevel(payload)
+
+		var ids []string
+		for _, item := range payload[TypeOrganisationUnits] {
+			ids = append(ids, item["id"].(string))
+		}
+		assert.Equal(t, []string{"ouOtherHF01", "ouDistrict1", "ouChiefdom1", "ouFacility1"}, ids)
+	})
+}
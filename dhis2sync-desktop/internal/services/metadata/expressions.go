@@ -0,0 +1,67 @@
+package metadata
+
+import (
+	"regexp"
+	"strings"
+)
+
+// expressionRefPattern matches the object references in DHIS2 indicator, validation rule
+// and program indicator expressions, e.g. #{de.coc}, R{ds.REPORTING_RATE} or N{indicator}
+var expressionRefPattern = regexp.MustCompile(`(OUG|[#RINCDAV])\{([^}]*)\}`)
+
+// walkExpressionRefs calls visit for each metadata object an expression references and
+// rewrites the reference with the UID visit returns. Program expressions use
+// #{stage.de}, where aggregate ones use #{de.coc.aoc}. References to objects this
+// package doesn't sync (stages, attributes, constants, org unit groups) are left alone.
+func walkExpressionRefs(expr string, program bool, visit func(t MetadataType, uid string) string) string {
+	return expressionRefPattern.ReplaceAllStringFunc(expr, func(ref string) string {
+		match := expressionRefPattern.FindStringSubmatch(ref)
+		prefix, parts := match[1], strings.Split(match[2], ".")
+
+		// Type of each dot-separated part; "" leaves the part as it is
+		var types []MetadataType
+		switch prefix {
+		case "#":
+			if program {
+				types = []MetadataType{"", TypeDataElements}
+			} else {
+				types = []MetadataType{TypeDataElements, TypeCategoryOptionCombos, TypeCategoryOptionCombos}
+			}
+		case "D":
+			types = []MetadataType{"", TypeDataElements}
+		case "R":
+			types = []MetadataType{TypeDataSets}
+		case "N":
+			types = []MetadataType{TypeIndicators}
+		case "I":
+			types = []MetadataType{TypeProgramIndicators}
+		default:
+			return ref
+		}
+
+		for i, t := range types {
+			if i >= len(parts) || t == "" || parts[i] == "" || parts[i] == "*" {
+				continue
+			}
+			parts[i] = visit(t, parts[i])
+		}
+		return prefix + "{" + strings.Join(parts, ".") + "}"
+	})
+}
+
+// remapExpression rewrites an expression's references to destination UIDs
+func (s *Service) remapExpression(expr string, program bool, mappings map[MetadataType]map[string]string) string {
+	return walkExpressionRefs(expr, program, func(t MetadataType, uid string) string {
+		return s.remapUID(t, uid, mappings)
+	})
+}
+
+// expressionReferences lists the objects an expression references
+func expressionReferences(expr string, program bool) []metadataObject {
+	var refs []metadataObject
+	walkExpressionRefs(expr, program, func(t MetadataType, uid string) string {
+		refs = append(refs, metadataObject{Type: t, UID: uid})
+		return uid
+	})
+	return refs
+}
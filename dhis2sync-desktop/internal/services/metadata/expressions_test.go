@@ -0,0 +1,44 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemapExpression(t *testing.T) {
+	s := &Service{}
+	mappings := map[MetadataType]map[string]string{
+		TypeDataElements:         {"srcDE000001": "dstDE000001"},
+		TypeCategoryOptionCombos: {"srcCOC00001": "dstCOC00001"},
+		TypeDataSets:             {"srcDS000001": "dstDS000001"},
+		TypeIndicators:           {"srcIND00001": "dstIND00001"},
+	}
+
+	t.Run("Should remap aggregate references and keep the rest", func(t *testing.T) {
+		expr := "#{srcDE000001.srcCOC00001} + #{srcDE000001.*.srcCOC00001} + #{unmappedDE1} * C{constant001} / R{srcDS000001.REPORTING_RATE} + N{srcIND00001} + OUG{group000001}"
+
+		remapped := s.remapExpression(expr, false, mappings)
+
+		assert.Equal(t, "#{dstDE000001.dstCOC00001} + #{dstDE000001.*.dstCOC00001} + #{unmappedDE1} * C{constant001} / R{dstDS000001.REPORTING_RATE} + N{dstIND00001} + OUG{group000001}", remapped)
+	})
+
+	t.Run("Should treat the first part of a program reference as a stage", func(t *testing.T) {
+		expr := "d2:hasValue(#{srcDE000001.srcDE000001}) && D{program0001.srcDE000001} > A{attribute01} + V{enrollment_count}"
+
+		remapped := s.remapExpression(expr, true, mappings)
+
+		assert.Equal(t, "d2:hasValue(#{srcDE000001.dstDE000001}) && D{program0001.dstDE000001} > A{attribute01} + V{enrollment_count}", remapped)
+	})
+
+	t.Run("Should list the objects an expression references", func(t *testing.T) {
+		refs := expressionReferences("#{deA00000001.cocA0000001} / R{dsA00000001.ACTUAL_REPORTS} + #{deB00000001.*}", false)
+
+		assert.Equal(t, []metadataObject{
+			{Type: TypeDataElements, UID: "deA00000001"},
+			{Type: TypeCategoryOptionCombos, UID: "cocA0000001"},
+			{Type: TypeDataSets, UID: "dsA00000001"},
+			{Type: TypeDataElements, UID: "deB00000001"},
+		}, refs)
+	})
+}
@@ -0,0 +1,166 @@
+package metadata
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"dhis2sync-desktop/internal/events"
+	"dhis2sync-desktop/internal/models"
+	"dhis2sync-desktop/internal/notifications"
+)
+
+// StartBuildPayload builds a payload preview like BuildPayloadPreview in a background
+// task, reporting progress on the metadata:payload:{id} channel as each type's missing
+// objects are fetched. The preview is kept on the task for GetBuildPayloadProgress.
+func (s *Service) StartBuildPayload(profileID string, types []MetadataType, mappings map[MetadataType]map[string]string, preserveSharing bool) (string, error) {
+	profile, err := s.getProfile(profileID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	taskID := uuid.New().String()
+	s.payloadMu.Lock()
+	s.payloadStore[taskID] = &PayloadProgress{
+		TaskID:   taskID,
+		Status:   "starting",
+		Messages: []string{},
+	}
+	s.payloadMu.Unlock()
+
+	s.emitPayloadEvent(taskID)
+
+	go s.performBuildPayload(taskID, profile, types, mappings, preserveSharing)
+
+	return taskID, nil
+}
+
+// GetBuildPayloadProgress retrieves the current progress of a payload build task
+func (s *Service) GetBuildPayloadProgress(taskID string) (*PayloadProgress, error) {
+	s.payloadMu.RLock()
+	defer s.payloadMu.RUnlock()
+
+	progress, exists := s.payloadStore[taskID]
+	if !exists {
+		return nil, fmt.Errorf("task not found: %s", taskID)
+	}
+	return progress, nil
+}
+
+func (s *Service) performBuildPayload(taskID string, profile *models.ConnectionProfile, types []MetadataType, mappings map[MetadataType]map[string]string, preserveSharing bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.updatePayloadProgress(taskID, "error", 0, fmt.Sprintf("Panic: %v", r))
+		}
+	}()
+
+	s.updatePayloadProgress(taskID, "running", 5, "Comparing source and destination...")
+
+	preview, err := s.buildPayloadPreview(profile, types, mappings, preserveSharing, s.payloadItemProgress(taskID, types))
+	if err != nil {
+		s.updatePayloadProgress(taskID, "error", 0, err.Error())
+		return
+	}
+
+	total := 0
+	for _, n := range preview.Counts {
+		total += n
+	}
+
+	s.payloadMu.Lock()
+	if p, exists := s.payloadStore[taskID]; exists {
+		p.Preview = preview
+		p.CompletedAt = time.Now().Unix()
+	}
+	s.payloadMu.Unlock()
+
+	s.updatePayloadProgress(taskID, "completed", 100, fmt.Sprintf("Payload ready: %d objects", total))
+}
+
+// payloadItemProgress maps buildPayloadForTypes progress onto 5-95%, an equal share per
+// type, announcing each type as its fetches start. Progress is only published when the
+// percentage moves, so thousands of fetches don't flood the frontend with events.
+func (s *Service) payloadItemProgress(taskID string, types []MetadataType) func(t MetadataType, done, total int) {
+	position := make(map[MetadataType]int, len(types))
+	for i, t := range orderTypes(types) {
+		position[t] = i
+	}
+	last := -1
+
+	return func(t MetadataType, done, total int) {
+		if done == 0 {
+			s.appendPayloadMessage(taskID, fmt.Sprintf("Fetching %d missing %s...", total, t))
+		}
+
+		share := 1.0
+		if total > 0 {
+			share = float64(done) / float64(total)
+		}
+		progress := 5 + int(90*(float64(position[t])+share)/float64(len(types)))
+		if progress != last {
+			last = progress
+			s.updatePayloadProgress(taskID, "running", progress, "")
+		}
+	}
+}
+
+func (s *Service) updatePayloadProgress(taskID, status string, progress int, message string) {
+	s.payloadMu.Lock()
+	updated := false
+	statusChanged := false
+	if p, exists := s.payloadStore[taskID]; exists {
+		statusChanged = p.Status != status
+		p.Status = status
+		p.Progress = progress
+		if message != "" {
+			p.Messages = append(p.Messages, message)
+		}
+		updated = true
+	}
+	s.payloadMu.Unlock()
+
+	if updated {
+		go s.emitPayloadEvent(taskID)
+	}
+	if statusChanged {
+		notifications.TaskFinished(taskID, "metadata_payload", status, message)
+	}
+}
+
+func (s *Service) appendPayloadMessage(taskID, message string) {
+	s.payloadMu.Lock()
+	defer s.payloadMu.Unlock()
+
+	if p, exists := s.payloadStore[taskID]; exists {
+		p.Messages = append(p.Messages, message)
+		go s.emitPayloadEvent(taskID)
+	}
+}
+
+func (s *Service) emitPayloadEvent(taskID string) {
+	s.payloadMu.RLock()
+	progress, exists := s.payloadStore[taskID]
+	if !exists {
+		s.payloadMu.RUnlock()
+		return
+	}
+	payload := map[string]interface{}{
+		"task_id":  taskID,
+		"status":   progress.Status,
+		"progress": progress.Progress,
+		"messages": append([]string(nil), progress.Messages...),
+	}
+	if len(progress.Messages) > 0 {
+		payload["message"] = progress.Messages[len(progress.Messages)-1]
+	}
+	if progress.Preview != nil {
+		payload["preview"] = progress.Preview
+	}
+	if progress.CompletedAt != 0 {
+		payload["completed_at"] = progress.CompletedAt
+	}
+	s.payloadMu.RUnlock()
+
+	events.EmitProgress(s.ctx, fmt.Sprintf("metadata:payload:%s", taskID), "metadata_payload", payload)
+}
@@ -0,0 +1,176 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"gorm.io/gorm/clause"
+
+	"dhis2sync-desktop/internal/api"
+	"dhis2sync-desktop/internal/models"
+)
+
+// metadataObject identifies an object in an import payload
+type metadataObject struct {
+	Type MetadataType
+	UID  string
+}
+
+// decodeImportReport parses an /api/metadata response. Newer DHIS2 versions wrap
+// the report in {"response": {...}}; older ones return it directly.
+func decodeImportReport(resp *resty.Response, endpoint string) (*ImportReport, error) {
+	var wrapped struct {
+		Status   string        `json:"status"`
+		Response *ImportReport `json:"response"`
+	}
+	if err := json.Unmarshal(resp.Body(), &wrapped); err == nil && wrapped.Response != nil && wrapped.Response.Status != "" {
+		return wrapped.Response, nil
+	}
+
+	var report ImportReport
+	if err := api.DecodeJSON(resp, endpoint, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// importedObjects returns the payload objects a report shows as created or updated.
+// Nothing is imported when the request failed outright or an atomic (ALL) import
+// reported an error; otherwise every object without error reports made it.
+func importedObjects(payload map[MetadataType][]map[string]interface{}, report *ImportReport, atomicMode string) []metadataObject {
+	if report == nil || report.Error != "" || (strings.EqualFold(report.Status, "error") && atomicMode != "NONE") {
+		return nil
+	}
+
+	failed := make(map[string]bool)
+	for _, tr := range report.TypeReports {
+		for _, obj := range tr.Objects {
+			if len(obj.ErrorReports) > 0 {
+				failed[obj.UID] = true
+			}
+		}
+	}
+
+	imported := []metadataObject{}
+	for t, items := range payload {
+		for _, item := range items {
+			uid := getStringOr(item, "id", "")
+			if uid != "" && !failed[uid] {
+				imported = append(imported, metadataObject{Type: t, UID: uid})
+			}
+		}
+	}
+	return imported
+}
+
+// withoutImported drops objects an earlier attempt already imported, returning the
+// remaining payload and how many were dropped
+func withoutImported(payload map[MetadataType][]map[string]interface{}, done map[metadataObject]bool) (map[MetadataType][]map[string]interface{}, int) {
+	if len(done) == 0 {
+		return payload, 0
+	}
+
+	remaining := make(map[MetadataType][]map[string]interface{})
+	skipped := 0
+	for t, items := range payload {
+		for _, item := range items {
+			if done[metadataObject{Type: t, UID: getStringOr(item, "id", "")}] {
+				skipped++
+				continue
+			}
+			remaining[t] = append(remaining[t], item)
+		}
+	}
+	return remaining, skipped
+}
+
+// loadImported returns the objects recorded as imported for an apply
+func (s *Service) loadImported(applyID string) (map[metadataObject]bool, error) {
+	var outcomes []models.MetadataImportOutcome
+	if err := s.db.Where("apply_id = ?", applyID).Find(&outcomes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load import outcomes: %w", err)
+	}
+
+	done := make(map[metadataObject]bool, len(outcomes))
+	for _, o := range outcomes {
+		done[metadataObject{Type: MetadataType(o.ObjectType), UID: o.UID}] = true
+	}
+	return done, nil
+}
+
+// recordImported persists objects an apply imported
+func (s *Service) recordImported(applyID, profileID string, objects []metadataObject) error {
+	if len(objects) == 0 {
+		return nil
+	}
+
+	outcomes := make([]models.MetadataImportOutcome, 0, len(objects))
+	for _, obj := range objects {
+		outcomes = append(outcomes, models.MetadataImportOutcome{
+			ApplyID:    applyID,
+			ProfileID:  profileID,
+			ObjectType: string(obj.Type),
+			UID:        obj.UID,
+		})
+	}
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&outcomes).Error
+}
+
+// applyResumable imports payload into the destination, skipping objects an earlier
+// attempt of the same apply imported and recording what this attempt imports
+func (s *Service) applyResumable(client *api.Client, profileID, applyID string, payload map[MetadataType][]map[string]interface{}, importStrategy, atomicMode string) *ImportReport {
+	done, err := s.loadImported(applyID)
+	if err != nil {
+		return &ImportReport{Status: "error", Error: err.Error(), ApplyID: applyID}
+	}
+
+	remaining, skipped := withoutImported(payload, done)
+	if len(remaining) == 0 {
+		return &ImportReport{
+			Status:  "OK",
+			Message: fmt.Sprintf("All %d objects were imported by an earlier attempt", skipped),
+			ApplyID: applyID,
+			Skipped: skipped,
+		}
+	}
+
+	endpoint := fmt.Sprintf("/api/metadata?importStrategy=%s&atomicMode=%s", importStrategy, atomicMode)
+
+	resp, err := client.Post(endpoint, orderedPayload(remaining))
+	if err != nil {
+		return &ImportReport{Status: "error", Message: "Could not reach the destination", Error: err.Error(), ApplyID: applyID, Skipped: skipped}
+	}
+
+	// Imported org units may have been renamed
+	if len(remaining[TypeOrganisationUnits]) > 0 {
+		api.ClearOrgUnitNameCache()
+	}
+
+	report, err := decodeImportReport(resp, endpoint)
+	if err != nil {
+		// If JSON parsing fails, return raw response
+		report = parseFailureReport(err, resp.Body())
+	}
+	report.ApplyID = applyID
+	report.Skipped = skipped
+
+	// 409 carries an import report with per-object conflicts; any other failure
+	// status (5xx, auth) means nothing was imported
+	if code := resp.StatusCode(); !resp.IsSuccess() && code != http.StatusConflict && report.Error == "" {
+		report.Status = "error"
+		report.Error = fmt.Sprintf("HTTP %d: %s", code, api.BodySnippet(resp.Body(), 500))
+	}
+
+	if err := s.recordImported(applyID, profileID, importedObjects(remaining, report, atomicMode)); err != nil {
+		report.Message = strings.TrimSpace(report.Message + " (import outcomes not saved; a retry may resend objects: " + err.Error() + ")")
+	}
+
+	if resp.StatusCode() == http.StatusConflict && report.Error == "" {
+		markConflicts(report)
+	}
+
+	return report
+}
@@ -0,0 +1,87 @@
+package metadata
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"dhis2sync-desktop/internal/api/apitest"
+	"dhis2sync-desktop/internal/models"
+)
+
+func TestApplyResumable(t *testing.T) {
+	t.Run("Should send only previously failed objects on retry", func(t *testing.T) {
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		require.NoError(t, err)
+		require.NoError(t, db.AutoMigrate(&models.MetadataImportOutcome{}))
+		s := &Service{db: db}
+
+		attempts := 0
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/metadata": func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if attempts == 1 {
+					apitest.JSON(http.StatusConflict, map[string]interface{}{
+						"status": "ERROR",
+						"response": map[string]interface{}{
+							"status": "ERROR",
+							"typeReports": []map[string]interface{}{{
+								"klass": "org.hisp.dhis.dataelement.DataElement",
+								"objectReports": []map[string]interface{}{{
+									"uid":          "deBBBBBBBB2",
+									"errorReports": []map[string]interface{}{{"message": "Missing category combo", "errorCode": "E5002"}},
+								}},
+							}},
+						},
+					})(w, r)
+					return
+				}
+				apitest.JSON(http.StatusOK, map[string]interface{}{"status": "OK"})(w, r)
+			},
+		})
+		client := srv.Client()
+
+		payload := map[MetadataType][]map[string]interface{}{
+			TypeDataElements: {
+				{"id": "deAAAAAAAA1", "name": "ANC 1st visit"},
+				{"id": "deBBBBBBBB2", "name": "ANC 2nd visit"},
+			},
+			TypeOptionSets: {
+				{"id": "osCCCCCCCC3", "name": "Sex"},
+			},
+		}
+
+		first := s.applyResumable(client, "profile-1", "apply-1", payload, "CREATE_AND_UPDATE", "NONE")
+		assert.Equal(t, StatusConflicts, first.Status)
+		assert.Equal(t, 0, first.Skipped)
+		require.Len(t, first.TypeReports, 1)
+		assert.Equal(t, "Missing category combo", first.TypeReports[0].Objects[0].ErrorReports[0].Message)
+
+		retry := s.applyResumable(client, "profile-1", "apply-1", payload, "CREATE_AND_UPDATE", "NONE")
+		assert.Equal(t, "OK", retry.Status)
+		assert.Equal(t, "apply-1", retry.ApplyID)
+		assert.Equal(t, 2, retry.Skipped)
+
+		var sent map[MetadataType][]map[string]interface{}
+		apitest.DecodeBody(t, srv.LastBody("/api/metadata"), &sent)
+		require.Len(t, sent, 1, "Only data elements should remain")
+		require.Len(t, sent[TypeDataElements], 1)
+		assert.Equal(t, "deBBBBBBBB2", sent[TypeDataElements][0]["id"])
+	})
+
+	t.Run("Should record nothing when an atomic import fails", func(t *testing.T) {
+		report := &ImportReport{Status: "ERROR", TypeReports: []TypeReport{{
+			Objects: []ObjectReport{{UID: "deBBBBBBBB2", ErrorReports: []ObjectError{{Message: "bad"}}}},
+		}}}
+		payload := map[MetadataType][]map[string]interface{}{
+			TypeDataElements: {{"id": "deAAAAAAAA1"}, {"id": "deBBBBBBBB2"}},
+		}
+
+		assert.Empty(t, importedObjects(payload, report, "ALL"))
+		assert.Equal(t, []metadataObject{{Type: TypeDataElements, UID: "deAAAAAAAA1"}}, importedObjects(payload, report, "NONE"))
+	})
+}
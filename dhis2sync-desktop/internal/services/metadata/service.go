@@ -2,19 +2,23 @@ package metadata
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 	"gorm.io/gorm"
 
 	"dhis2sync-desktop/internal/api"
-	"dhis2sync-desktop/internal/crypto"
+	"dhis2sync-desktop/internal/events"
 	"dhis2sync-desktop/internal/models"
+	"dhis2sync-desktop/internal/notifications"
+	"dhis2sync-desktop/internal/writewindow"
 )
 
 // Service handles metadata comparison and synchronization
@@ -25,6 +29,8 @@ type Service struct {
 	progressMu    sync.RWMutex
 	mappingsStore map[string]map[MetadataType]map[string]string // profileID -> type -> srcID:dstID
 	mappingsMu    sync.RWMutex
+	payloadStore  map[string]*PayloadProgress
+	payloadMu     sync.RWMutex
 }
 
 // NewService creates a new metadata service
@@ -34,6 +40,7 @@ func NewService(db *gorm.DB, ctx context.Context) *Service {
 		ctx:           ctx,
 		progressStore: make(map[string]*DiffProgress),
 		mappingsStore: make(map[string]map[MetadataType]map[string]string),
+		payloadStore:  make(map[string]*PayloadProgress),
 	}
 }
 
@@ -56,8 +63,8 @@ func (s *Service) GetSummary(profileID string, types []MetadataType) (map[Metada
 
 	result := make(map[MetadataType]TypeSummary)
 	for _, t := range types {
-		source := s.fetchType(sourceClient, t)
-		dest := s.fetchType(destClient, t)
+		source := s.fetchType(sourceClient, t, nil)
+		dest := s.fetchType(destClient, t, nil)
 		result[t] = TypeSummary{
 			Source: source,
 			Dest:   dest,
@@ -67,8 +74,10 @@ func (s *Service) GetSummary(profileID string, types []MetadataType) (map[Metada
 	return result, nil
 }
 
-// StartDiff initiates a background metadata comparison task
-func (s *Service) StartDiff(profileID string, types []MetadataType) (string, error) {
+// StartDiff initiates a background metadata comparison task. With countsOnly the task
+// reports per-type Counts instead of the full Results lists, for a quick overview of
+// large instances; diff a single type again without it to drill into the details.
+func (s *Service) StartDiff(profileID string, types []MetadataType, countsOnly bool) (string, error) {
 	profile, err := s.getProfile(profileID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get profile: %w", err)
@@ -90,7 +99,7 @@ func (s *Service) StartDiff(profileID string, types []MetadataType) (string, err
 	s.emitProgressEvent(taskID)
 
 	// Run in background goroutine
-	go s.performDiff(taskID, profile, types)
+	go s.performDiff(taskID, profile, types, countsOnly)
 
 	return taskID, nil
 }
@@ -108,6 +117,81 @@ func (s *Service) GetDiffProgress(taskID string) (*DiffProgress, error) {
 	return progress, nil
 }
 
+// ExportDiffResults exports a completed diff's findings as pretty JSON or as CSV with
+// one row per missing object, conflict, suggestion or UID collision
+func (s *Service) ExportDiffResults(taskID, format string) (string, error) {
+	s.progressMu.RLock()
+	progress, exists := s.progressStore[taskID]
+	var status string
+	var results map[MetadataType]ComparisonResult
+	if exists {
+		status = progress.Status
+		results = progress.Results
+	}
+	s.progressMu.RUnlock()
+
+	if !exists {
+		return "", fmt.Errorf("task not found: %s", taskID)
+	}
+	if status != "completed" {
+		return "", fmt.Errorf("diff %s is not completed (status: %s)", taskID, status)
+	}
+	if results == nil {
+		return "", fmt.Errorf("diff %s has no results to export (counts-only diff)", taskID)
+	}
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return string(data), nil
+	case "csv":
+		return diffResultsCSV(results)
+	default:
+		return "", fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// diffResultsCSV writes diff results as CSV, types in name order
+func diffResultsCSV(results map[MetadataType]ComparisonResult) (string, error) {
+	types := make([]string, 0, len(results))
+	for objType := range results {
+		types = append(types, string(objType))
+	}
+	sort.Strings(types)
+
+	var buf strings.Builder
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"type", "category", "source_id", "source_name", "dest_id", "dest_name", "confidence", "diff_fields", "reason"})
+
+	for _, objType := range types {
+		result := results[MetadataType(objType)]
+		for _, item := range result.Missing {
+			writer.Write([]string{objType, "missing", item.ID, item.Name, "", "", "", "", ""})
+		}
+		for _, item := range result.Conflicts {
+			fields := make([]string, 0, len(item.Diffs))
+			for field := range item.Diffs {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			writer.Write([]string{objType, "conflict", item.ID, item.Name, item.ID, "", "", strings.Join(fields, ";"), ""})
+		}
+		for _, item := range result.Suggestions {
+			writer.Write([]string{objType, "suggestion", item.Source.ID, item.Source.Name, item.Dest.ID, item.Dest.Name,
+				fmt.Sprintf("%.2f", item.Confidence), "", "matched by " + item.By})
+		}
+		for _, item := range result.Collisions {
+			writer.Write([]string{objType, "collision", item.Source.ID, item.Source.Name, item.Dest.ID, item.Dest.Name, "", "", item.Reason})
+		}
+	}
+
+	writer.Flush()
+	return buf.String(), writer.Error()
+}
+
 // SaveMappings persists mapping pairs for a profile
 func (s *Service) SaveMappings(profileID string, pairs []MappingPair) (*SaveMappingsResponse, error) {
 	s.mappingsMu.Lock()
@@ -170,13 +254,22 @@ func (s *Service) GetMappings(profileID string) map[MetadataType]map[string]stri
 	return result
 }
 
-// BuildPayloadPreview generates a metadata import payload for missing items
-func (s *Service) BuildPayloadPreview(profileID string, types []MetadataType, mappings map[MetadataType]map[string]string) (*PayloadPreviewResponse, error) {
+// BuildPayloadPreview generates a metadata import payload for missing items. With
+// preserveSharing the items keep their source sharing (see copySharing); user groups are
+// remapped through the userGroups mappings and any not found on the destination are
+// dropped with a warning. Otherwise the destination's default sharing applies. It blocks until
+// every missing object is fetched, so large type sets should use StartBuildPayload.
+func (s *Service) BuildPayloadPreview(profileID string, types []MetadataType, mappings map[MetadataType]map[string]string, preserveSharing bool) (*PayloadPreviewResponse, error) {
 	profile, err := s.getProfile(profileID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get profile: %w", err)
 	}
+	return s.buildPayloadPreview(profile, types, mappings, preserveSharing, nil)
+}
 
+// buildPayloadPreview builds the preview for BuildPayloadPreview and StartBuildPayload;
+// onItem is passed to buildPayloadForTypes
+func (s *Service) buildPayloadPreview(profile *models.ConnectionProfile, types []MetadataType, mappings map[MetadataType]map[string]string, preserveSharing bool, onItem func(t MetadataType, done, total int)) (*PayloadPreviewResponse, error) {
 	sourceClient, err := s.getAPIClient(profile, "source")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create source client: %w", err)
@@ -188,7 +281,12 @@ func (s *Service) BuildPayloadPreview(profileID string, types []MetadataType, ma
 	}
 
 	// Build payload
-	payload := s.buildPayloadForTypes(types, sourceClient, destClient, mappings)
+	payload, collisions := s.buildPayloadForTypes(types, sourceClient, destClient, mappings, preserveSharing, onItem)
+
+	var warnings []string
+	if preserveSharing {
+		warnings = dropUnknownUserGroups(payload, indexBy(s.fetchType(destClient, TypeUserGroups, nil), "id"))
+	}
 
 	// Calculate counts
 	counts := make(map[MetadataType]int)
@@ -200,14 +298,16 @@ func (s *Service) BuildPayloadPreview(profileID string, types []MetadataType, ma
 	required := s.fetchRequiredFields(destClient, types)
 
 	return &PayloadPreviewResponse{
-		Payload:  payload,
-		Counts:   counts,
-		Required: required,
+		Payload:    payload,
+		Counts:     counts,
+		Required:   required,
+		Collisions: collisions,
+		Warnings:   warnings,
 	}, nil
 }
 
-// DryRun performs a dry-run metadata import
-func (s *Service) DryRun(profileID string, payload map[MetadataType][]map[string]interface{}, importStrategy, atomicMode string) (*ImportReport, error) {
+// DryRun performs a dry-run metadata import; preserveSharing is as for Apply
+func (s *Service) DryRun(profileID string, payload map[MetadataType][]map[string]interface{}, importStrategy, atomicMode string, preserveSharing bool) (*ImportReport, error) {
 	profile, err := s.getProfile(profileID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get profile: %w", err)
@@ -228,9 +328,13 @@ func (s *Service) DryRun(profileID string, payload map[MetadataType][]map[string
 		}, nil
 	}
 
+	if !preserveSharing {
+		payload = withoutSharing(payload)
+	}
+
 	endpoint := fmt.Sprintf("/api/metadata?importStrategy=%s&atomicMode=%s&dryRun=true", importStrategy, atomicMode)
 
-	resp, err := destClient.Post(endpoint, payload)
+	resp, err := destClient.Post(endpoint, orderedPayload(payload))
 	if err != nil {
 		return &ImportReport{
 			Status: "error",
@@ -239,26 +343,27 @@ func (s *Service) DryRun(profileID string, payload map[MetadataType][]map[string
 	}
 
 	var result ImportReport
-	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+	if err := api.DecodeJSON(resp, endpoint, &result); err != nil {
 		// If JSON parsing fails, return raw response
-		respBody := resp.Body()
-		bodyText := string(respBody[:min(1000, len(respBody))])
-		return &ImportReport{
-			Status:  "error",
-			Message: "Failed to parse response",
-			Body:    map[string]interface{}{"text": bodyText},
-		}, nil
+		return parseFailureReport(err, resp.Body()), nil
 	}
 
 	return &result, nil
 }
 
-// Apply performs an actual metadata import
-func (s *Service) Apply(profileID string, payload map[MetadataType][]map[string]interface{}, importStrategy, atomicMode string) (*ImportReport, error) {
+// Apply performs an actual metadata import. Imports are resumable: the report's ApplyID
+// (generated when applyID is empty) passed back on a retry skips the objects that
+// attempt imported, so a partially failed apply only sends the remainder. Sharing in
+// the payload is only imported with preserveSharing; otherwise it's stripped and the
+// destination's defaults apply.
+func (s *Service) Apply(profileID, applyID string, payload map[MetadataType][]map[string]interface{}, importStrategy, atomicMode string, preserveSharing bool) (*ImportReport, error) {
 	profile, err := s.getProfile(profileID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get profile: %w", err)
 	}
+	if err := writewindow.Check(profile, time.Now()); err != nil {
+		return nil, err
+	}
 
 	if importStrategy == "" {
 		importStrategy = "CREATE_AND_UPDATE"
@@ -275,28 +380,64 @@ func (s *Service) Apply(profileID string, payload map[MetadataType][]map[string]
 		}, nil
 	}
 
-	endpoint := fmt.Sprintf("/api/metadata?importStrategy=%s&atomicMode=%s", importStrategy, atomicMode)
+	if applyID == "" {
+		applyID = uuid.New().String()
+	}
+	if !preserveSharing {
+		payload = withoutSharing(payload)
+	}
+
+	return s.applyResumable(destClient, profileID, applyID, payload, importStrategy, atomicMode), nil
+}
+
+// parseFailureReport turns an unparseable import response into an error report carrying the body text
+func parseFailureReport(err error, body []byte) *ImportReport {
+	return &ImportReport{
+		Status:  "error",
+		Message: "Failed to parse response",
+		Error:   err.Error(),
+		Body:    map[string]interface{}{"text": api.BodySnippet(body, 1000)},
+	}
+}
+
+// CreateMissing fetches the given source objects, builds a minimal payload with
+// saved mappings applied and imports it into the destination in one atomic request.
+// Used to fix metadata reported missing by an audit without a full diff.
+func (s *Service) CreateMissing(profileID string, ids map[MetadataType][]string) (*ImportReport, error) {
+	profile, err := s.getProfile(profileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+	// Checked before fetching from source; Apply would only refuse after that work
+	if err := writewindow.Check(profile, time.Now()); err != nil {
+		return nil, err
+	}
 
-	resp, err := destClient.Post(endpoint, payload)
+	sourceClient, err := s.getAPIClient(profile, "source")
 	if err != nil {
-		return &ImportReport{
-			Status: "error",
-			Error:  err.Error(),
-		}, nil
+		return nil, fmt.Errorf("failed to create source client: %w", err)
 	}
 
-	var result ImportReport
-	if err := json.Unmarshal(resp.Body(), &result); err != nil {
-		respBody := resp.Body()
-		bodyText := string(respBody[:min(1000, len(respBody))])
-		return &ImportReport{
-			Status:  "error",
-			Message: "Failed to parse response",
-			Body:    map[string]interface{}{"text": bodyText},
-		}, nil
+	mappings := s.GetMappings(profileID)
+	payload := make(map[MetadataType][]map[string]interface{})
+	for t, uids := range ids {
+		for _, uid := range uids {
+			fullItem := s.fetchFullItem(sourceClient, t, uid)
+			if fullItem == nil {
+				continue
+			}
+			if minimal := s.buildMinimalItem(t, fullItem, mappings); minimal != nil {
+				payload[t] = append(payload[t], minimal)
+			}
+		}
 	}
 
-	return &result, nil
+	if len(payload) == 0 {
+		return nil, fmt.Errorf("none of the selected items could be fetched from source")
+	}
+	sortOrgUnitsByLevel(payload)
+
+	return s.Apply(profileID, "", payload, "CREATE", "ALL", false)
 }
 
 // Helper functions
@@ -311,28 +452,10 @@ func (s *Service) getProfile(profileID string) (*models.ConnectionProfile, error
 
 // getAPIClient creates an API client for the specified instance (source or dest)
 func (s *Service) getAPIClient(profile *models.ConnectionProfile, sourceOrDest string) (*api.Client, error) {
-	var url, username, encPassword string
-
-	if sourceOrDest == "source" {
-		url = profile.SourceURL
-		username = profile.SourceUsername
-		encPassword = profile.SourcePasswordEnc
-	} else {
-		url = profile.DestURL
-		username = profile.DestUsername
-		encPassword = profile.DestPasswordEnc
-	}
-
-	// Decrypt password
-	password, err := crypto.DecryptPassword(encPassword)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt password: %w", err)
-	}
-
-	return api.NewClient(url, username, password), nil
+	return api.NewProfileClient(profile, sourceOrDest)
 }
 
-func (s *Service) performDiff(taskID string, profile *models.ConnectionProfile, types []MetadataType) {
+func (s *Service) performDiff(taskID string, profile *models.ConnectionProfile, types []MetadataType, countsOnly bool) {
 	defer func() {
 		if r := recover(); r != nil {
 			s.updateProgress(taskID, "error", 0, fmt.Sprintf("Panic: %v", r))
@@ -354,24 +477,39 @@ func (s *Service) performDiff(taskID string, profile *models.ConnectionProfile,
 	}
 
 	results := make(map[MetadataType]ComparisonResult)
+	counts := make(map[MetadataType]DiffCounts)
 	total := len(types)
 
 	for i, t := range types {
 		s.appendMessage(taskID, fmt.Sprintf("Fetching %s from source and destination...", t))
 
-		src := s.fetchType(sourceClient, t)
-		dst := s.fetchType(destClient, t)
+		src := s.fetchType(sourceClient, t, s.fetchProgress(taskID, t, "source"))
+		dst := s.fetchType(destClient, t, s.fetchProgress(taskID, t, "destination"))
 
 		s.appendMessage(taskID, fmt.Sprintf("Comparing %s (%d vs %d)...", t, len(src), len(dst)))
 
-		results[t] = s.compareLists(src, dst, t)
+		var collisions int
+		if countsOnly {
+			counts[t] = countDifferences(src, dst, t)
+			collisions = counts[t].Collisions
+		} else {
+			results[t] = s.compareLists(src, dst, t)
+			collisions = len(results[t].Collisions)
+		}
+		if collisions > 0 {
+			s.appendMessage(taskID, fmt.Sprintf("⚠ %d %s share a UID with a different destination object", collisions, t))
+		}
 
 		progress := 5 + int(90*float64(i+1)/float64(total))
 		s.updateProgress(taskID, "running", progress, "")
 	}
 
 	s.progressMu.Lock()
-	s.progressStore[taskID].Results = results
+	if countsOnly {
+		s.progressStore[taskID].Counts = counts
+	} else {
+		s.progressStore[taskID].Results = results
+	}
 	s.progressStore[taskID].CompletedAt = time.Now().Unix()
 	s.progressMu.Unlock()
 
@@ -380,12 +518,21 @@ func (s *Service) performDiff(taskID string, profile *models.ConnectionProfile,
 	s.updateProgress(taskID, "completed", 100, "Assessment complete.")
 }
 
+// fetchProgress reports paged fetches of a type that span more than one page
+func (s *Service) fetchProgress(taskID string, objType MetadataType, instance string) func(fetched, total int) {
+	return func(fetched, total int) {
+		if total > metadataPageSize {
+			s.appendMessage(taskID, fmt.Sprintf("Fetched %d/%d %s from %s", fetched, total, objType, instance))
+		}
+	}
+}
+
 func (s *Service) updateProgress(taskID, status string, progress int, message string) {
 	s.progressMu.Lock()
-	defer s.progressMu.Unlock()
-
 	updated := false
+	statusChanged := false
 	if p, exists := s.progressStore[taskID]; exists {
+		statusChanged = p.Status != status
 		p.Status = status
 		p.Progress = progress
 		if message != "" {
@@ -393,10 +540,14 @@ func (s *Service) updateProgress(taskID, status string, progress int, message st
 		}
 		updated = true
 	}
+	s.progressMu.Unlock()
 
 	if updated {
 		go s.emitProgressEvent(taskID)
 	}
+	if statusChanged {
+		notifications.TaskFinished(taskID, "metadata", status, message)
+	}
 }
 
 func (s *Service) appendMessage(taskID, message string) {
@@ -441,62 +592,112 @@ func (s *Service) emitProgressEvent(taskID string) {
 		payload["completed_at"] = progress.CompletedAt
 	}
 
-	runtime.EventsEmit(s.ctx, fmt.Sprintf("metadata:%s", taskID), payload)
+	events.EmitProgress(s.ctx, fmt.Sprintf("metadata:%s", taskID), "metadata", payload)
 }
 
-// fetchType retrieves metadata objects for a specific type
-func (s *Service) fetchType(client *api.Client, objType MetadataType) []map[string]interface{} {
-	var endpoint string
-	var params map[string]string
+// metadataPageSize is the number of objects fetchType requests per page
+const metadataPageSize = 1000
+
+// fetchType retrieves metadata objects for a specific type, a page at a time so large
+// collections don't arrive as one huge response. onPage, if set, is called after each
+// page with the objects fetched so far and the total the server reports (0 if unknown).
+// Any failed page returns an empty list, as an unreadable collection always has.
+func (s *Service) fetchType(client *api.Client, objType MetadataType, onPage func(fetched, total int)) []map[string]interface{} {
+	var endpoint, fields string
 
 	switch objType {
 	case TypeOrganisationUnits:
 		endpoint = "/api/organisationUnits.json"
-		params = map[string]string{"fields": "id,code,displayName,level,parent[id]", "paging": "false"}
+		fields = "id,code,displayName,level,parent[id]"
 	case TypeCategoryOptions:
 		endpoint = "/api/categoryOptions.json"
-		params = map[string]string{"fields": "id,code,displayName", "paging": "false"}
+		fields = "id,code,displayName"
 	case TypeCategories:
 		endpoint = "/api/categories.json"
-		params = map[string]string{"fields": "id,code,displayName,categoryOptions[id]", "paging": "false"}
+		fields = "id,code,displayName,categoryOptions[id]"
 	case TypeCategoryCombos:
 		endpoint = "/api/categoryCombos.json"
-		params = map[string]string{"fields": "id,code,displayName,categories[id]", "paging": "false"}
+		fields = "id,code,displayName,categories[id]"
 	case TypeCategoryOptionCombos:
 		endpoint = "/api/categoryOptionCombos.json"
-		params = map[string]string{"fields": "id,code,displayName,categoryCombo[id]", "paging": "false"}
+		fields = "id,code,displayName,categoryCombo[id]"
 	case TypeOptionSets:
 		endpoint = "/api/optionSets.json"
-		params = map[string]string{"fields": "id,code,displayName,options[id,code,displayName]", "paging": "false"}
+		fields = "id,code,displayName,options[id,code,displayName]"
 	case TypeDataElements:
 		endpoint = "/api/dataElements.json"
-		params = map[string]string{"fields": "id,code,displayName,valueType,categoryCombo[id],optionSet[id]", "paging": "false"}
+		fields = "id,code,displayName,valueType,categoryCombo[id],optionSet[id]"
+	case TypeDataElementGroups:
+		endpoint = "/api/dataElementGroups.json"
+		fields = "id,code,displayName,dataElements[id]"
+	case TypeDataElementGroupSets:
+		endpoint = "/api/dataElementGroupSets.json"
+		fields = "id,code,displayName,dataElementGroups[id]"
 	case TypeDataSets:
 		endpoint = "/api/dataSets.json"
-		params = map[string]string{"fields": "id,code,displayName,periodType,categoryCombo[id],dataSetElements[dataElement[id,code]]", "paging": "false"}
+		fields = "id,code,displayName,periodType,categoryCombo[id],dataSetElements[dataElement[id,code]]"
+	case TypeSections:
+		endpoint = "/api/sections.json"
+		fields = "id,code,displayName,sortOrder,dataSet[id],dataElements[id]"
+	case TypeIndicatorTypes:
+		endpoint = "/api/indicatorTypes.json"
+		fields = "id,code,displayName,factor,number"
+	case TypeIndicators:
+		endpoint = "/api/indicators.json"
+		fields = "id,code,displayName,indicatorType[id],numerator,denominator"
+	case TypeOptionGroups:
+		endpoint = "/api/optionGroups.json"
+		fields = "id,code,displayName,optionSet[id],options[id]"
+	case TypeValidationRules:
+		endpoint = "/api/validationRules.json"
+		fields = "id,code,displayName,periodType,operator,importance,leftSide[expression],rightSide[expression]"
+	case TypeProgramIndicators:
+		endpoint = "/api/programIndicators.json"
+		fields = "id,code,displayName,program[id],expression,filter"
+	case TypeUserGroups:
+		endpoint = "/api/userGroups.json"
+		fields = "id,code,displayName"
 	default:
 		return []map[string]interface{}{}
 	}
 
-	resp, err := client.Get(endpoint, params)
-	if err != nil {
-		return []map[string]interface{}{}
-	}
+	converted := []map[string]interface{}{}
+	for page := 1; ; page++ {
+		resp, err := client.Get(endpoint, map[string]string{
+			"fields":   fields,
+			"order":    "id:asc", // A stable order keeps objects from shifting between pages
+			"page":     strconv.Itoa(page),
+			"pageSize": strconv.Itoa(metadataPageSize),
+		})
+		if err != nil || !resp.IsSuccess() {
+			return []map[string]interface{}{}
+		}
 
-	var data map[string]interface{}
-	if err := json.Unmarshal(resp.Body(), &data); err != nil {
-		return []map[string]interface{}{}
-	}
+		var data map[string]interface{}
+		if err := json.Unmarshal(resp.Body(), &data); err != nil {
+			return []map[string]interface{}{}
+		}
 
-	items, ok := data[string(objType)].([]interface{})
-	if !ok {
-		return []map[string]interface{}{}
-	}
+		items, ok := data[string(objType)].([]interface{})
+		if !ok {
+			return []map[string]interface{}{}
+		}
+		for _, item := range items {
+			if m, ok := item.(map[string]interface{}); ok {
+				converted = append(converted, m)
+			}
+		}
 
-	converted := make([]map[string]interface{}, 0, len(items))
-	for _, item := range items {
-		if m, ok := item.(map[string]interface{}); ok {
-			converted = append(converted, m)
+		pager, _ := data["pager"].(map[string]interface{})
+		total, _ := pager["total"].(float64)
+		pageCount, _ := pager["pageCount"].(float64)
+		if onPage != nil {
+			onPage(len(converted), int(total))
+		}
+
+		// Without a pager the server ignored paging and returned every object
+		if pager == nil || page >= int(pageCount) {
+			break
 		}
 	}
 
@@ -505,13 +706,65 @@ func (s *Service) fetchType(client *api.Client, objType MetadataType) []map[stri
 
 // compareLists compares source and destination lists to find missing, conflicts, and suggestions
 func (s *Service) compareLists(src, dst []map[string]interface{}, objType MetadataType) ComparisonResult {
-	srcByID := indexBy(src, "id")
-	dstByID := indexBy(dst, "id")
-	dstByCode := indexBy(dst, "code")
-
 	missing := []MissingItem{}
 	conflicts := []ConflictItem{}
 	suggestions := []SuggestionItem{}
+	collisions := []CollisionItem{}
+
+	walkComparison(src, dst, objType, comparisonVisitor{
+		missing: func(sid string, sitem map[string]interface{}) {
+			missing = append(missing, MissingItem{
+				ID:   sid,
+				Code: getStringOr(sitem, "code", ""),
+				Name: getDisplayName(sitem),
+			})
+		},
+		conflict: func(sid string, sitem map[string]interface{}, diffs map[string]map[string]interface{}) {
+			conflicts = append(conflicts, ConflictItem{
+				ID:    sid,
+				Code:  getStringOr(sitem, "code", ""),
+				Name:  getDisplayName(sitem),
+				Diffs: diffs,
+			})
+		},
+		suggestion: func(item SuggestionItem) { suggestions = append(suggestions, item) },
+		collision:  func(item CollisionItem) { collisions = append(collisions, item) },
+	})
+
+	return ComparisonResult{
+		Missing:     missing,
+		Conflicts:   conflicts,
+		Suggestions: suggestions,
+		Collisions:  collisions,
+	}
+}
+
+// countDifferences counts what compareLists would list, without building the item slices
+func countDifferences(src, dst []map[string]interface{}, objType MetadataType) DiffCounts {
+	counts := DiffCounts{Source: len(src), Dest: len(dst)}
+	walkComparison(src, dst, objType, comparisonVisitor{
+		missing:    func(string, map[string]interface{}) { counts.Missing++ },
+		conflict:   func(string, map[string]interface{}, map[string]map[string]interface{}) { counts.Conflicts++ },
+		suggestion: func(SuggestionItem) { counts.Suggestions++ },
+		collision:  func(CollisionItem) { counts.Collisions++ },
+	})
+	return counts
+}
+
+// comparisonVisitor receives each difference walkComparison finds
+type comparisonVisitor struct {
+	missing    func(sid string, sitem map[string]interface{})
+	conflict   func(sid string, sitem map[string]interface{}, diffs map[string]map[string]interface{})
+	suggestion func(item SuggestionItem)
+	collision  func(item CollisionItem)
+}
+
+// walkComparison compares source and destination lists, reporting missing items,
+// conflicts, suggestions and collisions to v
+func walkComparison(src, dst []map[string]interface{}, objType MetadataType, v comparisonVisitor) {
+	srcByID := indexBy(src, "id")
+	dstByID := indexBy(dst, "id")
+	dstByCode := indexBy(dst, "code")
 
 	criticalFields := getCriticalFields(objType)
 
@@ -519,6 +772,14 @@ func (s *Service) compareLists(src, dst []map[string]interface{}, objType Metada
 	for sid, sitem := range srcByID {
 		ditem, existsByID := dstByID[sid]
 
+		// A shared UID on a clearly different object is a collision, not a conflict
+		if existsByID {
+			if collision, ok := detectCollision(sitem, ditem); ok {
+				v.collision(collision)
+				continue
+			}
+		}
+
 		// Try code match if not found by ID
 		if !existsByID {
 			if scode, ok := getString(sitem, "code"); ok && scode != "" {
@@ -530,11 +791,7 @@ func (s *Service) compareLists(src, dst []map[string]interface{}, objType Metada
 		}
 
 		if !existsByID {
-			missing = append(missing, MissingItem{
-				ID:   sid,
-				Code: getStringOr(sitem, "code", ""),
-				Name: getDisplayName(sitem),
-			})
+			v.missing(sid, sitem)
 			continue
 		}
 
@@ -552,12 +809,7 @@ func (s *Service) compareLists(src, dst []map[string]interface{}, objType Metada
 		}
 
 		if len(diffs) > 0 {
-			conflicts = append(conflicts, ConflictItem{
-				ID:    sid,
-				Code:  getStringOr(sitem, "code", ""),
-				Name:  getDisplayName(sitem),
-				Diffs: diffs,
-			})
+			v.conflict(sid, sitem, diffs)
 		}
 	}
 
@@ -588,7 +840,7 @@ func (s *Service) compareLists(src, dst []map[string]interface{}, objType Metada
 		// Suggest by code match
 		if scode != "" {
 			if ditem, ok := dstByCode[scode]; ok {
-				suggestions = append(suggestions, SuggestionItem{
+				v.suggestion(SuggestionItem{
 					Source: SuggestionDetail{
 						ID:   sid,
 						Code: scode,
@@ -618,7 +870,7 @@ func (s *Service) compareLists(src, dst []map[string]interface{}, objType Metada
 		}
 
 		if bestScore >= 0.7 {
-			suggestions = append(suggestions, SuggestionItem{
+			v.suggestion(SuggestionItem{
 				Source: SuggestionDetail{
 					ID:   sid,
 					Code: scode,
@@ -634,37 +886,51 @@ func (s *Service) compareLists(src, dst []map[string]interface{}, objType Metada
 			})
 		}
 	}
-
-	return ComparisonResult{
-		Missing:     missing,
-		Conflicts:   conflicts,
-		Suggestions: suggestions,
-	}
 }
 
-// buildPayloadForTypes generates metadata import payload
-func (s *Service) buildPayloadForTypes(types []MetadataType, sourceClient, destClient *api.Client, mappings map[MetadataType]map[string]string) map[MetadataType][]map[string]interface{} {
+// buildPayloadForTypes generates metadata import payload, along with the source objects
+// skipped because their UID already names a different destination object. onItem, if
+// set, reports each type's progress in fetching its missing objects in full: it's called
+// before each fetch and once all are done, with the number fetched so far.
+func (s *Service) buildPayloadForTypes(types []MetadataType, sourceClient, destClient *api.Client, mappings map[MetadataType]map[string]string, preserveSharing bool, onItem func(t MetadataType, done, total int)) (map[MetadataType][]map[string]interface{}, map[MetadataType][]CollisionItem) {
 	payload := make(map[MetadataType][]map[string]interface{})
+	collisions := make(map[MetadataType][]CollisionItem)
 
 	// Fetch summaries for all types
 	summaries := make(map[MetadataType]struct{ src, dst []map[string]interface{} })
 	for _, t := range types {
 		summaries[t] = struct{ src, dst []map[string]interface{} }{
-			src: s.fetchType(sourceClient, t),
-			dst: s.fetchType(destClient, t),
+			src: s.fetchType(sourceClient, t, nil),
+			dst: s.fetchType(destClient, t, nil),
 		}
 	}
 
+	// Destination UIDs by type, fetched on first use for types outside types
+	destIndex := make(map[MetadataType]map[string]map[string]interface{})
+	destItems := func(t MetadataType) map[string]map[string]interface{} {
+		if _, ok := destIndex[t]; !ok {
+			summary, fetched := summaries[t]
+			if !fetched {
+				summary.dst = s.fetchType(destClient, t, nil)
+			}
+			destIndex[t] = indexBy(summary.dst, "id")
+		}
+		return destIndex[t]
+	}
+	destHas := func(t MetadataType, uid string) bool {
+		_, exists := destItems(t)[uid]
+		return exists
+	}
+
 	// Helper: check if missing in destination
 	isMissing := func(t MetadataType, uid string) bool {
-		dstByID := indexBy(summaries[t].dst, "id")
-		if _, exists := dstByID[uid]; exists {
+		if destHas(t, uid) {
 			return false
 		}
 		// Check if mapped to existing destination UID
 		if mappings != nil && mappings[t] != nil {
 			if mapped, ok := mappings[t][uid]; ok {
-				if _, exists := dstByID[mapped]; exists {
+				if destHas(t, mapped) {
 					return false
 				}
 			}
@@ -672,13 +938,30 @@ func (s *Service) buildPayloadForTypes(types []MetadataType, sourceClient, destC
 		return true
 	}
 
-	// Process each type (simplified - production code would handle dependencies)
-	for _, t := range types {
+	// Process each type in dependency order so the payload imports in one atomic request
+	for _, t := range orderTypes(types) {
+		dstByID := destItems(t)
+		var missing []string
 		for _, sitem := range summaries[t].src {
 			uid := getStringOr(sitem, "id", "")
-			if uid == "" || !isMissing(t, uid) {
+			if uid == "" {
 				continue
 			}
+			if ditem, exists := dstByID[uid]; exists {
+				if collision, ok := detectCollision(sitem, ditem); ok {
+					collisions[t] = append(collisions[t], collision)
+				}
+				continue
+			}
+			if isMissing(t, uid) {
+				missing = append(missing, uid)
+			}
+		}
+
+		for i, uid := range missing {
+			if onItem != nil {
+				onItem(t, i, len(missing))
+			}
 
 			// Fetch full item from source
 			fullItem := s.fetchFullItem(sourceClient, t, uid)
@@ -687,14 +970,58 @@ func (s *Service) buildPayloadForTypes(types []MetadataType, sourceClient, destC
 			}
 
 			// Build minimal payload item
-			minimal := s.buildMinimalItem(t, fullItem, mappings)
+			minimal := s.buildPayloadItem(t, fullItem, mappings, preserveSharing)
 			if minimal != nil {
 				payload[t] = append(payload[t], minimal)
 			}
+
+			// Sections depend on their dataset, so bring them along to keep the entry form layout
+			if t == TypeDataSets {
+				s.appendDataSetSections(payload, sourceClient, fullItem, mappings)
+			}
+		}
+		if onItem != nil {
+			onItem(t, len(missing), len(missing))
 		}
 	}
 
-	return payload
+	// References to objects missing from the destination would fail the import
+	s.addMissingDependencies(payload, sourceClient, destHas, mappings, preserveSharing)
+	sortOrgUnitsByLevel(payload)
+
+	return payload, collisions
+}
+
+// appendDataSetSections adds the sections of a dataset to the payload, skipping any already present
+func (s *Service) appendDataSetSections(payload map[MetadataType][]map[string]interface{}, sourceClient *api.Client, dataSet map[string]interface{}, mappings map[MetadataType]map[string]string) {
+	sections, ok := dataSet["sections"].([]interface{})
+	if !ok {
+		return
+	}
+
+	existing := indexBy(payload[TypeSections], "id")
+	for _, sec := range sections {
+		secMap, ok := sec.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id := getStringOr(secMap, "id", "")
+		if id == "" {
+			continue
+		}
+		if _, done := existing[id]; done {
+			continue
+		}
+
+		fullSection := s.fetchFullItem(sourceClient, TypeSections, id)
+		if fullSection == nil {
+			continue
+		}
+		if minimal := s.buildMinimalItem(TypeSections, fullSection, mappings); minimal != nil {
+			payload[TypeSections] = append(payload[TypeSections], minimal)
+			existing[id] = minimal
+		}
+	}
 }
 
 // fetchFullItem retrieves complete metadata object
@@ -715,18 +1042,48 @@ func (s *Service) fetchFullItem(client *api.Client, objType MetadataType, uid st
 	case TypeDataElements:
 		endpoint = fmt.Sprintf("/api/dataElements/%s.json", uid)
 		params = map[string]string{"fields": "id,code,displayName,name,shortName,valueType,aggregationType,domainType,categoryCombo[id],optionSet[id]"}
+	case TypeDataElementGroups:
+		endpoint = fmt.Sprintf("/api/dataElementGroups/%s.json", uid)
+		params = map[string]string{"fields": "id,code,displayName,name,shortName,dataElements[id]"}
+	case TypeDataElementGroupSets:
+		endpoint = fmt.Sprintf("/api/dataElementGroupSets/%s.json", uid)
+		params = map[string]string{"fields": "id,code,displayName,name,shortName,description,compulsory,dataDimension,dataElementGroups[id]"}
 	case TypeDataSets:
 		endpoint = fmt.Sprintf("/api/dataSets/%s.json", uid)
-		params = map[string]string{"fields": "id,code,displayName,name,shortName,periodType,categoryCombo[id],dataSetElements[dataElement[id]]"}
+		params = map[string]string{"fields": "id,code,displayName,name,shortName,periodType,categoryCombo[id],dataSetElements[dataElement[id],categoryCombo[id]],compulsoryDataElementOperands[dataElement[id],categoryOptionCombo[id]],sections[id]"}
+	case TypeSections:
+		endpoint = fmt.Sprintf("/api/sections/%s.json", uid)
+		params = map[string]string{"fields": "id,code,displayName,name,description,sortOrder,showRowTotals,showColumnTotals,dataSet[id],dataElements[id],greyedFields[dataElement[id],categoryOptionCombo[id]]"}
 	case TypeOrganisationUnits:
 		endpoint = fmt.Sprintf("/api/organisationUnits/%s.json", uid)
-		params = map[string]string{"fields": "id,code,displayName,name,parent[id]"}
+		params = map[string]string{"fields": "id,code,displayName,name,shortName,openingDate,parent[id]"}
+	case TypeCategoryOptionCombos:
+		endpoint = fmt.Sprintf("/api/categoryOptionCombos/%s.json", uid)
+		params = map[string]string{"fields": "id,code,displayName,name,categoryCombo[id],categoryOptions[id]"}
 	case TypeOptionSets:
 		endpoint = fmt.Sprintf("/api/optionSets/%s.json", uid)
 		params = map[string]string{"fields": "id,code,displayName,name,valueType,options[id,code,displayName,name]"}
+	case TypeIndicatorTypes:
+		endpoint = fmt.Sprintf("/api/indicatorTypes/%s.json", uid)
+		params = map[string]string{"fields": "id,code,displayName,name,factor,number"}
+	case TypeIndicators:
+		endpoint = fmt.Sprintf("/api/indicators/%s.json", uid)
+		params = map[string]string{"fields": "id,code,displayName,name,shortName,description,annualized,decimals,indicatorType[id],numerator,numeratorDescription,denominator,denominatorDescription"}
+	case TypeOptionGroups:
+		endpoint = fmt.Sprintf("/api/optionGroups/%s.json", uid)
+		params = map[string]string{"fields": "id,code,displayName,name,shortName,optionSet[id],options[id]"}
+	case TypeValidationRules:
+		endpoint = fmt.Sprintf("/api/validationRules/%s.json", uid)
+		params = map[string]string{"fields": "id,code,displayName,name,description,instruction,importance,operator,periodType,organisationUnitLevels,skipFormValidation,leftSide[expression,description,missingValueStrategy],rightSide[expression,description,missingValueStrategy]"}
+	case TypeProgramIndicators:
+		endpoint = fmt.Sprintf("/api/programIndicators/%s.json", uid)
+		params = map[string]string{"fields": "id,code,displayName,name,shortName,description,program[id],expression,filter,analyticsType,aggregationType,decimals"}
 	default:
 		return nil
 	}
+	if shareableTypes[objType] {
+		params["fields"] += "," + sharingFields
+	}
 
 	resp, err := client.Get(endpoint, params)
 	if err != nil {
@@ -775,6 +1132,9 @@ func (s *Service) buildMinimalItem(objType MetadataType, full map[string]interfa
 			minimal["categoryOptions"] = remapped
 		}
 
+	case TypeCategoryCombos:
+		minimal["categories"] = s.remapRefs(full["categories"], TypeCategories, mappings)
+
 	case TypeDataElements:
 		if val, ok := full["valueType"]; ok {
 			minimal["valueType"] = val
@@ -797,11 +1157,254 @@ func (s *Service) buildMinimalItem(objType MetadataType, full map[string]interfa
 				}
 			}
 		}
+		if optSet, ok := full["optionSet"].(map[string]interface{}); ok {
+			if id := getStringOr(optSet, "id", ""); id != "" {
+				minimal["optionSet"] = map[string]interface{}{
+					"id": s.remapUID(TypeOptionSets, id, mappings),
+				}
+			}
+		}
+
+	case TypeDataElementGroups:
+		minimal["dataElements"] = s.remapRefs(full["dataElements"], TypeDataElements, mappings)
+
+	case TypeDataElementGroupSets:
+		for _, field := range []string{"description", "compulsory", "dataDimension"} {
+			if val, ok := full[field]; ok && val != nil {
+				minimal[field] = val
+			}
+		}
+		minimal["dataElementGroups"] = s.remapRefs(full["dataElementGroups"], TypeDataElementGroups, mappings)
+
+	case TypeDataSets:
+		if val, ok := full["periodType"]; ok {
+			minimal["periodType"] = val
+		}
+		if cc, ok := full["categoryCombo"].(map[string]interface{}); ok {
+			if id := getStringOr(cc, "id", ""); id != "" {
+				minimal["categoryCombo"] = map[string]interface{}{
+					"id": s.remapUID(TypeCategoryCombos, id, mappings),
+				}
+			}
+		}
+		// Keep dataSetElements in source order, with any per-element category combo override
+		if dses, ok := full["dataSetElements"].([]interface{}); ok {
+			remapped := []map[string]interface{}{}
+			for _, dse := range dses {
+				dseMap, ok := dse.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				de, _ := dseMap["dataElement"].(map[string]interface{})
+				deID := getStringOr(de, "id", "")
+				if deID == "" {
+					continue
+				}
+				item := map[string]interface{}{
+					"dataElement": map[string]interface{}{"id": s.remapUID(TypeDataElements, deID, mappings)},
+				}
+				if cc, ok := dseMap["categoryCombo"].(map[string]interface{}); ok {
+					if id := getStringOr(cc, "id", ""); id != "" {
+						item["categoryCombo"] = map[string]interface{}{"id": s.remapUID(TypeCategoryCombos, id, mappings)}
+					}
+				}
+				remapped = append(remapped, item)
+			}
+			minimal["dataSetElements"] = remapped
+		}
+		if operands, ok := full["compulsoryDataElementOperands"].([]interface{}); ok {
+			minimal["compulsoryDataElementOperands"] = s.remapOperands(operands, mappings)
+		}
+
+	case TypeSections:
+		for _, field := range []string{"description", "sortOrder", "showRowTotals", "showColumnTotals"} {
+			if val, ok := full[field]; ok && val != nil {
+				minimal[field] = val
+			}
+		}
+		if ds, ok := full["dataSet"].(map[string]interface{}); ok {
+			if id := getStringOr(ds, "id", ""); id != "" {
+				minimal["dataSet"] = map[string]interface{}{
+					"id": s.remapUID(TypeDataSets, id, mappings),
+				}
+			}
+		}
+		// Section data element order is the form's row order, so preserve it exactly
+		if des, ok := full["dataElements"].([]interface{}); ok {
+			remapped := []map[string]interface{}{}
+			for _, de := range des {
+				if deMap, ok := de.(map[string]interface{}); ok {
+					if id := getStringOr(deMap, "id", ""); id != "" {
+						remapped = append(remapped, map[string]interface{}{
+							"id": s.remapUID(TypeDataElements, id, mappings),
+						})
+					}
+				}
+			}
+			minimal["dataElements"] = remapped
+		}
+		if greyed, ok := full["greyedFields"].([]interface{}); ok {
+			minimal["greyedFields"] = s.remapOperands(greyed, mappings)
+		}
+
+	case TypeOrganisationUnits:
+		if val, ok := full["openingDate"]; ok {
+			minimal["openingDate"] = val
+		}
+		// Remap parent
+		if parent, ok := full["parent"].(map[string]interface{}); ok {
+			if id := getStringOr(parent, "id", ""); id != "" {
+				minimal["parent"] = map[string]interface{}{
+					"id": s.remapUID(TypeOrganisationUnits, id, mappings),
+				}
+			}
+		}
+
+	case TypeCategoryOptionCombos:
+		// Remap category combo and options
+		if cc, ok := full["categoryCombo"].(map[string]interface{}); ok {
+			if id := getStringOr(cc, "id", ""); id != "" {
+				minimal["categoryCombo"] = map[string]interface{}{
+					"id": s.remapUID(TypeCategoryCombos, id, mappings),
+				}
+			}
+		}
+		if opts, ok := full["categoryOptions"].([]interface{}); ok {
+			remapped := []map[string]interface{}{}
+			for _, opt := range opts {
+				if optMap, ok := opt.(map[string]interface{}); ok {
+					if id := getStringOr(optMap, "id", ""); id != "" {
+						remapped = append(remapped, map[string]interface{}{
+							"id": s.remapUID(TypeCategoryOptions, id, mappings),
+						})
+					}
+				}
+			}
+			minimal["categoryOptions"] = remapped
+		}
+
+	case TypeIndicatorTypes:
+		for _, field := range []string{"factor", "number"} {
+			if val, ok := full[field]; ok && val != nil {
+				minimal[field] = val
+			}
+		}
+
+	case TypeIndicators:
+		for _, field := range []string{"description", "annualized", "decimals", "numeratorDescription", "denominatorDescription"} {
+			if val, ok := full[field]; ok && val != nil {
+				minimal[field] = val
+			}
+		}
+		if it, ok := full["indicatorType"].(map[string]interface{}); ok {
+			if id := getStringOr(it, "id", ""); id != "" {
+				minimal["indicatorType"] = map[string]interface{}{
+					"id": s.remapUID(TypeIndicatorTypes, id, mappings),
+				}
+			}
+		}
+		// Numerator and denominator reference data elements, option combos and datasets by UID
+		for _, field := range []string{"numerator", "denominator"} {
+			if expr, ok := getString(full, field); ok {
+				minimal[field] = s.remapExpression(expr, false, mappings)
+			}
+		}
+
+	case TypeOptionGroups:
+		if optSet, ok := full["optionSet"].(map[string]interface{}); ok {
+			if id := getStringOr(optSet, "id", ""); id != "" {
+				minimal["optionSet"] = map[string]interface{}{
+					"id": s.remapUID(TypeOptionSets, id, mappings),
+				}
+			}
+		}
+		minimal["options"] = s.remapRefs(full["options"], TypeOptions, mappings)
+
+	case TypeValidationRules:
+		for _, field := range []string{"description", "instruction", "importance", "operator", "periodType", "organisationUnitLevels", "skipFormValidation"} {
+			if val, ok := full[field]; ok && val != nil {
+				minimal[field] = val
+			}
+		}
+		for _, field := range []string{"leftSide", "rightSide"} {
+			side, ok := full[field].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			remapped := map[string]interface{}{}
+			for k, v := range side {
+				remapped[k] = v
+			}
+			if expr, ok := getString(side, "expression"); ok {
+				remapped["expression"] = s.remapExpression(expr, false, mappings)
+			}
+			minimal[field] = remapped
+		}
+
+	case TypeProgramIndicators:
+		for _, field := range []string{"description", "analyticsType", "aggregationType", "decimals"} {
+			if val, ok := full[field]; ok && val != nil {
+				minimal[field] = val
+			}
+		}
+		// Programs aren't synced, so the program must already exist in the destination
+		if program, ok := full["program"].(map[string]interface{}); ok {
+			if id := getStringOr(program, "id", ""); id != "" {
+				minimal["program"] = map[string]interface{}{"id": id}
+			}
+		}
+		for _, field := range []string{"expression", "filter"} {
+			if expr, ok := getString(full, field); ok {
+				minimal[field] = s.remapExpression(expr, true, mappings)
+			}
+		}
 	}
 
 	return minimal
 }
 
+// remapRefs remaps a list of {id} references of the given type to destination UIDs
+func (s *Service) remapRefs(refs interface{}, refType MetadataType, mappings map[MetadataType]map[string]string) []map[string]interface{} {
+	remapped := []map[string]interface{}{}
+	list, _ := refs.([]interface{})
+	for _, ref := range list {
+		if refMap, ok := ref.(map[string]interface{}); ok {
+			if id := getStringOr(refMap, "id", ""); id != "" {
+				remapped = append(remapped, map[string]interface{}{
+					"id": s.remapUID(refType, id, mappings),
+				})
+			}
+		}
+	}
+	return remapped
+}
+
+// remapOperands remaps data element operands ({dataElement, categoryOptionCombo}) to destination UIDs
+func (s *Service) remapOperands(operands []interface{}, mappings map[MetadataType]map[string]string) []map[string]interface{} {
+	remapped := []map[string]interface{}{}
+	for _, op := range operands {
+		opMap, ok := op.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		de, _ := opMap["dataElement"].(map[string]interface{})
+		deID := getStringOr(de, "id", "")
+		if deID == "" {
+			continue
+		}
+		item := map[string]interface{}{
+			"dataElement": map[string]interface{}{"id": s.remapUID(TypeDataElements, deID, mappings)},
+		}
+		if coc, ok := opMap["categoryOptionCombo"].(map[string]interface{}); ok {
+			if id := getStringOr(coc, "id", ""); id != "" {
+				item["categoryOptionCombo"] = map[string]interface{}{"id": s.remapUID(TypeCategoryOptionCombos, id, mappings)}
+			}
+		}
+		remapped = append(remapped, item)
+	}
+	return remapped
+}
+
 // remapUID applies mapping if exists, otherwise returns original
 func (s *Service) remapUID(objType MetadataType, uid string, mappings map[MetadataType]map[string]string) string {
 	if mappings == nil || mappings[objType] == nil {
@@ -853,7 +1456,15 @@ func (s *Service) fetchRequiredFields(client *api.Client, types []MetadataType)
 		TypeCategoryOptionCombos: "CategoryOptionCombo",
 		TypeOptionSets:           "OptionSet",
 		TypeDataElements:         "DataElement",
+		TypeDataElementGroups:    "DataElementGroup",
+		TypeDataElementGroupSets: "DataElementGroupSet",
 		TypeDataSets:             "DataSet",
+		TypeSections:             "Section",
+		TypeIndicatorTypes:       "IndicatorType",
+		TypeIndicators:           "Indicator",
+		TypeOptionGroups:         "OptionGroup",
+		TypeValidationRules:      "ValidationRule",
+		TypeProgramIndicators:    "ProgramIndicator",
 	}
 
 	for _, t := range types {
@@ -919,6 +1530,43 @@ func getDisplayName(m map[string]interface{}) string {
 	return getStringOr(m, "name", "")
 }
 
+// collisionNameThreshold is the name similarity below which two objects sharing a UID
+// are considered different objects
+const collisionNameThreshold = 0.4
+
+// detectCollision reports whether a source and destination object with the same UID
+// look like different objects: their codes disagree, or (without codes to go by)
+// their names are very different. Matching codes vouch for the pair.
+func detectCollision(sitem, ditem map[string]interface{}) (CollisionItem, bool) {
+	scode := getStringOr(sitem, "code", "")
+	dcode := getStringOr(ditem, "code", "")
+	sname := getDisplayName(sitem)
+	dname := getDisplayName(ditem)
+
+	var reason string
+	switch {
+	case scode != "" && dcode != "":
+		if !strings.EqualFold(scode, dcode) {
+			reason = fmt.Sprintf("code %q in source but %q in destination", scode, dcode)
+		}
+	case sname != "" && dname != "":
+		if score := nameSimilarity(sname, dname); score < collisionNameThreshold {
+			reason = fmt.Sprintf("names differ (%q vs %q, similarity %.2f)", sname, dname, score)
+		}
+	}
+	if reason == "" {
+		return CollisionItem{}, false
+	}
+
+	id := getStringOr(sitem, "id", "")
+	return CollisionItem{
+		ID:     id,
+		Source: SuggestionDetail{ID: id, Code: scode, Name: sname},
+		Dest:   SuggestionDetail{ID: id, Code: dcode, Name: dname},
+		Reason: reason,
+	}, true
+}
+
 func equalValues(a, b interface{}) bool {
 	// Simple equality check - could be enhanced for deep comparison
 	return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
@@ -933,7 +1581,15 @@ func getCriticalFields(objType MetadataType) []string {
 		TypeCategoryOptionCombos: {"displayName", "categoryCombo"},
 		TypeOptionSets:           {"displayName", "options"},
 		TypeDataElements:         {"displayName", "valueType", "categoryCombo", "optionSet"},
+		TypeDataElementGroups:    {"displayName", "dataElements"},
+		TypeDataElementGroupSets: {"displayName", "dataElementGroups"},
 		TypeDataSets:             {"displayName", "periodType", "categoryCombo", "dataSetElements"},
+		TypeSections:             {"displayName", "dataSet", "sortOrder", "dataElements"},
+		TypeIndicatorTypes:       {"displayName", "factor"},
+		TypeIndicators:           {"displayName", "indicatorType", "numerator", "denominator"},
+		TypeOptionGroups:         {"displayName", "optionSet", "options"},
+		TypeValidationRules:      {"displayName", "operator", "leftSide", "rightSide"},
+		TypeProgramIndicators:    {"displayName", "program", "expression", "filter"},
 	}
 	if f, ok := fields[objType]; ok {
 		return f
@@ -941,35 +1597,68 @@ func getCriticalFields(objType MetadataType) []string {
 	return []string{"displayName"}
 }
 
+// nameSimilarity scores two names from 0 to 1 by normalized edit distance, ignoring
+// case and extra whitespace. Names are also compared with their words sorted, so
+// "Malaria Cases" and "Cases of Malaria" score well; the higher score wins.
 func nameSimilarity(a, b string) float64 {
-	a = strings.ToLower(a)
-	b = strings.ToLower(b)
+	a = strings.Join(strings.Fields(strings.ToLower(a)), " ")
+	b = strings.Join(strings.Fields(strings.ToLower(b)), " ")
 
 	if a == b {
 		return 1.0
 	}
 
-	// Simple Levenshtein-inspired similarity (simplified)
-	longer := a
-	shorter := b
-	if len(b) > len(a) {
-		longer = b
-		shorter = a
+	score := editSimilarity(a, b)
+	if sorted := editSimilarity(sortedWords(a), sortedWords(b)); sorted > score {
+		score = sorted
 	}
+	return score
+}
 
-	if len(longer) == 0 {
+// sortedWords returns s with its words in alphabetical order
+func sortedWords(s string) string {
+	words := strings.Fields(s)
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// editSimilarity is 1 - editDistance/len(longer), over runes
+func editSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
 		return 1.0
 	}
+	return 1 - float64(editDistance(ra, rb))/float64(longest)
+}
 
-	// Count matching characters
-	matches := 0
-	for i := 0; i < len(shorter); i++ {
-		if i < len(longer) && shorter[i] == longer[i] {
-			matches++
+// editDistance is the optimal string alignment distance: insertions, deletions,
+// substitutions and transpositions of adjacent characters each cost 1
+func editDistance(a, b []rune) int {
+	// Three rolling rows: two back (for transpositions), previous and current
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
 		}
+		prev2, prev, curr = prev, curr, prev2
 	}
 
-	return float64(matches) / float64(len(longer))
+	return prev[len(b)]
 }
 
 func round(val float64, precision int) float64 {
@@ -979,10 +1668,3 @@ func round(val float64, precision int) float64 {
 	}
 	return float64(int(val*multiplier+0.5)) / multiplier
 }
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
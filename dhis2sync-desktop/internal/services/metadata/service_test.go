@@ -0,0 +1,333 @@
+package metadata
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"dhis2sync-desktop/internal/api/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareListsCollisions(t *testing.T) {
+	s := &Service{}
+
+	t.Run("Should flag a shared UID whose code differs", func(t *testing.T) {
+		src := []map[string]interface{}{{"id": "abcdefghij1", "code": "ANC1", "displayName": "ANC 1st visit"}}
+		dst := []map[string]interface{}{{"id": "abcdefghij1", "code": "MEASLES", "displayName": "Measles doses"}}
+
+		result := s.compareLists(src, dst, TypeDataElements)
+
+		require.Len(t, result.Collisions, 1)
+		assert.Equal(t, "abcdefghij1", result.Collisions[0].ID)
+		assert.Equal(t, "MEASLES", result.Collisions[0].Dest.Code)
+		assert.Contains(t, result.Collisions[0].Reason, "code")
+		assert.Empty(t, result.Conflicts, "A collision should not also be reported as a conflict")
+		assert.Empty(t, result.Missing)
+	})
+
+	t.Run("Should flag very different names when codes are absent", func(t *testing.T) {
+		src := []map[string]interface{}{{"id": "abcdefghij2", "displayName": "Malaria cases"}}
+		dst := []map[string]interface{}{{"id": "abcdefghij2", "displayName": "Bed occupancy"}}
+
+		result := s.compareLists(src, dst, TypeDataElements)
+
+		require.Len(t, result.Collisions, 1)
+		assert.Contains(t, result.Collisions[0].Reason, "names differ")
+	})
+
+	t.Run("Should treat a renamed object with the same code as a conflict", func(t *testing.T) {
+		src := []map[string]interface{}{{"id": "abcdefghij3", "code": "OPD", "displayName": "OPD attendance"}}
+		dst := []map[string]interface{}{{"id": "abcdefghij3", "code": "OPD", "displayName": "Outpatient visits"}}
+
+		result := s.compareLists(src, dst, TypeCategoryOptions)
+
+		assert.Empty(t, result.Collisions)
+		require.Len(t, result.Conflicts, 1)
+		assert.Contains(t, result.Conflicts[0].Diffs, "displayName")
+	})
+
+	t.Run("Should not flag near-identical names", func(t *testing.T) {
+		src := []map[string]interface{}{{"id": "abcdefghij4", "displayName": "Malaria cases"}}
+		dst := []map[string]interface{}{{"id": "abcdefghij4", "displayName": "Malaria cases (new)"}}
+
+		result := s.compareLists(src, dst, TypeCategoryOptions)
+
+		assert.Empty(t, result.Collisions)
+	})
+}
+
+func TestBuildMinimalItemDataElementGroups(t *testing.T) {
+	s := &Service{}
+	mappings := map[MetadataType]map[string]string{
+		TypeDataElements:      {"srcDE000001": "dstDE000001"},
+		TypeDataElementGroups: {"srcDEG00001": "dstDEG00001"},
+	}
+
+	t.Run("Should remap group member data elements", func(t *testing.T) {
+		full := map[string]interface{}{
+			"id":   "grpANC00001",
+			"name": "ANC",
+			"dataElements": []interface{}{
+				map[string]interface{}{"id": "srcDE000001"},
+				map[string]interface{}{"id": "unmappedDE1"},
+			},
+		}
+
+		minimal := s.buildMinimalItem(TypeDataElementGroups, full, mappings)
+
+		assert.Equal(t, []map[string]interface{}{{"id": "dstDE000001"}, {"id": "unmappedDE1"}}, minimal["dataElements"])
+	})
+
+	t.Run("Should remap group set member groups", func(t *testing.T) {
+		full := map[string]interface{}{
+			"id":            "grpSet00001",
+			"name":          "Programme",
+			"dataDimension": true,
+			"dataElementGroups": []interface{}{
+				map[string]interface{}{"id": "srcDEG00001"},
+			},
+		}
+
+		minimal := s.buildMinimalItem(TypeDataElementGroupSets, full, mappings)
+
+		assert.Equal(t, []map[string]interface{}{{"id": "dstDEG00001"}}, minimal["dataElementGroups"])
+		assert.Equal(t, true, minimal["dataDimension"])
+	})
+}
+
+func TestBuildMinimalItemIndicatorsAndValidationRules(t *testing.T) {
+	s := &Service{}
+	mappings := map[MetadataType]map[string]string{
+		TypeDataElements:   {"srcDE000001": "dstDE000001"},
+		TypeIndicatorTypes: {"srcIT000001": "dstIT000001"},
+	}
+
+	t.Run("Should remap an indicator's type and expressions", func(t *testing.T) {
+		full := map[string]interface{}{
+			"id":            "indANC00001",
+			"name":          "ANC coverage",
+			"annualized":    true,
+			"indicatorType": map[string]interface{}{"id": "srcIT000001"},
+			"numerator":     "#{srcDE000001}",
+			"denominator":   "#{popDE000001}",
+		}
+
+		minimal := s.buildMinimalItem(TypeIndicators, full, mappings)
+
+		assert.Equal(t, map[string]interface{}{"id": "dstIT000001"}, minimal["indicatorType"])
+		assert.Equal(t, "#{dstDE000001}", minimal["numerator"])
+		assert.Equal(t, "#{popDE000001}", minimal["denominator"])
+		assert.Equal(t, true, minimal["annualized"])
+	})
+
+	t.Run("Should remap both sides of a validation rule", func(t *testing.T) {
+		full := map[string]interface{}{
+			"id":         "vrANC000001",
+			"name":       "ANC 1 >= ANC 4",
+			"operator":   "greater_than_or_equal_to",
+			"importance": "MEDIUM",
+			"periodType": "Monthly",
+			"leftSide":   map[string]interface{}{"expression": "#{srcDE000001}", "missingValueStrategy": "NEVER_SKIP"},
+			"rightSide":  map[string]interface{}{"expression": "#{anc4DE00001}"},
+		}
+
+		minimal := s.buildMinimalItem(TypeValidationRules, full, mappings)
+
+		assert.Equal(t, map[string]interface{}{"expression": "#{dstDE000001}", "missingValueStrategy": "NEVER_SKIP"}, minimal["leftSide"])
+		assert.Equal(t, map[string]interface{}{"expression": "#{anc4DE00001}"}, minimal["rightSide"])
+		assert.Equal(t, "greater_than_or_equal_to", minimal["operator"])
+		assert.Equal(t, "#{srcDE000001}", full["leftSide"].(map[string]interface{})["expression"], "source item is left untouched")
+	})
+}
+
+func TestNameSimilarity(t *testing.T) {
+	t.Run("Should score a transposition as a single edit", func(t *testing.T) {
+		// "Malaira" is one swap away from "Malaria": 1 edit over 13 runes
+		assert.InDelta(t, 1-1.0/13, nameSimilarity("Malaria cases", "Malaira cases"), 0.001)
+	})
+
+	t.Run("Should not be thrown off by an inserted character", func(t *testing.T) {
+		assert.GreaterOrEqual(t, nameSimilarity("ANC 1st visit", "ANC 1st  visit"), 0.99, "Extra whitespace is ignored")
+		assert.GreaterOrEqual(t, nameSimilarity("ANC1st visit", "ANC 1st visit"), 0.9)
+	})
+
+	t.Run("Should recognise reordered words", func(t *testing.T) {
+		assert.GreaterOrEqual(t, nameSimilarity("Malaria Cases", "Cases of Malaria"), 0.7)
+	})
+
+	t.Run("Should keep unrelated names apart", func(t *testing.T) {
+		assert.Less(t, nameSimilarity("Malaria cases", "Bed occupancy"), 0.4)
+		assert.Equal(t, 1.0, nameSimilarity("OPD Visits", "opd visits"))
+		assert.Equal(t, 1.0, nameSimilarity("", ""))
+	})
+}
+
+func TestCompareListsNameSuggestions(t *testing.T) {
+	s := &Service{}
+
+	t.Run("Should suggest a reworded destination object with its score as confidence", func(t *testing.T) {
+		src := []map[string]interface{}{{"id": "srcDE000001", "displayName": "Malaria Cases"}}
+		dst := []map[string]interface{}{
+			{"id": "dstDE000001", "displayName": "Cases of Malaria"},
+			{"id": "dstDE000002", "displayName": "Bed occupancy"},
+		}
+
+		result := s.compareLists(src, dst, TypeDataElements)
+
+		require.Len(t, result.Suggestions, 1)
+		suggestion := result.Suggestions[0]
+		assert.Equal(t, "dstDE000001", suggestion.Dest.ID)
+		assert.Equal(t, "name", suggestion.By)
+		assert.Equal(t, round(nameSimilarity("Malaria Cases", "Cases of Malaria"), 3), suggestion.Confidence)
+	})
+}
+
+func TestCountDifferences(t *testing.T) {
+	s := &Service{}
+	src := []map[string]interface{}{
+		{"id": "abcdefghij1", "code": "ANC1", "displayName": "ANC 1st visit"},
+		{"id": "abcdefghij2", "code": "OPD", "displayName": "OPD attendance"},
+		{"id": "abcdefghij3", "code": "MAL", "displayName": "Malaria cases"},
+		{"id": "abcdefghij4", "displayName": "Bed occupancy rate"},
+		{"id": "abcdefghij5", "displayName": "Completely new element"},
+	}
+	dst := []map[string]interface{}{
+		{"id": "abcdefghij1", "code": "MEASLES", "displayName": "Measles doses"},
+		{"id": "abcdefghij2", "code": "OPD", "displayName": "Outpatient visits"},
+		{"id": "zyxwvutsrq3", "code": "MAL", "displayName": "Malaria cases"},
+		{"id": "zyxwvutsrq4", "displayName": "Bed occupancy rates"},
+	}
+
+	t.Run("Should match the lengths of the detailed lists", func(t *testing.T) {
+		result := s.compareLists(src, dst, TypeDataElements)
+		counts := countDifferences(src, dst, TypeDataElements)
+
+		assert.Equal(t, DiffCounts{
+			Source:      len(src),
+			Dest:        len(dst),
+			Missing:     len(result.Missing),
+			Conflicts:   len(result.Conflicts),
+			Suggestions: len(result.Suggestions),
+			Collisions:  len(result.Collisions),
+		}, counts)
+		assert.Equal(t, 1, counts.Collisions)
+		assert.Equal(t, 2, counts.Missing)
+		assert.Equal(t, 2, counts.Suggestions)
+	})
+}
+
+func TestExportDiffResults(t *testing.T) {
+	s := &Service{progressStore: map[string]*DiffProgress{
+		"running": {TaskID: "running", Status: "running"},
+		"done": {TaskID: "done", Status: "completed", Results: map[MetadataType]ComparisonResult{
+			TypeDataElements: {
+				Missing: []MissingItem{{ID: "de1", Name: "ANC 1st visit"}},
+				Conflicts: []ConflictItem{{ID: "de2", Name: "Malaria cases", Diffs: map[string]map[string]interface{}{
+					"valueType": {"source": "INTEGER", "dest": "NUMBER"},
+					"code":      {"source": "MAL", "dest": "MAL_CASES"},
+				}}},
+				Suggestions: []SuggestionItem{{
+					Source: SuggestionDetail{ID: "de3", Name: "BCG doses"}, Dest: SuggestionDetail{ID: "deX", Name: "BCG doses given"},
+					Confidence: 0.875, By: "name",
+				}},
+			},
+		}},
+	}}
+
+	t.Run("Should write one CSV row per finding", func(t *testing.T) {
+		out, err := s.ExportDiffResults("done", "csv")
+
+		require.NoError(t, err)
+		assert.Equal(t, "type,category,source_id,source_name,dest_id,dest_name,confidence,diff_fields,reason\n"+
+			"dataElements,missing,de1,ANC 1st visit,,,,,\n"+
+			"dataElements,conflict,de2,Malaria cases,de2,,,code;valueType,\n"+
+			"dataElements,suggestion,de3,BCG doses,deX,BCG doses given,0.88,,matched by name\n", out)
+	})
+
+	t.Run("Should export JSON", func(t *testing.T) {
+		out, err := s.ExportDiffResults("done", "json")
+
+		require.NoError(t, err)
+		assert.Contains(t, out, `"dataElements": {`)
+	})
+
+	t.Run("Should refuse diffs that are not completed", func(t *testing.T) {
+		_, err := s.ExportDiffResults("running", "csv")
+		assert.ErrorContains(t, err, "not completed")
+
+		_, err = s.ExportDiffResults("unknown", "csv")
+		assert.ErrorContains(t, err, "task not found")
+	})
+}
+
+func TestFetchTypePaging(t *testing.T) {
+	s := &Service{}
+
+	// page serves objects [from, to) under a pager for total objects
+	page := func(from, to, total int, withPager bool) map[string]interface{} {
+		items := []map[string]string{}
+		for i := from; i < to; i++ {
+			items = append(items, map[string]string{"id": fmt.Sprintf("coc%08d", i)})
+		}
+		body := map[string]interface{}{"categoryOptionCombos": items}
+		if withPager {
+			body["pager"] = map[string]int{"total": total, "pageCount": (total + metadataPageSize - 1) / metadataPageSize}
+		}
+		return body
+	}
+
+	t.Run("Should follow the pager and report progress", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/categoryOptionCombos.json": func(w http.ResponseWriter, r *http.Request) {
+				n, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				assert.Equal(t, "1000", r.URL.Query().Get("pageSize"))
+				assert.Equal(t, "id:asc", r.URL.Query().Get("order"))
+				from := (n - 1) * metadataPageSize
+				to := from + metadataPageSize
+				if to > 2500 {
+					to = 2500
+				}
+				apitest.JSON(http.StatusOK, page(from, to, 2500, true))(w, r)
+			},
+		})
+
+		var progress []string
+		items := s.fetchType(srv.Client(), TypeCategoryOptionCombos, func(fetched, total int) {
+			progress = append(progress, fmt.Sprintf("%d/%d", fetched, total))
+		})
+
+		assert.Len(t, items, 2500)
+		assert.Equal(t, "coc00002499", items[2499]["id"])
+		assert.Equal(t, 3, srv.Hits("/api/categoryOptionCombos.json"))
+		assert.Equal(t, []string{"1000/2500", "2000/2500", "2500/2500"}, progress)
+	})
+
+	t.Run("Should stop when the server returns no pager", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/categoryOptionCombos.json": apitest.JSON(http.StatusOK, page(0, metadataPageSize+5, 0, false)),
+		})
+
+		items := s.fetchType(srv.Client(), TypeCategoryOptionCombos, nil)
+
+		assert.Len(t, items, metadataPageSize+5)
+		assert.Equal(t, 1, srv.Hits("/api/categoryOptionCombos.json"))
+	})
+
+	t.Run("Should return nothing when a page fails", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/categoryOptionCombos.json": func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("page") == "1" {
+					apitest.JSON(http.StatusOK, page(0, metadataPageSize, 1500, true))(w, r)
+					return
+				}
+				apitest.Raw(http.StatusInternalServerError, "boom")(w, r)
+			},
+		})
+
+		assert.Empty(t, s.fetchType(srv.Client(), TypeCategoryOptionCombos, nil))
+	})
+}
@@ -0,0 +1,153 @@
+package metadata
+
+import (
+	"fmt"
+	"sort"
+)
+
+// sharingFields are the source fields a payload item's sharing is copied from: the
+// sharing object of DHIS2 2.36+ and the access fields older versions use
+const sharingFields = "sharing,publicAccess,externalAccess,userGroupAccesses[id,access]"
+
+// shareableTypes are the types DHIS2 keeps sharing settings for
+var shareableTypes = map[MetadataType]bool{
+	TypeCategoryOptions:      true,
+	TypeCategories:           true,
+	TypeCategoryCombos:       true,
+	TypeOptionSets:           true,
+	TypeDataElements:         true,
+	TypeDataElementGroups:    true,
+	TypeDataElementGroupSets: true,
+	TypeDataSets:             true,
+	TypeIndicators:           true,
+	TypeOptionGroups:         true,
+	TypeValidationRules:      true,
+	TypeProgramIndicators:    true,
+}
+
+// buildPayloadItem builds a minimal payload item, carrying over the source object's
+// sharing when preserveSharing is set. Without sharing the destination applies its
+// defaults, which may be public.
+func (s *Service) buildPayloadItem(objType MetadataType, full map[string]interface{}, mappings map[MetadataType]map[string]string, preserveSharing bool) map[string]interface{} {
+	minimal := s.buildMinimalItem(objType, full, mappings)
+	if minimal != nil && preserveSharing && shareableTypes[objType] {
+		s.copySharing(minimal, full, mappings)
+	}
+	return minimal
+}
+
+// copySharing copies public, external and user group access from full to minimal with
+// user group UIDs remapped. Access granted to individual users is dropped, since users
+// aren't mapped between instances and an unknown user fails the import; the owner is
+// left for the destination to set to the importing user.
+func (s *Service) copySharing(minimal, full map[string]interface{}, mappings map[MetadataType]map[string]string) {
+	if sharing, ok := full["sharing"].(map[string]interface{}); ok {
+		copied := map[string]interface{}{}
+		for _, field := range []string{"public", "external"} {
+			if val, ok := sharing[field]; ok && val != nil {
+				copied[field] = val
+			}
+		}
+		userGroups := map[string]interface{}{}
+		groups, _ := sharing["userGroups"].(map[string]interface{})
+		for id, access := range groups {
+			accessMap, ok := access.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			destID := s.remapUID(TypeUserGroups, id, mappings)
+			userGroups[destID] = map[string]interface{}{"id": destID, "access": accessMap["access"]}
+		}
+		copied["userGroups"] = userGroups
+		minimal["sharing"] = copied
+	}
+
+	for _, field := range []string{"publicAccess", "externalAccess"} {
+		if val, ok := full[field]; ok && val != nil {
+			minimal[field] = val
+		}
+	}
+	if accesses, ok := full["userGroupAccesses"].([]interface{}); ok {
+		remapped := []map[string]interface{}{}
+		for _, access := range refMaps(accesses) {
+			if id := getStringOr(access, "id", ""); id != "" {
+				remapped = append(remapped, map[string]interface{}{
+					"id":     s.remapUID(TypeUserGroups, id, mappings),
+					"access": access["access"],
+				})
+			}
+		}
+		minimal["userGroupAccesses"] = remapped
+	}
+}
+
+// sharingKeys are the payload item fields copySharing sets
+var sharingKeys = []string{"sharing", "publicAccess", "externalAccess", "userGroupAccesses"}
+
+// dropUnknownUserGroups removes user group access to groups missing from destGroups (the
+// destination's groups by UID) from the payload's sharing, since an unknown group fails
+// the import. Returns a warning per dropped group.
+func dropUnknownUserGroups(payload map[MetadataType][]map[string]interface{}, destGroups map[string]map[string]interface{}) []string {
+	dropped := map[string]int{}
+	for _, items := range payload {
+		for _, item := range items {
+			droppedHere := map[string]bool{}
+			if sharing, ok := item["sharing"].(map[string]interface{}); ok {
+				groups, _ := sharing["userGroups"].(map[string]interface{})
+				for id := range groups {
+					if _, exists := destGroups[id]; !exists {
+						delete(groups, id)
+						droppedHere[id] = true
+					}
+				}
+			}
+			if accesses, ok := item["userGroupAccesses"].([]map[string]interface{}); ok {
+				kept := []map[string]interface{}{}
+				for _, access := range accesses {
+					id := getStringOr(access, "id", "")
+					if _, exists := destGroups[id]; !exists {
+						droppedHere[id] = true
+						continue
+					}
+					kept = append(kept, access)
+				}
+				item["userGroupAccesses"] = kept
+			}
+			for id := range droppedHere {
+				dropped[id]++
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(dropped))
+	for id := range dropped {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	warnings := make([]string, 0, len(ids))
+	for _, id := range ids {
+		warnings = append(warnings, fmt.Sprintf("User group %s isn't on the destination and has no mapping; its access was left out of %d objects", id, dropped[id]))
+	}
+	return warnings
+}
+
+// withoutSharing returns payload with the sharing fields removed from every item; the
+// items are copied, so payload itself is left as is
+func withoutSharing(payload map[MetadataType][]map[string]interface{}) map[MetadataType][]map[string]interface{} {
+	stripped := make(map[MetadataType][]map[string]interface{}, len(payload))
+	for t, items := range payload {
+		copies := make([]map[string]interface{}, 0, len(items))
+		for _, item := range items {
+			cp := make(map[string]interface{}, len(item))
+			for k, v := range item {
+				cp[k] = v
+			}
+			for _, key := range sharingKeys {
+				delete(cp, key)
+			}
+			copies = append(copies, cp)
+		}
+		stripped[t] = copies
+	}
+	return stripped
+}
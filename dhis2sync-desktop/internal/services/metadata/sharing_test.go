@@ -0,0 +1,113 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildPayloadItemSharing(t *testing.T) {
+	s := &Service{}
+	mappings := map[MetadataType]map[string]string{
+		TypeUserGroups: {"ugSrcData01": "ugDstData01"},
+	}
+	full := map[string]interface{}{
+		"id":        "deMalaria01",
+		"name":      "Malaria cases",
+		"valueType": "INTEGER",
+		"sharing": map[string]interface{}{
+			"owner":    "userAdmin01",
+			"public":   "r-------",
+			"external": false,
+			"users":    map[string]interface{}{"userAdmin01": map[string]interface{}{"id": "userAdmin01", "access": "rw------"}},
+			"userGroups": map[string]interface{}{
+				"ugSrcData01": map[string]interface{}{"id": "ugSrcData01", "access": "rwrw----"},
+				"ugUnmapped1": map[string]interface{}{"id": "ugUnmapped1", "access": "r-r-----"},
+			},
+		},
+		"publicAccess": "r-------",
+		"userGroupAccesses": []interface{}{
+			map[string]interface{}{"id": "ugSrcData01", "access": "rwrw----"},
+		},
+	}
+
+	t.Run("Should leave sharing out by default", func(t *testing.T) {
+		item := s.buildPayloadItem(TypeDataElements, full, mappings, false)
+
+		assert.NotContains(t, item, "sharing")
+		assert.NotContains(t, item, "publicAccess")
+		assert.NotContains(t, item, "userGroupAccesses")
+	})
+
+	t.Run("Should carry public and remapped user group access over", func(t *testing.T) {
+		item := s.buildPayloadItem(TypeDataElements, full, mappings, true)
+
+		assert.Equal(t, map[string]interface{}{
+			"public":   "r-------",
+			"external": false,
+			"userGroups": map[string]interface{}{
+				"ugDstData01": map[string]interface{}{"id": "ugDstData01", "access": "rwrw----"},
+				"ugUnmapped1": map[string]interface{}{"id": "ugUnmapped1", "access": "r-r-----"},
+			},
+		}, item["sharing"], "user access and the owner are dropped")
+		assert.Equal(t, "r-------", item["publicAccess"])
+		assert.Equal(t, []map[string]interface{}{{"id": "ugDstData01", "access": "rwrw----"}}, item["userGroupAccesses"])
+	})
+
+	t.Run("Should ignore sharing on types DHIS2 doesn't share", func(t *testing.T) {
+		item := s.buildPayloadItem(TypeOrganisationUnits, full, mappings, true)
+
+		assert.NotContains(t, item, "sharing")
+	})
+}
+
+func TestDropUnknownUserGroups(t *testing.T) {
+	payload := map[MetadataType][]map[string]interface{}{
+		TypeDataElements: {
+			{
+				"id": "deMalaria01",
+				"sharing": map[string]interface{}{
+					"public": "r-------",
+					"userGroups": map[string]interface{}{
+						"ugDstData01": map[string]interface{}{"id": "ugDstData01", "access": "rwrw----"},
+						"ugUnmapped1": map[string]interface{}{"id": "ugUnmapped1", "access": "r-r-----"},
+					},
+				},
+				"userGroupAccesses": []map[string]interface{}{
+					{"id": "ugDstData01", "access": "rwrw----"},
+					{"id": "ugUnmapped1", "access": "r-r-----"},
+				},
+			},
+			{"id": "deMalaria02"},
+		},
+	}
+	destGroups := map[string]map[string]interface{}{"ugDstData01": {"id": "ugDstData01"}}
+
+	warnings := dropUnknownUserGroups(payload, destGroups)
+
+	item := payload[TypeDataElements][0]
+	assert.Equal(t, map[string]interface{}{
+		"ugDstData01": map[string]interface{}{"id": "ugDstData01", "access": "rwrw----"},
+	}, item["sharing"].(map[string]interface{})["userGroups"])
+	assert.Equal(t, []map[string]interface{}{{"id": "ugDstData01", "access": "rwrw----"}}, item["userGroupAccesses"])
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "ugUnmapped1")
+	assert.Contains(t, warnings[0], "1 objects", "counted once per object")
+}
+
+func TestWithoutSharing(t *testing.T) {
+	payload := map[MetadataType][]map[string]interface{}{
+		TypeDataElements: {{
+			"id":                "deMalaria01",
+			"name":              "Malaria cases",
+			"sharing":           map[string]interface{}{"public": "r-------"},
+			"publicAccess":      "r-------",
+			"userGroupAccesses": []map[string]interface{}{},
+		}},
+	}
+
+	stripped := withoutSharing(payload)
+
+	assert.Equal(t, map[string]interface{}{"id": "deMalaria01", "name": "Malaria cases"}, stripped[TypeDataElements][0])
+	assert.Contains(t, payload[TypeDataElements][0], "sharing", "the original payload is left as is")
+}
@@ -12,7 +12,16 @@ const (
 	TypeOptionSets           MetadataType = "optionSets"
 	TypeOptions              MetadataType = "options"
 	TypeDataElements         MetadataType = "dataElements"
+	TypeDataElementGroups    MetadataType = "dataElementGroups"
+	TypeDataElementGroupSets MetadataType = "dataElementGroupSets"
 	TypeDataSets             MetadataType = "dataSets"
+	TypeSections             MetadataType = "sections"
+	TypeIndicatorTypes       MetadataType = "indicatorTypes"
+	TypeIndicators           MetadataType = "indicators"
+	TypeOptionGroups         MetadataType = "optionGroups"
+	TypeValidationRules      MetadataType = "validationRules"
+	TypeProgramIndicators    MetadataType = "programIndicators"
+	TypeUserGroups           MetadataType = "userGroups" // Not synced; mapped so sharing can be carried over
 )
 
 // MetadataObject represents a generic DHIS2 metadata object
@@ -42,6 +51,7 @@ type ComparisonResult struct {
 	Missing     []MissingItem     `json:"missing"`
 	Conflicts   []ConflictItem    `json:"conflicts"`
 	Suggestions []SuggestionItem  `json:"suggestions"`
+	Collisions  []CollisionItem   `json:"collisions"` // Same UID in both instances naming different objects
 }
 
 // MissingItem represents a metadata object missing in destination
@@ -59,6 +69,16 @@ type ConflictItem struct {
 	Diffs map[string]map[string]interface{} `json:"diffs"` // field -> {source: val, dest: val}
 }
 
+// CollisionItem flags a source object whose UID exists in the destination on what
+// looks like a different object. The missing-check treats it as present, so it's
+// never imported and data mapped by that UID lands on the wrong object.
+type CollisionItem struct {
+	ID     string           `json:"id"`
+	Source SuggestionDetail `json:"source"`
+	Dest   SuggestionDetail `json:"dest"`
+	Reason string           `json:"reason"`
+}
+
 // SuggestionItem represents a suggested mapping based on code or name similarity
 type SuggestionItem struct {
 	Source     SuggestionDetail `json:"source"`
@@ -88,6 +108,20 @@ type DiffProgress struct {
 	Messages    []string                    `json:"messages"`
 	Results     map[MetadataType]ComparisonResult `json:"results,omitempty"`
 	CompletedAt int64                       `json:"completed_at,omitempty"` // Unix timestamp
+
+	// Counts replaces Results for counts-only diffs
+	Counts map[MetadataType]DiffCounts `json:"counts,omitempty"`
+}
+
+// DiffCounts is a counts-only comparison of one type; the numbers match the
+// lengths of the corresponding ComparisonResult lists
+type DiffCounts struct {
+	Source      int `json:"source"` // Objects fetched from the source
+	Dest        int `json:"dest"`   // Objects fetched from the destination
+	Missing     int `json:"missing"`
+	Conflicts   int `json:"conflicts"`
+	Suggestions int `json:"suggestions"`
+	Collisions  int `json:"collisions"`
 }
 
 // MappingPair represents a source -> destination ID mapping for a type
@@ -120,6 +154,21 @@ type PayloadPreviewResponse struct {
 	Payload  map[MetadataType][]map[string]interface{} `json:"payload"`
 	Counts   map[MetadataType]int                      `json:"counts"`
 	Required map[MetadataType][]string                 `json:"required"` // Required fields per type
+
+	// Collisions are source objects left out of the payload because their UID names a different destination object
+	Collisions map[MetadataType][]CollisionItem `json:"collisions,omitempty"`
+	// Warnings report sharing left out of the payload, e.g. user groups the destination lacks
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// PayloadProgress tracks a background payload build started by StartBuildPayload
+type PayloadProgress struct {
+	TaskID      string                  `json:"task_id"`
+	Status      string                  `json:"status"`   // starting, running, completed, error
+	Progress    int                     `json:"progress"` // 0-100
+	Messages    []string                `json:"messages"`
+	Preview     *PayloadPreviewResponse `json:"preview,omitempty"` // Set once completed
+	CompletedAt int64                   `json:"completed_at,omitempty"`
 }
 
 // DryRunRequest performs a metadata import dry-run
@@ -146,6 +195,24 @@ type ImportReport struct {
 	Message     string                 `json:"message,omitempty"`
 	Error       string                 `json:"error,omitempty"`
 	Body        map[string]interface{} `json:"body,omitempty"`
+
+	// ApplyID identifies a resumable apply; pass it again to retry only what didn't import
+	ApplyID string `json:"apply_id,omitempty"`
+	// Skipped counts objects left out because an earlier attempt of the same apply imported them
+	Skipped int `json:"skipped,omitempty"`
+
+	// Conflicts flattens the object errors of an import DHIS2 rejected with HTTP 409
+	// (Status is then StatusConflicts)
+	Conflicts []ImportConflict `json:"conflicts,omitempty"`
+}
+
+// ImportConflict is one object-level error from a rejected metadata import
+type ImportConflict struct {
+	Type      string `json:"type,omitempty"` // DHIS2 class, e.g. "DataElement"
+	UID       string `json:"uid,omitempty"`
+	Property  string `json:"property,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"`
+	Message   string `json:"message"`
 }
 
 // TypeReport contains import statistics for a specific metadata type
@@ -157,8 +224,15 @@ type TypeReport struct {
 
 // ObjectReport contains import details for a single object
 type ObjectReport struct {
-	UID          string   `json:"uid"`
-	ErrorReports []string `json:"errorReports,omitempty"`
+	UID          string        `json:"uid"`
+	ErrorReports []ObjectError `json:"errorReports,omitempty"`
+}
+
+// ObjectError is one error DHIS2 reports for an object
+type ObjectError struct {
+	Message       string `json:"message"`
+	ErrorCode     string `json:"errorCode,omitempty"`
+	ErrorProperty string `json:"errorProperty,omitempty"`
 }
 
 // SchemaInfo contains required fields info for a metadata type
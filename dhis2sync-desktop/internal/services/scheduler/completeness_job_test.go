@@ -96,7 +96,7 @@ func TestCompletenessJobExecution(t *testing.T) {
 		}
 
 		// Execute job
-		service.runCompletenessJob(payload)
+		service.runCompletenessJob(payload, nil)
 
 		// Wait a bit for goroutine to start
 		time.Sleep(100 * time.Millisecond)
@@ -151,7 +151,7 @@ func TestCompletenessJobExecution(t *testing.T) {
 			"parent_org_units": []interface{}{"ou003"},
 		}
 
-		service.runCompletenessJob(payload)
+		service.runCompletenessJob(payload, nil)
 		time.Sleep(100 * time.Millisecond)
 
 		assert.True(t, mockService.startAssessmentCalled)
@@ -196,7 +196,7 @@ func TestCompletenessJobExecution(t *testing.T) {
 			"required_elements": []interface{}{"de001", "de002", "de003"},
 		}
 
-		service.runCompletenessJob(payload)
+		service.runCompletenessJob(payload, nil)
 		time.Sleep(100 * time.Millisecond)
 
 		assert.True(t, mockService.startAssessmentCalled)
@@ -221,7 +221,7 @@ func TestCompletenessJobExecution(t *testing.T) {
 			// Missing dataset_id, periods, parent_org_units
 		}
 
-		service.runCompletenessJob(payload)
+		service.runCompletenessJob(payload, nil)
 		time.Sleep(100 * time.Millisecond)
 
 		assert.False(t, mockService.startAssessmentCalled, "Should not call StartAssessment with incomplete payload")
@@ -271,7 +271,7 @@ func TestCompletenessJobProgressTracking(t *testing.T) {
 			"parent_org_units": []interface{}{"ou999"},
 		}
 
-		service.runCompletenessJob(payload)
+		service.runCompletenessJob(payload, nil)
 
 		// Wait for initial progress poll
 		time.Sleep(6 * time.Second)
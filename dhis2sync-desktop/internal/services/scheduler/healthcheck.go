@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"dhis2sync-desktop/internal/api"
+	"dhis2sync-desktop/internal/models"
+	"dhis2sync-desktop/internal/notifications"
+)
+
+// runHealthCheckJob pings the source and destination of a profile and records the
+// outcome as a "healthcheck" task in the job history
+func (s *Service) runHealthCheckJob(payload map[string]interface{}) jobOutcome {
+	profileID, _ := payload["profile_id"].(string)
+	if profileID == "" {
+		log.Printf("WARNING: Incomplete healthcheck job payload")
+		return failedRun(fmt.Errorf("incomplete healthcheck job payload"))
+	}
+
+	var profile models.ConnectionProfile
+	if err := s.db.First(&profile, "id = ?", profileID).Error; err != nil {
+		log.Printf("ERROR: Failed to get profile: %v", err)
+		return failedRun(fmt.Errorf("failed to get profile: %w", err))
+	}
+
+	results := []HealthCheckResult{}
+	for _, instance := range []string{"source", "dest"} {
+		url := profile.SourceURL
+		if instance == "dest" {
+			url = profile.DestURL
+		}
+
+		client, err := s.getAPIClient(&profile, instance)
+		if err != nil {
+			results = append(results, HealthCheckResult{Instance: instance, URL: url, Error: err.Error()})
+			continue
+		}
+		results = append(results, pingInstance(client, instance, url))
+	}
+
+	taskID, err := s.recordHealthCheck(profile.Name, results)
+	if err != nil {
+		log.Printf("ERROR: Failed to record healthcheck: %v", err)
+	}
+	return healthCheckOutcome(taskID, results)
+}
+
+// healthCheckOutcome reports how many instances were reachable
+func healthCheckOutcome(taskID string, results []HealthCheckResult) jobOutcome {
+	reachable := 0
+	var lastErr error
+	for _, r := range results {
+		if r.OK {
+			reachable++
+		} else {
+			lastErr = fmt.Errorf("%s unreachable: %s", r.Instance, r.Error)
+		}
+	}
+
+	outcome := jobOutcome{
+		Status:  RunCompleted,
+		Summary: fmt.Sprintf("%d of %d instances reachable", reachable, len(results)),
+		Err:     lastErr,
+		TaskID:  taskID,
+	}
+	if lastErr != nil {
+		outcome.Status = RunError
+	}
+	return outcome
+}
+
+// pingInstance pings one instance and reports reachability and latency
+func pingInstance(client *api.Client, instance, url string) HealthCheckResult {
+	latency, err := client.Ping()
+
+	result := HealthCheckResult{
+		Instance:  instance,
+		URL:       url,
+		OK:        err == nil,
+		LatencyMs: latency.Milliseconds(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// recordHealthCheck stores the results as a completed (all reachable) or error task
+// and returns its ID. A failed check also lands in the notification inbox.
+func (s *Service) recordHealthCheck(profileName string, results []HealthCheckResult) (string, error) {
+	status := "completed"
+	messages := []string{}
+	unreachable := []string{}
+	for _, r := range results {
+		if r.OK {
+			messages = append(messages, fmt.Sprintf("✓ %s %s reachable (%d ms)", r.Instance, r.URL, r.LatencyMs))
+			continue
+		}
+		status = "error"
+		messages = append(messages, fmt.Sprintf("✗ %s %s unreachable: %s", r.Instance, r.URL, r.Error))
+		unreachable = append(unreachable, fmt.Sprintf("%s %s unreachable: %s", r.Instance, r.URL, r.Error))
+		log.Printf("✗ Healthcheck for profile %s: %s %s unreachable: %s", profileName, r.Instance, r.URL, r.Error)
+	}
+
+	messagesJSON, _ := json.Marshal(messages)
+	resultsJSON, _ := json.Marshal(results)
+
+	task := models.TaskProgress{
+		ID:       uuid.New().String(),
+		TaskType: "healthcheck",
+		Status:   status,
+		Progress: 100,
+		Messages: string(messagesJSON),
+		Results:  string(resultsJSON),
+	}
+	if err := s.db.Create(&task).Error; err != nil {
+		return "", fmt.Errorf("failed to save healthcheck result: %w", err)
+	}
+
+	if status == "error" {
+		notifications.TaskFinished(task.ID, "healthcheck", status, fmt.Sprintf("Profile %s: %s", profileName, strings.Join(unreachable, "; ")))
+	}
+
+	return task.ID, nil
+}
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"dhis2sync-desktop/internal/api/apitest"
+	"dhis2sync-desktop/internal/database"
+	"dhis2sync-desktop/internal/models"
+)
+
+func TestHealthCheck(t *testing.T) {
+	t.Run("Should report reachable and unreachable instances", func(t *testing.T) {
+		up := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/system/ping": apitest.Raw(http.StatusOK, "pong"),
+		})
+		down := apitest.NewServer(t, map[string]http.HandlerFunc{})
+
+		ok := pingInstance(up.Client(), "source", up.URL)
+		failed := pingInstance(down.Client(), "dest", down.URL)
+
+		assert.True(t, ok.OK)
+		assert.Empty(t, ok.Error)
+		assert.False(t, failed.OK)
+		assert.Contains(t, failed.Error, "HTTP 404")
+	})
+
+	t.Run("Should record the outcome in the job history", func(t *testing.T) {
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		require.NoError(t, err)
+		require.NoError(t, db.AutoMigrate(&models.TaskProgress{}, &models.Notification{}))
+		previous := database.DB
+		database.DB = db
+		defer func() { database.DB = previous }()
+
+		service := &Service{db: db, ctx: context.Background()}
+		results := []HealthCheckResult{
+			{Instance: "source", URL: "https://source.dhis2.org", OK: true, LatencyMs: 42},
+			{Instance: "dest", URL: "https://dest.dhis2.org", Error: "connection refused"},
+		}
+
+		taskID, err := service.recordHealthCheck("Test Profile", results)
+		require.NoError(t, err)
+
+		var task models.TaskProgress
+		require.NoError(t, db.First(&task, "id = ?", taskID).Error)
+		assert.Equal(t, "healthcheck", task.TaskType)
+		assert.Equal(t, "error", task.Status, "Any unreachable instance should fail the check")
+
+		var stored []HealthCheckResult
+		require.NoError(t, json.Unmarshal([]byte(task.Results), &stored))
+		assert.Equal(t, results, stored)
+
+		var notification models.Notification
+		require.NoError(t, db.First(&notification, "task_id = ?", taskID).Error, "A failed check should notify")
+		assert.Equal(t, "error", notification.Type)
+		assert.Contains(t, notification.Body, "Test Profile")
+		assert.Contains(t, notification.Body, "dest https://dest.dhis2.org unreachable: connection refused")
+	})
+}
@@ -0,0 +1,53 @@
+package scheduler
+
+import "time"
+
+// Defaults for monitoring a job's progress after it starts
+const (
+	defaultPollInitial = 2 * time.Second
+	defaultPollMax     = 30 * time.Second
+	defaultPollTimeout = 30 * time.Minute
+	pollBackoffFactor  = 1.5
+)
+
+// pollPolicy controls how often a started job's progress is checked: frequently at
+// first, backing off towards Max, giving up after Timeout
+type pollPolicy struct {
+	Initial time.Duration
+	Max     time.Duration
+	Timeout time.Duration
+}
+
+// pollPolicyFromPayload reads optional "poll_interval_seconds", "max_poll_interval_seconds"
+// and "timeout_minutes" from a job payload, falling back to the defaults
+func pollPolicyFromPayload(payload map[string]interface{}) pollPolicy {
+	policy := pollPolicy{
+		Initial: defaultPollInitial,
+		Max:     defaultPollMax,
+		Timeout: defaultPollTimeout,
+	}
+
+	if v, ok := payload["poll_interval_seconds"].(float64); ok && v > 0 {
+		policy.Initial = time.Duration(v * float64(time.Second))
+	}
+	if v, ok := payload["max_poll_interval_seconds"].(float64); ok && v > 0 {
+		policy.Max = time.Duration(v * float64(time.Second))
+	}
+	if v, ok := payload["timeout_minutes"].(float64); ok && v > 0 {
+		policy.Timeout = time.Duration(v * float64(time.Minute))
+	}
+	if policy.Max < policy.Initial {
+		policy.Max = policy.Initial
+	}
+
+	return policy
+}
+
+// next returns the interval to wait after current
+func (p pollPolicy) next(current time.Duration) time.Duration {
+	next := time.Duration(float64(current) * pollBackoffFactor)
+	if next > p.Max {
+		return p.Max
+	}
+	return next
+}
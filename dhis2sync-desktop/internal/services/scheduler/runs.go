@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"dhis2sync-desktop/internal/models"
+)
+
+// Job run statuses (models.JobRun.Status)
+const (
+	RunRunning   = "running"
+	RunCompleted = "completed"
+	RunPartial   = "partial" // Finished, but only part of the work succeeded
+	RunSkipped   = "skipped" // Nothing attempted, e.g. outside the profile's write window
+	RunError     = "error"
+)
+
+const (
+	defaultRunHistoryLimit = 20
+	maxRunHistoryLimit     = 200
+)
+
+// jobOutcome is how a job run ended
+type jobOutcome struct {
+	Status  string
+	Summary string
+	Err     error
+	TaskID  string // Task started by the run, if any
+}
+
+// failedRun is the outcome of a run that could not do its work
+func failedRun(err error) jobOutcome {
+	return jobOutcome{Status: RunError, Err: err}
+}
+
+// startJobRun records a run as started; failures only cost the history entry
+func (s *Service) startJobRun(jobID string, startedAt time.Time) string {
+	run := models.JobRun{JobID: jobID, StartedAt: startedAt, Status: RunRunning}
+	if err := s.db.Create(&run).Error; err != nil {
+		log.Printf("WARNING: Failed to record run of job %s: %v", jobID, err)
+		return ""
+	}
+	return run.ID
+}
+
+// finishJobRun stores a run's final status, summary and error
+func (s *Service) finishJobRun(runID string, outcome jobOutcome) {
+	if runID == "" {
+		return
+	}
+
+	updates := map[string]interface{}{
+		"finished_at": time.Now(),
+		"status":      outcome.Status,
+		"summary":     outcome.Summary,
+	}
+	if outcome.Err != nil {
+		updates["error"] = outcome.Err.Error()
+	}
+	if outcome.TaskID != "" {
+		updates["task_id"] = outcome.TaskID
+	}
+
+	if err := s.db.Model(&models.JobRun{}).Where("id = ?", runID).Updates(updates).Error; err != nil {
+		log.Printf("WARNING: Failed to update job run %s: %v", runID, err)
+	}
+}
+
+// ListJobRuns returns a job's most recent runs, newest first (limit defaults to 20, max 200)
+func (s *Service) ListJobRuns(jobID string, limit int) ([]models.JobRun, error) {
+	if limit <= 0 {
+		limit = defaultRunHistoryLimit
+	}
+	if limit > maxRunHistoryLimit {
+		limit = maxRunHistoryLimit
+	}
+
+	runs := []models.JobRun{}
+	if err := s.db.Where("job_id = ?", jobID).Order("started_at DESC").Limit(limit).Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list job runs: %w", err)
+	}
+	return runs, nil
+}
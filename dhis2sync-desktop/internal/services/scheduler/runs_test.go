@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"dhis2sync-desktop/internal/models"
+)
+
+func newRunsService(t *testing.T) *Service {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&ScheduledJob{}, &models.JobRun{}))
+	return &Service{db: db, ctx: context.Background(), completenessService: &mockCompletenessService{}}
+}
+
+func TestJobRuns(t *testing.T) {
+	t.Run("Should record a run and list history newest first", func(t *testing.T) {
+		service := newRunsService(t)
+		start := time.Date(2025, 3, 1, 2, 0, 0, 0, time.UTC)
+
+		first := service.startJobRun("job1", start)
+		service.finishJobRun(first, jobOutcome{Status: RunCompleted, Summary: "Sent 10 values in 2 batches"})
+		second := service.startJobRun("job1", start.Add(time.Hour))
+		service.finishJobRun(second, jobOutcome{Status: RunPartial, Summary: "Sent 5 values in 1 batches, 1 batches failed", Err: errors.New("HTTP 409")})
+		service.startJobRun("job2", start)
+
+		runs, err := service.ListJobRuns("job1", 0)
+
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		assert.Equal(t, second, runs[0].ID)
+		assert.Equal(t, RunPartial, runs[0].Status)
+		assert.Equal(t, "HTTP 409", runs[0].Error)
+		assert.NotNil(t, runs[0].FinishedAt)
+		assert.Equal(t, RunCompleted, runs[1].Status)
+		assert.Empty(t, runs[1].Error)
+
+		limited, err := service.ListJobRuns("job1", 1)
+		require.NoError(t, err)
+		assert.Len(t, limited, 1)
+	})
+
+	t.Run("Should record a failed run for an unknown job type", func(t *testing.T) {
+		service := newRunsService(t)
+		job := ScheduledJob{ID: "job1", Name: "Mystery", JobType: "mystery", Cron: "0 0 2 * * *", Timezone: "UTC", Enabled: true}
+		require.NoError(t, service.db.Create(&job).Error)
+
+		service.executeJob("job1")
+
+		runs, err := service.ListJobRuns("job1", 10)
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		assert.Equal(t, RunError, runs[0].Status)
+		assert.Contains(t, runs[0].Error, "unknown job type")
+	})
+
+	t.Run("Should finish a completeness run that cannot start", func(t *testing.T) {
+		service := newRunsService(t)
+		var outcome jobOutcome
+
+		service.runCompletenessJob(map[string]interface{}{"profile_id": "p1"}, func(o jobOutcome) { outcome = o })
+
+		assert.Equal(t, RunError, outcome.Status)
+		assert.Contains(t, outcome.Err.Error(), "incomplete")
+	})
+}
+
+func TestRecordJobSuccess(t *testing.T) {
+	t.Run("Should store the start of the last successful run", func(t *testing.T) {
+		service := newRunsService(t)
+		job := ScheduledJob{ID: "job1", Name: "Nightly", JobType: "transfer", Cron: "0 0 2 * * *", Timezone: "UTC", Enabled: true}
+		require.NoError(t, service.db.Create(&job).Error)
+		start := time.Date(2025, 3, 1, 2, 0, 0, 0, time.UTC)
+
+		service.recordJobSuccess("job1", start)
+
+		var stored ScheduledJob
+		require.NoError(t, service.db.First(&stored, "id = ?", "job1").Error)
+		require.NotNil(t, stored.LastSuccessAt)
+		assert.True(t, stored.LastSuccessAt.Equal(start))
+	})
+}
+
+func TestRunOutcomes(t *testing.T) {
+	t.Run("Should report a transfer with some failed batches as partial", func(t *testing.T) {
+		assert.Equal(t, RunCompleted, transferOutcome(10, 2, 0, nil).Status)
+
+		partial := transferOutcome(10, 2, 1, errors.New("HTTP 500"))
+		assert.Equal(t, RunPartial, partial.Status)
+		assert.Equal(t, "Sent 10 values in 2 batches, 1 batches failed", partial.Summary)
+		assert.EqualError(t, partial.Err, "HTTP 500")
+
+		assert.Equal(t, RunError, transferOutcome(0, 0, 3, errors.New("HTTP 500")).Status)
+	})
+
+	t.Run("Should report unreachable instances as an error", func(t *testing.T) {
+		outcome := healthCheckOutcome("task1", []HealthCheckResult{
+			{Instance: "source", OK: true},
+			{Instance: "dest", Error: "HTTP 502"},
+		})
+
+		assert.Equal(t, RunError, outcome.Status)
+		assert.Equal(t, "1 of 2 instances reachable", outcome.Summary)
+		assert.Equal(t, "task1", outcome.TaskID)
+	})
+}
@@ -8,15 +8,16 @@ import (
 	"strings"
 	"sync"
 	"time"
+	_ "time/tzdata" // Job timezones must resolve on machines without a system zoneinfo database (Windows)
 
 	"github.com/google/uuid"
 	"github.com/robfig/cron/v3"
 	"gorm.io/gorm"
 
 	"dhis2sync-desktop/internal/api"
-	"dhis2sync-desktop/internal/crypto"
 	"dhis2sync-desktop/internal/models"
 	"dhis2sync-desktop/internal/services/completeness"
+	"dhis2sync-desktop/internal/writewindow"
 )
 
 // CompletenessServiceInterface defines the interface for completeness service integration
@@ -54,7 +55,7 @@ func (s *Service) Start() error {
 	log.Println("Starting scheduler...")
 
 	// Auto-migrate ScheduledJob table
-	if err := s.db.AutoMigrate(&ScheduledJob{}); err != nil {
+	if err := s.db.AutoMigrate(&ScheduledJob{}, &models.JobRun{}); err != nil {
 		return fmt.Errorf("failed to migrate scheduled_jobs table: %w", err)
 	}
 
@@ -118,6 +119,10 @@ func (s *Service) UpsertJob(req UpsertJobRequest) (string, error) {
 	}
 	req.Cron = normalizedCron
 
+	if _, err := jobLocation(req.Timezone); err != nil {
+		return "", err
+	}
+
 	// Find or create job
 	var job ScheduledJob
 	result := s.db.Where("name = ?", req.Name).First(&job)
@@ -165,9 +170,8 @@ func (s *Service) UpsertJob(req UpsertJobRequest) (string, error) {
 	}
 	job.Payload = payloadStr
 
-	// Calculate next run time (cron parser uses the 6-field format stored in DB)
-	parser := cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
-	schedule, err := parser.Parse(job.Cron)
+	// Calculate next run time in the job's timezone
+	schedule, err := parseJobSchedule(&job)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse cron for next run: %w", err)
 	}
@@ -207,6 +211,9 @@ func (s *Service) DeleteJob(jobID string) error {
 	if err := s.db.Delete(&ScheduledJob{}, "id = ?", jobID).Error; err != nil {
 		return fmt.Errorf("failed to delete job: %w", err)
 	}
+	if err := s.db.Delete(&models.JobRun{}, "job_id = ?", jobID).Error; err != nil {
+		log.Printf("WARNING: Failed to delete run history of job %s: %v", jobID, err)
+	}
 
 	return nil
 }
@@ -224,14 +231,15 @@ func (s *Service) scheduleJob(job *ScheduledJob) error {
 	}
 	s.jobsMu.Unlock()
 
-	// Add job to cron
-	entryID, err := s.cron.AddFunc(job.Cron, func() {
-		s.executeJob(job.ID)
-	})
-
+	// Add job to cron, firing in the job's timezone rather than machine local time
+	schedule, err := parseJobSchedule(job)
 	if err != nil {
 		return fmt.Errorf("failed to add cron job: %w", err)
 	}
+	jobID := job.ID
+	entryID := s.cron.Schedule(schedule, cron.FuncJob(func() {
+		s.executeJob(jobID)
+	}))
 
 	s.jobsMu.Lock()
 	s.jobs[job.ID] = entryID
@@ -274,10 +282,10 @@ func (s *Service) executeJob(jobID string) {
 	// Update last run time
 	now := time.Now()
 	job.LastRunAt = &now
+	runID := s.startJobRun(jobID, now)
 
-	// Calculate next run time (cron parser uses the 6-field format stored in DB)
-	parser := cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
-	schedule, err := parser.Parse(job.Cron)
+	// Calculate next run time in the job's timezone
+	schedule, err := parseJobSchedule(&job)
 	if err != nil {
 		log.Printf("WARNING: Failed to parse cron for next run: %v", err)
 	} else {
@@ -294,25 +302,38 @@ func (s *Service) executeJob(jobID string) {
 	if job.Payload != "" {
 		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
 			log.Printf("ERROR: Failed to parse job payload: %v", err)
+			s.finishJobRun(runID, failedRun(fmt.Errorf("invalid job payload: %w", err)))
 			return
 		}
 	}
 
-	// Execute based on job type
+	// Execute based on job type; completeness runs are finished by their monitor
 	switch job.JobType {
 	case "completeness":
-		s.runCompletenessJob(payload)
+		s.runCompletenessJob(payload, func(outcome jobOutcome) { s.finishJobRun(runID, outcome) })
 	case "transfer":
-		s.runTransferJob(payload)
+		outcome := s.runTransferJob(payload, job.LastSuccessAt)
+		s.finishJobRun(runID, outcome)
+		if outcome.Status == RunCompleted {
+			s.recordJobSuccess(jobID, now)
+		}
+	case "healthcheck":
+		s.finishJobRun(runID, s.runHealthCheckJob(payload))
 	default:
 		log.Printf("WARNING: Unknown job type: %s", job.JobType)
+		s.finishJobRun(runID, failedRun(fmt.Errorf("unknown job type: %s", job.JobType)))
 	}
 
 	log.Printf("Completed scheduled job: %s", jobID)
 }
 
-// runCompletenessJob executes a completeness assessment job
-func (s *Service) runCompletenessJob(payload map[string]interface{}) {
+// runCompletenessJob executes a completeness assessment job. finish, when non-nil,
+// receives the outcome once the assessment ends (or fails to start).
+func (s *Service) runCompletenessJob(payload map[string]interface{}, finish func(jobOutcome)) {
+	if finish == nil {
+		finish = func(jobOutcome) {}
+	}
+
 	// Extract parameters
 	profileID, _ := payload["profile_id"].(string)
 	instance, _ := payload["instance"].(string)
@@ -341,6 +362,7 @@ func (s *Service) runCompletenessJob(payload map[string]interface{}) {
 
 	if profileID == "" || datasetID == "" || len(periods) == 0 || len(parentOrgUnits) == 0 {
 		log.Printf("WARNING: Incomplete completeness job payload")
+		finish(failedRun(fmt.Errorf("incomplete completeness job payload")))
 		return
 	}
 
@@ -377,48 +399,68 @@ func (s *Service) runCompletenessJob(payload map[string]interface{}) {
 	taskID, err := s.completenessService.StartAssessment(req)
 	if err != nil {
 		log.Printf("ERROR: Failed to start completeness assessment: %v", err)
+		finish(failedRun(fmt.Errorf("failed to start assessment: %w", err)))
 		return
 	}
 
 	log.Printf("Completeness assessment started with task ID: %s", taskID)
 
-	// Wait for completion (with timeout) - run in background to not block scheduler
+	// Wait for completion (with timeout) - run in background to not block scheduler.
+	// Polls often at first and backs off, so short jobs finish promptly and long ones aren't over-polled.
+	policy := pollPolicyFromPayload(payload)
 	go func() {
-		timeout := time.After(30 * time.Minute) // 30-minute timeout
-		ticker := time.NewTicker(5 * time.Second)
-		defer ticker.Stop()
+		timeout := time.After(policy.Timeout)
+		interval := policy.Initial
+		timer := time.NewTimer(interval)
+		defer timer.Stop()
 
 		for {
 			select {
 			case <-timeout:
-				log.Printf("WARNING: Completeness assessment %s timed out after 30 minutes", taskID)
+				log.Printf("WARNING: Completeness assessment %s timed out after %v", taskID, policy.Timeout)
+				finish(jobOutcome{Status: RunError, TaskID: taskID, Err: fmt.Errorf("assessment timed out after %v", policy.Timeout)})
 				return
-			case <-ticker.C:
+			case <-timer.C:
+				interval = policy.next(interval)
+				timer.Reset(interval)
+
 				progress, err := s.completenessService.GetAssessmentProgress(taskID)
 				if err != nil {
 					log.Printf("ERROR: Failed to get progress for assessment %s: %v", taskID, err)
+					finish(jobOutcome{Status: RunError, TaskID: taskID, Err: fmt.Errorf("failed to get assessment progress: %w", err)})
 					return
 				}
 
 				if progress == nil {
 					log.Printf("WARNING: Progress for assessment %s is nil, stopping monitoring", taskID)
+					finish(jobOutcome{Status: RunError, TaskID: taskID, Err: fmt.Errorf("assessment progress unavailable")})
 					return
 				}
 
 				if progress.Status == "completed" {
 					log.Printf("Scheduled completeness assessment completed successfully (task: %s)", taskID)
+					outcome := jobOutcome{Status: RunCompleted, TaskID: taskID, Summary: "Assessment completed"}
 					if progress.Results != nil {
 						log.Printf("Results: %d compliant, %d non-compliant, %d errors",
 							progress.Results.TotalCompliant,
 							progress.Results.TotalNonCompliant,
 							progress.Results.TotalErrors)
+						outcome.Summary = fmt.Sprintf("%d compliant, %d non-compliant, %d errors",
+							progress.Results.TotalCompliant, progress.Results.TotalNonCompliant, progress.Results.TotalErrors)
+						if progress.Results.TotalErrors > 0 {
+							outcome.Status = RunPartial
+						}
 					}
+					finish(outcome)
 					return
 				} else if progress.Status == "error" {
 					log.Printf("ERROR: Completeness assessment failed (task: %s)", taskID)
+					outcome := jobOutcome{Status: RunError, TaskID: taskID, Err: fmt.Errorf("assessment failed")}
 					if len(progress.Messages) > 0 {
 						log.Printf("Last message: %s", progress.Messages[len(progress.Messages)-1])
+						outcome.Err = fmt.Errorf("assessment failed: %s", progress.Messages[len(progress.Messages)-1])
 					}
+					finish(outcome)
 					return
 				}
 			}
@@ -428,8 +470,14 @@ func (s *Service) runCompletenessJob(payload map[string]interface{}) {
 	log.Printf("Completeness job initiated for dataset %s", datasetID)
 }
 
-// runTransferJob executes a data transfer job
-func (s *Service) runTransferJob(payload map[string]interface{}) {
+// deltaSyncOverlap re-fetches a little before the last successful run, so clock skew
+// between this machine and DHIS2 can't drop changes; re-sending them is harmless
+const deltaSyncOverlap = 5 * time.Minute
+
+// runTransferJob executes a data transfer job. A run where some org unit/period
+// batches failed to fetch or import is reported as partial. lastSuccess is the start
+// of the job's last successful run, which delta-sync jobs fetch changes since.
+func (s *Service) runTransferJob(payload map[string]interface{}, lastSuccess *time.Time) jobOutcome {
 	// Extract parameters
 	profileID, _ := payload["profile_id"].(string)
 	datasetID, _ := payload["dataset_id"].(string)
@@ -461,30 +509,46 @@ func (s *Service) runTransferJob(payload map[string]interface{}) {
 		markComplete = mc
 	}
 
+	// Without a previous success there is nothing to chain off, so transfer everything
+	modifiedSince := ""
+	if deltaSync, _ := payload["delta_sync"].(bool); deltaSync && lastSuccess != nil {
+		modifiedSince = lastSuccess.Add(-deltaSyncOverlap).UTC().Format(time.RFC3339)
+	}
+
 	if profileID == "" || datasetID == "" || len(periods) == 0 {
 		log.Printf("WARNING: Incomplete transfer job payload")
-		return
+		return failedRun(fmt.Errorf("incomplete transfer job payload"))
 	}
 
 	// Get profile
 	var profile models.ConnectionProfile
 	if err := s.db.First(&profile, "id = ?", profileID).Error; err != nil {
 		log.Printf("ERROR: Failed to get profile: %v", err)
-		return
+		return failedRun(fmt.Errorf("failed to get profile: %w", err))
+	}
+
+	// Writes outside the profile's window wait for a run that falls inside it
+	if err := writewindow.Check(&profile, time.Now()); err != nil {
+		log.Printf("WARNING: Skipping scheduled transfer run: %v", err)
+		return jobOutcome{Status: RunSkipped, Summary: "Outside the profile's write window", Err: err}
 	}
 
 	srcClient, err := s.getAPIClient(&profile, "source")
 	if err != nil {
 		log.Printf("ERROR: Failed to create source client: %v", err)
-		return
+		return failedRun(fmt.Errorf("failed to create source client: %w", err))
 	}
 
 	destClient, err := s.getAPIClient(&profile, "dest")
 	if err != nil {
 		log.Printf("ERROR: Failed to create dest client: %v", err)
-		return
+		return failedRun(fmt.Errorf("failed to create dest client: %w", err))
 	}
 
+	// Tally batches (one per period and parent org unit) for the run summary
+	var sentValues, sentBatches, failedBatches int
+	var lastErr error
+
 	// Execute transfer for each period and org unit
 	for _, period := range periods {
 		orgUnits := parentOrgUnits
@@ -501,16 +565,23 @@ func (s *Service) runTransferJob(payload map[string]interface{}) {
 			if parentOU != "" {
 				params["orgUnit"] = parentOU
 			}
+			if modifiedSince != "" {
+				params["lastUpdated"] = modifiedSince
+			}
 
 			resp, err := srcClient.Get("/api/dataValueSets", params)
 			if err != nil {
 				log.Printf("WARNING: Failed to fetch data: %v", err)
+				failedBatches++
+				lastErr = err
 				continue
 			}
 
 			var data map[string]interface{}
-			if err := json.Unmarshal(resp.Body(), &data); err != nil {
+			if err := api.DecodeJSON(resp, "/api/dataValueSets", &data); err != nil {
 				log.Printf("WARNING: Failed to parse response: %v", err)
+				failedBatches++
+				lastErr = err
 				continue
 			}
 
@@ -533,10 +604,18 @@ func (s *Service) runTransferJob(payload map[string]interface{}) {
 				"dataValues": dataValues,
 			}
 
-			if _, err := destClient.Post("/api/dataValueSets", postPayload); err != nil {
+			postResp, err := destClient.Post("/api/dataValueSets", postPayload)
+			if err == nil && !postResp.IsSuccess() {
+				err = fmt.Errorf("HTTP %d: %s", postResp.StatusCode(), api.BodySnippet(postResp.Body(), 200))
+			}
+			if err != nil {
 				log.Printf("WARNING: Failed to post data values: %v", err)
+				failedBatches++
+				lastErr = err
 				continue
 			}
+			sentBatches++
+			sentValues += len(dataValues)
 
 			// Mark complete if requested
 			if markComplete {
@@ -571,27 +650,62 @@ func (s *Service) runTransferJob(payload map[string]interface{}) {
 			}
 		}
 	}
+
+	outcome := transferOutcome(sentValues, sentBatches, failedBatches, lastErr)
+	if modifiedSince != "" {
+		outcome.Summary += fmt.Sprintf(" (changes since %s)", modifiedSince)
+	}
+	return outcome
 }
 
-func (s *Service) getAPIClient(profile *models.ConnectionProfile, instance string) (*api.Client, error) {
-	var url, username, encPassword string
+// recordJobSuccess stores the start of a fully successful run for delta sync
+func (s *Service) recordJobSuccess(jobID string, startedAt time.Time) {
+	if err := s.db.Model(&ScheduledJob{}).Where("id = ?", jobID).UpdateColumn("last_success_at", startedAt).Error; err != nil {
+		log.Printf("WARNING: Failed to record last successful run of job %s: %v", jobID, err)
+	}
+}
 
-	if instance == "source" {
-		url = profile.SourceURL
-		username = profile.SourceUsername
-		encPassword = profile.SourcePasswordEnc
-	} else {
-		url = profile.DestURL
-		username = profile.DestUsername
-		encPassword = profile.DestPasswordEnc
+// transferOutcome summarizes a transfer run: partial when only some batches failed
+func transferOutcome(sentValues, sentBatches, failedBatches int, lastErr error) jobOutcome {
+	summary := fmt.Sprintf("Sent %d values in %d batches", sentValues, sentBatches)
+	if failedBatches == 0 {
+		return jobOutcome{Status: RunCompleted, Summary: summary}
+	}
+
+	summary += fmt.Sprintf(", %d batches failed", failedBatches)
+	status := RunPartial
+	if sentBatches == 0 {
+		status = RunError
 	}
+	return jobOutcome{Status: status, Summary: summary, Err: lastErr}
+}
+
+func (s *Service) getAPIClient(profile *models.ConnectionProfile, instance string) (*api.Client, error) {
+	return api.NewProfileClient(profile, instance)
+}
 
-	password, err := crypto.DecryptPassword(encPassword)
+// cronParser parses the 6-field expressions stored in the DB (seconds optional)
+var cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// jobLocation resolves a job's IANA timezone (e.g. "Africa/Lagos"); empty means UTC
+func jobLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(timezone)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt password: %w", err)
+		return nil, fmt.Errorf("unknown timezone %q: use an IANA name such as \"Africa/Lagos\" or \"UTC\"", timezone)
 	}
+	return loc, nil
+}
 
-	return api.NewClient(url, username, password), nil
+// parseJobSchedule parses a job's cron expression to fire in the job's timezone
+func parseJobSchedule(job *ScheduledJob) (cron.Schedule, error) {
+	loc, err := jobLocation(job.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	return cronParser.Parse("CRON_TZ=" + loc.String() + " " + job.Cron)
 }
 
 // normalizeCron converts 5-field cron to 6-field format by prepending seconds
@@ -607,8 +721,7 @@ func normalizeCron(cronExpr string) (string, error) {
 	fields := strings.Fields(cronExpr)
 	if len(fields) == 6 {
 		// Already 6-field, try to validate it
-		parser := cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
-		if _, err := parser.Parse(cronExpr); err == nil {
+		if _, err := cronParser.Parse(cronExpr); err == nil {
 			return cronExpr, nil // Valid 6-field expression
 		}
 	}
@@ -3,6 +3,7 @@ package scheduler
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/robfig/cron/v3"
 	"github.com/stretchr/testify/assert"
@@ -327,3 +328,76 @@ func TestPayloadHandling(t *testing.T) {
 		assert.IsType(t, "", req.Payload)
 	})
 }
+
+func TestPollPolicy(t *testing.T) {
+	t.Run("Should use defaults when the payload has no settings", func(t *testing.T) {
+		policy := pollPolicyFromPayload(map[string]interface{}{})
+
+		assert.Equal(t, defaultPollInitial, policy.Initial)
+		assert.Equal(t, defaultPollMax, policy.Max)
+		assert.Equal(t, defaultPollTimeout, policy.Timeout)
+	})
+
+	t.Run("Should read intervals and timeout from the payload", func(t *testing.T) {
+		policy := pollPolicyFromPayload(map[string]interface{}{
+			"poll_interval_seconds":     float64(1),
+			"max_poll_interval_seconds": float64(60),
+			"timeout_minutes":           float64(120),
+		})
+
+		assert.Equal(t, time.Second, policy.Initial)
+		assert.Equal(t, time.Minute, policy.Max)
+		assert.Equal(t, 2*time.Hour, policy.Timeout)
+	})
+
+	t.Run("Should back off up to the maximum interval", func(t *testing.T) {
+		policy := pollPolicy{Initial: 2 * time.Second, Max: 5 * time.Second}
+
+		interval := policy.Initial
+		intervals := []time.Duration{}
+		for i := 0; i < 4; i++ {
+			interval = policy.next(interval)
+			intervals = append(intervals, interval)
+		}
+
+		assert.Equal(t, []time.Duration{3 * time.Second, 4500 * time.Millisecond, 5 * time.Second, 5 * time.Second}, intervals)
+	})
+
+	t.Run("Should never back off below the initial interval", func(t *testing.T) {
+		policy := pollPolicyFromPayload(map[string]interface{}{
+			"poll_interval_seconds":     float64(20),
+			"max_poll_interval_seconds": float64(5),
+		})
+
+		assert.Equal(t, 20*time.Second, policy.Max)
+	})
+}
+
+func TestJobTimezone(t *testing.T) {
+	t.Run("Should fire at the configured hour in the job's timezone", func(t *testing.T) {
+		job := &ScheduledJob{Cron: "0 0 8 * * *", Timezone: "Africa/Lagos"}
+
+		schedule, err := parseJobSchedule(job)
+		require.NoError(t, err)
+
+		next := schedule.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		assert.Equal(t, time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC), next.UTC(), "08:00 in Lagos (UTC+1) is 07:00 UTC")
+	})
+
+	t.Run("Should treat an empty timezone as UTC", func(t *testing.T) {
+		schedule, err := parseJobSchedule(&ScheduledJob{Cron: "0 0 8 * * *"})
+		require.NoError(t, err)
+
+		next := schedule.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		assert.Equal(t, time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC), next.UTC())
+	})
+
+	t.Run("Should reject an unknown timezone in UpsertJob", func(t *testing.T) {
+		service := &Service{jobs: make(map[string]cron.EntryID)}
+
+		_, err := service.UpsertJob(UpsertJobRequest{Name: "Nightly", JobType: "transfer", Cron: "0 2 * * *", Timezone: "Mars/Olympus"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown timezone "Mars/Olympus"`)
+	})
+}
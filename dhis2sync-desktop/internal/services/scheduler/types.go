@@ -6,7 +6,7 @@ import "time"
 type ScheduledJob struct {
 	ID         string    `json:"id" gorm:"primaryKey"`
 	Name       string    `json:"name" gorm:"unique;not null"`
-	JobType    string    `json:"job_type" gorm:"not null"` // "completeness", "transfer", "metadata"
+	JobType    string    `json:"job_type" gorm:"not null"` // "completeness", "transfer", "healthcheck"
 	Cron       string    `json:"cron" gorm:"not null"`     // CRON expression
 	Timezone   string    `json:"timezone" gorm:"default:UTC"`
 	Payload    string    `json:"payload" gorm:"type:text"`    // JSON payload string
@@ -15,6 +15,10 @@ type ScheduledJob struct {
 	NextRunAt  *time.Time `json:"next_run_at"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
+
+	// LastSuccessAt is when the last fully successful run started; delta-sync
+	// transfer runs fetch only values changed since then
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
 }
 
 // TableName specifies the table name for GORM
@@ -39,7 +43,7 @@ type JobListResponse struct {
 // UpsertJobRequest represents a request to create or update a scheduled job
 type UpsertJobRequest struct {
 	Name     string      `json:"name"`
-	JobType  string      `json:"job_type"` // "completeness" or "transfer"
+	JobType  string      `json:"job_type"` // "completeness", "transfer" or "healthcheck"
 	Cron     string      `json:"cron"`
 	Timezone string      `json:"timezone"`
 	Enabled  bool        `json:"enabled"`
@@ -66,4 +70,17 @@ type TransferJobPayload struct {
 	Periods        []string `json:"periods"`
 	ParentOrgUnits []string `json:"parent_org_units"`
 	MarkComplete   bool     `json:"mark_complete"`
+
+	// DeltaSync transfers only values changed since the job's last successful run
+	// (the first run transfers everything)
+	DeltaSync bool `json:"delta_sync"`
+}
+
+// HealthCheckResult is the outcome of pinging one instance of a profile
+type HealthCheckResult struct {
+	Instance  string `json:"instance"` // "source" or "dest"
+	URL       string `json:"url"`
+	OK        bool   `json:"ok"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
 }
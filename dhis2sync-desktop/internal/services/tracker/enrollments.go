@@ -0,0 +1,86 @@
+package tracker
+
+import (
+	"fmt"
+	"net/http"
+
+	"dhis2sync-desktop/internal/api"
+)
+
+// enrollmentChecker remembers which source enrollments exist in the destination,
+// so each is looked up once per transfer
+type enrollmentChecker struct {
+	client *api.Client
+	modern bool // Look up through /api/tracker/enrollments
+	known  map[string]bool
+}
+
+func newEnrollmentChecker(client *api.Client, modern bool) *enrollmentChecker {
+	return &enrollmentChecker{client: client, modern: modern, known: make(map[string]bool)}
+}
+
+// exists reports whether the enrollment is present in the destination
+func (c *enrollmentChecker) exists(enrollmentID string) (bool, error) {
+	if found, ok := c.known[enrollmentID]; ok {
+		return found, nil
+	}
+
+	endpoint := fmt.Sprintf("/api/enrollments/%s", enrollmentID)
+	if c.modern {
+		endpoint = fmt.Sprintf("/api/tracker/enrollments/%s", enrollmentID)
+	}
+	resp, err := c.client.Get(endpoint, map[string]string{"fields": "enrollment"})
+	if err != nil {
+		return false, fmt.Errorf("failed to look up enrollment %s: %w", enrollmentID, err)
+	}
+
+	switch {
+	case resp.IsSuccess():
+		c.known[enrollmentID] = true
+	case resp.StatusCode() == http.StatusNotFound:
+		c.known[enrollmentID] = false
+	default:
+		return false, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), api.BodySnippet(resp.Body(), 200))
+	}
+	return c.known[enrollmentID], nil
+}
+
+// prepareEvents turns raw source events into import payloads. Events of tracker
+// programs (those with a trackedEntityInstance) keep their enrollment and TEI so the
+// destination can attach them, but are skipped when that enrollment doesn't exist
+// in the destination: DHIS2 would reject them with an opaque error. Enrollments that
+// can't be verified are sent anyway and left to the import to judge.
+func prepareEvents(events []interface{}, checker *enrollmentChecker) (ready []map[string]interface{}, missingEnrollment int, lookupErr error) {
+	ready = []map[string]interface{}{}
+
+	for _, evt := range events {
+		evtMap, ok := evt.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		minimal := minimalEvent(evtMap)
+		tei, _ := evtMap["trackedEntityInstance"].(string)
+		enrollment, _ := evtMap["enrollment"].(string)
+		if tei == "" || enrollment == "" {
+			ready = append(ready, minimal)
+			continue
+		}
+
+		found, err := checker.exists(enrollment)
+		if err != nil {
+			lookupErr = err
+			found = true
+		}
+		if !found {
+			missingEnrollment++
+			continue
+		}
+
+		minimal["enrollment"] = enrollment
+		minimal["trackedEntityInstance"] = tei
+		ready = append(ready, minimal)
+	}
+
+	return ready, missingEnrollment, lookupErr
+}
@@ -0,0 +1,59 @@
+package tracker
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"dhis2sync-desktop/internal/api/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareEvents(t *testing.T) {
+	srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+		"/api/enrollments/enrPresent1": apitest.JSON(http.StatusOK, map[string]string{"enrollment": "enrPresent1"}),
+		"/api/enrollments/enrMissing1": apitest.Raw(http.StatusNotFound, `{"message":"not found"}`),
+		"/api/enrollments/enrBroken01": apitest.Raw(http.StatusInternalServerError, "boom"),
+	})
+
+	var page struct {
+		Events []interface{} `json:"events"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"events":[
+		{"event":"ev1","programStage":"ps1","orgUnit":"ou1","enrollment":"enrPresent1","trackedEntityInstance":"tei1"},
+		{"event":"ev2","programStage":"ps1","orgUnit":"ou1","enrollment":"enrMissing1","trackedEntityInstance":"tei2"},
+		{"event":"ev3","programStage":"ps1","orgUnit":"ou1","enrollment":"enrMissing1","trackedEntityInstance":"tei2"},
+		{"event":"ev4","programStage":"ps2","orgUnit":"ou1","enrollment":"enrEventProg"},
+		{"event":"ev5","programStage":"ps1","orgUnit":"ou1","enrollment":"enrPresent1","trackedEntityInstance":"tei1"}
+	]}`), &page))
+
+	t.Run("Should skip events whose enrollment is missing in destination", func(t *testing.T) {
+		checker := newEnrollmentChecker(srv.Client(), false)
+
+		ready, missing, err := prepareEvents(page.Events, checker)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, missing)
+		require.Len(t, ready, 3)
+		assert.Equal(t, "enrPresent1", ready[0]["enrollment"])
+		assert.Equal(t, "tei1", ready[0]["trackedEntityInstance"])
+		assert.NotContains(t, ready[1], "enrollment", "Event program events are sent without their enrollment")
+		assert.Equal(t, 1, srv.Hits("/api/enrollments/enrPresent1"), "Lookups should be cached")
+		assert.Equal(t, 1, srv.Hits("/api/enrollments/enrMissing1"))
+	})
+
+	t.Run("Should send events whose enrollment can't be verified", func(t *testing.T) {
+		client := srv.Client()
+		client.SetRetryCount(0)
+		checker := newEnrollmentChecker(client, false)
+		events := []interface{}{map[string]interface{}{"event": "ev6", "enrollment": "enrBroken01", "trackedEntityInstance": "tei3"}}
+
+		ready, missing, err := prepareEvents(events, checker)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, missing)
+		assert.Len(t, ready, 1)
+	})
+}
@@ -0,0 +1,58 @@
+package tracker
+
+import "sort"
+
+// eventMapper rewrites minimal event payloads for the destination's metadata UIDs.
+// With an element mapping, values of unmapped data elements are dropped (as in aggregate
+// transfers) and counted per source element. Program stages missing from the stage
+// mapping keep their source ID.
+type eventMapper struct {
+	elements map[string]string // source data element ID -> destination ID
+	stages   map[string]string // source program stage ID -> destination ID
+	unmapped map[string]int    // source data element ID -> values dropped
+}
+
+func newEventMapper(elements, stages map[string]string) *eventMapper {
+	return &eventMapper{elements: elements, stages: stages, unmapped: make(map[string]int)}
+}
+
+// apply remaps an event's program stage and data elements in place
+func (m *eventMapper) apply(evt map[string]interface{}) {
+	if stage, ok := evt["programStage"].(string); ok {
+		if mapped, ok := m.stages[stage]; ok {
+			evt["programStage"] = mapped
+		}
+	}
+
+	if len(m.elements) == 0 {
+		return
+	}
+	dataValues, ok := evt["dataValues"].([]map[string]interface{})
+	if !ok {
+		return
+	}
+
+	kept := make([]map[string]interface{}, 0, len(dataValues))
+	for _, dv := range dataValues {
+		element, _ := dv["dataElement"].(string)
+		mapped, ok := m.elements[element]
+		if !ok {
+			m.unmapped[element]++
+			continue
+		}
+		dv["dataElement"] = mapped
+		kept = append(kept, dv)
+	}
+	evt["dataValues"] = kept
+}
+
+// record stores the unmapped elements, sorted, and the values dropped for them in result
+func (m *eventMapper) record(result *TransferResult) {
+	result.UnmappedElements = make([]string, 0, len(m.unmapped))
+	result.UnmappedValues = 0
+	for element, count := range m.unmapped {
+		result.UnmappedElements = append(result.UnmappedElements, element)
+		result.UnmappedValues += count
+	}
+	sort.Strings(result.UnmappedElements)
+}
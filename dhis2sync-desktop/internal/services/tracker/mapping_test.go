@@ -0,0 +1,47 @@
+package tracker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventMapper(t *testing.T) {
+	event := func() map[string]interface{} {
+		return minimalEvent(map[string]interface{}{
+			"programStage": "psSource1",
+			"dataValues": []interface{}{
+				map[string]interface{}{"dataElement": "deSource1", "value": "5"},
+				map[string]interface{}{"dataElement": "deUnknown", "value": "7"},
+			},
+		})
+	}
+
+	t.Run("Should remap stages and elements and drop unmapped values", func(t *testing.T) {
+		mapper := newEventMapper(map[string]string{"deSource1": "deDest1"}, map[string]string{"psSource1": "psDest1"})
+		evt := event()
+
+		mapper.apply(evt)
+		mapper.apply(event())
+
+		assert.Equal(t, "psDest1", evt["programStage"])
+		assert.Equal(t, []map[string]interface{}{{"dataElement": "deDest1", "value": "5"}}, evt["dataValues"])
+
+		var result TransferResult
+		mapper.record(&result)
+		assert.Equal(t, []string{"deUnknown"}, result.UnmappedElements)
+		assert.Equal(t, 2, result.UnmappedValues)
+	})
+
+	t.Run("Should leave events alone without mappings", func(t *testing.T) {
+		mapper := newEventMapper(nil, nil)
+		evt := event()
+
+		mapper.apply(evt)
+
+		assert.Equal(t, event(), evt)
+		var result TransferResult
+		mapper.record(&result)
+		assert.Empty(t, result.UnmappedElements)
+	})
+}
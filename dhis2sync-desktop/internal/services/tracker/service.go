@@ -2,18 +2,17 @@ package tracker
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 	"gorm.io/gorm"
 
 	"dhis2sync-desktop/internal/api"
-	"dhis2sync-desktop/internal/crypto"
+	"dhis2sync-desktop/internal/events"
 	"dhis2sync-desktop/internal/models"
+	"dhis2sync-desktop/internal/notifications"
 )
 
 // Service handles tracker event operations
@@ -63,7 +62,7 @@ func (s *Service) ListPrograms(profileID, instance string, includeAll bool, sear
 		Programs []Program `json:"programs"`
 	}
 
-	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+	if err := api.DecodeJSON(resp, "/api/programs.json", &result); err != nil {
 		return nil, fmt.Errorf("failed to parse programs response: %w", err)
 	}
 
@@ -105,7 +104,7 @@ func (s *Service) GetProgramDetail(profileID, programID, instance string) (*Prog
 	}
 
 	var program Program
-	if err := json.Unmarshal(resp.Body(), &program); err != nil {
+	if err := api.DecodeJSON(resp, fmt.Sprintf("/api/programs/%s", programID), &program); err != nil {
 		return nil, fmt.Errorf("failed to parse program detail: %w", err)
 	}
 
@@ -139,44 +138,34 @@ func (s *Service) PreviewEvents(req PreviewRequest) (*PreviewResponse, error) {
 
 	totalCollected := 0
 	sample := []map[string]interface{}{}
+	summarizer := newPreviewSummarizer()
+
+	// Older servers, or ones whose version can't be read, are read through /api/events
+	modern, _ := usesTrackerAPI(client)
 
 	for _, orgUnit := range req.OrgUnits {
 		page := 1
 		for totalCollected < previewCap {
-			params := map[string]string{
-				"program":    req.ProgramID,
-				"orgUnit":    orgUnit,
-				"ouMode":     "DESCENDANTS",
-				"startDate":  req.StartDate,
-				"endDate":    req.EndDate,
-				"page":       fmt.Sprintf("%d", page),
-				"pageSize":   fmt.Sprintf("%d", pageSize),
-				"totalPages": "true",
-			}
-
-			if req.ProgramStage != "" {
-				params["programStage"] = req.ProgramStage
-			}
-			if req.Status != "" {
-				params["status"] = req.Status
-			}
-
-			resp, err := client.Get("/api/events", params)
-			if err != nil {
-				break
-			}
-
-			var data map[string]interface{}
-			if err := json.Unmarshal(resp.Body(), &data); err != nil {
-				break
-			}
-
-			events, _ := data["events"].([]interface{})
-			if len(events) == 0 {
+			events, pageCount, err := fetchEventPage(client, modern, eventQuery{
+				Program:      req.ProgramID,
+				OrgUnit:      orgUnit,
+				StartDate:    req.StartDate,
+				EndDate:      req.EndDate,
+				ProgramStage: req.ProgramStage,
+				Status:       req.Status,
+				Page:         page,
+				PageSize:     pageSize,
+			})
+			if err != nil || len(events) == 0 {
 				break
 			}
 
 			totalCollected += len(events)
+			for _, evt := range events {
+				if evtMap, ok := evt.(map[string]interface{}); ok {
+					summarizer.add(evtMap)
+				}
+			}
 
 			// Collect up to 5 sample events
 			if len(sample) < 5 {
@@ -190,13 +179,6 @@ func (s *Service) PreviewEvents(req PreviewRequest) (*PreviewResponse, error) {
 				}
 			}
 
-			// Check pagination
-			pager, _ := data["pager"].(map[string]interface{})
-			pageCount := 1
-			if pc, ok := pager["pageCount"].(float64); ok {
-				pageCount = int(pc)
-			}
-
 			if page >= pageCount {
 				break
 			}
@@ -211,11 +193,17 @@ func (s *Service) PreviewEvents(req PreviewRequest) (*PreviewResponse, error) {
 		EndDate:       req.EndDate,
 		EstimateTotal: totalCollected,
 		Sample:        sample,
+		Summary:       summarizer.summary(),
 	}, nil
 }
 
 // StartTransfer initiates a background event transfer
 func (s *Service) StartTransfer(req TransferRequest) (string, error) {
+	return s.startTransferTask(req, "Starting tracker event transfer...", s.performTransfer)
+}
+
+// startTransferTask fills in the request defaults, registers a task and runs perform in the background
+func (s *Service) startTransferTask(req TransferRequest, message string, perform func(taskID string, profile *models.ConnectionProfile, req TransferRequest)) (string, error) {
 	profile, err := s.getProfile(req.ProfileID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get profile: %w", err)
@@ -237,7 +225,7 @@ func (s *Service) StartTransfer(req TransferRequest) (string, error) {
 		TaskID:   taskID,
 		Status:   "starting",
 		Progress: 0,
-		Messages: []string{"Starting tracker event transfer..."},
+		Messages: []string{message},
 	}
 
 	s.transferMu.Lock()
@@ -248,7 +236,7 @@ func (s *Service) StartTransfer(req TransferRequest) (string, error) {
 	s.emitTransferEvent(taskID)
 
 	// Run in background goroutine
-	go s.performTransfer(taskID, profile, req)
+	go perform(taskID, profile, req)
 
 	return taskID, nil
 }
@@ -275,24 +263,7 @@ func (s *Service) getProfile(profileID string) (*models.ConnectionProfile, error
 }
 
 func (s *Service) getAPIClient(profile *models.ConnectionProfile, instance string) (*api.Client, error) {
-	var url, username, encPassword string
-
-	if instance == "source" {
-		url = profile.SourceURL
-		username = profile.SourceUsername
-		encPassword = profile.SourcePasswordEnc
-	} else {
-		url = profile.DestURL
-		username = profile.DestUsername
-		encPassword = profile.DestPasswordEnc
-	}
-
-	password, err := crypto.DecryptPassword(encPassword)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt password: %w", err)
-	}
-
-	return api.NewClient(url, username, password), nil
+	return api.NewProfileClient(profile, instance)
 }
 
 func (s *Service) performTransfer(taskID string, profile *models.ConnectionProfile, req TransferRequest) {
@@ -324,9 +295,12 @@ func (s *Service) performTransfer(taskID string, profile *models.ConnectionProfi
 		pageSize = 500
 	}
 
-	totalFetched := 0
-	totalSent := 0
-	batchesSent := 0
+	srcModern, destModern := s.detectTrackerAPI(taskID, srcClient, destClient)
+	s.appendMessage(taskID, fmt.Sprintf("Reading events via %s, importing via %s", eventsEndpoint(srcModern), importEndpoint(destModern)))
+
+	result := TransferResult{DryRun: req.DryRun, TrackerAPI: destModern}
+	enrollments := newEnrollmentChecker(destClient, destModern)
+	mapper := newEventMapper(req.ElementMapping, req.ProgramStageMapping)
 	startTime := time.Now()
 
 	for idx, orgUnit := range req.OrgUnits {
@@ -337,54 +311,42 @@ func (s *Service) performTransfer(taskID string, profile *models.ConnectionProfi
 			// Check max runtime
 			if time.Since(startTime).Seconds() > float64(req.MaxRuntimeSeconds) {
 				s.appendMessage(taskID, "Max runtime reached; finishing early with partial results")
-				s.finalizeTransfer(taskID, totalFetched, totalSent, batchesSent, req.DryRun, true)
+				result.Partial = true
+				s.finalizeTransfer(taskID, result, mapper)
 				return
 			}
 
-			params := map[string]string{
-				"program":    req.ProgramID,
-				"orgUnit":    orgUnit,
-				"ouMode":     "DESCENDANTS",
-				"startDate":  req.StartDate,
-				"endDate":    req.EndDate,
-				"page":       fmt.Sprintf("%d", page),
-				"pageSize":   fmt.Sprintf("%d", pageSize),
-				"totalPages": "true",
-			}
-
-			if req.ProgramStage != "" {
-				params["programStage"] = req.ProgramStage
-			}
-			if req.Status != "" {
-				params["status"] = req.Status
-			}
-
-			resp, err := srcClient.Get("/api/events", params)
+			events, pageCount, err := fetchEventPage(srcClient, srcModern, eventQuery{
+				Program:      req.ProgramID,
+				OrgUnit:      orgUnit,
+				StartDate:    req.StartDate,
+				EndDate:      req.EndDate,
+				ProgramStage: req.ProgramStage,
+				Status:       req.Status,
+				Page:         page,
+				PageSize:     pageSize,
+			})
 			if err != nil {
 				s.appendMessage(taskID, fmt.Sprintf("Fetch failed for %s page %d: %v", orgUnit, page, err))
 				break
 			}
-
-			var data map[string]interface{}
-			if err := json.Unmarshal(resp.Body(), &data); err != nil {
-				s.appendMessage(taskID, fmt.Sprintf("Failed to parse events: %v", err))
-				break
-			}
-
-			events, _ := data["events"].([]interface{})
 			if len(events) == 0 {
 				break
 			}
 
-			totalFetched += len(events)
+			result.TotalFetched += len(events)
 
-			// Transform events to minimal payload
-			transformed := []map[string]interface{}{}
-			for _, evt := range events {
-				if evtMap, ok := evt.(map[string]interface{}); ok {
-					minimal := minimalEvent(evtMap)
-					transformed = append(transformed, minimal)
-				}
+			// Transform events to minimal payload, leaving out those whose enrollment the destination lacks
+			transformed, missingEnrollment, lookupErr := prepareEvents(events, enrollments)
+			for _, evt := range transformed {
+				mapper.apply(evt)
+			}
+			if lookupErr != nil {
+				s.appendMessage(taskID, fmt.Sprintf("⚠ Could not verify some enrollments in destination, sending those events anyway: %v", lookupErr))
+			}
+			if missingEnrollment > 0 {
+				result.SkippedMissingEnrollment += missingEnrollment
+				s.appendMessage(taskID, fmt.Sprintf("⚠ Skipped %d events (OU %s, page %d) because their enrollment is missing in destination", missingEnrollment, orgUnit, page))
 			}
 
 			if req.DryRun {
@@ -399,41 +361,22 @@ func (s *Service) performTransfer(taskID string, profile *models.ConnectionProfi
 					}
 					batch := transformed[i:end]
 
-					payload := map[string]interface{}{
-						"events": batch,
-					}
-
-					_, err := destClient.Post("/api/events", payload)
+					counts, err := postEvents(destClient, destModern, batch)
+					result.Created += counts.Created
+					result.Updated += counts.Updated
+					result.Ignored += counts.Ignored
 					if err != nil {
 						s.appendMessage(taskID, fmt.Sprintf("✗ Failed to send batch (OU %s, page %d): %v", orgUnit, page, err))
 					} else {
-						totalSent += len(batch)
-						batchesSent++
-						s.appendMessage(taskID, fmt.Sprintf("✓ Sent %d events (OU %s, batch %d, page %d)", len(batch), orgUnit, batchesSent, page))
+						result.TotalSent += len(batch)
+						result.BatchesSent++
+						s.appendMessage(taskID, fmt.Sprintf("✓ Sent %d events (OU %s, batch %d, page %d): %d created, %d updated, %d ignored",
+							len(batch), orgUnit, result.BatchesSent, page, counts.Created, counts.Updated, counts.Ignored))
 					}
 				}
 			}
 
-			// Update progress
-			s.transferMu.Lock()
-			if p, exists := s.transferStore[taskID]; exists {
-				p.Progress = min(95, p.Progress+2)
-				// Trim messages to prevent memory growth
-				if len(p.Messages) > 500 {
-					p.Messages = p.Messages[len(p.Messages)-500:]
-				}
-			}
-			s.transferMu.Unlock()
-
-			// Small sleep to avoid blocking
-			time.Sleep(10 * time.Millisecond)
-
-			// Check pagination
-			pager, _ := data["pager"].(map[string]interface{})
-			pageCount := 1
-			if pc, ok := pager["pageCount"].(float64); ok {
-				pageCount = int(pc)
-			}
+			s.advancePage(taskID)
 
 			if page >= pageCount {
 				break
@@ -442,40 +385,84 @@ func (s *Service) performTransfer(taskID string, profile *models.ConnectionProfi
 		}
 	}
 
-	s.finalizeTransfer(taskID, totalFetched, totalSent, batchesSent, req.DryRun, false)
+	s.finalizeTransfer(taskID, result, mapper)
 }
 
-func (s *Service) finalizeTransfer(taskID string, fetched, sent, batches int, dryRun, partial bool) {
+// detectTrackerAPI picks the endpoints for a transfer: 2.40+ is read from and imported
+// into through /api/tracker, older servers (or ones whose version can't be read) through
+// the deprecated endpoints
+func (s *Service) detectTrackerAPI(taskID string, srcClient, destClient *api.Client) (srcModern, destModern bool) {
+	srcModern, err := usesTrackerAPI(srcClient)
+	if err != nil {
+		s.appendMessage(taskID, fmt.Sprintf("⚠ Could not read source version, using the deprecated tracker endpoints: %v", err))
+	}
+	destModern, err = usesTrackerAPI(destClient)
+	if err != nil {
+		s.appendMessage(taskID, fmt.Sprintf("⚠ Could not read destination version, using the deprecated tracker endpoints: %v", err))
+	}
+	return srcModern, destModern
+}
+
+// advancePage nudges a task's progress after a page and trims its messages
+func (s *Service) advancePage(taskID string) {
+	s.transferMu.Lock()
+	if p, exists := s.transferStore[taskID]; exists {
+		p.Progress = min(95, p.Progress+2)
+		// Trim messages to prevent memory growth
+		if len(p.Messages) > 500 {
+			p.Messages = p.Messages[len(p.Messages)-500:]
+		}
+	}
+	s.transferMu.Unlock()
+
+	// Small sleep to avoid blocking
+	time.Sleep(10 * time.Millisecond)
+}
+
+func (s *Service) finalizeTransfer(taskID string, result TransferResult, mapper *eventMapper) {
+	mapper.record(&result)
+
+	noun := "events"
+	if result.TrackedEntities {
+		noun = "tracked entities"
+	}
+	msg := fmt.Sprintf("Done. Fetched %d %s, sent %d across %d batches", result.TotalFetched, noun, result.TotalSent, result.BatchesSent)
+	if result.TrackedEntities && !result.DryRun {
+		msg += fmt.Sprintf(" with %d enrollments and %d events", result.EnrollmentsSent, result.EventsSent)
+	}
+	if !result.DryRun {
+		msg += fmt.Sprintf(" (%d created, %d updated, %d ignored)", result.Created, result.Updated, result.Ignored)
+	}
+	if result.SkippedMissingEnrollment > 0 {
+		msg += fmt.Sprintf("; %d events skipped because their enrollment is missing in destination", result.SkippedMissingEnrollment)
+	}
+	if result.UnmappedValues > 0 {
+		msg += fmt.Sprintf("; %d values dropped for %d unmapped data elements", result.UnmappedValues, len(result.UnmappedElements))
+	}
+	if result.Partial {
+		msg += " (partial - stopped due to runtime limit)"
+	}
+
 	s.transferMu.Lock()
 	if p, exists := s.transferStore[taskID]; exists {
 		p.Status = "completed"
 		p.Progress = 100
-		p.Results = &TransferResult{
-			TotalFetched: fetched,
-			TotalSent:    sent,
-			BatchesSent:  batches,
-			DryRun:       dryRun,
-			Partial:      partial,
-		}
+		p.Results = &result
 		p.CompletedAt = time.Now().Unix()
-
-		msg := fmt.Sprintf("Done. Fetched %d events, sent %d across %d batches", fetched, sent, batches)
-		if partial {
-			msg += " (partial - stopped due to runtime limit)"
-		}
 		p.Messages = append(p.Messages, msg)
 	}
 	s.transferMu.Unlock()
 
 	s.emitTransferEvent(taskID)
+	notifications.TaskFinished(taskID, "tracker", "completed", msg)
 }
 
 func (s *Service) updateProgress(taskID, status string, progress int, message string) {
 	s.transferMu.Lock()
-	defer s.transferMu.Unlock()
-
 	updated := false
+	statusChanged := false
 	if p, exists := s.transferStore[taskID]; exists {
+		statusChanged = p.Status != status
 		p.Status = status
 		p.Progress = progress
 		if message != "" {
@@ -483,10 +470,14 @@ func (s *Service) updateProgress(taskID, status string, progress int, message st
 		}
 		updated = true
 	}
+	s.transferMu.Unlock()
 
 	if updated {
 		go s.emitTransferEvent(taskID)
 	}
+	if statusChanged {
+		notifications.TaskFinished(taskID, "tracker", status, message)
+	}
 }
 
 func (s *Service) appendMessage(taskID, message string) {
@@ -535,7 +526,7 @@ func (s *Service) emitTransferEvent(taskID string) {
 		payload["completed_at"] = progress.CompletedAt
 	}
 
-	runtime.EventsEmit(s.ctx, fmt.Sprintf("tracker:%s", taskID), payload)
+	events.EmitProgress(s.ctx, fmt.Sprintf("tracker:%s", taskID), "tracker", payload)
 }
 
 // minimalEvent transforms a source event to a minimal payload
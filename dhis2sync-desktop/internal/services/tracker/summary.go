@@ -0,0 +1,96 @@
+package tracker
+
+import "sort"
+
+// previewSummarizer accumulates a PreviewSummary over raw /api/events entries
+type previewSummarizer struct {
+	events   int
+	stages   map[string]int
+	statuses map[string]int
+	elements map[string]int
+}
+
+func newPreviewSummarizer() *previewSummarizer {
+	return &previewSummarizer{
+		stages:   make(map[string]int),
+		statuses: make(map[string]int),
+		elements: make(map[string]int),
+	}
+}
+
+// add counts one event's stage, status and the data elements it holds a value for
+func (p *previewSummarizer) add(evt map[string]interface{}) {
+	p.events++
+
+	if stage, _ := evt["programStage"].(string); stage != "" {
+		p.stages[stage]++
+	}
+
+	status, _ := evt["status"].(string)
+	if status == "" {
+		status = "UNKNOWN"
+	}
+	p.statuses[status]++
+
+	values, _ := evt["dataValues"].([]interface{})
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		dv, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		de, _ := dv["dataElement"].(string)
+		if de == "" || seen[de] || isBlankValue(dv["value"]) {
+			continue
+		}
+		seen[de] = true
+		p.elements[de]++
+	}
+}
+
+// summary returns stages and elements ordered by event count (then ID)
+func (p *previewSummarizer) summary() *PreviewSummary {
+	out := &PreviewSummary{
+		EventsScanned: p.events,
+		ProgramStages: make([]StageCount, 0, len(p.stages)),
+		StatusCounts:  p.statuses,
+		DataElements:  make([]ElementCoverage, 0, len(p.elements)),
+	}
+
+	for id, n := range p.stages {
+		out.ProgramStages = append(out.ProgramStages, StageCount{ID: id, Events: n})
+	}
+	sort.Slice(out.ProgramStages, func(i, j int) bool {
+		a, b := out.ProgramStages[i], out.ProgramStages[j]
+		if a.Events != b.Events {
+			return a.Events > b.Events
+		}
+		return a.ID < b.ID
+	})
+
+	for id, n := range p.elements {
+		out.DataElements = append(out.DataElements, ElementCoverage{
+			ID:       id,
+			Events:   n,
+			Coverage: float64(n) / float64(p.events) * 100,
+		})
+	}
+	sort.Slice(out.DataElements, func(i, j int) bool {
+		a, b := out.DataElements[i], out.DataElements[j]
+		if a.Events != b.Events {
+			return a.Events > b.Events
+		}
+		return a.ID < b.ID
+	})
+
+	return out
+}
+
+// isBlankValue reports whether an event data value is missing or an empty string
+func isBlankValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	s, ok := v.(string)
+	return ok && s == ""
+}
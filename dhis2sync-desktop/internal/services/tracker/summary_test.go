@@ -0,0 +1,51 @@
+package tracker
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewSummarizer(t *testing.T) {
+	var page struct {
+		Events []map[string]interface{} `json:"events"`
+	}
+	err := json.Unmarshal([]byte(`{"events":[
+		{"programStage":"stageVisit1","status":"COMPLETED","dataValues":[{"dataElement":"deWeight01","value":"3.2"},{"dataElement":"deHeight01","value":"50"}]},
+		{"programStage":"stageVisit1","status":"ACTIVE","dataValues":[{"dataElement":"deWeight01","value":"3.9"},{"dataElement":"deHeight01","value":""}]},
+		{"programStage":"stageBirth1","status":"COMPLETED","dataValues":[{"dataElement":"deWeight01","value":"2.8"}]},
+		{"programStage":"stageVisit1","dataValues":[]}
+	]}`), &page)
+	require.NoError(t, err)
+
+	summarizer := newPreviewSummarizer()
+	for _, evt := range page.Events {
+		summarizer.add(evt)
+	}
+	summary := summarizer.summary()
+
+	t.Run("Should count distinct stages, most events first", func(t *testing.T) {
+		assert.Equal(t, 4, summary.EventsScanned)
+		assert.Equal(t, []StageCount{{ID: "stageVisit1", Events: 3}, {ID: "stageBirth1", Events: 1}}, summary.ProgramStages)
+	})
+
+	t.Run("Should report the status distribution", func(t *testing.T) {
+		assert.Equal(t, map[string]int{"COMPLETED": 2, "ACTIVE": 1, "UNKNOWN": 1}, summary.StatusCounts)
+	})
+
+	t.Run("Should measure element coverage ignoring blank values", func(t *testing.T) {
+		require.Len(t, summary.DataElements, 2)
+		assert.Equal(t, ElementCoverage{ID: "deWeight01", Events: 3, Coverage: 75}, summary.DataElements[0])
+		assert.Equal(t, ElementCoverage{ID: "deHeight01", Events: 1, Coverage: 25}, summary.DataElements[1])
+	})
+
+	t.Run("Should summarize an empty preview", func(t *testing.T) {
+		empty := newPreviewSummarizer().summary()
+
+		assert.Equal(t, 0, empty.EventsScanned)
+		assert.Empty(t, empty.ProgramStages)
+		assert.Empty(t, empty.DataElements)
+	})
+}
@@ -0,0 +1,456 @@
+package tracker
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"dhis2sync-desktop/internal/api"
+	"dhis2sync-desktop/internal/models"
+)
+
+// Tracked entities carry their enrollments and events, so pages stay smaller than event pages
+const (
+	defaultTEIPageSize = 50
+	maxTEIPageSize     = 200
+)
+
+// teiFieldNames maps /api/trackedEntityInstances field names to their /api/tracker equivalents
+var teiFieldNames = map[string]string{
+	"trackedEntityInstance": "trackedEntity",
+}
+
+// enrollmentFieldNames maps /api/enrollments field names to their /api/tracker equivalents
+var enrollmentFieldNames = map[string]string{
+	"enrollmentDate":        "enrolledAt",
+	"incidentDate":          "occurredAt",
+	"completedDate":         "completedAt",
+	"trackedEntityInstance": "trackedEntity",
+}
+
+var (
+	legacyTEIFieldNames        = invertNames(teiFieldNames)
+	legacyEnrollmentFieldNames = invertNames(enrollmentFieldNames)
+)
+
+// trackedEntitiesEndpoint names the endpoint tracked entities are read from
+func trackedEntitiesEndpoint(modern bool) string {
+	if modern {
+		return "/api/tracker/trackedEntities"
+	}
+	return "/api/trackedEntityInstances"
+}
+
+// fetchTEIPage reads one page of a program's tracked entities with their attributes,
+// enrollments and events, in the /api/trackedEntityInstances shape whichever endpoint
+// served them, along with the page count. StartDate and EndDate bound the enrollment date.
+func fetchTEIPage(client *api.Client, modern bool, q eventQuery) ([]interface{}, int, error) {
+	params := map[string]string{
+		"program":    q.Program,
+		"ouMode":     "DESCENDANTS",
+		"page":       strconv.Itoa(q.Page),
+		"pageSize":   strconv.Itoa(q.PageSize),
+		"totalPages": "true",
+		"fields":     "trackedEntityInstance,trackedEntityType,orgUnit,inactive,geometry,attributes[attribute,value],enrollments[*,events[*]]",
+	}
+	key := "trackedEntityInstances"
+	after, before := "programStartDate", "programEndDate"
+	if modern {
+		params["orgUnit"] = q.OrgUnit
+		params["fields"] = "trackedEntity,trackedEntityType,orgUnit,inactive,geometry,attributes[attribute,value],enrollments[*,events[*]]"
+		key = "trackedEntities"
+		after, before = "enrollmentEnrolledAfter", "enrollmentEnrolledBefore"
+	} else {
+		params["ou"] = q.OrgUnit
+	}
+	if q.StartDate != "" {
+		params[after] = q.StartDate
+	}
+	if q.EndDate != "" {
+		params[before] = q.EndDate
+	}
+
+	endpoint := trackedEntitiesEndpoint(modern)
+	resp, err := client.Get(endpoint, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	teis, pageCount, err := decodePage(resp, endpoint, key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if modern {
+		for i, tei := range teis {
+			if teiMap, ok := tei.(map[string]interface{}); ok {
+				teis[i] = legacyTEI(teiMap)
+			}
+		}
+	}
+	return teis, pageCount, nil
+}
+
+// legacyTEI renames an /api/tracker tracked entity's fields, and those of its
+// enrollments and events, to their deprecated endpoints' names
+func legacyTEI(tei map[string]interface{}) map[string]interface{} {
+	out := renameFields(tei, legacyTEIFieldNames)
+
+	enrollments, _ := out["enrollments"].([]interface{})
+	for i, enr := range enrollments {
+		enrMap, ok := enr.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		enrMap = renameFields(enrMap, legacyEnrollmentFieldNames)
+		events, _ := enrMap["events"].([]interface{})
+		for j, evt := range events {
+			if evtMap, ok := evt.(map[string]interface{}); ok {
+				events[j] = legacyEvent(evtMap)
+			}
+		}
+		enrollments[i] = enrMap
+	}
+	return out
+}
+
+// teiBundle is a page of tracked entities split into import payloads, each linked to
+// its parent by UID. They must be imported in field order: an enrollment needs its
+// tracked entity and an event its enrollment.
+type teiBundle struct {
+	TEIs        []map[string]interface{}
+	Enrollments []map[string]interface{}
+	Events      []map[string]interface{}
+}
+
+// splitTEIs turns source tracked entities into minimal payloads: the entities with
+// their attributes, their enrollments in the program, and those enrollments' events
+func splitTEIs(teis []interface{}, programID string) teiBundle {
+	var bundle teiBundle
+
+	for _, tei := range teis {
+		teiMap, ok := tei.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		teiID, _ := teiMap["trackedEntityInstance"].(string)
+		if teiID == "" {
+			continue
+		}
+		bundle.TEIs = append(bundle.TEIs, minimalTEI(teiMap))
+
+		enrollments, _ := teiMap["enrollments"].([]interface{})
+		for _, enr := range enrollments {
+			enrMap, ok := enr.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			enrollmentID, _ := enrMap["enrollment"].(string)
+			program, _ := enrMap["program"].(string)
+			if enrollmentID == "" || (programID != "" && program != "" && program != programID) {
+				continue
+			}
+			bundle.Enrollments = append(bundle.Enrollments, minimalEnrollment(enrMap, teiID))
+
+			events, _ := enrMap["events"].([]interface{})
+			for _, evt := range events {
+				evtMap, ok := evt.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				minimal := minimalEvent(evtMap)
+				if id, ok := evtMap["event"]; ok {
+					minimal["event"] = id
+				}
+				if _, ok := minimal["program"]; !ok && program != "" {
+					minimal["program"] = program
+				}
+				minimal["enrollment"] = enrollmentID
+				minimal["trackedEntityInstance"] = teiID
+				bundle.Events = append(bundle.Events, minimal)
+			}
+		}
+	}
+
+	return bundle
+}
+
+// minimalTEI keeps a tracked entity's identity, placement and attribute values
+func minimalTEI(tei map[string]interface{}) map[string]interface{} {
+	out := pickFields(tei, "trackedEntityInstance", "trackedEntityType", "orgUnit", "inactive", "geometry")
+
+	attributes, _ := tei["attributes"].([]interface{})
+	cleaned := []map[string]interface{}{}
+	for _, attr := range attributes {
+		if attrMap, ok := attr.(map[string]interface{}); ok {
+			cleaned = append(cleaned, pickFields(attrMap, "attribute", "value"))
+		}
+	}
+	out["attributes"] = cleaned
+
+	return out
+}
+
+// minimalEnrollment keeps an enrollment's identity, dates and status, linked to its tracked entity
+func minimalEnrollment(enrollment map[string]interface{}, teiID string) map[string]interface{} {
+	out := pickFields(enrollment, "enrollment", "program", "orgUnit", "enrollmentDate", "incidentDate", "status", "completedDate", "geometry")
+	out["trackedEntityInstance"] = teiID
+	return out
+}
+
+// pickFields copies the named fields that m has
+func pickFields(m map[string]interface{}, fields ...string) map[string]interface{} {
+	out := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if v, ok := m[field]; ok {
+			out[field] = v
+		}
+	}
+	return out
+}
+
+// teiImportCounts is what importing a bundle did to each kind of object
+type teiImportCounts struct {
+	TEIs        importCounts
+	Enrollments importCounts
+	Events      importCounts
+}
+
+// total adds up the counts across kinds
+func (c teiImportCounts) total() importCounts {
+	var total importCounts
+	total.add(c.TEIs)
+	total.add(c.Enrollments)
+	total.add(c.Events)
+	return total
+}
+
+// postTEIBundle imports a bundle parents first: tracked entities, then enrollments,
+// then events. A failed stage stops the bundle, since its children would be rejected;
+// the counts cover the stages that ran.
+func postTEIBundle(client *api.Client, modern bool, bundle teiBundle) (teiImportCounts, error) {
+	var counts teiImportCounts
+	var err error
+
+	if len(bundle.TEIs) > 0 {
+		if modern {
+			counts.TEIs, err = postTrackerBundle(client, map[string]interface{}{"trackedEntities": renameAll(bundle.TEIs, teiFieldNames)})
+		} else {
+			counts.TEIs, err = postLegacyImport(client, "/api/trackedEntityInstances?strategy=CREATE_AND_UPDATE", "trackedEntityInstances", bundle.TEIs)
+		}
+		if err != nil {
+			return counts, fmt.Errorf("tracked entities: %w", err)
+		}
+	}
+
+	if len(bundle.Enrollments) > 0 {
+		if modern {
+			counts.Enrollments, err = postTrackerBundle(client, map[string]interface{}{"enrollments": renameAll(bundle.Enrollments, enrollmentFieldNames)})
+		} else {
+			counts.Enrollments, err = postLegacyImport(client, "/api/enrollments?strategy=CREATE_AND_UPDATE", "enrollments", bundle.Enrollments)
+		}
+		if err != nil {
+			return counts, fmt.Errorf("enrollments: %w", err)
+		}
+	}
+
+	if len(bundle.Events) > 0 {
+		counts.Events, err = postEvents(client, modern, bundle.Events)
+		if err != nil {
+			return counts, fmt.Errorf("events: %w", err)
+		}
+	}
+
+	return counts, nil
+}
+
+// renameAll renames the fields of every payload in items
+func renameAll(items []map[string]interface{}, names map[string]string) []map[string]interface{} {
+	out := make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		out[i] = renameFields(item, names)
+	}
+	return out
+}
+
+// teiPageSize bounds a requested tracked entity page size
+func teiPageSize(requested int) int {
+	if requested <= 0 {
+		return defaultTEIPageSize
+	}
+	if requested > maxTEIPageSize {
+		return maxTEIPageSize
+	}
+	return requested
+}
+
+// PreviewTEIs fetches a preview of a program's tracked entities with their
+// enrollments and events, counting what a tracked entity transfer would send
+func (s *Service) PreviewTEIs(req PreviewRequest) (*TEIPreviewResponse, error) {
+	profile, err := s.getProfile(req.ProfileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	client, err := s.getAPIClient(profile, req.Instance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	previewCap := req.PreviewCap
+	if previewCap <= 0 {
+		previewCap = 1000
+	}
+	pageSize := teiPageSize(req.PageSize)
+
+	// Older servers, or ones whose version can't be read, are read through /api/trackedEntityInstances
+	modern, _ := usesTrackerAPI(client)
+
+	preview := &TEIPreviewResponse{
+		ProgramID: req.ProgramID,
+		OrgUnits:  req.OrgUnits,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Sample:    []map[string]interface{}{},
+	}
+
+	for _, orgUnit := range req.OrgUnits {
+		page := 1
+		for preview.EstimateTotal < previewCap {
+			teis, pageCount, err := fetchTEIPage(client, modern, eventQuery{
+				Program:   req.ProgramID,
+				OrgUnit:   orgUnit,
+				StartDate: req.StartDate,
+				EndDate:   req.EndDate,
+				Page:      page,
+				PageSize:  pageSize,
+			})
+			if err != nil || len(teis) == 0 {
+				break
+			}
+
+			bundle := splitTEIs(teis, req.ProgramID)
+			preview.EstimateTotal += len(bundle.TEIs)
+			preview.Enrollments += len(bundle.Enrollments)
+			preview.Events += len(bundle.Events)
+
+			// Collect up to 5 sample tracked entities
+			for _, tei := range bundle.TEIs {
+				if len(preview.Sample) >= 5 {
+					break
+				}
+				preview.Sample = append(preview.Sample, tei)
+			}
+
+			if page >= pageCount {
+				break
+			}
+			page++
+		}
+	}
+
+	return preview, nil
+}
+
+// StartTEITransfer initiates a background transfer of a program's tracked entities
+// with their attributes, enrollments and events
+func (s *Service) StartTEITransfer(req TransferRequest) (string, error) {
+	return s.startTransferTask(req, "Starting tracked entity transfer...", s.performTEITransfer)
+}
+
+func (s *Service) performTEITransfer(taskID string, profile *models.ConnectionProfile, req TransferRequest) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.updateProgress(taskID, "error", 0, fmt.Sprintf("Panic: %v", r))
+		}
+	}()
+
+	s.updateProgress(taskID, "running", 5, "Creating API clients...")
+
+	srcClient, err := s.getAPIClient(profile, "source")
+	if err != nil {
+		s.updateProgress(taskID, "error", 0, fmt.Sprintf("Failed to create source client: %v", err))
+		return
+	}
+
+	destClient, err := s.getAPIClient(profile, "dest")
+	if err != nil {
+		s.updateProgress(taskID, "error", 0, fmt.Sprintf("Failed to create destination client: %v", err))
+		return
+	}
+
+	pageSize := teiPageSize(req.BatchSize)
+	srcModern, destModern := s.detectTrackerAPI(taskID, srcClient, destClient)
+	s.appendMessage(taskID, fmt.Sprintf("Reading tracked entities via %s, importing via %s", trackedEntitiesEndpoint(srcModern), importEndpoint(destModern)))
+
+	result := TransferResult{DryRun: req.DryRun, TrackerAPI: destModern, TrackedEntities: true}
+	mapper := newEventMapper(req.ElementMapping, req.ProgramStageMapping)
+	startTime := time.Now()
+
+	for idx, orgUnit := range req.OrgUnits {
+		s.appendMessage(taskID, fmt.Sprintf("Processing OU %d/%d: %s", idx+1, len(req.OrgUnits), orgUnit))
+
+		page := 1
+		for page <= req.MaxPages {
+			// Check max runtime
+			if time.Since(startTime).Seconds() > float64(req.MaxRuntimeSeconds) {
+				s.appendMessage(taskID, "Max runtime reached; finishing early with partial results")
+				result.Partial = true
+				s.finalizeTransfer(taskID, result, mapper)
+				return
+			}
+
+			teis, pageCount, err := fetchTEIPage(srcClient, srcModern, eventQuery{
+				Program:   req.ProgramID,
+				OrgUnit:   orgUnit,
+				StartDate: req.StartDate,
+				EndDate:   req.EndDate,
+				Page:      page,
+				PageSize:  pageSize,
+			})
+			if err != nil {
+				s.appendMessage(taskID, fmt.Sprintf("Fetch failed for %s page %d: %v", orgUnit, page, err))
+				break
+			}
+			if len(teis) == 0 {
+				break
+			}
+
+			result.TotalFetched += len(teis)
+			bundle := splitTEIs(teis, req.ProgramID)
+			for _, evt := range bundle.Events {
+				mapper.apply(evt)
+			}
+
+			if req.DryRun {
+				s.appendMessage(taskID, fmt.Sprintf("Dry-run: would send %d tracked entities, %d enrollments and %d events (OU %s, page %d)",
+					len(bundle.TEIs), len(bundle.Enrollments), len(bundle.Events), orgUnit, page))
+			} else {
+				counts, err := postTEIBundle(destClient, destModern, bundle)
+				total := counts.total()
+				result.Created += total.Created
+				result.Updated += total.Updated
+				result.Ignored += total.Ignored
+				if err != nil {
+					s.appendMessage(taskID, fmt.Sprintf("✗ Failed to send page (OU %s, page %d): %v", orgUnit, page, err))
+				} else {
+					result.TotalSent += len(bundle.TEIs)
+					result.EnrollmentsSent += len(bundle.Enrollments)
+					result.EventsSent += len(bundle.Events)
+					result.BatchesSent++
+					s.appendMessage(taskID, fmt.Sprintf("✓ Sent %d tracked entities, %d enrollments and %d events (OU %s, page %d): %d created, %d updated, %d ignored",
+						len(bundle.TEIs), len(bundle.Enrollments), len(bundle.Events), orgUnit, page, total.Created, total.Updated, total.Ignored))
+				}
+			}
+
+			s.advancePage(taskID)
+
+			if page >= pageCount {
+				break
+			}
+			page++
+		}
+	}
+
+	s.finalizeTransfer(taskID, result, mapper)
+}
@@ -0,0 +1,135 @@
+package tracker
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"dhis2sync-desktop/internal/api/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitTEIs(t *testing.T) {
+	var teis []interface{}
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"trackedEntityInstance":"tei1","trackedEntityType":"person","orgUnit":"ou1","created":"2024-01-01",
+		 "attributes":[{"attribute":"firstName","value":"Ada","displayName":"First name"}],
+		 "enrollments":[
+			{"enrollment":"enr1","program":"prg1","orgUnit":"ou1","enrollmentDate":"2024-01-02","status":"ACTIVE",
+			 "events":[{"event":"ev1","programStage":"ps1","orgUnit":"ou1","eventDate":"2024-01-03","dataValues":[{"dataElement":"de1","value":"5","lastUpdated":"x"}]}]},
+			{"enrollment":"enr2","program":"otherProg","events":[{"event":"ev2"}]}
+		 ]},
+		{"trackedEntityType":"person"}
+	]`), &teis))
+
+	bundle := splitTEIs(teis, "prg1")
+
+	t.Run("Should keep tracked entities with their attribute values", func(t *testing.T) {
+		require.Len(t, bundle.TEIs, 1)
+		assert.Equal(t, "tei1", bundle.TEIs[0]["trackedEntityInstance"])
+		assert.NotContains(t, bundle.TEIs[0], "created")
+		assert.NotContains(t, bundle.TEIs[0], "enrollments")
+		assert.Equal(t, []map[string]interface{}{{"attribute": "firstName", "value": "Ada"}}, bundle.TEIs[0]["attributes"])
+	})
+
+	t.Run("Should keep only the program's enrollments, linked to their tracked entity", func(t *testing.T) {
+		require.Len(t, bundle.Enrollments, 1)
+		assert.Equal(t, "enr1", bundle.Enrollments[0]["enrollment"])
+		assert.Equal(t, "tei1", bundle.Enrollments[0]["trackedEntityInstance"])
+		assert.NotContains(t, bundle.Enrollments[0], "events")
+	})
+
+	t.Run("Should link events to their enrollment and tracked entity", func(t *testing.T) {
+		require.Len(t, bundle.Events, 1)
+		evt := bundle.Events[0]
+		assert.Equal(t, "ev1", evt["event"])
+		assert.Equal(t, "prg1", evt["program"])
+		assert.Equal(t, "enr1", evt["enrollment"])
+		assert.Equal(t, "tei1", evt["trackedEntityInstance"])
+	})
+}
+
+func TestPostTEIBundle(t *testing.T) {
+	bundle := teiBundle{
+		TEIs:        []map[string]interface{}{{"trackedEntityInstance": "tei1"}},
+		Enrollments: []map[string]interface{}{{"enrollment": "enr1", "trackedEntityInstance": "tei1", "enrollmentDate": "2024-01-02"}},
+		Events:      []map[string]interface{}{{"event": "ev1", "enrollment": "enr1", "trackedEntityInstance": "tei1"}},
+	}
+
+	t.Run("Should import tracked entities, then enrollments, then events", func(t *testing.T) {
+		var order []string
+		record := func(name string) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				apitest.Raw(http.StatusOK, `{"response":{"imported":1}}`)(w, r)
+			}
+		}
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/trackedEntityInstances": record("teis"),
+			"POST /api/enrollments":            record("enrollments"),
+			"POST /api/events":                 record("events"),
+		})
+
+		counts, err := postTEIBundle(srv.Client(), false, bundle)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"teis", "enrollments", "events"}, order)
+		assert.Equal(t, 3, counts.total().Created)
+	})
+
+	t.Run("Should stop before the children of a failed stage", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/trackedEntityInstances": apitest.Raw(http.StatusOK, `{"response":{"imported":1}}`),
+			"POST /api/enrollments":            apitest.Raw(http.StatusForbidden, "Forbidden"),
+		})
+
+		counts, err := postTEIBundle(srv.Client(), false, bundle)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "enrollments")
+		assert.Equal(t, 1, counts.TEIs.Created)
+		assert.Equal(t, 0, srv.Hits("/api/events"))
+	})
+
+	t.Run("Should send /api/tracker field names", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/tracker":             apitest.Raw(http.StatusOK, `{"response":{"id":"job1"}}`),
+			"/api/tracker/jobs/job1":        apitest.Raw(http.StatusOK, `[{"completed":true}]`),
+			"/api/tracker/jobs/job1/report": apitest.Raw(http.StatusOK, `{"status":"OK","stats":{"created":1}}`),
+		})
+
+		_, err := postTEIBundle(srv.Client(), true, teiBundle{Enrollments: bundle.Enrollments})
+
+		require.NoError(t, err)
+		var sent struct {
+			Enrollments []map[string]interface{} `json:"enrollments"`
+		}
+		require.NoError(t, json.Unmarshal(srv.LastBody("/api/tracker"), &sent))
+		require.Len(t, sent.Enrollments, 1)
+		assert.Equal(t, "tei1", sent.Enrollments[0]["trackedEntity"])
+		assert.Equal(t, "2024-01-02", sent.Enrollments[0]["enrolledAt"])
+	})
+}
+
+func TestFetchTEIPage(t *testing.T) {
+	t.Run("Should read /api/tracker/trackedEntities in the deprecated shape", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/tracker/trackedEntities": apitest.Raw(http.StatusOK, `{"pager":{"pageCount":2},"trackedEntities":[
+				{"trackedEntity":"tei1","enrollments":[{"enrollment":"enr1","enrolledAt":"2024-01-02","trackedEntity":"tei1",
+					"events":[{"event":"ev1","occurredAt":"2024-01-03"}]}]}
+			]}`),
+		})
+
+		teis, pageCount, err := fetchTEIPage(srv.Client(), true, eventQuery{Program: "prg1", OrgUnit: "ou1", Page: 1, PageSize: 50})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, pageCount)
+		bundle := splitTEIs(teis, "prg1")
+		require.Len(t, bundle.Events, 1)
+		assert.Equal(t, "tei1", bundle.TEIs[0]["trackedEntityInstance"])
+		assert.Equal(t, "2024-01-02", bundle.Enrollments[0]["enrollmentDate"])
+		assert.Equal(t, "2024-01-03", bundle.Events[0]["eventDate"])
+	})
+}
@@ -0,0 +1,346 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"dhis2sync-desktop/internal/api"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// trackerAPIMinor is the first 2.x release read from and imported into through
+// /api/tracker; /api/events is deprecated there and gone or different after it
+const trackerAPIMinor = 40
+
+var (
+	// trackerJobPollInterval spaces polls of an async tracker import job
+	trackerJobPollInterval = 2 * time.Second
+	// trackerJobTimeout bounds the wait for one async tracker import job
+	trackerJobTimeout = 30 * time.Minute
+)
+
+// usesTrackerAPI reports whether the instance should be served by /api/tracker,
+// judging by the version in api/system/info
+func usesTrackerAPI(client *api.Client) (bool, error) {
+	version, err := client.ServerVersion()
+	if err != nil {
+		return false, err
+	}
+	return api.VersionAtLeast(version, 2, trackerAPIMinor), nil
+}
+
+// eventsEndpoint names the endpoint events are read from
+func eventsEndpoint(modern bool) string {
+	if modern {
+		return "/api/tracker/events"
+	}
+	return "/api/events"
+}
+
+// importEndpoint names the endpoint events are imported through
+func importEndpoint(modern bool) string {
+	if modern {
+		return "/api/tracker"
+	}
+	return "/api/events"
+}
+
+// eventQuery selects a page of events
+type eventQuery struct {
+	Program      string
+	OrgUnit      string // Events of this org unit and its descendants
+	StartDate    string // YYYY-MM-DD
+	EndDate      string // YYYY-MM-DD
+	ProgramStage string
+	Status       string
+	Page         int
+	PageSize     int
+}
+
+// fetchEventPage reads one page of events from /api/tracker/events (modern) or
+// /api/events, returning them in the /api/events shape either way, with the page count
+func fetchEventPage(client *api.Client, modern bool, q eventQuery) ([]interface{}, int, error) {
+	params := map[string]string{
+		"program":    q.Program,
+		"orgUnit":    q.OrgUnit,
+		"ouMode":     "DESCENDANTS",
+		"page":       strconv.Itoa(q.Page),
+		"pageSize":   strconv.Itoa(q.PageSize),
+		"totalPages": "true",
+	}
+	if modern {
+		params["occurredAfter"] = q.StartDate
+		params["occurredBefore"] = q.EndDate
+	} else {
+		params["startDate"] = q.StartDate
+		params["endDate"] = q.EndDate
+	}
+	if q.ProgramStage != "" {
+		params["programStage"] = q.ProgramStage
+	}
+	if q.Status != "" {
+		params["status"] = q.Status
+	}
+
+	endpoint := eventsEndpoint(modern)
+	resp, err := client.Get(endpoint, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	events, pageCount, err := decodePage(resp, endpoint, "events")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if modern {
+		for i, evt := range events {
+			if evtMap, ok := evt.(map[string]interface{}); ok {
+				events[i] = legacyEvent(evtMap)
+			}
+		}
+	}
+	return events, pageCount, nil
+}
+
+// decodePage reads a page listed under key with its page count. 2.40's /api/tracker
+// lists "instances" with the pager fields at the top level; other releases list under
+// key with a "pager".
+func decodePage(resp *resty.Response, endpoint, key string) ([]interface{}, int, error) {
+	var data map[string]interface{}
+	if err := api.DecodeJSON(resp, endpoint, &data); err != nil {
+		return nil, 0, err
+	}
+
+	items, ok := data[key].([]interface{})
+	if !ok {
+		items, _ = data["instances"].([]interface{})
+	}
+
+	pageCount := 1
+	pager, ok := data["pager"].(map[string]interface{})
+	if !ok {
+		pager = data
+	}
+	if pc, ok := pager["pageCount"].(float64); ok {
+		pageCount = int(pc)
+	}
+	return items, pageCount, nil
+}
+
+// trackerFieldNames maps /api/events field names to their /api/tracker equivalents
+var trackerFieldNames = map[string]string{
+	"eventDate":             "occurredAt",
+	"dueDate":               "scheduledAt",
+	"completedDate":         "completedAt",
+	"trackedEntityInstance": "trackedEntity",
+}
+
+// legacyEventFieldNames maps /api/tracker event field names back to /api/events
+var legacyEventFieldNames = invertNames(trackerFieldNames)
+
+// legacyEvent renames an /api/tracker event's fields to their /api/events names
+func legacyEvent(evt map[string]interface{}) map[string]interface{} {
+	return renameFields(evt, legacyEventFieldNames)
+}
+
+// trackerEvent renames a minimal /api/events payload's fields for /api/tracker,
+// which has no "coordinate" (geometry carries the location)
+func trackerEvent(evt map[string]interface{}) map[string]interface{} {
+	out := renameFields(evt, trackerFieldNames)
+	delete(out, "coordinate")
+	return out
+}
+
+// renameFields copies m with the keys in names (old -> new) renamed
+func renameFields(m map[string]interface{}, names map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if renamed, ok := names[k]; ok {
+			k = renamed
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// invertNames swaps a field name mapping's direction
+func invertNames(names map[string]string) map[string]string {
+	inverted := make(map[string]string, len(names))
+	for from, to := range names {
+		inverted[to] = from
+	}
+	return inverted
+}
+
+// importCounts is what an event import did
+type importCounts struct {
+	Created int
+	Updated int
+	Deleted int
+	Ignored int
+}
+
+func (c *importCounts) add(o importCounts) {
+	c.Created += o.Created
+	c.Updated += o.Updated
+	c.Deleted += o.Deleted
+	c.Ignored += o.Ignored
+}
+
+// postEvents imports a batch of minimal events through /api/tracker (modern) or /api/events
+func postEvents(client *api.Client, modern bool, batch []map[string]interface{}) (importCounts, error) {
+	if modern {
+		events := make([]map[string]interface{}, len(batch))
+		for i, evt := range batch {
+			events[i] = trackerEvent(evt)
+		}
+		return postTrackerBundle(client, map[string]interface{}{"events": events})
+	}
+	return postLegacyImport(client, "/api/events", "events", batch)
+}
+
+// postLegacyImport imports a batch through one of the deprecated endpoints
+// (/api/events, /api/enrollments, /api/trackedEntityInstances) under key, reading
+// the counts from its import summaries (under "response" since 2.30)
+func postLegacyImport(client *api.Client, endpoint, key string, batch []map[string]interface{}) (importCounts, error) {
+	resp, err := client.Post(endpoint, map[string]interface{}{key: batch})
+	if err != nil {
+		return importCounts{}, err
+	}
+
+	type summaries struct {
+		Imported int `json:"imported"`
+		Updated  int `json:"updated"`
+		Deleted  int `json:"deleted"`
+		Ignored  int `json:"ignored"`
+	}
+	var body struct {
+		summaries
+		Response *summaries `json:"response"`
+	}
+	// Conflicts come back as 409 with summaries, which still say what was imported
+	if err := json.Unmarshal(resp.Body(), &body); err != nil {
+		if !resp.IsSuccess() {
+			return importCounts{}, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), api.BodySnippet(resp.Body(), 200))
+		}
+		return importCounts{}, fmt.Errorf("failed to parse %s import summary: %w", endpoint, err)
+	}
+
+	got := body.summaries
+	if body.Response != nil {
+		got = *body.Response
+	}
+	counts := importCounts{Created: got.Imported, Updated: got.Updated, Deleted: got.Deleted, Ignored: got.Ignored}
+	if !resp.IsSuccess() && counts == (importCounts{}) {
+		return counts, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), api.BodySnippet(resp.Body(), 200))
+	}
+	return counts, nil
+}
+
+// postTrackerBundle submits a tracker bundle ({"events": [...]} and the like, in
+// /api/tracker field names) as an async /api/tracker import, waits for the job and
+// reads the counts from its import report
+func postTrackerBundle(client *api.Client, bundle map[string]interface{}) (importCounts, error) {
+	resp, err := client.Post("/api/tracker?async=true&importStrategy=CREATE_AND_UPDATE&atomicMode=OBJECT", bundle)
+	if err != nil {
+		return importCounts{}, err
+	}
+	if !resp.IsSuccess() {
+		return importCounts{}, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), api.BodySnippet(resp.Body(), 200))
+	}
+
+	var submitted struct {
+		Response struct {
+			ID string `json:"id"`
+		} `json:"response"`
+	}
+	if err := api.DecodeJSON(resp, "/api/tracker", &submitted); err != nil {
+		return importCounts{}, err
+	}
+	if submitted.Response.ID == "" {
+		return importCounts{}, fmt.Errorf("/api/tracker returned no job id: %s", api.BodySnippet(resp.Body(), 200))
+	}
+
+	jobID := submitted.Response.ID
+	if err := waitForTrackerJob(client, jobID); err != nil {
+		return importCounts{}, err
+	}
+	return fetchTrackerReport(client, jobID)
+}
+
+// waitForTrackerJob polls api/tracker/jobs/{uid} until a notification marks it completed
+func waitForTrackerJob(client *api.Client, jobID string) error {
+	endpoint := fmt.Sprintf("/api/tracker/jobs/%s", jobID)
+	deadline := time.Now().Add(trackerJobTimeout)
+
+	for {
+		resp, err := client.Get(endpoint, nil)
+		if err != nil {
+			return fmt.Errorf("failed to poll tracker job %s: %w", jobID, err)
+		}
+
+		var notifications []struct {
+			Completed bool `json:"completed"`
+		}
+		if err := api.DecodeJSON(resp, endpoint, &notifications); err != nil {
+			return err
+		}
+		for _, n := range notifications {
+			if n.Completed {
+				return nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("tracker job %s did not finish within %s", jobID, trackerJobTimeout)
+		}
+		time.Sleep(trackerJobPollInterval)
+	}
+}
+
+// fetchTrackerReport reads a finished job's import report. A report with status
+// ERROR is returned as an error naming the first validation error, along with its counts.
+func fetchTrackerReport(client *api.Client, jobID string) (importCounts, error) {
+	endpoint := fmt.Sprintf("/api/tracker/jobs/%s/report", jobID)
+	resp, err := client.Get(endpoint, nil)
+	if err != nil {
+		return importCounts{}, fmt.Errorf("failed to fetch tracker import report: %w", err)
+	}
+
+	var report struct {
+		Status string `json:"status"`
+		Stats  struct {
+			Created int `json:"created"`
+			Updated int `json:"updated"`
+			Deleted int `json:"deleted"`
+			Ignored int `json:"ignored"`
+		} `json:"stats"`
+		ValidationReport struct {
+			ErrorReports []struct {
+				Message string `json:"message"`
+			} `json:"errorReports"`
+		} `json:"validationReport"`
+	}
+	if err := api.DecodeJSON(resp, endpoint, &report); err != nil {
+		return importCounts{}, err
+	}
+
+	counts := importCounts{
+		Created: report.Stats.Created,
+		Updated: report.Stats.Updated,
+		Deleted: report.Stats.Deleted,
+		Ignored: report.Stats.Ignored,
+	}
+	if report.Status == "ERROR" {
+		reason := "import failed"
+		if len(report.ValidationReport.ErrorReports) > 0 {
+			reason = report.ValidationReport.ErrorReports[0].Message
+		}
+		return counts, fmt.Errorf("tracker job %s: %s", jobID, reason)
+	}
+	return counts, nil
+}
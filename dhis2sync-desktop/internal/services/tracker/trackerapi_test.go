@@ -0,0 +1,144 @@
+package tracker
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"dhis2sync-desktop/internal/api/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchEventPage(t *testing.T) {
+	t.Run("Should read /api/tracker/events with tracker params and legacy field names", func(t *testing.T) {
+		var query map[string]string
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/tracker/events": func(w http.ResponseWriter, r *http.Request) {
+				query = map[string]string{}
+				for k := range r.URL.Query() {
+					query[k] = r.URL.Query().Get(k)
+				}
+				apitest.Raw(http.StatusOK, `{"pager":{"page":1,"pageCount":3},"events":[
+					{"event":"ev1","occurredAt":"2024-01-05T00:00:00.000","trackedEntity":"tei1","enrollment":"enr1"}
+				]}`)(w, r)
+			},
+		})
+
+		events, pageCount, err := fetchEventPage(srv.Client(), true, eventQuery{
+			Program: "prg1", OrgUnit: "ou1", StartDate: "2024-01-01", EndDate: "2024-01-31", Page: 1, PageSize: 50,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, pageCount)
+		assert.Equal(t, "2024-01-01", query["occurredAfter"])
+		assert.Equal(t, "2024-01-31", query["occurredBefore"])
+		assert.NotContains(t, query, "startDate")
+		require.Len(t, events, 1)
+		evt := events[0].(map[string]interface{})
+		assert.Equal(t, "2024-01-05T00:00:00.000", evt["eventDate"])
+		assert.Equal(t, "tei1", evt["trackedEntityInstance"])
+		assert.NotContains(t, evt, "trackedEntity")
+	})
+
+	t.Run("Should read 2.40 instances with a top-level page count", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/tracker/events": apitest.Raw(http.StatusOK, `{"page":1,"pageCount":2,"instances":[{"event":"ev1"},{"event":"ev2"}]}`),
+		})
+
+		events, pageCount, err := fetchEventPage(srv.Client(), true, eventQuery{Page: 1, PageSize: 50})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, pageCount)
+		assert.Len(t, events, 2)
+	})
+
+	t.Run("Should read /api/events on older servers", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/events": apitest.Raw(http.StatusOK, `{"pager":{"pageCount":1},"events":[{"event":"ev1","eventDate":"2024-01-05"}]}`),
+		})
+
+		events, pageCount, err := fetchEventPage(srv.Client(), false, eventQuery{Page: 1, PageSize: 50})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, pageCount)
+		assert.Equal(t, "2024-01-05", events[0].(map[string]interface{})["eventDate"])
+	})
+}
+
+func TestPostEvents(t *testing.T) {
+	trackerJobPollInterval = time.Millisecond
+	batch := []map[string]interface{}{
+		{"event": "ev1", "eventDate": "2024-01-05", "trackedEntityInstance": "tei1", "coordinate": map[string]float64{"latitude": 1}},
+		{"event": "ev2", "eventDate": "2024-01-06"},
+	}
+
+	t.Run("Should import through an async /api/tracker job and read its report", func(t *testing.T) {
+		polls := 0
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/tracker": apitest.JSON(http.StatusOK, map[string]interface{}{
+				"response": map[string]string{"id": "job1", "location": "/api/tracker/jobs/job1"},
+			}),
+			"/api/tracker/jobs/job1": func(w http.ResponseWriter, r *http.Request) {
+				polls++
+				apitest.JSON(http.StatusOK, []map[string]bool{{"completed": polls > 1}})(w, r)
+			},
+			"/api/tracker/jobs/job1/report": apitest.Raw(http.StatusOK, `{"status":"OK","stats":{"created":1,"updated":1,"deleted":0,"ignored":0}}`),
+		})
+
+		counts, err := postEvents(srv.Client(), true, batch)
+
+		require.NoError(t, err)
+		assert.Equal(t, importCounts{Created: 1, Updated: 1}, counts)
+		assert.Equal(t, 2, srv.Hits("/api/tracker/jobs/job1"))
+
+		var sent struct {
+			Events []map[string]interface{} `json:"events"`
+		}
+		require.NoError(t, json.Unmarshal(srv.LastBody("/api/tracker"), &sent))
+		require.Len(t, sent.Events, 2)
+		assert.Equal(t, "2024-01-05", sent.Events[0]["occurredAt"])
+		assert.Equal(t, "tei1", sent.Events[0]["trackedEntity"])
+		assert.NotContains(t, sent.Events[0], "eventDate")
+		assert.NotContains(t, sent.Events[0], "coordinate")
+	})
+
+	t.Run("Should report a failed tracker import with its counts", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/tracker":      apitest.Raw(http.StatusOK, `{"response":{"id":"job2"}}`),
+			"/api/tracker/jobs/job2": apitest.Raw(http.StatusOK, `[{"completed":true}]`),
+			"/api/tracker/jobs/job2/report": apitest.Raw(http.StatusOK, `{"status":"ERROR","stats":{"ignored":2},
+				"validationReport":{"errorReports":[{"message":"Enrollment not found"}]}}`),
+		})
+
+		counts, err := postEvents(srv.Client(), true, batch)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Enrollment not found")
+		assert.Equal(t, 2, counts.Ignored)
+	})
+
+	t.Run("Should read /api/events import summaries, including conflicts", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/events": apitest.Raw(http.StatusConflict, `{"httpStatus":"Conflict","response":{"imported":1,"updated":0,"ignored":1}}`),
+		})
+
+		counts, err := postEvents(srv.Client(), false, batch)
+
+		require.NoError(t, err)
+		assert.Equal(t, importCounts{Created: 1, Ignored: 1}, counts)
+	})
+
+	t.Run("Should fail an /api/events import without summaries", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/events": apitest.Raw(http.StatusUnauthorized, "Unauthorized"),
+		})
+
+		_, err := postEvents(srv.Client(), false, batch)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "HTTP 401")
+	})
+}
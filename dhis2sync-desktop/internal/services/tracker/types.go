@@ -37,6 +37,30 @@ type PreviewResponse struct {
 	EndDate       string           `json:"end_date"`
 	EstimateTotal int              `json:"estimate_total"`
 	Sample        []map[string]interface{} `json:"sample"` // Sample events
+
+	// Summary describes every event the preview fetched, not just the sample
+	Summary *PreviewSummary `json:"summary"`
+}
+
+// PreviewSummary is a typed overview of previewed events
+type PreviewSummary struct {
+	EventsScanned int               `json:"events_scanned"`
+	ProgramStages []StageCount      `json:"program_stages"` // Distinct stages, most events first
+	StatusCounts  map[string]int    `json:"status_counts"`  // Event status -> count (UNKNOWN when absent)
+	DataElements  []ElementCoverage `json:"data_elements"`  // Elements holding values, best covered first
+}
+
+// StageCount is the number of previewed events in a program stage
+type StageCount struct {
+	ID     string `json:"id"`
+	Events int    `json:"events"`
+}
+
+// ElementCoverage tells how many previewed events hold a value for a data element
+type ElementCoverage struct {
+	ID       string  `json:"id"`
+	Events   int     `json:"events"`
+	Coverage float64 `json:"coverage"` // Percentage of scanned events
 }
 
 // TransferRequest represents a request to transfer events between instances
@@ -52,6 +76,24 @@ type TransferRequest struct {
 	BatchSize         int      `json:"batch_size"`          // Events per batch (default: 200)
 	MaxPages          int      `json:"max_pages"`           // Max pages to fetch per OU (default: 500)
 	MaxRuntimeSeconds int      `json:"max_runtime_seconds"` // Max runtime in seconds (default: 1500)
+
+	// ElementMapping and ProgramStageMapping map source IDs to destination IDs. With an
+	// element mapping, values of unmapped data elements are left out; stages missing
+	// from the stage mapping keep their source ID.
+	ElementMapping      map[string]string `json:"element_mapping,omitempty"`
+	ProgramStageMapping map[string]string `json:"program_stage_mapping,omitempty"`
+}
+
+// TEIPreviewResponse contains tracked entity preview results
+type TEIPreviewResponse struct {
+	ProgramID     string                   `json:"program_id"`
+	OrgUnits      []string                 `json:"org_units"`
+	StartDate     string                   `json:"start_date"`
+	EndDate       string                   `json:"end_date"`
+	EstimateTotal int                      `json:"estimate_total"` // Tracked entities found
+	Enrollments   int                      `json:"enrollments"`    // Their enrollments in the program
+	Events        int                      `json:"events"`         // Events of those enrollments
+	Sample        []map[string]interface{} `json:"sample"`         // Sample tracked entities, as they'd be sent
 }
 
 // TransferProgress tracks the progress of an event transfer task
@@ -71,6 +113,26 @@ type TransferResult struct {
 	BatchesSent  int  `json:"batches_sent"`
 	DryRun       bool `json:"dry_run"`
 	Partial      bool `json:"partial,omitempty"` // True if stopped due to runtime limit
+
+	// SkippedMissingEnrollment counts tracker events left out because their enrollment doesn't exist in the destination
+	SkippedMissingEnrollment int `json:"skipped_missing_enrollment,omitempty"`
+
+	// Created, Updated and Ignored add up the destination's import reports
+	Created    int  `json:"created"`
+	Updated    int  `json:"updated"`
+	Ignored    int  `json:"ignored"`
+	TrackerAPI bool `json:"tracker_api"` // Imported through /api/tracker rather than /api/events
+
+	// TrackedEntities marks a tracked entity transfer, where the totals count tracked
+	// entities and their enrollments and events are counted separately
+	TrackedEntities bool `json:"tracked_entities,omitempty"`
+	EnrollmentsSent int  `json:"enrollments_sent,omitempty"`
+	EventsSent      int  `json:"events_sent,omitempty"`
+
+	// UnmappedElements lists source data elements missing from the element mapping,
+	// whose UnmappedValues values were left out
+	UnmappedElements []string `json:"unmapped_elements,omitempty"`
+	UnmappedValues   int      `json:"unmapped_values,omitempty"`
 }
 
 // Event represents a minimal DHIS2 event for transfer
@@ -0,0 +1,114 @@
+package transfer
+
+import (
+	"fmt"
+
+	"dhis2sync-desktop/internal/api"
+	"dhis2sync-desktop/internal/database"
+	"dhis2sync-desktop/internal/models"
+)
+
+// idRef is the {"id": ...} reference DHIS2 collection endpoints take
+type idRef struct {
+	ID string `json:"id"`
+}
+
+// fetchDatasetOrgUnits returns the org units a dataset is assigned to. DHIS2 ignores
+// values for org units outside this set, which is the usual reason a transfer reports
+// everything as ignored.
+func fetchDatasetOrgUnits(client *api.Client, datasetID string) (map[string]bool, error) {
+	endpoint := fmt.Sprintf("api/dataSets/%s.json", datasetID)
+	resp, err := client.Get(endpoint, map[string]string{
+		"fields": "organisationUnits[id]",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch dataset assignments: %w", err)
+	}
+	if !resp.IsSuccess() {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), resp.String())
+	}
+
+	var dataset struct {
+		OrganisationUnits []idRef `json:"organisationUnits"`
+	}
+	if err := api.DecodeJSON(resp, endpoint, &dataset); err != nil {
+		return nil, err
+	}
+
+	assigned := make(map[string]bool, len(dataset.OrganisationUnits))
+	for _, ou := range dataset.OrganisationUnits {
+		assigned[ou.ID] = true
+	}
+	return assigned, nil
+}
+
+// assignDatasetToOrgUnits adds org units to a dataset's assignments, leaving existing ones in place
+func assignDatasetToOrgUnits(client *api.Client, datasetID string, orgUnitIDs []string) error {
+	if len(orgUnitIDs) == 0 {
+		return nil
+	}
+
+	additions := make([]idRef, 0, len(orgUnitIDs))
+	for _, id := range orgUnitIDs {
+		additions = append(additions, idRef{ID: id})
+	}
+
+	resp, err := client.Post(fmt.Sprintf("api/dataSets/%s/organisationUnits", datasetID), map[string]interface{}{
+		"additions": additions,
+		"deletions": []idRef{},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to assign dataset: %w", err)
+	}
+	if !resp.IsSuccess() {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode(), resp.String())
+	}
+	return nil
+}
+
+// AssignDatasetToOrgUnits assigns the destination dataset to org units, typically those
+// a transfer reported in UnassignedOUs
+func (s *Service) AssignDatasetToOrgUnits(profileID, datasetID string, orgUnitIDs []string) error {
+	db := database.GetDB()
+	var profile models.ConnectionProfile
+	if err := db.Where("id = ?", profileID).First(&profile).Error; err != nil {
+		return fmt.Errorf("failed to load profile: %w", err)
+	}
+
+	destClient, err := s.getAPIClient(&profile, "destination")
+	if err != nil {
+		return fmt.Errorf("failed to create destination client: %w", err)
+	}
+
+	return assignDatasetToOrgUnits(destClient, datasetID, orgUnitIDs)
+}
+
+// recordUnassignedOrgUnit notes a destination org unit whose data was skipped because
+// the destination dataset isn't assigned to it
+func (s *Service) recordUnassignedOrgUnit(taskID, ouID, ouName, period string) {
+	s.taskMu.Lock()
+	defer s.taskMu.Unlock()
+
+	progress, exists := s.taskStore[taskID]
+	if !exists {
+		return
+	}
+	for i := range progress.UnassignedOUs {
+		if progress.UnassignedOUs[i].ID == ouID {
+			progress.UnassignedOUs[i].Periods = append(progress.UnassignedOUs[i].Periods, period)
+			return
+		}
+	}
+	progress.UnassignedOUs = append(progress.UnassignedOUs, UnassignedOrgUnit{ID: ouID, Name: ouName, Periods: []string{period}})
+}
+
+// unassignedCount returns how many org units a task skipped for lacking the dataset assignment
+func (s *Service) unassignedCount(taskID string) int {
+	s.taskMu.Lock()
+	defer s.taskMu.Unlock()
+
+	if progress, exists := s.taskStore[taskID]; exists {
+		return len(progress.UnassignedOUs)
+	}
+	return 0
+}
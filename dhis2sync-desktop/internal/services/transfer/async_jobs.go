@@ -0,0 +1,206 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"dhis2sync-desktop/internal/database"
+	"dhis2sync-desktop/internal/models"
+)
+
+// asyncJobRef identifies the transfer task that submitted async import jobs
+type asyncJobRef struct {
+	TaskID    string
+	ProfileID string
+}
+
+// recordAsyncJob persists a submitted DHIS2 job ID against its transfer task
+func (s *Service) recordAsyncJob(ref *asyncJobRef, jobID, jobType string, chunkNum, totalChunks, numValues int) {
+	db := database.GetDB()
+	if ref == nil || db == nil {
+		return
+	}
+
+	job := &models.AsyncImportJob{
+		TaskID:      ref.TaskID,
+		ProfileID:   ref.ProfileID,
+		JobID:       jobID,
+		JobType:     jobType,
+		ChunkNum:    chunkNum,
+		TotalChunks: totalChunks,
+		NumValues:   numValues,
+		Status:      "pending",
+	}
+	if err := db.Create(job).Error; err != nil {
+		log.Printf("[%s] ⚠ Failed to persist async job %s: %v", ref.TaskID, jobID, err)
+	}
+}
+
+// finishAsyncJob stores the outcome of a polled job. Jobs whose polling was cancelled
+// stay pending: they are still running server-side and can be polled again.
+func (s *Service) finishAsyncJob(ref *asyncJobRef, jobID string, summary *ImportSummary, pollErr error) {
+	db := database.GetDB()
+	if ref == nil || db == nil || errors.Is(pollErr, context.Canceled) {
+		return
+	}
+
+	updates := map[string]interface{}{"status": "completed"}
+	if pollErr != nil {
+		updates["status"] = "failed"
+		updates["error"] = pollErr.Error()
+	} else if summary != nil {
+		if data, err := json.Marshal(summary); err == nil {
+			updates["summary"] = string(data)
+		}
+	}
+
+	if err := db.Model(&models.AsyncImportJob{}).
+		Where("task_id = ? AND job_id = ?", ref.TaskID, jobID).
+		Updates(updates).Error; err != nil {
+		log.Printf("[%s] ⚠ Failed to update async job %s: %v", ref.TaskID, jobID, err)
+	}
+}
+
+// ResumeAsyncPolling re-polls the pending async import jobs of a transfer that
+// was interrupted (e.g. by an app restart) and rebuilds its import summary from
+// all of the task's persisted jobs. Polling runs in the background.
+func (s *Service) ResumeAsyncPolling(taskID string) error {
+	db := database.GetDB()
+
+	var jobs []models.AsyncImportJob
+	if err := db.Where("task_id = ?", taskID).Order("chunk_num").Find(&jobs).Error; err != nil {
+		return fmt.Errorf("failed to load async jobs: %w", err)
+	}
+	if len(jobs) == 0 {
+		return fmt.Errorf("no async import jobs recorded for task %s", taskID)
+	}
+
+	var taskProgress models.TaskProgress
+	if err := db.Where("id = ?", taskID).First(&taskProgress).Error; err != nil {
+		return fmt.Errorf("task not found: %w", err)
+	}
+
+	s.taskMu.Lock()
+	if p, exists := s.taskStore[taskID]; exists && (p.Status == "running" || p.Status == "starting") {
+		s.taskMu.Unlock()
+		return fmt.Errorf("task %s is still running", taskID)
+	}
+	s.taskStore[taskID] = &TransferProgress{
+		TaskID:    taskID,
+		Status:    "running",
+		Progress:  taskProgress.Progress,
+		Messages:  s.unmarshalMessages(taskProgress.Messages),
+		StartedAt: taskProgress.CreatedAt.Format(time.RFC3339),
+	}
+	s.taskStore[taskID].touchActivity(time.Now())
+	s.taskMu.Unlock()
+
+	s.startWatchdog()
+
+	go s.performResumeAsyncPolling(taskID, jobs)
+
+	return nil
+}
+
+func (s *Service) performResumeAsyncPolling(taskID string, jobs []models.AsyncImportJob) {
+	ctx := withTaskID(context.Background(), taskID)
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.updateProgress(taskID, "error", 0, fmt.Sprintf("Panic while resuming async polling: %v", r))
+			logf(ctx, "Resume polling panic recovered: %v", r)
+		}
+	}()
+
+	pending := []models.AsyncImportJob{}
+	for _, job := range jobs {
+		if job.Status == "pending" {
+			pending = append(pending, job)
+		}
+	}
+
+	s.updateProgress(taskID, "running", 20, fmt.Sprintf("Resuming: %d of %d async jobs still pending", len(pending), len(jobs)))
+
+	if len(pending) > 0 {
+		db := database.GetDB()
+		var profile models.ConnectionProfile
+		if err := db.Where("id = ?", pending[0].ProfileID).First(&profile).Error; err != nil {
+			s.updateProgress(taskID, "error", 0, fmt.Sprintf("Failed to load profile: %v", err))
+			return
+		}
+
+		destClient, err := s.getAPIClient(&profile, "destination")
+		if err != nil {
+			s.updateProgress(taskID, "error", 0, fmt.Sprintf("Failed to create destination client: %v", err))
+			return
+		}
+
+		ref := &asyncJobRef{TaskID: taskID, ProfileID: profile.ID}
+		for i, job := range pending {
+			summary, err := s.pollAsyncJobWithRetry(ctx, destClient, job.JobType, job.JobID, job.ChunkNum, job.TotalChunks, nil)
+			s.finishAsyncJob(ref, job.JobID, summary, err)
+
+			progress := 20 + int(75*float64(i+1)/float64(len(pending)))
+			if err != nil {
+				s.updateProgress(taskID, "running", progress, fmt.Sprintf("⚠ Job %d/%d (ID=%s) failed: %v", job.ChunkNum, job.TotalChunks, job.JobID, err))
+			} else {
+				s.updateProgress(taskID, "running", progress, fmt.Sprintf("✓ Job %d/%d (ID=%s) completed", job.ChunkNum, job.TotalChunks, job.JobID))
+			}
+		}
+	}
+
+	s.finalizeResumedTask(taskID)
+}
+
+// finalizeResumedTask aggregates every persisted job of the task into the final summary
+func (s *Service) finalizeResumedTask(taskID string) {
+	db := database.GetDB()
+
+	var jobs []models.AsyncImportJob
+	if err := db.Where("task_id = ?", taskID).Find(&jobs).Error; err != nil {
+		s.updateProgress(taskID, "error", 0, fmt.Sprintf("Failed to reload async jobs: %v", err))
+		return
+	}
+
+	summary := ImportSummary{Status: "SUCCESS"}
+	failed := 0
+	for _, job := range jobs {
+		if job.Status != "completed" {
+			failed++
+			continue
+		}
+		var jobSummary ImportSummary
+		if err := json.Unmarshal([]byte(job.Summary), &jobSummary); err != nil {
+			continue
+		}
+		summary.ImportCount.Imported += jobSummary.ImportCount.Imported
+		summary.ImportCount.Updated += jobSummary.ImportCount.Updated
+		summary.ImportCount.Ignored += jobSummary.ImportCount.Ignored
+		summary.ImportCount.Deleted += jobSummary.ImportCount.Deleted
+		summary.Conflicts = append(summary.Conflicts, jobSummary.Conflicts...)
+	}
+
+	if failed > 0 {
+		summary.Status = "WARNING"
+	}
+	summary.Description = fmt.Sprintf("Resumed: Imported=%d, Updated=%d, Already exist=%d, Failed jobs=%d",
+		summary.ImportCount.Imported, summary.ImportCount.Updated, summary.ImportCount.Ignored, failed)
+
+	s.taskMu.Lock()
+	if progress, exists := s.taskStore[taskID]; exists {
+		progress.ImportSummary = &summary
+		progress.TotalImported = summary.ImportCount.Imported + summary.ImportCount.Updated
+		progress.CompletedAt = time.Now().Format(time.RFC3339)
+	}
+	s.taskMu.Unlock()
+
+	if data, err := json.Marshal(summary); err == nil {
+		db.Model(&models.TaskProgress{}).Where("id = ?", taskID).Update("results", string(data))
+	}
+
+	s.updateProgress(taskID, "completed", 100, fmt.Sprintf("🎉 Resumed transfer complete: %s", summary.Description))
+}
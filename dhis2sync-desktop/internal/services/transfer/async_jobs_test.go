@@ -0,0 +1,148 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"dhis2sync-desktop/internal/api/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncJobEndpoint(t *testing.T) {
+	service := NewService(context.Background())
+
+	t.Run("Should default to the data value import category", func(t *testing.T) {
+		assert.Equal(t, "api/system/tasks/DATAVALUE_IMPORT/job1", asyncJobEndpoint("", "job1"))
+		assert.Equal(t, "api/system/tasks/METADATA_IMPORT/job1", asyncJobEndpoint("METADATA_IMPORT", "job1"))
+	})
+
+	t.Run("Should poll the category returned on submission", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/dataValueSets": apitest.JSON(http.StatusOK, map[string]interface{}{
+				"response": map[string]string{"id": "job7", "jobType": "TRACKER_IMPORT_JOB"},
+			}),
+			"/api/system/tasks/TRACKER_IMPORT_JOB/job7": apitest.JSON(http.StatusOK, []map[string]interface{}{{
+				"completed": true,
+				"level":     "INFO",
+				"summary":   map[string]interface{}{"status": "SUCCESS", "importCount": map[string]int{"imported": 1}},
+			}}),
+		})
+
+		summaries, err := service.importDataValuesBulkAsync(context.Background(), srv.Client(),
+			[]DataValue{{DataElement: "de1", Period: "202401", OrgUnit: "ou1", Value: "1"}}, 1000, 0, 0, "", nil, nil)
+
+		require.NoError(t, err)
+		require.Len(t, summaries, 1)
+		assert.Equal(t, 1, summaries[0].ImportCount.Imported)
+		assert.Equal(t, 1, srv.Hits("/api/system/tasks/TRACKER_IMPORT_JOB/job7"))
+	})
+}
+
+func TestAsyncSubmitRetry(t *testing.T) {
+	t.Run("Should log submission retries under the transfer's task ID", func(t *testing.T) {
+		var buf bytes.Buffer
+		log.SetOutput(&buf)
+		defer log.SetOutput(os.Stderr)
+
+		var submissions int32
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/dataValueSets": func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&submissions, 1) == 1 {
+					apitest.Raw(http.StatusBadGateway, "upstream unavailable")(w, r)
+					return
+				}
+				apitest.JSON(http.StatusOK, map[string]interface{}{"response": map[string]string{"id": "job1"}})(w, r)
+			},
+			"/api/system/tasks/DATAVALUE_IMPORT/job1": apitest.JSON(http.StatusOK, []map[string]interface{}{{
+				"completed": true,
+				"level":     "INFO",
+				"summary":   map[string]interface{}{"status": "SUCCESS", "importCount": map[string]int{"imported": 1}},
+			}}),
+		})
+		client := srv.Client()
+		client.SetRetryCount(0)
+
+		_, err := NewService(context.Background()).importDataValuesBulkAsync(withTaskID(context.Background(), "task-9"), client,
+			[]DataValue{{DataElement: "de1", Period: "202401", OrgUnit: "ou1", Value: "1"}}, 1000, 0, 0, "", nil, nil)
+
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "Task task-9: Retry 1/3")
+		assert.NotContains(t, buf.String(), "async_submit")
+	})
+}
+
+func TestImportTuning(t *testing.T) {
+	service := NewService(context.Background())
+
+	t.Run("Should clamp chunk size and concurrency", func(t *testing.T) {
+		assert.Equal(t, 1000, importChunkSize(TransferRequest{}))
+		assert.Equal(t, 100, importChunkSize(TransferRequest{ChunkSize: 10}))
+		assert.Equal(t, 2500, importChunkSize(TransferRequest{ChunkSize: 2500}))
+		assert.Equal(t, 5000, importChunkSize(TransferRequest{ChunkSize: 50000}))
+
+		assert.Equal(t, 10, maxConcurrentJobs(TransferRequest{}))
+		assert.Equal(t, 1, maxConcurrentJobs(TransferRequest{MaxConcurrentJobs: 1}))
+		assert.Equal(t, 20, maxConcurrentJobs(TransferRequest{MaxConcurrentJobs: 100}))
+	})
+
+	t.Run("Should submit one job per chunk and poll within the concurrency limit", func(t *testing.T) {
+		var inFlight, peak int32
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/dataValueSets": apitest.JSON(http.StatusOK, map[string]interface{}{
+				"response": map[string]string{"id": "job1"},
+			}),
+			"/api/system/tasks/DATAVALUE_IMPORT/job1": func(w http.ResponseWriter, r *http.Request) {
+				current := atomic.AddInt32(&inFlight, 1)
+				defer atomic.AddInt32(&inFlight, -1)
+				if current > atomic.LoadInt32(&peak) {
+					atomic.StoreInt32(&peak, current)
+				}
+				time.Sleep(5 * time.Millisecond)
+				apitest.JSON(http.StatusOK, []map[string]interface{}{{
+					"completed": true,
+					"level":     "INFO",
+					"summary":   map[string]interface{}{"status": "SUCCESS", "importCount": map[string]int{"imported": 100}},
+				}})(w, r)
+			},
+		})
+
+		values := make([]DataValue, 250)
+		for i := range values {
+			values[i] = DataValue{DataElement: "de1", Period: "202401", OrgUnit: "ou1", Value: "1"}
+		}
+		req := TransferRequest{ChunkSize: 100, MaxConcurrentJobs: 1}
+
+		summaries, err := service.importDataValuesBulkAsync(context.Background(), srv.Client(), values,
+			importChunkSize(req), maxConcurrentJobs(req), 0, "", nil, nil)
+
+		require.NoError(t, err)
+		assert.Len(t, summaries, 3)
+		assert.Equal(t, 3, srv.Hits("/api/dataValueSets"))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&peak))
+	})
+}
+
+func TestPollAsyncJobCancellation(t *testing.T) {
+	t.Run("Should stop polling once the context is cancelled", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/system/tasks/DATAVALUE_IMPORT/job1": apitest.JSON(http.StatusOK, []map[string]interface{}{{"completed": false}}),
+		})
+		service := NewService(context.Background())
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, err := service.pollAsyncJobWithRetry(ctx, srv.Client(), "", "job1", 1, 1, nil)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
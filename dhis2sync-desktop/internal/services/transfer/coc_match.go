@@ -0,0 +1,128 @@
+package transfer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"dhis2sync-desktop/internal/api"
+	"dhis2sync-desktop/internal/services/audit"
+)
+
+// cocAutoMatcher maps source category option combos that don't exist in the destination
+// to destination combos with the same category options (TransferRequest.AutoMatchCOCs).
+// COCs the request already resolves are left to the user's resolution.
+type cocAutoMatcher struct {
+	dest        *api.Client
+	matcher     *audit.COCMatcher
+	checked     map[string]bool // Source COC IDs already looked at
+	resolutions []Resolution    // The request's resolutions plus the auto-matched ones
+	matched     map[string]string
+	unresolved  []string
+}
+
+func newCOCAutoMatcher(source, dest *api.Client, resolutions []Resolution) *cocAutoMatcher {
+	m := &cocAutoMatcher{
+		dest:        dest,
+		matcher:     audit.NewCOCMatcher(source, dest),
+		checked:     make(map[string]bool),
+		resolutions: append([]Resolution(nil), resolutions...),
+		matched:     make(map[string]string),
+	}
+	for _, res := range resolutions {
+		if res.Type == "coc" {
+			m.checked[res.ID] = true
+		}
+	}
+	return m
+}
+
+// resolve looks at the values' COCs not seen before, auto-matching those missing from
+// the destination, and returns the resolutions to apply to the values
+func (m *cocAutoMatcher) resolve(values []DataValue) ([]Resolution, error) {
+	seen := make(map[string]bool)
+	for _, dv := range values {
+		if dv.CategoryOptionCombo != "" && !m.checked[dv.CategoryOptionCombo] {
+			seen[dv.CategoryOptionCombo] = true
+		}
+	}
+	if len(seen) == 0 {
+		return m.resolutions, nil
+	}
+
+	ids := sortedKeys(seen)
+	existing, err := fetchExistingCOCs(m.dest, ids)
+	if err != nil {
+		return m.resolutions, fmt.Errorf("failed to check category option combos in destination: %w", err)
+	}
+
+	for _, id := range ids {
+		m.checked[id] = true
+		if existing[id] {
+			continue
+		}
+
+		match, err := m.matcher.Match(id)
+		if err != nil || match == nil {
+			m.unresolved = append(m.unresolved, id)
+			continue
+		}
+		m.matched[id] = match.ID
+		m.resolutions = append(m.resolutions, Resolution{ID: id, Type: "coc", Action: "map:" + match.ID})
+	}
+
+	return m.resolutions, nil
+}
+
+// fetchExistingCOCs reports which of the category option combo IDs exist on the server
+func fetchExistingCOCs(client *api.Client, ids []string) (map[string]bool, error) {
+	existing := make(map[string]bool, len(ids))
+
+	for start := 0; start < len(ids); start += quickLookupChunkSize {
+		end := start + quickLookupChunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		resp, err := client.Get("api/categoryOptionCombos", map[string]string{
+			"filter": fmt.Sprintf("id:in:[%s]", strings.Join(ids[start:end], ",")),
+			"fields": "id",
+			"paging": "false",
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !resp.IsSuccess() {
+			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), api.BodySnippet(resp.Body(), 200))
+		}
+
+		var result struct {
+			CategoryOptionCombos []struct {
+				ID string `json:"id"`
+			} `json:"categoryOptionCombos"`
+		}
+		if err := api.DecodeJSON(resp, "api/categoryOptionCombos", &result); err != nil {
+			return nil, err
+		}
+
+		for _, coc := range result.CategoryOptionCombos {
+			existing[coc.ID] = true
+		}
+	}
+
+	return existing, nil
+}
+
+// recordCOCMatches stores the auto-matched and unresolved COCs on the task for review
+func (s *Service) recordCOCMatches(taskID string, m *cocAutoMatcher) {
+	unresolved := append([]string(nil), m.unresolved...)
+	sort.Strings(unresolved)
+
+	s.taskMu.Lock()
+	defer s.taskMu.Unlock()
+
+	if p, exists := s.taskStore[taskID]; exists {
+		p.AutoMatchedCOCs = m.matched
+		p.UnresolvedCOCs = unresolved
+	}
+}
@@ -0,0 +1,85 @@
+package transfer
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"dhis2sync-desktop/internal/api/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCOCAutoMatcher(t *testing.T) {
+	// One server plays both instances: cocKept exists in the destination, cocMale has
+	// a structural match there and cocOther's option is missing
+	newServer := func(t *testing.T) *apitest.Server {
+		return apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/categoryOptionCombos": func(w http.ResponseWriter, r *http.Request) {
+				filter := r.URL.Query().Get("filter")
+				if strings.HasPrefix(filter, "id:in:") {
+					apitest.JSON(http.StatusOK, map[string]interface{}{
+						"categoryOptionCombos": []map[string]string{{"id": "cocKept"}},
+					})(w, r)
+					return
+				}
+				apitest.JSON(http.StatusOK, map[string]interface{}{
+					"categoryOptionCombos": []map[string]interface{}{
+						{"id": "dstMale", "name": "Male", "categoryOptions": []map[string]string{{"id": "optMale"}}},
+					},
+				})(w, r)
+			},
+			"/api/categoryOptionCombos/cocMale": apitest.JSON(http.StatusOK, map[string]interface{}{
+				"categoryOptions": []map[string]string{{"name": "Male"}},
+			}),
+			"/api/categoryOptionCombos/cocOther": apitest.JSON(http.StatusOK, map[string]interface{}{
+				"categoryOptions": []map[string]string{{"name": "Other"}},
+			}),
+			"/api/categoryOptions": func(w http.ResponseWriter, r *http.Request) {
+				options := []map[string]string{}
+				if r.URL.Query().Get("filter") == "name:eq:Male" {
+					options = append(options, map[string]string{"id": "optMale"})
+				}
+				apitest.JSON(http.StatusOK, map[string]interface{}{"categoryOptions": options})(w, r)
+			},
+		})
+	}
+
+	values := []DataValue{
+		{DataElement: "de1", CategoryOptionCombo: "cocKept", Value: "1"},
+		{DataElement: "de1", CategoryOptionCombo: "cocMale", Value: "2"},
+		{DataElement: "de1", CategoryOptionCombo: "cocOther", Value: "3"},
+	}
+
+	t.Run("Should map missing COCs with the same options and report the rest", func(t *testing.T) {
+		srv := newServer(t)
+		m := newCOCAutoMatcher(srv.Client(), srv.Client(), nil)
+
+		resolutions, err := m.resolve(values)
+
+		require.NoError(t, err)
+		assert.Equal(t, []Resolution{{ID: "cocMale", Type: "coc", Action: "map:dstMale"}}, resolutions)
+		assert.Equal(t, map[string]string{"cocMale": "dstMale"}, m.matched)
+		assert.Equal(t, []string{"cocOther"}, m.unresolved)
+
+		resolved, _ := NewService(nil).applyResolutions(values, resolutions)
+		assert.Equal(t, "dstMale", resolved[1].CategoryOptionCombo)
+	})
+
+	t.Run("Should look each COC up once and leave user resolutions alone", func(t *testing.T) {
+		srv := newServer(t)
+		userRes := []Resolution{{ID: "cocOther", Type: "coc", Action: "skip"}}
+		m := newCOCAutoMatcher(srv.Client(), srv.Client(), userRes)
+
+		_, err := m.resolve(values)
+		require.NoError(t, err)
+		resolutions, err := m.resolve(values)
+		require.NoError(t, err)
+
+		assert.Equal(t, append(userRes, Resolution{ID: "cocMale", Type: "coc", Action: "map:dstMale"}), resolutions)
+		assert.Empty(t, m.unresolved)
+		assert.Equal(t, 1, srv.Hits("/api/categoryOptionCombos/cocMale"))
+		assert.Zero(t, srv.Hits("/api/categoryOptionCombos/cocOther"))
+	})
+}
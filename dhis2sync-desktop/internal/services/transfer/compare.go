@@ -0,0 +1,116 @@
+package transfer
+
+import (
+	"fmt"
+	"sync"
+
+	"dhis2sync-desktop/internal/database"
+	"dhis2sync-desktop/internal/models"
+)
+
+// CompareDatasets fetches a source and a destination dataset concurrently and lines up
+// their data elements. Only profile or client setup errors fail the call; a side that
+// can't be fetched is reported in the comparison so the other side is still shown.
+func (s *Service) CompareDatasets(profileID, sourceDatasetID, destDatasetID string) (*DatasetComparison, error) {
+	db := database.GetDB()
+	var profile models.ConnectionProfile
+	if err := db.Where("id = ?", profileID).First(&profile).Error; err != nil {
+		return nil, fmt.Errorf("profile not found: %w", err)
+	}
+
+	sourceClient, err := s.getAPIClient(&profile, "source")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create source client: %w", err)
+	}
+	destClient, err := s.getAPIClient(&profile, "destination")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create destination client: %w", err)
+	}
+
+	var (
+		wg              sync.WaitGroup
+		source, dest    *DatasetInfo
+		srcErr, destErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		source, srcErr = fetchDatasetInfo(sourceClient, sourceDatasetID)
+	}()
+	go func() {
+		defer wg.Done()
+		dest, destErr = fetchDatasetInfo(destClient, destDatasetID)
+	}()
+	wg.Wait()
+
+	return mergeDatasetComparison(source, srcErr, dest, destErr), nil
+}
+
+// mergeDatasetComparison builds a comparison from whatever each side returned. Elements
+// match by ID first, then by code; with one side missing, everything lands in the
+// other side's "only" list.
+func mergeDatasetComparison(source *DatasetInfo, srcErr error, dest *DatasetInfo, destErr error) *DatasetComparison {
+	cmp := &DatasetComparison{
+		Matched:    []ElementMatch{},
+		SourceOnly: []DataElement{},
+		DestOnly:   []DataElement{},
+	}
+	if srcErr != nil {
+		cmp.SourceError = srcErr.Error()
+		source = nil
+	}
+	if destErr != nil {
+		cmp.DestError = destErr.Error()
+		dest = nil
+	}
+	cmp.Source = source
+	cmp.Dest = dest
+
+	var srcElements, destElements []DataElement
+	if source != nil {
+		srcElements = source.DataElements
+	}
+	if dest != nil {
+		destElements = dest.DataElements
+	}
+	cmp.PeriodTypeMatch = source != nil && dest != nil && source.PeriodType == dest.PeriodType
+
+	destByID := make(map[string]int, len(destElements))
+	destByCode := make(map[string]int, len(destElements))
+	for i, de := range destElements {
+		destByID[de.ID] = i
+		if de.Code != "" {
+			destByCode[de.Code] = i
+		}
+	}
+
+	used := make(map[int]bool, len(destElements))
+	for _, de := range srcElements {
+		idx, matchedBy := -1, ""
+		if i, ok := destByID[de.ID]; ok && !used[i] {
+			idx, matchedBy = i, "id"
+		} else if i, ok := destByCode[de.Code]; ok && de.Code != "" && !used[i] {
+			idx, matchedBy = i, "code"
+		}
+
+		if idx < 0 {
+			cmp.SourceOnly = append(cmp.SourceOnly, de)
+			continue
+		}
+		used[idx] = true
+		cmp.Matched = append(cmp.Matched, ElementMatch{
+			Source:    de,
+			Dest:      destElements[idx],
+			MatchedBy: matchedBy,
+			TypeMatch: de.ValueType == destElements[idx].ValueType,
+		})
+	}
+
+	for i, de := range destElements {
+		if !used[i] {
+			cmp.DestOnly = append(cmp.DestOnly, de)
+		}
+	}
+
+	return cmp
+}
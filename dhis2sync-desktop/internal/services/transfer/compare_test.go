@@ -0,0 +1,69 @@
+package transfer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeDatasetComparison(t *testing.T) {
+	source := &DatasetInfo{
+		ID:         "srcDS000001",
+		PeriodType: "Monthly",
+		DataElements: []DataElement{
+			{ID: "deShared001", Code: "ANC1", ValueType: "INTEGER"},
+			{ID: "deSrcOnly01", Code: "ANC2", ValueType: "INTEGER"},
+			{ID: "deSrcOnly02", Code: "OPD", ValueType: "NUMBER"},
+		},
+	}
+	dest := &DatasetInfo{
+		ID:         "dstDS000001",
+		PeriodType: "Monthly",
+		DataElements: []DataElement{
+			{ID: "deShared001", Code: "ANC1", ValueType: "INTEGER"},
+			{ID: "deDstCode01", Code: "ANC2", ValueType: "TEXT"},
+			{ID: "deDstOnly01", Code: "IPD", ValueType: "NUMBER"},
+		},
+	}
+
+	t.Run("Should match elements by ID, then by code", func(t *testing.T) {
+		cmp := mergeDatasetComparison(source, nil, dest, nil)
+
+		assert.True(t, cmp.PeriodTypeMatch)
+		require.Len(t, cmp.Matched, 2)
+		assert.Equal(t, "id", cmp.Matched[0].MatchedBy)
+		assert.True(t, cmp.Matched[0].TypeMatch)
+		assert.Equal(t, "code", cmp.Matched[1].MatchedBy)
+		assert.Equal(t, "deDstCode01", cmp.Matched[1].Dest.ID)
+		assert.False(t, cmp.Matched[1].TypeMatch, "INTEGER vs TEXT should be flagged")
+
+		require.Len(t, cmp.SourceOnly, 1)
+		assert.Equal(t, "deSrcOnly02", cmp.SourceOnly[0].ID)
+		require.Len(t, cmp.DestOnly, 1)
+		assert.Equal(t, "deDstOnly01", cmp.DestOnly[0].ID)
+	})
+
+	t.Run("Should return the other side when one side fails", func(t *testing.T) {
+		cmp := mergeDatasetComparison(source, nil, nil, errors.New("HTTP 404: not found"))
+
+		assert.Equal(t, "HTTP 404: not found", cmp.DestError)
+		assert.Empty(t, cmp.SourceError)
+		assert.Nil(t, cmp.Dest)
+		assert.Same(t, source, cmp.Source)
+		assert.False(t, cmp.PeriodTypeMatch)
+		assert.Empty(t, cmp.Matched)
+		assert.Len(t, cmp.SourceOnly, 3)
+		assert.Empty(t, cmp.DestOnly)
+	})
+
+	t.Run("Should report both errors when neither side loads", func(t *testing.T) {
+		cmp := mergeDatasetComparison(nil, errors.New("timeout"), nil, errors.New("HTTP 401"))
+
+		assert.Equal(t, "timeout", cmp.SourceError)
+		assert.Equal(t, "HTTP 401", cmp.DestError)
+		assert.Empty(t, cmp.SourceOnly)
+		assert.Empty(t, cmp.DestOnly)
+	})
+}
@@ -0,0 +1,161 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"dhis2sync-desktop/internal/api"
+)
+
+// completionLookupChunkSize caps the org units per registration lookup to keep URLs short
+const completionLookupChunkSize = 100
+
+// fetchCompletedRegistrations reports which "orgUnitID:period" keys already have a
+// completed registration for the dataset in the destination. With an attribute option
+// combo only its registrations count; otherwise any does. Registrations without a
+// "completed" flag (older DHIS2) count as complete.
+func fetchCompletedRegistrations(client *api.Client, datasetID, attributeOptionCombo string, keys []string) (map[string]bool, error) {
+	orgUnitSet := make(map[string]bool)
+	periodSet := make(map[string]bool)
+	for _, key := range keys {
+		parts := strings.SplitN(key, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		orgUnitSet[parts[0]] = true
+		periodSet[parts[1]] = true
+	}
+
+	orgUnits := sortedKeys(orgUnitSet)
+	periods := sortedKeys(periodSet)
+	completed := make(map[string]bool)
+
+	for start := 0; start < len(orgUnits); start += completionLookupChunkSize {
+		end := start + completionLookupChunkSize
+		if end > len(orgUnits) {
+			end = len(orgUnits)
+		}
+
+		// period and orgUnit repeat, which the params map can't express
+		query := url.Values{"dataSet": {datasetID}, "period": periods, "orgUnit": orgUnits[start:end]}
+		resp, err := client.Get("api/completeDataSetRegistrations?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if !resp.IsSuccess() {
+			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), api.BodySnippet(resp.Body(), 200))
+		}
+
+		var result struct {
+			Registrations []struct {
+				Period               string `json:"period"`
+				OrganisationUnit     string `json:"organisationUnit"`
+				AttributeOptionCombo string `json:"attributeOptionCombo"`
+				Completed            *bool  `json:"completed"`
+			} `json:"completeDataSetRegistrations"`
+		}
+		if err := api.DecodeJSON(resp, "api/completeDataSetRegistrations", &result); err != nil {
+			return nil, err
+		}
+
+		for _, reg := range result.Registrations {
+			if attributeOptionCombo != "" && reg.AttributeOptionCombo != attributeOptionCombo {
+				continue
+			}
+			if reg.Completed == nil || *reg.Completed {
+				completed[reg.OrganisationUnit+":"+reg.Period] = true
+			}
+		}
+	}
+
+	return completed, nil
+}
+
+// markComplete registers the "orgUnitID:period" keys as complete for the destination
+// dataset in one batched POST, skipping registrations the destination already has,
+// and reports the outcome on the task at the given progress
+func (s *Service) markComplete(ctx context.Context, taskID string, client *api.Client, req TransferRequest, keys []string, progress int) {
+	if len(keys) == 0 {
+		return
+	}
+
+	// Skip registrations the destination already has, avoiding redundant writes and audit noise
+	alreadyComplete := 0
+	if completed, err := fetchCompletedRegistrations(client, req.DestDatasetID, req.AttributeOptionComboID, keys); err != nil {
+		logf(ctx, "Could not read existing registrations, marking all: %v", err)
+	} else {
+		keys, alreadyComplete = withoutCompleted(keys, completed)
+	}
+	if alreadyComplete > 0 {
+		s.taskMu.Lock()
+		if p, exists := s.taskStore[taskID]; exists {
+			p.AlreadyComplete += alreadyComplete
+		}
+		s.taskMu.Unlock()
+	}
+
+	regs := []map[string]interface{}{}
+	for _, key := range keys {
+		parts := strings.SplitN(key, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		regs = append(regs, api.CompletionRegistration(req.DestDatasetID, parts[1], parts[0], req.AttributeOptionComboID, true, req.CompleteDate, req.StoredBy, time.Now()))
+	}
+
+	if len(regs) == 0 {
+		s.updateProgress(taskID, "running", progress, fmt.Sprintf("✓ All %d dataset registrations were already complete", alreadyComplete))
+		return
+	}
+
+	resp, err := client.Post("api/completeDataSetRegistrations", map[string]interface{}{"completeDataSetRegistrations": regs})
+	if err != nil {
+		s.updateProgress(taskID, "running", progress, fmt.Sprintf("⚠ Failed to mark datasets complete: %v", err))
+		logf(ctx, "Completeness marking failed: %v", err)
+	} else if !resp.IsSuccess() {
+		s.updateProgress(taskID, "running", progress, fmt.Sprintf("⚠ Completion registration failed: HTTP %d", resp.StatusCode()))
+		logf(ctx, "Completeness marking failed: HTTP %d - %s", resp.StatusCode(), resp.String())
+	} else {
+		s.updateProgress(taskID, "running", progress, fmt.Sprintf("✓ Marked %d dataset registrations as complete (%d already complete)", len(regs), alreadyComplete))
+		logf(ctx, "Successfully marked %d dataset registrations as complete", len(regs))
+	}
+}
+
+// completionKeys lists the distinct "orgUnit:period" pairs among values, in first-seen order
+func completionKeys(values []DataValue) []string {
+	seen := make(map[string]bool)
+	keys := []string{}
+	for _, dv := range values {
+		key := dv.OrgUnit + ":" + dv.Period
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// withoutCompleted drops keys that are already complete, returning the rest and how many were dropped
+func withoutCompleted(keys []string, completed map[string]bool) ([]string, int) {
+	remaining := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !completed[key] {
+			remaining = append(remaining, key)
+		}
+	}
+	return remaining, len(keys) - len(remaining)
+}
+
+// sortedKeys lists a set's members in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
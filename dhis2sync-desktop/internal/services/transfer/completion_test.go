@@ -0,0 +1,115 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+
+	"dhis2sync-desktop/internal/api/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchCompletedRegistrations(t *testing.T) {
+	keys := []string{"ouA:202401", "ouB:202401", "ouA:202402"}
+
+	t.Run("Should report completed registrations and query every org unit and period", func(t *testing.T) {
+		var query map[string][]string
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/completeDataSetRegistrations": func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.Query()
+				apitest.JSON(http.StatusOK, map[string]interface{}{
+					"completeDataSetRegistrations": []map[string]interface{}{
+						{"period": "202401", "organisationUnit": "ouA", "completed": true},
+						{"period": "202401", "organisationUnit": "ouB", "completed": false},
+						{"period": "202402", "organisationUnit": "ouA"},
+					},
+				})(w, r)
+			},
+		})
+
+		completed, err := fetchCompletedRegistrations(srv.Client(), "ds1", "", keys)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"ouA:202401": true, "ouA:202402": true}, completed)
+		assert.Equal(t, []string{"ds1"}, query["dataSet"])
+		assert.Equal(t, []string{"ouA", "ouB"}, query["orgUnit"])
+		assert.Equal(t, []string{"202401", "202402"}, query["period"])
+
+		remaining, skipped := withoutCompleted(keys, completed)
+		assert.Equal(t, []string{"ouB:202401"}, remaining)
+		assert.Equal(t, 2, skipped)
+	})
+
+	t.Run("Should only count the requested attribute option combo", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/completeDataSetRegistrations": apitest.JSON(http.StatusOK, map[string]interface{}{
+				"completeDataSetRegistrations": []map[string]interface{}{
+					{"period": "202401", "organisationUnit": "ouA", "attributeOptionCombo": "aocPartner", "completed": true},
+					{"period": "202401", "organisationUnit": "ouB", "attributeOptionCombo": "aocOther", "completed": true},
+				},
+			}),
+		})
+
+		completed, err := fetchCompletedRegistrations(srv.Client(), "ds1", "aocPartner", keys)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"ouA:202401": true}, completed)
+	})
+
+	t.Run("Should surface a failed lookup", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/completeDataSetRegistrations": apitest.Raw(http.StatusConflict, `{"message":"Data set not found"}`),
+		})
+
+		_, err := fetchCompletedRegistrations(srv.Client(), "ds1", "", keys)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "409")
+	})
+}
+
+func TestMarkComplete(t *testing.T) {
+	useStagingDB(t)
+
+	t.Run("Should register under the requested attribute option combo and skip it next time", func(t *testing.T) {
+		var mu sync.Mutex
+		var stored []map[string]interface{}
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/completeDataSetRegistrations": func(w http.ResponseWriter, r *http.Request) {
+				var payload struct {
+					Registrations []map[string]interface{} `json:"completeDataSetRegistrations"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+				mu.Lock()
+				stored = append(stored, payload.Registrations...)
+				mu.Unlock()
+				apitest.JSON(http.StatusOK, map[string]string{"status": "OK"})(w, r)
+			},
+			"/api/completeDataSetRegistrations": func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				apitest.JSON(http.StatusOK, map[string]interface{}{"completeDataSetRegistrations": stored})(w, r)
+			},
+		})
+
+		service := NewService(nil)
+		service.taskStore["task-1"] = &TransferProgress{TaskID: "task-1", Status: "running"}
+		req := TransferRequest{DestDatasetID: "ds1", AttributeOptionComboID: "aocPartner"}
+		keys := []string{"ouA:202401"}
+
+		service.markComplete(context.Background(), "task-1", srv.Client(), req, keys, 95)
+
+		require.Len(t, stored, 1)
+		assert.Equal(t, "aocPartner", stored[0]["attributeOptionCombo"])
+		assert.Equal(t, 0, service.taskStore["task-1"].AlreadyComplete)
+
+		service.markComplete(context.Background(), "task-1", srv.Client(), req, keys, 95)
+
+		assert.Len(t, stored, 1, "The second run finds the registration and posts nothing")
+		assert.Equal(t, 1, service.taskStore["task-1"].AlreadyComplete)
+	})
+}
@@ -0,0 +1,164 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"dhis2sync-desktop/internal/database"
+	"dhis2sync-desktop/internal/models"
+	"dhis2sync-desktop/internal/writewindow"
+
+	"github.com/google/uuid"
+)
+
+// StartDeleteValues deletes the selected data elements' values for a dataset, period and
+// org units with an async DELETE import, in the background. With DryRun nothing is deleted
+// and the task's DeletePreview lists exactly what would be. Progress is reported like a
+// transfer under the returned task ID. The request must pass ValidateDeleteValuesRequest.
+func (s *Service) StartDeleteValues(req DeleteValuesRequest) (string, error) {
+	if err := ValidateDeleteValuesRequest(&req); err != nil {
+		return "", err
+	}
+
+	if !req.DryRun {
+		var profile models.ConnectionProfile
+		if err := database.GetDB().Where("id = ?", req.ProfileID).First(&profile).Error; err != nil {
+			return "", fmt.Errorf("profile not found: %w", err)
+		}
+		if err := writewindow.Check(&profile, time.Now()); err != nil {
+			return "", err
+		}
+	}
+
+	taskID := uuid.New().String()
+	if err := s.registerTask(taskID, "delete_values", TransferRequest{ProfileID: req.ProfileID}, "Initializing deletion..."); err != nil {
+		return "", err
+	}
+
+	s.startWatchdog()
+	go s.performDeleteValues(taskID, req)
+
+	return taskID, nil
+}
+
+// performDeleteValues reads the current values in scope and deletes (or previews) them
+func (s *Service) performDeleteValues(taskID string, req DeleteValuesRequest) {
+	ctx := withTaskID(context.Background(), taskID)
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.updateProgress(taskID, "error", 0, fmt.Sprintf("Panic during deletion: %v", r))
+			logf(ctx, "Deletion panic recovered: %v", r)
+		}
+	}()
+
+	s.updateProgress(taskID, "running", 5, "Loading connection profile...")
+
+	db := database.GetDB()
+	var profile models.ConnectionProfile
+	if err := db.Where("id = ?", req.ProfileID).First(&profile).Error; err != nil {
+		s.updateProgress(taskID, "error", 0, fmt.Sprintf("Failed to load profile: %v", err))
+		return
+	}
+
+	client, err := s.getAPIClient(&profile, req.Instance)
+	if err != nil {
+		s.updateProgress(taskID, "error", 0, fmt.Sprintf("Failed to create %s client: %v", req.Instance, err))
+		return
+	}
+
+	// Only values that exist are deleted, so the preview matches the deletion exactly
+	elements := make(map[string]bool, len(req.Elements))
+	for _, deID := range req.Elements {
+		elements[deID] = true
+	}
+
+	var targets []DataValue
+	for i, ouID := range req.OrgUnits {
+		if s.isCancelled(taskID) {
+			logf(ctx, "Deletion cancelled while reading %s", ouID)
+			return
+		}
+
+		existing, err := s.fetchExistingValues(client, req.DatasetID, req.Period, ouID)
+		if err != nil {
+			s.updateProgress(taskID, "error", 0, fmt.Sprintf("Failed to read values for %s/%s, nothing deleted: %v", ouID, req.Period, err))
+			return
+		}
+		targets = append(targets, selectDeletions(existing, elements)...)
+		s.updateProgressOnly(taskID, 5+(i+1)*25/len(req.OrgUnits), fmt.Sprintf("Read %s (%d/%d org units)", ouID, i+1, len(req.OrgUnits)))
+	}
+
+	if req.DryRun {
+		s.taskMu.Lock()
+		if progress, exists := s.taskStore[taskID]; exists {
+			progress.DeletePreview = targets
+			progress.CompletedAt = time.Now().Format(time.RFC3339)
+		}
+		s.taskMu.Unlock()
+
+		s.updateProgress(taskID, "completed", 100, fmt.Sprintf("Dry run complete: %d values would be deleted", len(targets)))
+		return
+	}
+
+	if len(targets) == 0 {
+		s.updateProgress(taskID, "completed", 100, "No matching values found, nothing deleted")
+		return
+	}
+
+	s.updateProgress(taskID, "running", 30, fmt.Sprintf("Deleting %d values...", len(targets)))
+	jobRef := &asyncJobRef{TaskID: taskID, ProfileID: req.ProfileID}
+	onProgress := func(p float64, msg string) {
+		if p < 0 {
+			p = 0.5
+		}
+		s.updateProgress(taskID, "running", 30+int(p*65), msg)
+	}
+
+	summaries, err := s.importDataValuesBulkAsync(ctx, client, targets, defaultImportChunkSize, defaultMaxConcurrentJobs, 0, ImportStrategyDelete, jobRef, onProgress)
+	if err != nil {
+		s.updateProgress(taskID, "error", 0, fmt.Sprintf("Deletion failed: %v", err))
+		return
+	}
+
+	var counts ImportCount
+	for _, summary := range summaries {
+		counts.Deleted += summary.ImportCount.Deleted
+		counts.Ignored += summary.ImportCount.Ignored
+	}
+
+	summary := ImportSummary{
+		Status:      "SUCCESS",
+		Description: fmt.Sprintf("Deleted=%d, Ignored=%d", counts.Deleted, counts.Ignored),
+		ImportCount: counts,
+	}
+	if counts.Ignored > 0 {
+		summary.Status = "WARNING"
+	}
+
+	s.taskMu.Lock()
+	if progress, exists := s.taskStore[taskID]; exists {
+		progress.ImportSummary = &summary
+		progress.CompletedAt = time.Now().Format(time.RFC3339)
+	}
+	s.taskMu.Unlock()
+
+	if data, err := json.Marshal(summary); err == nil {
+		db.Model(&models.TaskProgress{}).Where("id = ?", taskID).Update("results", string(data))
+	}
+
+	s.updateProgress(taskID, "completed", 100, fmt.Sprintf("✓ Deleted %d values (%d ignored)", counts.Deleted, counts.Ignored))
+}
+
+// selectDeletions keeps the existing values of the selected data elements
+func selectDeletions(existing []DataValue, elements map[string]bool) []DataValue {
+	var selected []DataValue
+	for _, dv := range existing {
+		if elements[dv.DataElement] {
+			selected = append(selected, dv)
+		}
+	}
+	return selected
+}
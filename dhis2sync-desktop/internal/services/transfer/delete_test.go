@@ -0,0 +1,105 @@
+package transfer
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"dhis2sync-desktop/internal/api/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDeleteValuesRequest(t *testing.T) {
+	valid := func() DeleteValuesRequest {
+		return DeleteValuesRequest{
+			ProfileID: "profile-1",
+			DatasetID: "dsAAAAAAAA1",
+			Period:    "202401",
+			OrgUnits:  []string{"ouAAAAAAAA1"},
+			Elements:  []string{"deAAAAAAAA1"},
+			Confirm:   true,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*DeleteValuesRequest)
+		field  string
+	}{
+		{name: "Should accept a confirmed deletion", modify: func(r *DeleteValuesRequest) {}},
+		{name: "Should accept an unconfirmed dry run", modify: func(r *DeleteValuesRequest) { r.Confirm = false; r.DryRun = true }},
+		{name: "Should require confirmation to delete", modify: func(r *DeleteValuesRequest) { r.Confirm = false }, field: "Confirm"},
+		{name: "Should require data elements", modify: func(r *DeleteValuesRequest) { r.Elements = nil }, field: "Elements"},
+		{name: "Should require org units", modify: func(r *DeleteValuesRequest) { r.OrgUnits = nil }, field: "OrgUnits"},
+		{name: "Should reject an invalid period", modify: func(r *DeleteValuesRequest) { r.Period = "Jan 2024" }, field: "Period"},
+		{name: "Should reject an unknown instance", modify: func(r *DeleteValuesRequest) { r.Instance = "both" }, field: "Instance"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(&req)
+
+			err := ValidateDeleteValuesRequest(&req)
+
+			if tt.field == "" {
+				require.NoError(t, err)
+				assert.Equal(t, "destination", req.Instance)
+				return
+			}
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.field, validationErr.Field)
+		})
+	}
+}
+
+func TestSelectDeletions(t *testing.T) {
+	t.Run("Should keep only values of the selected elements", func(t *testing.T) {
+		existing := []DataValue{
+			{DataElement: "de1", CategoryOptionCombo: "coc1", Value: "1"},
+			{DataElement: "de2", CategoryOptionCombo: "coc1", Value: "2"},
+			{DataElement: "de1", CategoryOptionCombo: "coc2", Value: "3"},
+		}
+
+		selected := selectDeletions(existing, map[string]bool{"de1": true})
+
+		require.Len(t, selected, 2)
+		assert.Equal(t, "1", selected[0].Value)
+		assert.Equal(t, "3", selected[1].Value)
+	})
+}
+
+func TestAsyncImportStrategy(t *testing.T) {
+	service := NewService(context.Background())
+
+	t.Run("Should submit with the requested import strategy and report deletions", func(t *testing.T) {
+		var strategy string
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/dataValueSets": func(w http.ResponseWriter, r *http.Request) {
+				strategy = r.URL.Query().Get("importStrategy")
+				apitest.JSON(http.StatusOK, map[string]interface{}{
+					"response": map[string]string{"id": "job1", "jobType": "DATAVALUE_IMPORT"},
+				})(w, r)
+			},
+			"/api/system/tasks/DATAVALUE_IMPORT/job1": apitest.JSON(http.StatusOK, []map[string]interface{}{{
+				"completed": true,
+				"level":     "INFO",
+				"summary":   map[string]interface{}{"status": "SUCCESS", "importCount": map[string]int{"deleted": 2}},
+			}}),
+		})
+		values := []DataValue{
+			{DataElement: "de1", Period: "202401", OrgUnit: "ou1", Value: "1"},
+			{DataElement: "de1", Period: "202401", OrgUnit: "ou2", Value: "2"},
+		}
+
+		summaries, err := service.importDataValuesBulkAsync(context.Background(), srv.Client(), values, 1000, 0, 0, "DELETE", nil, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, "DELETE", strategy)
+		require.Len(t, summaries, 1)
+		assert.Equal(t, 2, summaries[0].ImportCount.Deleted)
+	})
+}
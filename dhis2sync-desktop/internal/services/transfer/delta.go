@@ -0,0 +1,33 @@
+package transfer
+
+import (
+	"regexp"
+	"time"
+)
+
+// lastUpdatedDurationPattern matches DHIS2 durations such as "12h", "7d" or "30m"
+var lastUpdatedDurationPattern = regexp.MustCompile(`^[0-9]+[smhd]$`)
+
+// parseModifiedSince accepts an RFC 3339 timestamp or a YYYY-MM-DD date
+func parseModifiedSince(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// deltaParams returns the api/dataValueSets filter limiting a delta sync to values
+// changed since req.ModifiedSince or within req.LastUpdatedDuration; nil for a full transfer.
+// Discovery and the per-org-unit fetch use the same filter, so org units whose only
+// changes are recent are still found, and unchanged ones are not fetched at all.
+func deltaParams(req TransferRequest) map[string]string {
+	if req.LastUpdatedDuration != "" {
+		return map[string]string{"lastUpdatedDuration": req.LastUpdatedDuration}
+	}
+	if req.ModifiedSince != "" {
+		if since, err := parseModifiedSince(req.ModifiedSince); err == nil {
+			return map[string]string{"lastUpdated": since.UTC().Format(time.RFC3339)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package transfer
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"dhis2sync-desktop/internal/api/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeltaSync(t *testing.T) {
+	base := func() *TransferRequest {
+		return &TransferRequest{
+			ProfileID:       "abcdefghij1",
+			SourceDatasetID: "abcdefghij2",
+			Periods:         []string{"202401"},
+		}
+	}
+
+	t.Run("Should build the lastUpdated filter", func(t *testing.T) {
+		assert.Nil(t, deltaParams(*base()))
+
+		req := base()
+		req.ModifiedSince = "2024-02-01T06:30:00+03:00"
+		assert.Equal(t, map[string]string{"lastUpdated": "2024-02-01T03:30:00Z"}, deltaParams(*req))
+
+		req = base()
+		req.ModifiedSince = "2024-02-01"
+		assert.Equal(t, map[string]string{"lastUpdated": "2024-02-01T00:00:00Z"}, deltaParams(*req))
+
+		req = base()
+		req.LastUpdatedDuration = "12h"
+		assert.Equal(t, map[string]string{"lastUpdatedDuration": "12h"}, deltaParams(*req))
+	})
+
+	t.Run("Should validate delta options", func(t *testing.T) {
+		req := base()
+		req.ModifiedSince = "yesterday"
+		assert.Error(t, ValidateTransferRequest(req))
+
+		req = base()
+		req.LastUpdatedDuration = "2 weeks"
+		assert.Error(t, ValidateTransferRequest(req))
+
+		req = base()
+		req.ModifiedSince = "2024-02-01"
+		req.LastUpdatedDuration = "1d"
+		assert.Error(t, ValidateTransferRequest(req))
+
+		req = base()
+		req.LastUpdatedDuration = "1d"
+		req.ImportMode = ImportModeReplace
+		req.ConfirmReplace = true
+		err := ValidateTransferRequest(req)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "delta")
+
+		req = base()
+		req.LastUpdatedDuration = "7d"
+		assert.NoError(t, ValidateTransferRequest(req))
+	})
+
+	t.Run("Should apply the filter to discovery and the per-org-unit fetch", func(t *testing.T) {
+		var discoveryQuery, fetchQuery string
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/dataValueSets.csv": func(w http.ResponseWriter, r *http.Request) {
+				discoveryQuery = r.URL.Query().Get("lastUpdatedDuration")
+				apitest.Raw(http.StatusOK, "dataelement,period,orgunit,catoptcombo,attroptcombo,value,storedby,lastupdated,comment,followup\n"+
+					"de1,202401,ouA,coc1,aoc1,1,,,,false\n")(w, r)
+			},
+			"/api/dataValueSets": func(w http.ResponseWriter, r *http.Request) {
+				fetchQuery = r.URL.Query().Get("lastUpdatedDuration")
+				apitest.JSON(http.StatusOK, map[string]interface{}{"dataValues": []interface{}{}})(w, r)
+			},
+			"/api/organisationUnits.json": apitest.JSON(http.StatusOK, map[string]interface{}{
+				"organisationUnits": []map[string]string{{"id": "ouA", "name": "Clinic A"}},
+			}),
+		})
+
+		req := base()
+		req.LastUpdatedDuration = "1d"
+		service := NewService(context.Background())
+
+		discovered, err := service.discoverOrgUnitsWithData(context.Background(), srv.Client(), "ds1", "202401", "root", deltaParams(*req))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"ouA": "Clinic A"}, discovered)
+		assert.Equal(t, "1d", discoveryQuery)
+
+		_, err = fetchOrgUnitValues(srv.Client(), *req, "202401", "ouA")
+		require.NoError(t, err)
+		assert.Equal(t, "1d", fetchQuery)
+	})
+}
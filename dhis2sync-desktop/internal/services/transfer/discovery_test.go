@@ -0,0 +1,143 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"dhis2sync-desktop/internal/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDiscoveryServer serves dataValueSets per period and batched org unit name lookups,
+// counting name lookup requests so caching and batching can be asserted.
+func newDiscoveryServer(t *testing.T, ousByPeriod map[string][]string, names map[string]string, nameLookups *int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/dataValueSets.csv":
+			w.Header().Set("Content-Type", "application/csv")
+			io.WriteString(w, csvHeader)
+			for _, ou := range ousByPeriod[r.URL.Query().Get("period")] {
+				fmt.Fprintf(w, "de1,%s,%s,coc1,aoc1,1,,,,false\n", r.URL.Query().Get("period"), ou)
+			}
+		case r.URL.Path == "/api/organisationUnits.json":
+			atomic.AddInt32(nameLookups, 1)
+			ids := strings.TrimSuffix(strings.TrimPrefix(r.URL.Query().Get("filter"), "id:in:["), "]")
+			units := []map[string]string{}
+			for _, id := range strings.Split(ids, ",") {
+				units = append(units, map[string]string{"id": id, "name": names[id]})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"organisationUnits": units})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestDiscoverOrgUnitsWithData(t *testing.T) {
+	service := NewService(context.Background())
+	names := map[string]string{"ouA": "Clinic A", "ouB": "Clinic B", "ouC": "Clinic C"}
+	ousByPeriod := map[string][]string{
+		"202401": {"ouA", "ouB"},
+		"202402": {"ouA", "ouB", "ouC"},
+		"202403": {"ouB", "ouC"},
+	}
+
+	t.Run("Should resolve uncached org unit names in one request per period", func(t *testing.T) {
+		var nameLookups int32
+		srv := newDiscoveryServer(t, ousByPeriod, names, &nameLookups)
+		defer srv.Close()
+
+		client := api.NewClient(srv.URL, "admin", "district")
+
+		for _, period := range []string{"202401", "202402", "202403"} {
+			discovered, err := service.discoverOrgUnitsWithData(context.Background(), client, "ds1", period, "root", nil)
+			require.NoError(t, err)
+			assert.Len(t, discovered, len(ousByPeriod[period]))
+			for _, ou := range ousByPeriod[period] {
+				assert.Equal(t, names[ou], discovered[ou])
+			}
+		}
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(&nameLookups), "Only the first two periods have uncached org units")
+	})
+
+	t.Run("Should share cached names across clients of the same server", func(t *testing.T) {
+		var nameLookups int32
+		srv := newDiscoveryServer(t, ousByPeriod, names, &nameLookups)
+		defer srv.Close()
+
+		for _, period := range []string{"202401", "202402", "202403"} {
+			client := api.NewClient(srv.URL, "admin", "district")
+			_, err := service.discoverOrgUnitsWithData(context.Background(), client, "ds1", period, "root", nil)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(&nameLookups), "A new client per period still reuses names")
+	})
+}
+
+// newLevelServer serves org units per level; levels listed in hang never respond
+// until the client gives up.
+func newLevelServer(t *testing.T, hang map[string]bool) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		level := strings.TrimPrefix(r.URL.Query().Get("filter"), "level:eq:")
+		if hang[level] {
+			<-r.Context().Done()
+			return
+		}
+		units := []map[string]interface{}{}
+		if level == "1" || level == "2" || level == "3" {
+			units = append(units, map[string]interface{}{"id": "ou" + level, "name": "Level " + level})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"organisationUnits": units})
+	}))
+}
+
+func TestFetchOrgUnitLevels(t *testing.T) {
+	service := NewService(context.Background())
+
+	t.Run("Should return partial results with an error for a hung level", func(t *testing.T) {
+		srv := newLevelServer(t, map[string]bool{"2": true})
+		defer srv.Close()
+		client := api.NewClient(srv.URL, "admin", "district")
+
+		start := time.Now()
+		result := service.fetchOrgUnitLevels(context.Background(), client, 4, 200*time.Millisecond)
+
+		assert.Less(t, time.Since(start), 10*time.Second, "A hung level should not block the batch")
+		assert.Len(t, result.Levels, 2)
+		assert.Contains(t, result.Levels, 1)
+		assert.Contains(t, result.Levels, 3)
+		require.Contains(t, result.Errors, 2)
+		assert.Len(t, result.Errors, 1)
+	})
+
+	t.Run("Should abort remaining levels when the context is cancelled", func(t *testing.T) {
+		srv := newLevelServer(t, map[string]bool{"1": true, "2": true, "3": true})
+		defer srv.Close()
+		client := api.NewClient(srv.URL, "admin", "district")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		result := service.fetchOrgUnitLevels(ctx, client, 6, time.Minute)
+
+		assert.Empty(t, result.Levels)
+		assert.Len(t, result.Errors, 6, "Every level should report why it is missing")
+		assert.Contains(t, result.firstError(), "level 1")
+	})
+}
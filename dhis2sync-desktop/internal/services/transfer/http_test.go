@@ -0,0 +1,471 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"dhis2sync-desktop/internal/api/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// csvHeader is the header row of a dataValueSets CSV export
+const csvHeader = "dataelement,period,orgunit,catoptcombo,attroptcombo,value,storedby,lastupdated,comment,followup\n"
+
+func TestDiscoverOrgUnitsWithDataResponses(t *testing.T) {
+	service := NewService(context.Background())
+
+	orgUnits := func(units ...map[string]string) http.HandlerFunc {
+		return apitest.JSON(http.StatusOK, map[string]interface{}{"organisationUnits": units})
+	}
+
+	tests := []struct {
+		name      string
+		routes    map[string]http.HandlerFunc
+		expected  map[string]string
+		expectErr bool
+	}{
+		{
+			name: "Should return unique org units with display names",
+			routes: map[string]http.HandlerFunc{
+				"/api/dataValueSets.csv": apitest.Raw(http.StatusOK, csvHeader+
+					"de1,202401,ouA,coc1,aoc1,1,admin,2024-02-01,,false\n"+
+					"de2,202401,ouA,coc1,aoc1,2,admin,2024-02-01,,false\n"+
+					"de1,202401,ouB,coc1,aoc1,3,admin,2024-02-01,\"note, with comma\",false\n"),
+				"/api/organisationUnits.json": orgUnits(
+					map[string]string{"id": "ouA", "name": "Clinic A", "displayName": "Clinic A (display)"},
+					map[string]string{"id": "ouB", "name": "Clinic B"},
+				),
+			},
+			expected: map[string]string{"ouA": "Clinic A (display)", "ouB": "Clinic B"},
+		},
+		{
+			name: "Should treat an empty data value set as no data",
+			routes: map[string]http.HandlerFunc{
+				"/api/dataValueSets.csv": apitest.Raw(http.StatusOK, csvHeader),
+			},
+			expected: map[string]string{},
+		},
+		{
+			name: "Should treat a server error as no data",
+			routes: map[string]http.HandlerFunc{
+				"/api/dataValueSets.csv": apitest.Raw(http.StatusConflict, `{"message":"Data set not found"}`),
+			},
+			expected: map[string]string{},
+		},
+		{
+			name: "Should return no names when the name lookup fails",
+			routes: map[string]http.HandlerFunc{
+				"/api/dataValueSets.csv":      apitest.Raw(http.StatusOK, csvHeader+"de1,202401,ouA,coc1,aoc1,1,,,,false\n"),
+				"/api/organisationUnits.json": apitest.Raw(http.StatusInternalServerError, `{"message":"boom"}`),
+			},
+			expected: map[string]string{},
+		},
+		{
+			name: "Should fail on a malformed payload",
+			routes: map[string]http.HandlerFunc{
+				"/api/dataValueSets.csv": apitest.Raw(http.StatusOK, `<html>login</html>`),
+			},
+			expectErr: true,
+		},
+		{
+			name: "Should skip org units whose names cannot be fetched",
+			routes: map[string]http.HandlerFunc{
+				"/api/dataValueSets.csv": apitest.Raw(http.StatusOK, csvHeader+
+					"de1,202401,ouA,coc1,aoc1,1,,,,false\n"+
+					"de1,202401,ouGone,coc1,aoc1,1,,,,false\n"),
+				"/api/organisationUnits.json": orgUnits(map[string]string{"id": "ouA", "name": "Clinic A"}),
+			},
+			expected: map[string]string{"ouA": "Clinic A"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := apitest.NewServer(t, tt.routes)
+
+			discovered, err := service.discoverOrgUnitsWithData(context.Background(), srv.Client(), "ds1", "202401", "root", nil)
+
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, discovered)
+		})
+	}
+}
+
+func TestFindMatchingOrgUnit(t *testing.T) {
+	service := NewService(context.Background())
+
+	// byFilter serves organisationUnits.json by the filter the service sends
+	byFilter := func(results map[string][]map[string]string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			apitest.JSON(http.StatusOK, map[string]interface{}{
+				"organisationUnits": results[r.URL.Query().Get("filter")],
+			})(w, r)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		results   map[string][]map[string]string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "Should match by identical UID",
+			results:  map[string][]map[string]string{"id:eq:ouSrc": {{"id": "ouSrc", "name": "Clinic A"}}},
+			expected: "ouSrc",
+		},
+		{
+			name: "Should fall back to a case-insensitive name match",
+			results: map[string][]map[string]string{
+				"name:ilike:Clinic A": {{"id": "ouOther", "name": "Clinic A Annex"}, {"id": "ouDest", "name": "CLINIC A"}},
+			},
+			expected: "ouDest",
+		},
+		{
+			name: "Should not accept partial name matches",
+			results: map[string][]map[string]string{
+				"name:ilike:Clinic A": {{"id": "ouOther", "name": "Clinic A Annex"}},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+				"/api/organisationUnits.json": byFilter(tt.results),
+			})
+
+			destID, err := service.findMatchingOrgUnit(srv.Client(), "ouSrc", "Clinic A")
+
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, destID)
+		})
+	}
+
+	t.Run("Should surface a failed name search", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/organisationUnits.json": apitest.Raw(http.StatusUnauthorized, "Unauthorized"),
+		})
+
+		_, err := service.findMatchingOrgUnit(srv.Client(), "ouSrc", "Clinic A")
+
+		require.Error(t, err)
+		assert.True(t, strings.Contains(err.Error(), "401"))
+	})
+}
+
+func TestResolutionsImportFlow(t *testing.T) {
+	service := NewService(context.Background())
+
+	source := []DataValue{
+		{DataElement: "de1", Period: "202401", OrgUnit: "ouA", CategoryOptionCombo: "cocKeep", Value: "1"},
+		{DataElement: "de2", Period: "202401", OrgUnit: "ouA", CategoryOptionCombo: "cocOld", Value: "2"},
+		{DataElement: "de3", Period: "202401", OrgUnit: "ouA", CategoryOptionCombo: "cocDrop", Value: "3"},
+		{DataElement: "de4", Period: "202401", OrgUnit: "ouSkip", CategoryOptionCombo: "cocKeep", Value: "4"},
+	}
+	resolutions := []Resolution{
+		{ID: "cocOld", Type: "coc", Action: "map:cocNew"},
+		{ID: "cocDrop", Type: "coc", Action: "skip"},
+		{ID: "ouSkip", Type: "orgUnit", Action: "skip"},
+	}
+
+	tests := []struct {
+		name         string
+		response     http.HandlerFunc
+		expectErr    bool
+		expectedSent int
+	}{
+		{
+			name: "Should post only resolved values and parse the summary",
+			response: apitest.JSON(http.StatusOK, map[string]interface{}{
+				"status":      "SUCCESS",
+				"importCount": map[string]int{"imported": 2},
+			}),
+			expectedSent: 2,
+		},
+		{
+			name:         "Should report a rejected import",
+			response:     apitest.Raw(http.StatusConflict, `{"status":"ERROR","message":"Period is locked"}`),
+			expectErr:    true,
+			expectedSent: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+				"POST /api/dataValueSets": tt.response,
+			})
+
+			sanitized, skipped := service.applyResolutions(append([]DataValue{}, source...), resolutions)
+			assert.Equal(t, 2, skipped)
+
+			summary, err := service.importDataValuesChunk(srv.Client(), sanitized, "ds1", "202401", "ouA")
+
+			var posted DataValueSetPayload
+			apitest.DecodeBody(t, srv.LastBody("/api/dataValueSets"), &posted)
+			require.Len(t, posted.DataValues, tt.expectedSent)
+			assert.Equal(t, "cocKeep", posted.DataValues[0].CategoryOptionCombo)
+			assert.Equal(t, "cocNew", posted.DataValues[1].CategoryOptionCombo)
+			assert.Equal(t, "ds1", posted.DataSet)
+
+			if tt.expectErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "409")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 2, summary.ImportCount.Imported)
+		})
+	}
+}
+
+func TestDeleteDataValues(t *testing.T) {
+	service := NewService(context.Background())
+
+	t.Run("Should post stale values with the DELETE import strategy", func(t *testing.T) {
+		var strategy string
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/dataValueSets": func(w http.ResponseWriter, r *http.Request) {
+				strategy = r.URL.Query().Get("importStrategy")
+				apitest.JSON(http.StatusOK, map[string]interface{}{
+					"status":      "SUCCESS",
+					"importCount": map[string]int{"deleted": 2},
+				})(w, r)
+			},
+		})
+		values := []DataValue{
+			{DataElement: "de1", Period: "202401", OrgUnit: "ou1", CategoryOptionCombo: "coc1", Value: "1"},
+			{DataElement: "de2", Period: "202401", OrgUnit: "ou1", CategoryOptionCombo: "coc1", Value: "2"},
+		}
+
+		deleted, err := service.deleteDataValues(srv.Client(), values)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, deleted)
+		assert.Equal(t, "DELETE", strategy)
+
+		var payload BulkDataValueSetPayload
+		apitest.DecodeBody(t, srv.LastBody("/api/dataValueSets"), &payload)
+		assert.Len(t, payload.DataValues, 2)
+	})
+
+	t.Run("Should surface a rejected delete", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/dataValueSets": apitest.Raw(http.StatusConflict, `{"message":"Period locked"}`),
+		})
+
+		_, err := service.deleteDataValues(srv.Client(), []DataValue{{DataElement: "de1"}})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "409")
+	})
+}
+
+func TestImportChunkDelay(t *testing.T) {
+	service := NewService(context.Background())
+	values := []DataValue{
+		{DataElement: "de1", Period: "202401", OrgUnit: "ou1", Value: "1"},
+		{DataElement: "de2", Period: "202401", OrgUnit: "ou1", Value: "2"},
+		{DataElement: "de3", Period: "202401", OrgUnit: "ou1", Value: "3"},
+	}
+
+	t.Run("Should wait between chunks but not before the first", func(t *testing.T) {
+		var mu sync.Mutex
+		var posted []time.Time
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/dataValueSets": func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				posted = append(posted, time.Now())
+				mu.Unlock()
+				apitest.JSON(http.StatusOK, map[string]interface{}{
+					"status":      "SUCCESS",
+					"importCount": map[string]int{"imported": 1},
+				})(w, r)
+			},
+		})
+		delay := 40 * time.Millisecond
+
+		start := time.Now()
+		summaries, err := service.importDataValuesBulk(context.Background(), srv.Client(), values, 1, delay, nil)
+
+		require.NoError(t, err)
+		assert.Len(t, summaries, 3)
+		require.Len(t, posted, 3)
+		assert.Less(t, posted[0].Sub(start), delay)
+		for i := 1; i < len(posted); i++ {
+			assert.GreaterOrEqual(t, posted[i].Sub(posted[i-1]), delay)
+		}
+	})
+
+	t.Run("Should stop between chunks when the transfer is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/dataValueSets": func(w http.ResponseWriter, r *http.Request) {
+				cancel() // The user cancels once the first chunk is in
+				apitest.JSON(http.StatusOK, map[string]interface{}{
+					"status":      "SUCCESS",
+					"importCount": map[string]int{"imported": 1},
+				})(w, r)
+			},
+		})
+
+		start := time.Now()
+		summaries, err := service.importDataValuesBulk(ctx, srv.Client(), values, 1, time.Hour, nil)
+
+		assert.ErrorContains(t, err, "context canceled")
+		assert.Len(t, summaries, 1)
+		assert.Equal(t, 1, srv.Hits("/api/dataValueSets"))
+		assert.Less(t, time.Since(start), time.Minute)
+	})
+
+	t.Run("Should convert the request option and ignore negative delays", func(t *testing.T) {
+		assert.Equal(t, time.Duration(0), chunkDelay(TransferRequest{}))
+		assert.Equal(t, time.Duration(0), chunkDelay(TransferRequest{InterChunkDelayMs: -5}))
+		assert.Equal(t, 250*time.Millisecond, chunkDelay(TransferRequest{InterChunkDelayMs: 250}))
+	})
+
+	t.Run("Should stop waiting when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := waitChunkDelay(ctx, time.Hour)
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestFetchOrgUnitNames(t *testing.T) {
+	t.Run("Should return names only for org units that exist", func(t *testing.T) {
+		var filter string
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/organisationUnits.json": func(w http.ResponseWriter, r *http.Request) {
+				filter = r.URL.Query().Get("filter")
+				apitest.JSON(http.StatusOK, map[string]interface{}{
+					"organisationUnits": []map[string]string{
+						{"id": "ouA", "name": "Clinic A", "displayName": "Clinic A (display)"},
+						{"id": "ouB", "name": "Clinic B"},
+					},
+				})(w, r)
+			},
+		})
+
+		names, err := fetchOrgUnitNames(srv.Client(), []string{"ouA", "ouB", "ouGone"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "id:in:[ouA,ouB,ouGone]", filter)
+		assert.Equal(t, map[string]string{"ouA": "Clinic A (display)", "ouB": "Clinic B"}, names)
+	})
+
+	t.Run("Should split large ID lists into several lookups", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/organisationUnits.json": apitest.JSON(http.StatusOK, map[string]interface{}{"organisationUnits": []interface{}{}}),
+		})
+		ids := make([]string, quickLookupChunkSize+1)
+		for i := range ids {
+			ids[i] = "ou" + strings.Repeat("x", i%5)
+		}
+
+		_, err := fetchOrgUnitNames(srv.Client(), ids)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, srv.Hits("/api/organisationUnits.json"))
+	})
+
+	t.Run("Should keep the names from lookups that succeeded", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/organisationUnits.json": func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Query().Get("filter"), "ouFirst") {
+					apitest.JSON(http.StatusOK, map[string]interface{}{
+						"organisationUnits": []map[string]string{{"id": "ouFirst", "name": "Clinic First"}},
+					})(w, r)
+					return
+				}
+				apitest.Raw(http.StatusInternalServerError, "boom")(w, r)
+			},
+		})
+		ids := []string{"ouFirst"}
+		for i := 0; i < quickLookupChunkSize; i++ {
+			ids = append(ids, fmt.Sprintf("ouLater%03d", i))
+		}
+
+		names, err := fetchOrgUnitNames(srv.Client(), ids)
+
+		assert.ErrorContains(t, err, "1 of 101 org units could not be looked up")
+		assert.Equal(t, map[string]string{"ouFirst": "Clinic First"}, names)
+	})
+}
+
+func TestDatasetAssignments(t *testing.T) {
+	t.Run("Should read the dataset's assigned org units", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/dataSets/ds1.json": apitest.JSON(http.StatusOK, map[string]interface{}{
+				"organisationUnits": []map[string]string{{"id": "ou1"}, {"id": "ou2"}},
+			}),
+		})
+
+		assigned, err := fetchDatasetOrgUnits(srv.Client(), "ds1")
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"ou1": true, "ou2": true}, assigned)
+	})
+
+	t.Run("Should fail when the dataset can't be read", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/dataSets/ds1.json": apitest.Raw(http.StatusNotFound, `{"message":"not found"}`),
+		})
+
+		_, err := fetchDatasetOrgUnits(srv.Client(), "ds1")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "404")
+	})
+
+	t.Run("Should post org units as collection additions", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/dataSets/ds1/organisationUnits": apitest.Raw(http.StatusNoContent, ""),
+		})
+
+		err := assignDatasetToOrgUnits(srv.Client(), "ds1", []string{"ou3", "ou4"})
+
+		require.NoError(t, err)
+		var body struct {
+			Additions []idRef `json:"additions"`
+			Deletions []idRef `json:"deletions"`
+		}
+		apitest.DecodeBody(t, srv.LastBody("/api/dataSets/ds1/organisationUnits"), &body)
+		assert.Equal(t, []idRef{{ID: "ou3"}, {ID: "ou4"}}, body.Additions)
+		assert.Empty(t, body.Deletions)
+	})
+
+	t.Run("Should report unassigned org units once with every skipped period", func(t *testing.T) {
+		service := NewService(context.Background())
+		service.taskStore["task"] = &TransferProgress{TaskID: "task"}
+
+		service.recordUnassignedOrgUnit("task", "ou3", "Clinic A", "202401")
+		service.recordUnassignedOrgUnit("task", "ou3", "Clinic A", "202402")
+		service.recordUnassignedOrgUnit("task", "ou4", "Clinic B", "202401")
+
+		unassigned := service.taskStore["task"].UnassignedOUs
+		require.Len(t, unassigned, 2)
+		assert.Equal(t, []string{"202401", "202402"}, unassigned[0].Periods)
+		assert.Equal(t, 2, service.unassignedCount("task"))
+	})
+}
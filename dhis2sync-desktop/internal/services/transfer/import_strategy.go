@@ -0,0 +1,37 @@
+package transfer
+
+import "net/url"
+
+// Import strategies for TransferRequest.ImportStrategy, passed to DHIS2 as importStrategy
+const (
+	ImportStrategyCreateAndUpdate = "CREATE_AND_UPDATE" // Default
+	ImportStrategyCreate          = "CREATE"            // Only add values the destination lacks
+	ImportStrategyUpdate          = "UPDATE"            // Only change values the destination has
+	ImportStrategyDelete          = "DELETE"            // Remove the transferred values from the destination
+)
+
+var importStrategies = map[string]bool{
+	ImportStrategyCreateAndUpdate: true,
+	ImportStrategyCreate:          true,
+	ImportStrategyUpdate:          true,
+	ImportStrategyDelete:          true,
+}
+
+// withImportStrategy adds importStrategy to a dataValueSets endpoint that already has a
+// query string; an empty strategy leaves DHIS2's default
+func withImportStrategy(endpoint, strategy string) string {
+	if strategy == "" {
+		return endpoint
+	}
+	return endpoint + "&importStrategy=" + url.QueryEscape(strategy)
+}
+
+// markDeleted returns copies of values flagged deleted, DHIS2's convention for DELETE imports
+func markDeleted(values []DataValue) []DataValue {
+	marked := make([]DataValue, len(values))
+	for i, dv := range values {
+		dv.Deleted = true
+		marked[i] = dv
+	}
+	return marked
+}
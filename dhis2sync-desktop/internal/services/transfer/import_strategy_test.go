@@ -0,0 +1,95 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"dhis2sync-desktop/internal/api/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateImportStrategy(t *testing.T) {
+	base := func(strategy string) *TransferRequest {
+		return &TransferRequest{
+			ProfileID:       "abcdefghij1",
+			SourceDatasetID: "abcdefghij2",
+			Periods:         []string{"202401"},
+			ImportStrategy:  strategy,
+		}
+	}
+
+	t.Run("Should default to CREATE_AND_UPDATE and normalise case", func(t *testing.T) {
+		req := base("")
+		require.NoError(t, ValidateTransferRequest(req))
+		assert.Equal(t, ImportStrategyCreateAndUpdate, req.ImportStrategy)
+
+		req = base(" update ")
+		require.NoError(t, ValidateTransferRequest(req))
+		assert.Equal(t, ImportStrategyUpdate, req.ImportStrategy)
+	})
+
+	t.Run("Should reject unknown strategies", func(t *testing.T) {
+		err := ValidateTransferRequest(base("MERGE"))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ImportStrategy")
+	})
+
+	t.Run("Should require confirmation for a DELETE run that writes", func(t *testing.T) {
+		assert.Error(t, ValidateTransferRequest(base(ImportStrategyDelete)))
+
+		dryRun := base(ImportStrategyDelete)
+		dryRun.DryRun = true
+		assert.NoError(t, ValidateTransferRequest(dryRun))
+
+		confirmed := base(ImportStrategyDelete)
+		confirmed.ConfirmDelete = true
+		assert.NoError(t, ValidateTransferRequest(confirmed))
+	})
+
+	t.Run("Should reject strategies that contradict other options", func(t *testing.T) {
+		replace := base(ImportStrategyCreate)
+		replace.ImportMode = ImportModeReplace
+		replace.DryRun = true
+		assert.Error(t, ValidateTransferRequest(replace))
+
+		skip := base(ImportStrategyDelete)
+		skip.ConfirmDelete = true
+		skip.SkipUnchanged = true
+		assert.Error(t, ValidateTransferRequest(skip))
+	})
+}
+
+func TestImportStrategySubmission(t *testing.T) {
+	t.Run("Should send the strategy and flag values deleted for DELETE", func(t *testing.T) {
+		var strategy string
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"POST /api/dataValueSets": func(w http.ResponseWriter, r *http.Request) {
+				strategy = r.URL.Query().Get("importStrategy")
+				apitest.JSON(http.StatusOK, map[string]interface{}{"response": map[string]string{"id": "job1"}})(w, r)
+			},
+			"/api/system/tasks/DATAVALUE_IMPORT/job1": apitest.JSON(http.StatusOK, []map[string]interface{}{{
+				"completed": true,
+				"level":     "INFO",
+				"summary":   map[string]interface{}{"status": "SUCCESS", "importCount": map[string]int{"deleted": 1}},
+			}}),
+		})
+		values := []DataValue{{DataElement: "de1", Period: "202401", OrgUnit: "ou1", Value: "1"}}
+
+		summaries, err := NewService(context.Background()).importDataValuesBulkAsync(context.Background(), srv.Client(),
+			values, 1000, 0, 0, ImportStrategyDelete, nil, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, summaries[0].ImportCount.Deleted)
+		assert.Equal(t, ImportStrategyDelete, strategy)
+
+		var payload BulkDataValueSetPayload
+		require.NoError(t, json.Unmarshal(srv.LastBody("/api/dataValueSets"), &payload))
+		assert.True(t, payload.DataValues[0].Deleted)
+		assert.False(t, values[0].Deleted, "The caller's values are left untouched")
+	})
+}
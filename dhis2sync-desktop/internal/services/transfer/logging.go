@@ -0,0 +1,31 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// taskIDKey is the context key carrying a transfer's task ID
+type taskIDKey struct{}
+
+// withTaskID returns a context carrying taskID as the correlation ID for logf
+func withTaskID(ctx context.Context, taskID string) context.Context {
+	return context.WithValue(ctx, taskIDKey{}, taskID)
+}
+
+// taskIDFrom returns the task ID ctx carries, or "" when it carries none
+func taskIDFrom(ctx context.Context) string {
+	taskID, _ := ctx.Value(taskIDKey{}).(string)
+	return taskID
+}
+
+// logf logs like log.Printf, prefixed with "[taskID]" when ctx carries one, so lines
+// from concurrent transfers can be filtered by task
+func logf(ctx context.Context, format string, args ...interface{}) {
+	if taskID := taskIDFrom(ctx); taskID != "" {
+		log.Printf("[%s] %s", taskID, fmt.Sprintf(format, args...))
+		return
+	}
+	log.Printf(format, args...)
+}
@@ -0,0 +1,235 @@
+package transfer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxGeneratedPeriods bounds GeneratePeriods and ExpandRelativePeriods output
+const maxGeneratedPeriods = 1000
+
+// periodCalendar lays out one period type's periods by their start dates (UTC midnight)
+type periodCalendar struct {
+	months, days int                               // Length of one period
+	start        func(day time.Time) time.Time     // Start of the period containing day
+	format       func(start time.Time) string      // DHIS2 identifier of the period starting at start
+	parse        func(id string) (time.Time, bool) // Start of the period id names
+}
+
+// periodCalendars holds the period types the generator supports, keyed by normalizePeriodType
+var periodCalendars = map[string]periodCalendar{
+	"WEEKLY": {
+		days:  7,
+		start: func(day time.Time) time.Time { return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)) },
+		format: func(start time.Time) string {
+			year, week := start.ISOWeek()
+			return fmt.Sprintf("%dW%d", year, week)
+		},
+		parse: func(id string) (time.Time, bool) {
+			var year, week int
+			if _, err := fmt.Sscanf(id, "%4dW%d", &year, &week); err != nil {
+				return time.Time{}, false
+			}
+			return isoWeekStart(year, week), true
+		},
+	},
+	"MONTHLY": {
+		months: 1,
+		start:  func(day time.Time) time.Time { return utcDate(day.Year(), day.Month(), 1) },
+		format: func(start time.Time) string { return start.Format("200601") },
+		parse: func(id string) (time.Time, bool) {
+			t, err := time.Parse("200601", id)
+			return t, err == nil
+		},
+	},
+	"QUARTERLY": {
+		months: 3,
+		start:  func(day time.Time) time.Time { return utcDate(day.Year(), (day.Month()-1)/3*3+1, 1) },
+		format: func(start time.Time) string { return fmt.Sprintf("%dQ%d", start.Year(), (start.Month()-1)/3+1) },
+		parse: func(id string) (time.Time, bool) {
+			var year, quarter int
+			if _, err := fmt.Sscanf(id, "%4dQ%d", &year, &quarter); err != nil {
+				return time.Time{}, false
+			}
+			return utcDate(year, time.Month((quarter-1)*3+1), 1), true
+		},
+	},
+	"SIXMONTHLY": {
+		months: 6,
+		start:  func(day time.Time) time.Time { return utcDate(day.Year(), (day.Month()-1)/6*6+1, 1) },
+		format: func(start time.Time) string { return fmt.Sprintf("%dS%d", start.Year(), (start.Month()-1)/6+1) },
+		parse: func(id string) (time.Time, bool) {
+			var year, half int
+			if _, err := fmt.Sscanf(id, "%4dS%d", &year, &half); err != nil {
+				return time.Time{}, false
+			}
+			return utcDate(year, time.Month((half-1)*6+1), 1), true
+		},
+	},
+	"YEARLY": {
+		months: 12,
+		start:  func(day time.Time) time.Time { return utcDate(day.Year(), time.January, 1) },
+		format: func(start time.Time) string { return strconv.Itoa(start.Year()) },
+		parse: func(id string) (time.Time, bool) {
+			t, err := time.Parse("2006", id)
+			return t, err == nil
+		},
+	},
+}
+
+// Relative periods: THIS_MONTH, LAST_QUARTER, LAST_12_MONTHS, LAST_2_SIXMONTHS, ...
+var (
+	relativePeriodPattern = regexp.MustCompile(`^(THIS|LAST)(?:_(\d+))?_(WEEK|MONTH|QUARTER|SIX_?MONTH|YEAR)S?$`)
+	relativeSeparators    = regexp.MustCompile(`[\s-]+`)
+)
+
+// relativePeriodUnits maps a relative period's unit to the period type it counts in
+var relativePeriodUnits = map[string]string{
+	"WEEK":      "WEEKLY",
+	"MONTH":     "MONTHLY",
+	"QUARTER":   "QUARTERLY",
+	"SIXMONTH":  "SIXMONTHLY",
+	"SIX_MONTH": "SIXMONTHLY",
+	"YEAR":      "YEARLY",
+}
+
+// GeneratePeriods lists the DHIS2 period identifiers of a period type (MONTHLY, QUARTERLY,
+// WEEKLY, SIX_MONTHLY or YEARLY) from start to end inclusive, e.g. MONTHLY 202411 to
+// 202502 gives 202411, 202412, 202501, 202502. Weeks are ISO weeks, so 2020W53 exists
+// but 2021W53 doesn't.
+func GeneratePeriods(periodType, start, end string) ([]string, error) {
+	cal, err := lookupPeriodCalendar(periodType)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := parseCalendarPeriod(cal, periodType, start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start period: %w", err)
+	}
+	to, err := parseCalendarPeriod(cal, periodType, end)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end period: %w", err)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("start period %s is after end period %s", start, end)
+	}
+
+	return periodsWithin(cal, from, to.AddDate(0, cal.months, cal.days))
+}
+
+// ExpandRelativePeriods turns a relative period such as LAST_12_MONTHS, THIS_YEAR or
+// LAST_6_QUARTERS into identifiers of periodType. LAST_n excludes the current period.
+// The periods listed are those of periodType falling within the relative range (see
+// periodsWithin), so MONTHLY LAST_YEAR gives last year's 12 months; an empty periodType
+// uses the relative period's own unit.
+func ExpandRelativePeriods(periodType, relative string) ([]string, error) {
+	return expandRelativePeriods(periodType, relative, time.Now())
+}
+
+// expandRelativePeriods is ExpandRelativePeriods as of now
+func expandRelativePeriods(periodType, relative string, now time.Time) ([]string, error) {
+	match := relativePeriodPattern.FindStringSubmatch(normalizeRelativePeriod(relative))
+	if match == nil {
+		return nil, fmt.Errorf("unsupported relative period %q: use THIS_<unit>, LAST_<unit> or LAST_<n>_<unit>S with WEEK, MONTH, QUARTER, SIX_MONTH or YEAR", relative)
+	}
+
+	count := 1
+	if match[2] != "" {
+		if match[1] == "THIS" {
+			return nil, fmt.Errorf("unsupported relative period %q: THIS takes no count", relative)
+		}
+		count, _ = strconv.Atoi(match[2])
+		if count < 1 || count > maxGeneratedPeriods {
+			return nil, fmt.Errorf("relative period count must be between 1 and %d", maxGeneratedPeriods)
+		}
+	}
+
+	unitType := relativePeriodUnits[match[3]]
+	unit := periodCalendars[unitType]
+	if periodType == "" {
+		periodType = unitType
+	}
+	cal, err := lookupPeriodCalendar(periodType)
+	if err != nil {
+		return nil, err
+	}
+
+	current := unit.start(utcDate(now.Year(), now.Month(), now.Day()))
+	from, to := current, current.AddDate(0, unit.months, unit.days)
+	if match[1] == "LAST" {
+		from, to = current.AddDate(0, -count*unit.months, -count*unit.days), current
+	}
+
+	periods, err := periodsWithin(cal, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(periods) == 0 {
+		return nil, fmt.Errorf("no %s periods fall within %s", periodType, relative)
+	}
+	return periods, nil
+}
+
+// lookupPeriodCalendar finds the generator's calendar for a period type
+func lookupPeriodCalendar(periodType string) (periodCalendar, error) {
+	cal, ok := periodCalendars[normalizePeriodType(periodType)]
+	if !ok {
+		return periodCalendar{}, fmt.Errorf("unsupported period type %q: use MONTHLY, QUARTERLY, WEEKLY, SIX_MONTHLY or YEARLY", periodType)
+	}
+	return cal, nil
+}
+
+// parseCalendarPeriod finds the start of the period id names, rejecting identifiers that
+// don't exist (e.g. 2021W53) by formatting the start back and comparing
+func parseCalendarPeriod(cal periodCalendar, periodType, id string) (time.Time, error) {
+	if err := validatePeriods([]string{id}, periodType); err != nil {
+		return time.Time{}, err
+	}
+	start, ok := cal.parse(id)
+	if !ok || cal.format(start) != id {
+		return time.Time{}, fmt.Errorf("%s is not a %s period", id, periodType)
+	}
+	return start, nil
+}
+
+// periodsWithin lists the calendar's periods anchored in [from, to). A period's anchor
+// is its start, except weeks use their Thursday: as in ISO, 2025W1 (starting Monday
+// 2024-12-30) belongs to 2025.
+func periodsWithin(cal periodCalendar, from, to time.Time) ([]string, error) {
+	anchor := func(start time.Time) time.Time { return start.AddDate(0, 0, cal.days/2) }
+
+	start := cal.start(from)
+	if anchor(start).Before(from) {
+		start = start.AddDate(0, cal.months, cal.days)
+	}
+
+	periods := []string{}
+	for ; anchor(start).Before(to); start = start.AddDate(0, cal.months, cal.days) {
+		if len(periods) == maxGeneratedPeriods {
+			return nil, fmt.Errorf("more than %d periods requested", maxGeneratedPeriods)
+		}
+		periods = append(periods, cal.format(start))
+	}
+	return periods, nil
+}
+
+// isoWeekStart is the Monday starting ISO week of year; week 1 holds January 4th
+func isoWeekStart(year, week int) time.Time {
+	jan4 := utcDate(year, time.January, 4)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	return monday.AddDate(0, 0, (week-1)*7)
+}
+
+// utcDate is midnight UTC on a date, so period arithmetic ignores DST
+func utcDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// normalizeRelativePeriod accepts "last 12 months" as well as LAST_12_MONTHS
+func normalizeRelativePeriod(relative string) string {
+	return relativeSeparators.ReplaceAllString(strings.ToUpper(strings.TrimSpace(relative)), "_")
+}
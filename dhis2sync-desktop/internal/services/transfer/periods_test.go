@@ -0,0 +1,118 @@
+package transfer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratePeriods(t *testing.T) {
+	t.Run("Should roll months and quarters over the year end", func(t *testing.T) {
+		months, err := GeneratePeriods("MONTHLY", "202411", "202502")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"202411", "202412", "202501", "202502"}, months)
+
+		quarters, err := GeneratePeriods("Quarterly", "2023Q3", "2024Q2")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2023Q3", "2023Q4", "2024Q1", "2024Q2"}, quarters)
+	})
+
+	t.Run("Should generate six-monthly and yearly periods", func(t *testing.T) {
+		halves, err := GeneratePeriods("SIX_MONTHLY", "2023S2", "2024S2")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2023S2", "2024S1", "2024S2"}, halves)
+
+		years, err := GeneratePeriods("YEARLY", "2022", "2024")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2022", "2023", "2024"}, years)
+	})
+
+	t.Run("Should follow ISO weeks across years with and without week 53", func(t *testing.T) {
+		weeks, err := GeneratePeriods("WEEKLY", "2020W52", "2021W2")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2020W52", "2020W53", "2021W1", "2021W2"}, weeks)
+
+		weeks, err = GeneratePeriods("WEEKLY", "2021W51", "2022W1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2021W51", "2021W52", "2022W1"}, weeks)
+
+		_, err = GeneratePeriods("WEEKLY", "2021W53", "2022W1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2021W53 is not a WEEKLY period")
+	})
+
+	t.Run("Should return a single period when start and end match", func(t *testing.T) {
+		periods, err := GeneratePeriods("MONTHLY", "202401", "202401")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"202401"}, periods)
+	})
+
+	t.Run("Should reject bad bounds and unsupported types", func(t *testing.T) {
+		_, err := GeneratePeriods("MONTHLY", "202403", "202401")
+		assert.ErrorContains(t, err, "is after end period")
+
+		_, err = GeneratePeriods("MONTHLY", "2024Q1", "202401")
+		assert.ErrorContains(t, err, "invalid start period")
+
+		_, err = GeneratePeriods("DAILY", "20240101", "20240102")
+		assert.ErrorContains(t, err, "unsupported period type")
+
+		_, err = GeneratePeriods("WEEKLY", "1990W1", "2024W1")
+		assert.ErrorContains(t, err, "more than 1000 periods")
+	})
+}
+
+func TestExpandRelativePeriods(t *testing.T) {
+	now := time.Date(2025, time.February, 12, 15, 30, 0, 0, time.Local) // Wednesday of 2025W7
+
+	t.Run("Should list the periods before the current one", func(t *testing.T) {
+		months, err := expandRelativePeriods("MONTHLY", "LAST_12_MONTHS", now)
+		require.NoError(t, err)
+		require.Len(t, months, 12)
+		assert.Equal(t, "202402", months[0])
+		assert.Equal(t, "202501", months[11])
+
+		quarters, err := expandRelativePeriods("", "last 6 quarters", now)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2023Q3", "2023Q4", "2024Q1", "2024Q2", "2024Q3", "2024Q4"}, quarters)
+	})
+
+	t.Run("Should include the current period for THIS", func(t *testing.T) {
+		periods, err := expandRelativePeriods("", "THIS_MONTH", now)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"202502"}, periods)
+
+		periods, err = expandRelativePeriods("", "THIS_WEEK", now)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2025W7"}, periods)
+	})
+
+	t.Run("Should list another period type within the range", func(t *testing.T) {
+		months, err := expandRelativePeriods("MONTHLY", "LAST_YEAR", now)
+		require.NoError(t, err)
+		assert.Len(t, months, 12)
+		assert.Equal(t, "202401", months[0])
+
+		halves, err := expandRelativePeriods("SIX_MONTHLY", "LAST_2_YEARS", now)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2023S1", "2023S2", "2024S1", "2024S2"}, halves)
+
+		weeks, err := expandRelativePeriods("WEEKLY", "LAST_YEAR", now)
+		require.NoError(t, err)
+		assert.Equal(t, "2024W1", weeks[0], "2024W1 starts on January 1st")
+		assert.Equal(t, "2024W52", weeks[len(weeks)-1], "2025W1 starts in 2024 but belongs to 2025")
+	})
+
+	t.Run("Should reject unknown or empty relative periods", func(t *testing.T) {
+		_, err := expandRelativePeriods("", "LAST_FORTNIGHT", now)
+		assert.ErrorContains(t, err, "unsupported relative period")
+
+		_, err = expandRelativePeriods("", "THIS_3_MONTHS", now)
+		assert.ErrorContains(t, err, "THIS takes no count")
+
+		_, err = expandRelativePeriods("YEARLY", "LAST_QUARTER", now)
+		assert.ErrorContains(t, err, "no YEARLY periods fall within LAST_QUARTER")
+	})
+}
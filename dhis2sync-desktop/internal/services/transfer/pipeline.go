@@ -0,0 +1,156 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+
+	"dhis2sync-desktop/internal/api"
+	"dhis2sync-desktop/internal/services/audit"
+)
+
+// valuePipeline turns fetched source values into destination-ready values. Transfers,
+// staging, previews and retries with new mappings share it so they filter, map,
+// transform and resolve values the same way.
+type valuePipeline struct {
+	s                *Service
+	taskID           string // Combo counts are recorded against it; empty for previews
+	req              TransferRequest
+	sourceDefaultCOC string
+	cocMatcher       *cocAutoMatcher
+	transformer      *valueTransformer
+	warnings         []string // Setup problems worth showing the user; the pipeline still runs
+
+	// Totals across calls
+	duplicates        int
+	transformed       int
+	transformRejected int
+}
+
+// newValuePipeline prepares the request's value handling against both instances. It
+// returns validatePeriods' error when the periods don't match the source dataset's
+// period type, since those periods silently return no data.
+func (s *Service) newValuePipeline(ctx context.Context, taskID string, req TransferRequest, sourceClient, destClient *api.Client) (*valuePipeline, error) {
+	p := &valuePipeline{s: s, taskID: taskID, req: req}
+
+	periodType, err := fetchDatasetPeriodType(sourceClient, req.SourceDatasetID)
+	if err != nil {
+		logf(ctx, "Could not load the dataset's period type, skipping period check: %v", err)
+	} else if err := validatePeriods(req.Periods, periodType); err != nil {
+		return nil, err
+	}
+
+	if req.AutoMatchCOCs {
+		p.cocMatcher = newCOCAutoMatcher(sourceClient, destClient, req.Resolutions)
+	}
+
+	// Transformed values are checked against the destination elements' value types
+	if len(req.ValueTransforms) > 0 {
+		valueTypes, err := audit.FetchValueTypes(destClient, req.DestDatasetID)
+		if err != nil {
+			logf(ctx, "Could not load destination value types, transformed values won't be checked: %v", err)
+		}
+		p.transformer = newValueTransformer(req.ValueTransforms, req.ElementMapping, valueTypes)
+	}
+
+	// Resolve the source's default COC so its values can be routed to the configured destination COC
+	if req.DefaultCOCMapping != "" {
+		if defaultCOC, err := s.fetchDefaultCOCID(sourceClient); err != nil {
+			p.warnings = append(p.warnings, fmt.Sprintf("⚠ Could not resolve source default COC, only blank COCs will be remapped: %v", err))
+		} else {
+			p.sourceDefaultCOC = defaultCOC
+		}
+	}
+
+	return p, nil
+}
+
+// prepare runs one org unit/period's source values through the pipeline. It returns the
+// destination-ready values and those without an element mapping; both carry the
+// destination org unit and the period.
+func (p *valuePipeline) prepare(ctx context.Context, values []DataValue, destOUID, ouName, period string) ([]DataValue, []DataValue) {
+	values = p.selectValues(ctx, values, ouName, period)
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	for i := range values {
+		values[i].OrgUnit = destOUID
+		values[i].Period = period
+	}
+
+	mapped, unmapped := p.s.applyMapping(ctx, values, p.req.ElementMapping)
+	if len(mapped) == 0 {
+		return nil, unmapped
+	}
+	return p.resolveValues(ctx, mapped, ouName+"/"+period), unmapped
+}
+
+// selectValues applies the request's follow-up and category option combo filters
+func (p *valuePipeline) selectValues(ctx context.Context, values []DataValue, ouName, period string) []DataValue {
+	if p.req.FollowUpMode != "" && p.req.FollowUpMode != FollowUpAll {
+		var dropped int
+		values, dropped = filterFollowUp(values, p.req.FollowUpMode)
+		if dropped > 0 {
+			logf(ctx, "Follow-up filter (%s) dropped %d values for %s/%s", p.req.FollowUpMode, dropped, ouName, period)
+		}
+	}
+
+	// api/dataValueSets can't filter by COC, so the selection is applied here
+	if len(p.req.CategoryOptionCombos) > 0 {
+		var dropped int
+		values, dropped = filterCategoryOptionCombos(values, p.req.CategoryOptionCombos)
+		if dropped > 0 {
+			logf(ctx, "Category option combo filter dropped %d values for %s/%s", dropped, ouName, period)
+		}
+		p.s.recordComboCounts(p.taskID, values)
+	}
+
+	return values
+}
+
+// resolveValues transforms element-mapped values, routes default COCs, applies the
+// user's (and auto-matched) resolutions and collapses duplicate keys. label names the
+// values in log lines.
+func (p *valuePipeline) resolveValues(ctx context.Context, values []DataValue, label string) []DataValue {
+	if p.transformer != nil {
+		var transformed, rejected int
+		values, transformed, rejected = p.transformer.apply(values)
+		p.transformed += transformed
+		p.transformRejected += rejected
+		if rejected > 0 {
+			logf(ctx, "Dropped %d transformed values for %s that the destination value type rejects", rejected, label)
+		}
+	}
+
+	if p.req.DefaultCOCMapping != "" {
+		values = p.s.applyDefaultCOCMapping(ctx, values, p.sourceDefaultCOC, p.req.DefaultCOCMapping)
+	}
+
+	resolutions := p.req.Resolutions
+	if p.cocMatcher != nil {
+		var err error
+		if resolutions, err = p.cocMatcher.resolve(values); err != nil {
+			logf(ctx, "COC auto-matching skipped for %s: %v", label, err)
+		}
+	}
+	values, skipped := p.s.applyResolutions(values, resolutions)
+	if skipped > 0 {
+		logf(ctx, "Skipped %d values for %s based on resolutions", skipped, label)
+	}
+
+	// Collapse duplicate keys (including ones created by mapping) so the import is deterministic
+	values, duplicates := dedupeDataValues(values)
+	if duplicates > 0 {
+		p.duplicates += duplicates
+		logf(ctx, "Dropped %d duplicate values for %s, keeping the most recently updated", duplicates, label)
+	}
+
+	return values
+}
+
+// recordCOCMatches stores what auto-matching did on the pipeline's task
+func (p *valuePipeline) recordCOCMatches() {
+	if p.cocMatcher != nil {
+		p.s.recordCOCMatches(p.taskID, p.cocMatcher)
+	}
+}
@@ -0,0 +1,44 @@
+package transfer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValuePipelinePrepare(t *testing.T) {
+	service := NewService(context.Background())
+	req := TransferRequest{
+		FollowUpMode:      FollowUpExclude,
+		ElementMapping:    map[string]string{"srcDE1": "dstDE1", "srcDE2": "dstDE2"},
+		ValueTransforms:   map[string]ValueTransform{"srcDE1": {Factor: 10}},
+		DefaultCOCMapping: "dstDefault",
+		Resolutions:       []Resolution{{ID: "srcCOC9", Type: "coc", Action: "skip"}},
+	}
+	pipeline := &valuePipeline{
+		s:                service,
+		req:              req,
+		sourceDefaultCOC: "srcDefault",
+		transformer:      newValueTransformer(req.ValueTransforms, req.ElementMapping, nil),
+	}
+
+	values := []DataValue{
+		{DataElement: "srcDE1", CategoryOptionCombo: "srcDefault", Value: "2", LastUpdated: "2024-01-02T00:00:00"},
+		{DataElement: "srcDE1", CategoryOptionCombo: "srcDefault", Value: "3", LastUpdated: "2024-01-01T00:00:00"},
+		{DataElement: "srcDE2", CategoryOptionCombo: "srcCOC9", Value: "5"},
+		{DataElement: "srcDE2", CategoryOptionCombo: "", Value: "6", FollowUp: true},
+		{DataElement: "srcDE3", CategoryOptionCombo: "srcDefault", Value: "7"},
+	}
+
+	prepared, unmapped := pipeline.prepare(context.Background(), values, "dstOU", "District A", "202401")
+
+	require.Len(t, prepared, 1, "follow-up, resolution and duplicate rules all apply")
+	assert.Equal(t, DataValue{DataElement: "dstDE1", OrgUnit: "dstOU", Period: "202401", CategoryOptionCombo: "dstDefault", Value: "20", LastUpdated: "2024-01-02T00:00:00"}, prepared[0])
+	require.Len(t, unmapped, 1)
+	assert.Equal(t, "srcDE3", unmapped[0].DataElement)
+	assert.Equal(t, "dstOU", unmapped[0].OrgUnit)
+	assert.Equal(t, 1, pipeline.duplicates)
+	assert.Equal(t, 2, pipeline.transformed)
+}
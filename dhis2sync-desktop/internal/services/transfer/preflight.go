@@ -0,0 +1,194 @@
+package transfer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"dhis2sync-desktop/internal/api"
+)
+
+// newValidationReport starts an empty report; lists are non-nil so the UI always gets arrays
+func newValidationReport() *ValidationReport {
+	return &ValidationReport{Errors: []ValidationIssue{}, Warnings: []ValidationIssue{}}
+}
+
+func (r *ValidationReport) addError(field, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, ValidationIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *ValidationReport) addWarning(field, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, ValidationIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkTransfer runs the server-side pre-flight checks for a request that already passed
+// ValidateTransferRequest. Only metadata is read; nothing is imported.
+func checkTransfer(req *TransferRequest, sourceClient, destClient *api.Client) *ValidationReport {
+	report := newValidationReport()
+
+	var (
+		wg              sync.WaitGroup
+		source, dest    *DatasetInfo
+		srcErr, destErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		source, srcErr = fetchDatasetInfo(sourceClient, req.SourceDatasetID)
+	}()
+	go func() {
+		defer wg.Done()
+		dest, destErr = fetchDatasetInfo(destClient, req.DestDatasetID)
+	}()
+	wg.Wait()
+
+	if srcErr != nil {
+		report.addError("SourceDatasetID", "source dataset %s could not be read: %v", req.SourceDatasetID, srcErr)
+	}
+	if destErr != nil {
+		report.addError("DestDatasetID", "destination dataset %s could not be read: %v", req.DestDatasetID, destErr)
+	}
+
+	if source != nil && dest != nil {
+		checkElementMapping(report, req, source, dest)
+	}
+	if source != nil {
+		checkPeriodTypes(report, req.Periods, "source", source.PeriodType)
+	}
+	if dest != nil && (source == nil || normalizePeriodType(dest.PeriodType) != normalizePeriodType(source.PeriodType)) {
+		checkPeriodTypes(report, req.Periods, "destination", dest.PeriodType)
+	}
+	if dest != nil && len(dest.OrganisationUnits) == 0 && !req.AutoAssignDataset {
+		report.addWarning("DestDatasetID", "destination dataset %s is not assigned to any org units, so DHIS2 will ignore the values (set auto_assign_dataset to assign it)", req.DestDatasetID)
+	}
+
+	checkOrgUnits(report, req, sourceClient, destClient)
+
+	report.Valid = len(report.Errors) == 0
+	return report
+}
+
+// checkElementMapping verifies every mapped destination element is in the destination
+// dataset and flags source elements the transfer would drop
+func checkElementMapping(report *ValidationReport, req *TransferRequest, source, dest *DatasetInfo) {
+	sourceElements := make(map[string]bool, len(source.DataElements))
+	for _, de := range source.DataElements {
+		sourceElements[de.ID] = true
+	}
+	destElements := make(map[string]bool, len(dest.DataElements))
+	for _, de := range dest.DataElements {
+		destElements[de.ID] = true
+	}
+
+	// Without a mapping values keep their source element IDs
+	if len(req.ElementMapping) == 0 {
+		var missing []string
+		for _, de := range source.DataElements {
+			if !destElements[de.ID] {
+				missing = append(missing, de.ID)
+			}
+		}
+		if len(missing) > 0 {
+			report.addWarning("ElementMapping", "%d source data element(s) are not in the destination dataset and have no mapping: %s", len(missing), strings.Join(missing, ", "))
+		}
+		return
+	}
+
+	srcIDs := make([]string, 0, len(req.ElementMapping))
+	for srcID := range req.ElementMapping {
+		srcIDs = append(srcIDs, srcID)
+	}
+	sort.Strings(srcIDs)
+
+	for _, srcID := range srcIDs {
+		destID := req.ElementMapping[srcID]
+		if !destElements[destID] {
+			report.addError("ElementMapping", "%s maps to %s, which is not in destination dataset %s", srcID, destID, req.DestDatasetID)
+		}
+		if !sourceElements[srcID] {
+			report.addWarning("ElementMapping", "%s is not in source dataset %s, so its mapping is unused", srcID, req.SourceDatasetID)
+		}
+	}
+
+	unmapped := 0
+	for _, de := range source.DataElements {
+		if _, ok := req.ElementMapping[de.ID]; !ok {
+			unmapped++
+		}
+	}
+	if unmapped > 0 {
+		report.addWarning("ElementMapping", "%d source data element(s) have no mapping and will not be transferred", unmapped)
+	}
+}
+
+// checkPeriodTypes reports periods that aren't identifiers of a dataset's period type
+func checkPeriodTypes(report *ValidationReport, periods []string, side, periodType string) {
+	if err := validatePeriods(periods, periodType); err != nil {
+		report.addError("Periods", "%s dataset: %v", side, err)
+	}
+}
+
+// checkOrgUnits verifies the destination user has org units to write to and that
+// explicitly selected org units exist
+func checkOrgUnits(report *ValidationReport, req *TransferRequest, sourceClient, destClient *api.Client) {
+	userOrgUnits, err := fetchUserOrgUnitIDs(destClient)
+	if err != nil {
+		report.addError("OrgUnitIDs", "destination user's org units could not be read: %v", err)
+	} else if len(userOrgUnits) == 0 {
+		report.addError("OrgUnitIDs", "destination user has no assigned org units, so no values can be written")
+	}
+
+	if req.OrgUnitSelectionMode != "selected" || len(req.OrgUnitIDs) == 0 {
+		return
+	}
+
+	if names, err := fetchOrgUnitNames(sourceClient, req.OrgUnitIDs); err != nil {
+		report.addError("OrgUnitIDs", "selected org units could not be looked up in the source: %v", err)
+	} else if missing := missingIDs(req.OrgUnitIDs, names); len(missing) > 0 {
+		report.addError("OrgUnitIDs", "%d selected org unit(s) don't exist in the source: %s", len(missing), strings.Join(missing, ", "))
+	}
+
+	// The regular transfer matches destination org units by name, so IDs only need to
+	// line up for a quick transfer
+	if names, err := fetchOrgUnitNames(destClient, req.OrgUnitIDs); err != nil {
+		report.addWarning("OrgUnitIDs", "selected org units could not be looked up in the destination: %v", err)
+	} else if missing := missingIDs(req.OrgUnitIDs, names); len(missing) > 0 {
+		report.addWarning("OrgUnitIDs", "%d selected org unit(s) have no destination org unit with the same ID and will be matched by name: %s", len(missing), strings.Join(missing, ", "))
+	}
+}
+
+// fetchUserOrgUnitIDs lists the org units assigned to the client's user
+func fetchUserOrgUnitIDs(client *api.Client) ([]string, error) {
+	resp, err := client.Get("api/me.json", map[string]string{"fields": "organisationUnits[id]"})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), api.BodySnippet(resp.Body(), 200))
+	}
+
+	var result struct {
+		OrgUnits []OrgUnit `json:"organisationUnits"`
+	}
+	if err := api.DecodeJSON(resp, "api/me.json", &result); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(result.OrgUnits))
+	for _, ou := range result.OrgUnits {
+		ids = append(ids, ou.ID)
+	}
+	return ids, nil
+}
+
+// missingIDs lists the ids absent from found, in order
+func missingIDs(ids []string, found map[string]string) []string {
+	var missing []string
+	for _, id := range ids {
+		if _, ok := found[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,149 @@
+package transfer
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"dhis2sync-desktop/internal/api/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTransfer(t *testing.T) {
+	dataset := func(id, periodType string, elements ...string) http.HandlerFunc {
+		dses := []map[string]interface{}{}
+		for _, deID := range elements {
+			dses = append(dses, map[string]interface{}{"dataElement": map[string]string{"id": deID}})
+		}
+		return apitest.JSON(http.StatusOK, map[string]interface{}{
+			"id":                id,
+			"periodType":        periodType,
+			"dataSetElements":   dses,
+			"organisationUnits": []map[string]string{{"id": "ouDistrict1"}},
+		})
+	}
+	me := apitest.JSON(http.StatusOK, map[string]interface{}{
+		"organisationUnits": []map[string]string{{"id": "ouNational1"}},
+	})
+	orgUnits := func(ids ...string) http.HandlerFunc {
+		ous := []map[string]string{}
+		for _, id := range ids {
+			ous = append(ous, map[string]string{"id": id, "name": id})
+		}
+		return apitest.JSON(http.StatusOK, map[string]interface{}{"organisationUnits": ous})
+	}
+
+	t.Run("Should pass a request whose mapping, periods and org units check out", func(t *testing.T) {
+		source := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/dataSets/srcDataset1.json": dataset("srcDataset1", "Monthly", "deSource001"),
+			"/api/organisationUnits.json":    orgUnits("ouDistrict1"),
+		})
+		dest := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/dataSets/dstDataset1.json": dataset("dstDataset1", "Monthly", "deDest00001"),
+			"/api/me.json":                   me,
+			"/api/organisationUnits.json":    orgUnits("ouDistrict1"),
+		})
+
+		report := checkTransfer(&TransferRequest{
+			SourceDatasetID:      "srcDataset1",
+			DestDatasetID:        "dstDataset1",
+			Periods:              []string{"202401", "202402"},
+			OrgUnitSelectionMode: "selected",
+			OrgUnitIDs:           []string{"ouDistrict1"},
+			ElementMapping:       map[string]string{"deSource001": "deDest00001"},
+		}, source.Client(), dest.Client())
+
+		assert.True(t, report.Valid)
+		assert.Empty(t, report.Errors)
+		assert.Empty(t, report.Warnings)
+	})
+
+	t.Run("Should report unknown mapped elements and mismatched periods", func(t *testing.T) {
+		source := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/dataSets/srcDataset1.json": dataset("srcDataset1", "Monthly", "deSource001", "deSource002"),
+		})
+		dest := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/dataSets/dstDataset1.json": dataset("dstDataset1", "Monthly", "deDest00001"),
+			"/api/me.json":                   me,
+		})
+
+		report := checkTransfer(&TransferRequest{
+			SourceDatasetID:      "srcDataset1",
+			DestDatasetID:        "dstDataset1",
+			Periods:              []string{"202401", "2024"},
+			OrgUnitSelectionMode: "discovered",
+			ElementMapping:       map[string]string{"deSource001": "deMissing01"},
+		}, source.Client(), dest.Client())
+
+		assert.False(t, report.Valid)
+		require.Len(t, report.Errors, 2)
+		assert.Equal(t, "ElementMapping", report.Errors[0].Field)
+		assert.Contains(t, report.Errors[0].Message, "deMissing01")
+		assert.Equal(t, "Periods", report.Errors[1].Field)
+		assert.Contains(t, report.Errors[1].Message, "2024")
+		assert.NotContains(t, report.Errors[1].Message, "202401")
+		require.Len(t, report.Warnings, 1)
+		assert.Contains(t, report.Warnings[0].Message, "1 source data element(s) have no mapping")
+	})
+
+	t.Run("Should report a missing dataset and a user without org units", func(t *testing.T) {
+		source := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/dataSets/srcDataset1.json": dataset("srcDataset1", "Monthly", "deSource001"),
+		})
+		dest := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/dataSets/dstDataset1.json": apitest.Raw(http.StatusNotFound, `{"message":"not found"}`),
+			"/api/me.json":                   apitest.JSON(http.StatusOK, map[string]interface{}{"organisationUnits": []string{}}),
+		})
+
+		report := checkTransfer(&TransferRequest{
+			SourceDatasetID:      "srcDataset1",
+			DestDatasetID:        "dstDataset1",
+			Periods:              []string{"202401"},
+			OrgUnitSelectionMode: "discovered",
+		}, source.Client(), dest.Client())
+
+		assert.False(t, report.Valid)
+		require.Len(t, report.Errors, 2)
+		assert.Equal(t, "DestDatasetID", report.Errors[0].Field)
+		assert.Equal(t, "OrgUnitIDs", report.Errors[1].Field)
+		assert.Contains(t, report.Errors[1].Message, "no assigned org units")
+	})
+
+	t.Run("Should report selected org units missing from either side", func(t *testing.T) {
+		source := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/dataSets/srcDataset1.json": dataset("srcDataset1", "Quarterly", "deSource001"),
+			"/api/organisationUnits.json":    orgUnits("ouDistrict1"),
+		})
+		dest := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/dataSets/srcDataset1.json": dataset("srcDataset1", "Quarterly", "deSource001"),
+			"/api/me.json":                   me,
+			"/api/organisationUnits.json":    orgUnits(),
+		})
+
+		report := checkTransfer(&TransferRequest{
+			SourceDatasetID:      "srcDataset1",
+			DestDatasetID:        "srcDataset1",
+			Periods:              []string{"2024Q1"},
+			OrgUnitSelectionMode: "selected",
+			OrgUnitIDs:           []string{"ouDistrict1", "ouGone00001"},
+		}, source.Client(), dest.Client())
+
+		require.Len(t, report.Errors, 1)
+		assert.Contains(t, report.Errors[0].Message, "ouGone00001")
+		require.Len(t, report.Warnings, 1)
+		assert.Contains(t, report.Warnings[0].Message, "matched by name")
+	})
+}
+
+func TestValidateTransfer(t *testing.T) {
+	t.Run("Should report request errors without contacting the servers", func(t *testing.T) {
+		report, err := NewService(context.Background()).ValidateTransfer(TransferRequest{})
+
+		require.NoError(t, err)
+		assert.False(t, report.Valid)
+		require.Len(t, report.Errors, 1)
+		assert.Equal(t, ValidationIssue{Field: "ProfileID", Message: "required"}, report.Errors[0])
+	})
+}
@@ -0,0 +1,193 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dhis2sync-desktop/internal/api"
+	"dhis2sync-desktop/internal/database"
+	"dhis2sync-desktop/internal/models"
+)
+
+// fetchOrgUnitValues fetches one org unit's source values for a period (children=false)
+func fetchOrgUnitValues(client *api.Client, req TransferRequest, period, ouID string) (*DataValueSet, error) {
+	params := map[string]string{
+		"dataSet":        req.SourceDatasetID,
+		"period":         period,
+		"orgUnit":        ouID,
+		"children":       "false", // specific OU only
+		"includeDeleted": "false",
+	}
+	if req.AttributeOptionComboID != "" {
+		params["attributeOptionCombo"] = req.AttributeOptionComboID
+	}
+	for key, value := range deltaParams(req) {
+		params[key] = value
+	}
+	// Servers that ignore followUp return every value; filterFollowUp still applies
+	switch req.FollowUpMode {
+	case FollowUpOnly:
+		params["followUp"] = "true"
+	case FollowUpExclude:
+		params["followUp"] = "false"
+	}
+
+	resp, err := client.Get("api/dataValueSets", params)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), api.BodySnippet(resp.Body(), 200))
+	}
+
+	var payload DataValueSet
+	if err := api.DecodeJSON(resp, "api/dataValueSets", &payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+
+// PreviewByElement totals the values a transfer would send, grouped by destination data
+// element, as a sanity check before importing. It discovers org units (or uses
+// req.OrgUnitIDs), runs the values through the same pipeline as a transfer and writes
+// nothing. Destination org unit matching is not checked.
+func (s *Service) PreviewByElement(req TransferRequest) (*ElementPreviewResponse, error) {
+	if len(req.Periods) == 0 {
+		return nil, fmt.Errorf("at least one period is required")
+	}
+
+	db := database.GetDB()
+	var profile models.ConnectionProfile
+	if err := db.Where("id = ?", req.ProfileID).First(&profile).Error; err != nil {
+		return nil, fmt.Errorf("failed to load profile: %w", err)
+	}
+
+	sourceClient, err := s.getAPIClient(&profile, "source")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create source client: %w", err)
+	}
+
+	destClient, err := s.getAPIClient(&profile, "destination")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create destination client: %w", err)
+	}
+
+	ctx := context.Background()
+	pipeline, err := s.newValuePipeline(ctx, "", req, sourceClient, destClient)
+	if err != nil {
+		return nil, fmt.Errorf("invalid periods: %w", err)
+	}
+
+	// Explicit org units skip discovery, as in a quick transfer
+	var selectedOUs map[string]string
+	rootID := ""
+	if len(req.OrgUnitIDs) > 0 {
+		selectedOUs, err = fetchOrgUnitNames(sourceClient, req.OrgUnitIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up org units: %w", err)
+		}
+	} else {
+		rootOU, err := s.GetUserRootOrgUnit(req.ProfileID, "source")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get root org unit: %w", err)
+		}
+		rootID = rootOU.ID
+	}
+
+	discoveryClient, err := s.getAPIClient(&profile, "source")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	discoveryClient.SetTimeout(discoveryTimeout(&profile))
+
+	totals := newElementTotals()
+	orgUnits := make(map[string]bool)
+
+	for _, period := range req.Periods {
+		ous := selectedOUs
+		if ous == nil {
+			ous, err = s.discoverOrgUnitsWithData(ctx, discoveryClient, req.SourceDatasetID, period, rootID, deltaParams(req))
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan period %s: %w", period, err)
+			}
+		}
+
+		for ouID, ouName := range ous {
+			payload, err := fetchOrgUnitValues(sourceClient, req, period, ouID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch values for %s/%s: %w", ouID, period, err)
+			}
+
+			// Values keep the source org unit, which the preview doesn't match
+			mapped, unmapped := pipeline.prepare(ctx, payload.DataValues, ouID, ouName, period)
+
+			totals.unmapped += len(unmapped)
+			for _, dv := range mapped {
+				totals.add(dv)
+			}
+			if len(mapped) > 0 {
+				orgUnits[ouID] = true
+			}
+		}
+	}
+
+	// Name elements from the destination dataset when it can be read
+	names := make(map[string]string)
+	if info, err := fetchDatasetInfo(destClient, req.DestDatasetID); err == nil {
+		for _, de := range info.DataElements {
+			names[de.ID] = de.DisplayName
+		}
+	}
+
+	return totals.response(names, len(orgUnits), len(req.Periods)), nil
+}
+
+// elementTotals accumulates preview counts per destination data element
+type elementTotals struct {
+	byElement map[string]*ElementTotal
+	unmapped  int
+}
+
+func newElementTotals() *elementTotals {
+	return &elementTotals{byElement: make(map[string]*ElementTotal)}
+}
+
+// add counts a value; values that parse as numbers are also summed
+func (t *elementTotals) add(dv DataValue) {
+	total, ok := t.byElement[dv.DataElement]
+	if !ok {
+		total = &ElementTotal{DataElement: dv.DataElement}
+		t.byElement[dv.DataElement] = total
+	}
+	total.Values++
+	if n, err := strconv.ParseFloat(strings.TrimSpace(dv.Value), 64); err == nil {
+		total.NumericValues++
+		total.Sum += n
+	}
+}
+
+// response orders elements by name, then ID
+func (t *elementTotals) response(names map[string]string, orgUnits, periods int) *ElementPreviewResponse {
+	resp := &ElementPreviewResponse{
+		Elements:       make([]ElementTotal, 0, len(t.byElement)),
+		UnmappedValues: t.unmapped,
+		OrgUnits:       orgUnits,
+		Periods:        periods,
+	}
+	for _, total := range t.byElement {
+		total.Name = names[total.DataElement]
+		resp.Elements = append(resp.Elements, *total)
+		resp.TotalValues += total.Values
+	}
+	sort.Slice(resp.Elements, func(i, j int) bool {
+		a, b := resp.Elements[i], resp.Elements[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.DataElement < b.DataElement
+	})
+	return resp
+}
@@ -0,0 +1,102 @@
+package transfer
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"dhis2sync-desktop/internal/api/apitest"
+)
+
+func TestElementTotals(t *testing.T) {
+	t.Run("Should count and sum values per element, ordered by name", func(t *testing.T) {
+		totals := newElementTotals()
+		for _, dv := range []DataValue{
+			{DataElement: "deMalaria01", Value: "40000"},
+			{DataElement: "deMalaria01", Value: " 5000.5 "},
+			{DataElement: "deComment01", Value: "Stock-out in March"},
+			{DataElement: "deAnc000001", Value: "12"},
+		} {
+			totals.add(dv)
+		}
+		totals.unmapped = 3
+
+		resp := totals.response(map[string]string{"deMalaria01": "Malaria cases", "deAnc000001": "ANC 1st visit"}, 2, 1)
+
+		require.Len(t, resp.Elements, 3)
+		assert.Equal(t, "deComment01", resp.Elements[0].DataElement, "Unnamed elements sort first")
+		assert.Equal(t, 1, resp.Elements[0].Values)
+		assert.Equal(t, 0, resp.Elements[0].NumericValues)
+
+		assert.Equal(t, "ANC 1st visit", resp.Elements[1].Name)
+		malaria := resp.Elements[2]
+		assert.Equal(t, "Malaria cases", malaria.Name)
+		assert.Equal(t, 2, malaria.Values)
+		assert.Equal(t, 2, malaria.NumericValues)
+		assert.InDelta(t, 45000.5, malaria.Sum, 0.001)
+
+		assert.Equal(t, 4, resp.TotalValues)
+		assert.Equal(t, 3, resp.UnmappedValues)
+		assert.Equal(t, 2, resp.OrgUnits)
+	})
+}
+
+func TestFetchOrgUnitValues(t *testing.T) {
+	req := TransferRequest{SourceDatasetID: "ds1", AttributeOptionComboID: "aoc1"}
+
+	t.Run("Should request a single org unit without children", func(t *testing.T) {
+		var query map[string]string
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/dataValueSets": func(w http.ResponseWriter, r *http.Request) {
+				query = map[string]string{}
+				for k := range r.URL.Query() {
+					query[k] = r.URL.Query().Get(k)
+				}
+				apitest.JSON(http.StatusOK, map[string]interface{}{
+					"dataValues": []map[string]string{{"dataElement": "de1", "value": "3"}},
+				})(w, r)
+			},
+		})
+
+		payload, err := fetchOrgUnitValues(srv.Client(), req, "202401", "ouA")
+
+		require.NoError(t, err)
+		require.Len(t, payload.DataValues, 1)
+		assert.Equal(t, "false", query["children"])
+		assert.Equal(t, "ouA", query["orgUnit"])
+		assert.Equal(t, "aoc1", query["attributeOptionCombo"])
+		assert.NotContains(t, query, "followUp")
+	})
+
+	t.Run("Should pass the follow-up filter to the server", func(t *testing.T) {
+		var followUp []string
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/dataValueSets": func(w http.ResponseWriter, r *http.Request) {
+				followUp = append(followUp, r.URL.Query().Get("followUp"))
+				apitest.JSON(http.StatusOK, map[string]interface{}{"dataValues": []interface{}{}})(w, r)
+			},
+		})
+
+		for _, mode := range []string{FollowUpOnly, FollowUpExclude, FollowUpAll} {
+			filtered := req
+			filtered.FollowUpMode = mode
+			_, err := fetchOrgUnitValues(srv.Client(), filtered, "202401", "ouA")
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, []string{"true", "false", ""}, followUp)
+	})
+
+	t.Run("Should report a non-success response", func(t *testing.T) {
+		srv := apitest.NewServer(t, map[string]http.HandlerFunc{
+			"/api/dataValueSets": apitest.Raw(http.StatusConflict, `{"message":"Period not valid"}`),
+		})
+
+		_, err := fetchOrgUnitValues(srv.Client(), req, "2024XX", "ouA")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "HTTP 409")
+	})
+}
@@ -0,0 +1,127 @@
+package transfer
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"dhis2sync-desktop/internal/api"
+	"dhis2sync-desktop/internal/database"
+	"dhis2sync-desktop/internal/models"
+)
+
+// quickLookupChunkSize bounds org unit IDs per id:in filter to keep URLs short
+const quickLookupChunkSize = 100
+
+// StartQuickTransfer transfers data for explicitly listed org units whose IDs are the
+// same in source and destination (same instance or ID-aligned metadata). It skips the
+// children=true discovery and name matching but otherwise behaves like StartTransfer.
+// Every org unit must exist in the source.
+func (s *Service) StartQuickTransfer(req TransferRequest) (string, error) {
+	if len(req.OrgUnitIDs) == 0 {
+		return "", fmt.Errorf("quick transfer requires explicit org units")
+	}
+
+	db := database.GetDB()
+	var profile models.ConnectionProfile
+	if err := db.Where("id = ?", req.ProfileID).First(&profile).Error; err != nil {
+		return "", fmt.Errorf("failed to load profile: %w", err)
+	}
+
+	sourceClient, err := s.getAPIClient(&profile, "source")
+	if err != nil {
+		return "", fmt.Errorf("failed to create source client: %w", err)
+	}
+
+	orgUnits, err := fetchOrgUnitNames(sourceClient, req.OrgUnitIDs)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify org units in source: %w", err)
+	}
+
+	missing := []string{}
+	for _, id := range req.OrgUnitIDs {
+		if _, ok := orgUnits[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return "", fmt.Errorf("org units not found in source: %s", strings.Join(missing, ", "))
+	}
+
+	req.OrgUnitSelectionMode = "selected"
+	return s.startTransferTask(req, orgUnits)
+}
+
+// fetchOrgUnitNames looks up org units by ID, returning ID -> name for those that exist.
+// Names already in the shared org unit name cache aren't requested again. A failed
+// lookup doesn't stop the others: the names found so far come back with the error.
+func fetchOrgUnitNames(client *api.Client, ids []string) (map[string]string, error) {
+	names := make(map[string]string, len(ids))
+	var firstErr error
+	failed := 0
+
+	uncached := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := client.CachedOrgUnitName(id); ok {
+			names[id] = name
+			continue
+		}
+		uncached = append(uncached, id)
+	}
+
+	for start := 0; start < len(uncached); start += quickLookupChunkSize {
+		end := start + quickLookupChunkSize
+		if end > len(uncached) {
+			end = len(uncached)
+		}
+
+		result, err := lookupOrgUnits(client, uncached[start:end])
+		if err != nil {
+			log.Printf("Org unit lookup failed for %d IDs (%s...): %v", end-start, uncached[start], err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed += end - start
+			continue
+		}
+
+		for _, ou := range result {
+			name := ou.DisplayName
+			if name == "" {
+				name = ou.Name
+			}
+			names[ou.ID] = name
+			client.CacheOrgUnitName(ou.ID, name)
+		}
+	}
+
+	if firstErr != nil {
+		return names, fmt.Errorf("%d of %d org units could not be looked up: %w", failed, len(uncached), firstErr)
+	}
+	return names, nil
+}
+
+// lookupOrgUnits fetches one chunk of org units with an id:in filter
+func lookupOrgUnits(client *api.Client, ids []string) ([]OrganisationUnit, error) {
+	resp, err := client.Get("api/organisationUnits.json", map[string]string{
+		"filter": fmt.Sprintf("id:in:[%s]", strings.Join(ids, ",")),
+		"fields": "id,name,displayName",
+		"paging": "false",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), resp.String())
+	}
+
+	var result struct {
+		OrganisationUnits []OrganisationUnit `json:"organisationUnits"`
+	}
+	if err := api.DecodeJSON(resp, "api/organisationUnits.json", &result); err != nil {
+		return nil, err
+	}
+	return result.OrganisationUnits, nil
+}
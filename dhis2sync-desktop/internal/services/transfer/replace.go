@@ -0,0 +1,106 @@
+package transfer
+
+import (
+	"fmt"
+	"log"
+
+	"dhis2sync-desktop/internal/api"
+)
+
+// Import modes for TransferRequest.ImportMode
+const (
+	ImportModeMerge   = "MERGE"   // Default: create and update, never delete
+	ImportModeReplace = "REPLACE" // Destination mirrors the source for the mapped elements
+)
+
+// replaceDeleteChunkSize bounds values per DELETE import
+const replaceDeleteChunkSize = 1000
+
+// replaceElementSet returns the destination data elements REPLACE mode may delete from.
+// Without an element mapping every element of the destination dataset is in scope (nil).
+func replaceElementSet(mapping map[string]string) map[string]bool {
+	if len(mapping) == 0 {
+		return nil
+	}
+
+	elements := make(map[string]bool, len(mapping))
+	for _, destID := range mapping {
+		elements[destID] = true
+	}
+	return elements
+}
+
+// replaceDeletions picks the existing destination values REPLACE mode removes for one
+// org unit and period: those in the element set that the incoming values won't overwrite.
+// Values about to be re-imported stay in place, so a failed import never leaves a gap.
+// A blank incoming AOC covers the destination value of any AOC; a non-empty aoc restricts
+// deletions to that attribute option combo.
+func replaceDeletions(existing, incoming []DataValue, elements map[string]bool, aoc string) []DataValue {
+	byFullKey := make(map[string]bool, len(incoming))
+	byElementCOC := make(map[string]bool, len(incoming))
+	for _, dv := range incoming {
+		if dv.AttributeOptionCombo == "" {
+			byElementCOC[dv.DataElement+"|"+dv.CategoryOptionCombo] = true
+		} else {
+			byFullKey[dv.DataElement+"|"+dv.CategoryOptionCombo+"|"+dv.AttributeOptionCombo] = true
+		}
+	}
+
+	var stale []DataValue
+	for _, dv := range existing {
+		if elements != nil && !elements[dv.DataElement] {
+			continue
+		}
+		if aoc != "" && dv.AttributeOptionCombo != aoc {
+			continue
+		}
+		if byElementCOC[dv.DataElement+"|"+dv.CategoryOptionCombo] ||
+			byFullKey[dv.DataElement+"|"+dv.CategoryOptionCombo+"|"+dv.AttributeOptionCombo] {
+			continue
+		}
+		stale = append(stale, dv)
+	}
+
+	return stale
+}
+
+// deleteDataValues removes values from the destination with a DELETE import strategy
+// and returns how many DHIS2 reports as deleted
+func (s *Service) deleteDataValues(client *api.Client, values []DataValue) (int, error) {
+	deleted := 0
+
+	for start := 0; start < len(values); start += replaceDeleteChunkSize {
+		end := start + replaceDeleteChunkSize
+		if end > len(values) {
+			end = len(values)
+		}
+
+		payload := BulkDataValueSetPayload{DataValues: values[start:end]}
+		resp, err := client.Post("api/dataValueSets?importStrategy="+ImportStrategyDelete, payload)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete data values: %w", err)
+		}
+		if !resp.IsSuccess() {
+			return deleted, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), resp.String())
+		}
+
+		var summary ImportSummary
+		if err := api.DecodeJSON(resp, "api/dataValueSets", &summary); err != nil {
+			return deleted, fmt.Errorf("failed to parse delete response: %w", err)
+		}
+		deleted += summary.ImportCount.Deleted
+	}
+
+	return deleted, nil
+}
+
+// recordReplacePreview stores values a dry run would have deleted
+func (s *Service) recordReplacePreview(taskID string, values []DataValue) {
+	s.taskMu.Lock()
+	defer s.taskMu.Unlock()
+
+	if progress, exists := s.taskStore[taskID]; exists {
+		progress.ReplacePreview = append(progress.ReplacePreview, values...)
+	}
+	log.Printf("[%s] Dry run: %d destination values would be deleted", taskID, len(values))
+}
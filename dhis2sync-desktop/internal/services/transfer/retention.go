@@ -0,0 +1,66 @@
+package transfer
+
+import (
+	"os"
+	"sort"
+	"strconv"
+)
+
+const (
+	// maxTaskMessages caps a task's in-memory message log, like the tracker and completeness services
+	maxTaskMessages = 500
+
+	// defaultTaskRetention is how many finished tasks stay in memory; older ones are
+	// served from the task_progress table. Override with TRANSFER_TASK_RETENTION.
+	defaultTaskRetention = 50
+)
+
+// appendMessage adds messages to the task's log, dropping the oldest beyond
+// maxTaskMessages. Callers hold taskMu.
+func (p *TransferProgress) appendMessage(messages ...string) {
+	p.Messages = capMessages(append(p.Messages, messages...))
+}
+
+// capMessages keeps the newest maxTaskMessages of a message log, in memory or persisted
+func capMessages(messages []string) []string {
+	if len(messages) > maxTaskMessages {
+		return messages[len(messages)-maxTaskMessages:]
+	}
+	return messages
+}
+
+// taskRetentionFromEnv reads TRANSFER_TASK_RETENTION, falling back to defaultTaskRetention
+func taskRetentionFromEnv() int {
+	if val := os.Getenv("TRANSFER_TASK_RETENTION"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultTaskRetention
+}
+
+// isFinished reports whether a task status is final, so the task can be evicted
+func isFinished(status string) bool {
+	return status == "completed" || status == "cancelled" || status == "error"
+}
+
+// evictFinishedTasks drops all but the taskRetention most recently active finished
+// tasks from memory. Caller must hold taskMu.
+func (s *Service) evictFinishedTasks() {
+	var finished []string
+	for taskID, p := range s.taskStore {
+		if isFinished(p.Status) {
+			finished = append(finished, taskID)
+		}
+	}
+	if len(finished) <= s.taskRetention {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		return s.taskStore[finished[i]].lastActivity.After(s.taskStore[finished[j]].lastActivity)
+	})
+	for _, taskID := range finished[s.taskRetention:] {
+		delete(s.taskStore, taskID)
+	}
+}
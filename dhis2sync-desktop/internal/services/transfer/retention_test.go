@@ -0,0 +1,109 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"dhis2sync-desktop/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvictFinishedTasks(t *testing.T) {
+	now := time.Now()
+	newTask := func(status string, age time.Duration) *TransferProgress {
+		p := &TransferProgress{Status: status}
+		p.touchActivity(now.Add(-age))
+		return p
+	}
+
+	t.Run("Should keep only the most recently active finished tasks", func(t *testing.T) {
+		service := NewService(context.Background())
+		service.taskRetention = 2
+		for i, status := range []string{"completed", "cancelled", "error", "completed"} {
+			service.taskStore[fmt.Sprintf("done-%d", i)] = newTask(status, time.Duration(i)*time.Hour)
+		}
+		service.taskStore["running"] = newTask("running", 10*time.Hour)
+		service.taskStore["parked"] = newTask("awaiting_user_decision", 10*time.Hour)
+
+		service.evictFinishedTasks()
+
+		assert.Len(t, service.taskStore, 4)
+		assert.Contains(t, service.taskStore, "done-0")
+		assert.Contains(t, service.taskStore, "done-1")
+		assert.Contains(t, service.taskStore, "running")
+		assert.Contains(t, service.taskStore, "parked")
+	})
+
+	t.Run("Should read the retention from the environment", func(t *testing.T) {
+		t.Setenv("TRANSFER_TASK_RETENTION", "5")
+		assert.Equal(t, 5, taskRetentionFromEnv())
+
+		t.Setenv("TRANSFER_TASK_RETENTION", "0")
+		assert.Equal(t, defaultTaskRetention, taskRetentionFromEnv())
+	})
+}
+
+func TestAppendMessage(t *testing.T) {
+	t.Run("Should keep the newest messages up to the cap", func(t *testing.T) {
+		p := &TransferProgress{}
+		for i := 0; i < maxTaskMessages; i++ {
+			p.appendMessage(fmt.Sprintf("message %d", i))
+		}
+
+		p.appendMessage("skip", "done")
+
+		require.Len(t, p.Messages, maxTaskMessages)
+		assert.Equal(t, "message 2", p.Messages[0])
+		assert.Equal(t, []string{"skip", "done"}, p.Messages[maxTaskMessages-2:])
+	})
+}
+
+func TestUpdateProgressPersisted(t *testing.T) {
+	db := useStagingDB(t)
+	require.NoError(t, db.AutoMigrate(&models.TaskProgress{}, &models.Notification{}))
+
+	// The task was evicted from memory; only its row is left
+	service := NewService(nil)
+	messages := make([]string, maxTaskMessages)
+	for i := range messages {
+		messages[i] = fmt.Sprintf("message %d", i)
+	}
+	require.NoError(t, db.Create(&models.TaskProgress{ID: "task-1", TaskType: "transfer", Status: "running", Messages: service.marshalMessages(messages)}).Error)
+
+	notified := func() int64 {
+		var count int64
+		require.NoError(t, db.Model(&models.Notification{}).Where("task_id = ?", "task-1").Count(&count).Error)
+		return count
+	}
+
+	t.Run("Should cap the persisted messages", func(t *testing.T) {
+		service.updateProgress("task-1", "running", 50, "still going")
+
+		var row models.TaskProgress
+		require.NoError(t, db.First(&row, "id = ?", "task-1").Error)
+		persisted := service.unmarshalMessages(row.Messages)
+		require.Len(t, persisted, maxTaskMessages)
+		assert.Equal(t, "message 1", persisted[0])
+		assert.Equal(t, "still going", persisted[maxTaskMessages-1])
+		assert.Zero(t, notified(), "Staying running isn't a change")
+	})
+
+	t.Run("Should notify once when the status changes", func(t *testing.T) {
+		service.updateProgress("task-1", "completed", 100, "done")
+		service.updateProgress("task-1", "completed", 100, "done again")
+
+		assert.Equal(t, int64(1), notified())
+	})
+
+	t.Run("Should not notify for an unknown task", func(t *testing.T) {
+		service.updateProgress("task-unknown", "completed", 100, "done")
+
+		var count int64
+		require.NoError(t, db.Model(&models.Notification{}).Where("task_id = ?", "task-unknown").Count(&count).Error)
+		assert.Zero(t, count)
+	})
+}
@@ -2,36 +2,49 @@ package transfer
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"dhis2sync-desktop/internal/api"
-	"dhis2sync-desktop/internal/crypto"
 	"dhis2sync-desktop/internal/database"
+	"dhis2sync-desktop/internal/events"
 	"dhis2sync-desktop/internal/models"
+	"dhis2sync-desktop/internal/notifications"
+	"dhis2sync-desktop/internal/writewindow"
 
 	"github.com/google/uuid"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // Service handles data transfer operations between DHIS2 instances
 type Service struct {
-	ctx       context.Context
-	taskStore map[string]*TransferProgress
-	taskMu    sync.RWMutex
+	ctx           context.Context
+	taskStore     map[string]*TransferProgress
+	taskMu        sync.RWMutex
+	taskRetention int // Finished tasks kept in taskStore
+	watchdogOnce  sync.Once
+	batchCancels  map[string]context.CancelFunc // Running org unit level batches by batch ID
+	batchMu       sync.Mutex
 }
 
 // NewService creates a new Transfer service
 func NewService(ctx context.Context) *Service {
 	return &Service{
-		ctx:       ctx,
-		taskStore: make(map[string]*TransferProgress),
+		ctx:           ctx,
+		taskStore:     make(map[string]*TransferProgress),
+		taskRetention: taskRetentionFromEnv(),
+		batchCancels:  make(map[string]context.CancelFunc),
 	}
 }
 
@@ -69,7 +82,7 @@ func (s *Service) ListDatasets(profileID string, sourceOrDest string) ([]Dataset
 		DataSets []Dataset `json:"dataSets"`
 	}
 
-	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+	if err := api.DecodeJSON(resp, "api/dataSets.json", &result); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
@@ -91,6 +104,53 @@ func (s *Service) GetDatasetInfo(profileID string, datasetID string, sourceOrDes
 		return nil, err
 	}
 
+	return fetchDatasetInfo(client, datasetID)
+}
+
+// fetchDatasetPeriodType looks up a dataset's period type (e.g. "Monthly")
+func fetchDatasetPeriodType(client *api.Client, datasetID string) (string, error) {
+	endpoint := fmt.Sprintf("api/dataSets/%s.json", datasetID)
+	resp, err := client.Get(endpoint, map[string]string{"fields": "periodType"})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch dataset period type: %w", err)
+	}
+	if !resp.IsSuccess() {
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode(), api.BodySnippet(resp.Body(), 200))
+	}
+
+	var dataset struct {
+		PeriodType string `json:"periodType"`
+	}
+	if err := api.DecodeJSON(resp, endpoint, &dataset); err != nil {
+		return "", err
+	}
+	return dataset.PeriodType, nil
+}
+
+// validatePeriods checks that every period is an identifier of the dataset's period type
+// (YYYYMM for Monthly, YYYYQn for Quarterly, ...), listing the ones that aren't. Period
+// types without a known format aren't checked, so unusual calendars aren't rejected.
+func validatePeriods(periods []string, periodType string) error {
+	format, ok := periodFormats[normalizePeriodType(periodType)]
+	if !ok {
+		return nil
+	}
+
+	var bad []string
+	for _, period := range periods {
+		if !format.pattern.MatchString(strings.TrimSpace(period)) {
+			bad = append(bad, period)
+		}
+	}
+	if len(bad) > 0 {
+		return fmt.Errorf("%d period(s) don't match the dataset's %s period type (expected %s): %s",
+			len(bad), periodType, format.layout, strings.Join(bad, ", "))
+	}
+	return nil
+}
+
+// fetchDatasetInfo retrieves a dataset's elements, org units and category combo
+func fetchDatasetInfo(client *api.Client, datasetID string) (*DatasetInfo, error) {
 	// Fetch dataset details
 	endpoint := fmt.Sprintf("api/dataSets/%s.json", datasetID)
 	params := map[string]string{
@@ -120,7 +180,7 @@ func (s *Service) GetDatasetInfo(profileID string, datasetID string, sourceOrDes
 		OrganisationUnits []OrganisationUnit `json:"organisationUnits"`
 	}
 
-	if err := json.Unmarshal(resp.Body(), &apiResponse); err != nil {
+	if err := api.DecodeJSON(resp, endpoint, &apiResponse); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
@@ -174,7 +234,7 @@ func (s *Service) GetOrgUnitTree(profileID, sourceOrDest, rootID string, maxDept
 		var userInfo struct {
 			OrganisationUnits []OrganisationUnit `json:"organisationUnits"`
 		}
-		if err := json.Unmarshal(resp.Body(), &userInfo); err != nil {
+		if err := api.DecodeJSON(resp, "api/me.json", &userInfo); err != nil {
 			return nil, fmt.Errorf("failed to parse user info: %w", err)
 		}
 
@@ -189,7 +249,7 @@ func (s *Service) GetOrgUnitTree(profileID, sourceOrDest, rootID string, maxDept
 		}
 
 		var ou OrganisationUnit
-		if err := json.Unmarshal(resp.Body(), &ou); err != nil {
+		if err := api.DecodeJSON(resp, fmt.Sprintf("api/organisationUnits/%s.json", rootID), &ou); err != nil {
 			return nil, fmt.Errorf("failed to parse org unit: %w", err)
 		}
 
@@ -297,7 +357,7 @@ func (s *Service) GetOrgUnitsByLevel(profileID, sourceOrDest string, level int)
 		OrganisationUnits []OrgUnit `json:"organisationUnits"`
 	}
 
-	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+	if err := api.DecodeJSON(resp, "api/organisationUnits.json", &result); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
@@ -339,26 +399,93 @@ func (s *Service) SearchOrgUnits(profileID, sourceOrDest, query string, limit in
 		OrganisationUnits []OrgUnit `json:"organisationUnits"`
 	}
 
-	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+	if err := api.DecodeJSON(resp, "api/organisationUnits.json", &result); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	return result.OrganisationUnits, nil
 }
 
+// ValidateTransfer checks a transfer request against both servers without moving any
+// data: the datasets exist, mapped elements are in the destination dataset, periods fit
+// the period type and the org units exist. Problems go in the report; only profile or
+// client setup errors fail the call.
+func (s *Service) ValidateTransfer(req TransferRequest) (*ValidationReport, error) {
+	if err := ValidateTransferRequest(&req); err != nil {
+		report := newValidationReport()
+		var vErr *ValidationError
+		if errors.As(err, &vErr) {
+			report.addError(vErr.Field, "%s", vErr.Message)
+		} else {
+			report.addError("", "%v", err)
+		}
+		return report, nil
+	}
+
+	db := database.GetDB()
+	var profile models.ConnectionProfile
+	if err := db.Where("id = ?", req.ProfileID).First(&profile).Error; err != nil {
+		return nil, fmt.Errorf("profile not found: %w", err)
+	}
+
+	sourceClient, err := s.getAPIClient(&profile, "source")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create source client: %w", err)
+	}
+	destClient, err := s.getAPIClient(&profile, "destination")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create destination client: %w", err)
+	}
+
+	return checkTransfer(&req, sourceClient, destClient), nil
+}
+
 // StartTransfer initiates a data transfer operation in the background
 func (s *Service) StartTransfer(req TransferRequest) (string, error) {
+	return s.startTransferTask(req, nil)
+}
+
+// startTransferTask registers a transfer task and runs it in the background.
+// quickOUs (source ID -> name) bypasses discovery and name matching; nil discovers.
+func (s *Service) startTransferTask(req TransferRequest, quickOUs map[string]string) (string, error) {
+	// Dry runs never write, so only real transfers are held to the profile's write window
+	if !req.DryRun {
+		var profile models.ConnectionProfile
+		if err := database.GetDB().Where("id = ?", req.ProfileID).First(&profile).Error; err != nil {
+			return "", fmt.Errorf("profile not found: %w", err)
+		}
+		if err := writewindow.Check(&profile, time.Now()); err != nil {
+			return "", err
+		}
+	}
+
 	// Generate task ID
 	taskID := uuid.New().String()
+	if err := s.registerTask(taskID, "transfer", req, "Initializing transfer..."); err != nil {
+		return "", err
+	}
+
+	// Start background goroutine, watched for stalls
+	s.startWatchdog()
+	go s.performTransfer(taskID, req, quickOUs)
 
+	return taskID, nil
+}
+
+// registerTask tracks a new task in memory and persists its TaskProgress record
+func (s *Service) registerTask(taskID, taskType string, req TransferRequest, message string) error {
 	// Initialize progress tracking
 	progress := &TransferProgress{
 		TaskID:    taskID,
 		Status:    "starting",
 		Progress:  0,
-		Messages:  []string{"Initializing transfer..."},
+		Messages:  []string{message},
 		StartedAt: time.Now().Format(time.RFC3339),
+
+		stallTimeout:  time.Duration(req.StallTimeoutSeconds) * time.Second,
+		cancelOnStall: req.CancelOnStall,
 	}
+	progress.touchActivity(time.Now())
 
 	// Store in memory
 	s.taskMu.Lock()
@@ -368,7 +495,7 @@ func (s *Service) StartTransfer(req TransferRequest) (string, error) {
 	// Persist to database
 	taskProgress := &models.TaskProgress{
 		ID:       taskID,
-		TaskType: "transfer",
+		TaskType: taskType,
 		Status:   "starting",
 		Progress: 0,
 		Messages: s.marshalMessages(progress.Messages),
@@ -376,13 +503,23 @@ func (s *Service) StartTransfer(req TransferRequest) (string, error) {
 
 	db := database.GetDB()
 	if err := db.Create(taskProgress).Error; err != nil {
-		return "", fmt.Errorf("failed to create task record: %w", err)
+		return fmt.Errorf("failed to create task record: %w", err)
 	}
+	return nil
+}
 
-	// Start background goroutine
-	go s.performTransfer(taskID, req)
+// taskContext returns the context a task's background work runs under; CancelTransfer
+// (or the watchdog) cancels it to stop the work and any polling of submitted jobs
+func (s *Service) taskContext(taskID string) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(withTaskID(context.Background(), taskID))
 
-	return taskID, nil
+	s.taskMu.Lock()
+	if p, exists := s.taskStore[taskID]; exists {
+		p.cancel = cancel
+	}
+	s.taskMu.Unlock()
+
+	return ctx, cancel
 }
 
 // GetTransferProgress retrieves the current progress of a transfer operation
@@ -420,12 +557,17 @@ func (s *Service) GetTransferProgress(taskID string) (*TransferProgress, error)
 	return progress, nil
 }
 
-// performTransfer executes the data transfer in a background goroutine
-func (s *Service) performTransfer(taskID string, req TransferRequest) {
+// performTransfer executes the data transfer in a background goroutine.
+// With quickOUs set, those org units are transferred for every period under the
+// same IDs in the destination, skipping root lookup, discovery and name matching.
+func (s *Service) performTransfer(taskID string, req TransferRequest, quickOUs map[string]string) {
+	ctx, cancel := s.taskContext(taskID)
+	defer cancel()
+
 	defer func() {
 		if r := recover(); r != nil {
 			s.updateProgress(taskID, "error", 0, fmt.Sprintf("Panic during transfer: %v", r))
-			log.Printf("Transfer panic recovered: %v", r)
+			logf(ctx, "Transfer panic recovered: %v", r)
 		}
 	}()
 
@@ -452,14 +594,33 @@ func (s *Service) performTransfer(taskID string, req TransferRequest) {
 		return
 	}
 
-	// Get user's root org unit from source instance
-	s.updateProgress(taskID, "running", 10, "Getting user's root organization unit...")
-	rootOU, err := s.GetUserRootOrgUnit(req.ProfileID, "source")
+	// Periods of another type silently return no data, so fail before reading anything
+	pipeline, err := s.newValuePipeline(ctx, taskID, req, sourceClient, destClient)
 	if err != nil {
-		s.updateProgress(taskID, "error", 0, fmt.Sprintf("Failed to get root org unit: %v", err))
+		s.updateProgress(taskID, "error", 0, fmt.Sprintf("Invalid periods: %v", err))
 		return
 	}
-	s.updateProgress(taskID, "running", 15, fmt.Sprintf("Using root org unit: %s (%s)", rootOU.Name, rootOU.ID))
+
+	// Load the destination dataset's org unit assignments; values for unassigned org units are ignored
+	assignedOUs, err := fetchDatasetOrgUnits(destClient, req.DestDatasetID)
+	if err != nil {
+		logf(ctx, "Could not load dataset assignments, skipping assignment check: %v", err)
+		assignedOUs = nil
+	}
+
+	// Get user's root org unit from source instance (discovery only)
+	rootOU := &OrgUnit{}
+	if quickOUs == nil {
+		s.updateProgress(taskID, "running", 10, "Getting user's root organization unit...")
+		rootOU, err = s.GetUserRootOrgUnit(req.ProfileID, "source")
+		if err != nil {
+			s.updateProgress(taskID, "error", 0, fmt.Sprintf("Failed to get root org unit: %v", err))
+			return
+		}
+		s.updateProgress(taskID, "running", 15, fmt.Sprintf("Using root org unit: %s (%s)", rootOU.Name, rootOU.ID))
+	} else {
+		s.updateProgress(taskID, "running", 15, fmt.Sprintf("Quick transfer of %d org units (no discovery)", len(quickOUs)))
+	}
 
 	// PHASE 1: Smart Batching Transfer Strategy
 	// Iterate by Period -> Discover OUs -> Process per OU
@@ -467,6 +628,7 @@ func (s *Service) performTransfer(taskID string, req TransferRequest) {
 
 	// Initialize aggregate import stats
 	var totalImported, totalUpdated, totalIgnored, totalDeleted int
+	totalUnchanged := 0 // Values omitted by SkipUnchanged
 	processedOUs := 0
 	notFoundOUs := []string{}
 
@@ -480,11 +642,37 @@ func (s *Service) performTransfer(taskID string, req TransferRequest) {
 		return
 	}
 
+	// Discovery client with a longer timeout for large children=true payloads,
+	// plus a name cache shared across periods so each OU name is resolved once per job
+	discoveryClient, err := s.getAPIClient(&profile, "source")
+	if err != nil {
+		s.updateProgress(taskID, "error", 0, fmt.Sprintf("Failed to create discovery client: %v", err))
+		return
+	}
+	discoveryClient.SetTimeout(discoveryTimeout(&profile))
+
+	for _, warning := range pipeline.warnings {
+		s.updateProgress(taskID, "running", 15, warning)
+	}
+
+	// Submitted async jobs are persisted against this task for ResumeAsyncPolling
+	jobRef := &asyncJobRef{TaskID: taskID, ProfileID: req.ProfileID}
+
+	// REPLACE removes destination values the source no longer has, per org unit/period
+	replaceMode := req.ImportMode == ImportModeReplace
+	replaceElements := replaceElementSet(req.ElementMapping)
+	totalWouldImport := 0 // DryRun: values that would have been sent
+
 	// Define a chunk size for progress updates within the OU loop
 	// We allocate 80% of progress bar to the transfer phase (20% was setup)
 	periodProgressChunk := 80 / totalPeriods
 
 	for i, period := range req.Periods {
+		if ctx.Err() != nil || s.isCancelled(taskID) {
+			logf(ctx, "Transfer cancelled, stopping before period %s", period)
+			return
+		}
+
 		// Update progress for the current period
 		currentPeriodProgress := 20 + (i * periodProgressChunk)
 		s.updateProgress(taskID, "running", currentPeriodProgress, fmt.Sprintf("Processing period %s...", period))
@@ -494,10 +682,13 @@ func (s *Service) performTransfer(taskID string, req TransferRequest) {
 		s.updateProgress(taskID, "running", currentPeriodProgress, fmt.Sprintf("Scanning for data in period %s...", period))
 
 		// Discover OUs with data for the current period, under the root OU
-		discoveredOUs, err := s.DiscoverOrgUnitsWithData(req.ProfileID, "source", req.SourceDatasetID, period, rootOU.ID)
-		if err != nil {
-			s.updateProgress(taskID, "running", currentPeriodProgress, fmt.Sprintf("⚠ Failed to scan period %s: %v", period, err))
-			continue
+		discoveredOUs := quickOUs
+		if quickOUs == nil {
+			discoveredOUs, err = s.discoverOrgUnitsWithData(ctx, discoveryClient, req.SourceDatasetID, period, rootOU.ID, deltaParams(req))
+			if err != nil {
+				s.updateProgress(taskID, "running", currentPeriodProgress, fmt.Sprintf("⚠ Failed to scan period %s: %v", period, err))
+				continue
+			}
 		}
 
 		if len(discoveredOUs) == 0 {
@@ -510,6 +701,11 @@ func (s *Service) performTransfer(taskID string, req TransferRequest) {
 		// 2. Process each Org Unit
 		ouIdx := 0
 		for ouID, ouName := range discoveredOUs {
+			if ctx.Err() != nil || s.isCancelled(taskID) {
+				logf(ctx, "Transfer cancelled, stopping before %s/%s", ouName, period)
+				return
+			}
+
 			ouIdx++
 			processedOUs++
 
@@ -523,7 +719,7 @@ func (s *Service) performTransfer(taskID string, req TransferRequest) {
 				}
 			}
 			if skipOU {
-				log.Printf("Skipping org unit %s (%s) based on user resolution", ouName, ouID)
+				logf(ctx, "Skipping org unit %s (%s) based on user resolution", ouName, ouID)
 				continue
 			}
 
@@ -533,56 +729,42 @@ func (s *Service) performTransfer(taskID string, req TransferRequest) {
 				s.updateProgress(taskID, "running", batchProgress, fmt.Sprintf("Processing %s (%d/%d)...", ouName, ouIdx, len(discoveredOUs)))
 			}
 
-			// Find matching org unit in destination
-			destOUID, err := s.FindMatchingOrgUnit(req.ProfileID, ouID, ouName)
-			if err != nil {
-				// Log warning but don't fail entire transfer
-				log.Printf("No matching org unit found in destination for %s (%s): %v", ouName, ouID, err)
-				notFoundOUs = append(notFoundOUs, ouName)
-				continue
+			// Find matching org unit in destination (quick transfers use the same ID)
+			destOUID := ouID
+			if quickOUs == nil {
+				destOUID, err = s.findMatchingOrgUnit(destClient, ouID, ouName)
+				if err != nil {
+					// Log warning but don't fail entire transfer
+					logf(ctx, "No matching org unit found in destination for %s (%s): %v", ouName, ouID, err)
+					notFoundOUs = append(notFoundOUs, ouName)
+					s.recordUnmatchedOrgUnit(taskID, sourceClient, ouID, ouName, period)
+					continue
+				}
 			}
 
-			// Fetch data for this specific Org Unit (children=false)
-			dvParams := map[string]string{
-				"dataSet":        req.SourceDatasetID,
-				"period":         period,
-				"orgUnit":        ouID,
-				"children":       "false", // specific OU only
-				"includeDeleted": "false",
-			}
-			if req.AttributeOptionComboID != "" {
-				dvParams["attributeOptionCombo"] = req.AttributeOptionComboID
+			if assignedOUs != nil && !assignedOUs[destOUID] {
+				if !req.AutoAssignDataset || req.DryRun {
+					s.updateProgress(taskID, "running", currentPeriodProgress, fmt.Sprintf("⚠ Destination dataset is not assigned to %s, skipping", ouName))
+					s.recordUnassignedOrgUnit(taskID, destOUID, ouName, period)
+					continue
+				}
+				if err := assignDatasetToOrgUnits(destClient, req.DestDatasetID, []string{destOUID}); err != nil {
+					s.updateProgress(taskID, "running", currentPeriodProgress, fmt.Sprintf("⚠ Failed to assign destination dataset to %s, skipping: %v", ouName, err))
+					s.recordUnassignedOrgUnit(taskID, destOUID, ouName, period)
+					continue
+				}
+				assignedOUs[destOUID] = true
+				s.updateProgress(taskID, "running", currentPeriodProgress, fmt.Sprintf("✓ Assigned destination dataset to %s", ouName))
 			}
 
-			resp, err := sourceClient.Get("api/dataValueSets", dvParams)
+			// Fetch data for this specific Org Unit (children=false)
+			dvPayload, err := fetchOrgUnitValues(sourceClient, req, period, ouID)
 			if err != nil {
-				log.Printf("Failed to fetch data for OU %s: %v", ouID, err)
-				continue
-			}
-
-			if !resp.IsSuccess() {
-				log.Printf("Source API returned HTTP %d for %s/%s", resp.StatusCode(), ouName, period)
+				logf(ctx, "Failed to fetch source data for %s/%s: %v", ouName, period, err)
 				continue
 			}
 
-			var dvPayload DataValueSet
-			if err := json.Unmarshal(resp.Body(), &dvPayload); err != nil {
-				log.Printf("Failed to parse source data for %s: %v", ouName, err)
-				continue
-			}
-
-			if len(dvPayload.DataValues) == 0 {
-				continue
-			}
-
-			// Update org unit + period in values to match destination
-			for i := range dvPayload.DataValues {
-				dvPayload.DataValues[i].OrgUnit = destOUID
-				dvPayload.DataValues[i].Period = period
-			}
-
-			// Apply element mapping if provided
-			mappedValues, unmappedValues := s.applyMapping(dvPayload.DataValues, req.ElementMapping)
+			sanitizedValues, unmappedValues := pipeline.prepare(ctx, dvPayload.DataValues, destOUID, ouName, period)
 
 			// Track unmapped values
 			if len(unmappedValues) > 0 {
@@ -598,23 +780,54 @@ func (s *Service) performTransfer(taskID string, req TransferRequest) {
 				s.taskMu.Unlock()
 			}
 
-			if len(mappedValues) == 0 {
+			if len(sanitizedValues) == 0 {
 				continue
 			}
 
-			// 3. Sanitize / Apply Resolutions
-			sanitizedValues, skippedCount := s.applyResolutions(mappedValues, req.Resolutions)
+			var existing []DataValue
+			var existingErr error
+			if req.SkipUnchanged || replaceMode {
+				existing, existingErr = s.fetchExistingValues(destClient, req.DestDatasetID, period, destOUID)
+			}
 
-			if skippedCount > 0 {
-				log.Printf("Skipped %d values for OU %s based on resolutions", skippedCount, ouName)
+			// Delete stale destination values before importing (REPLACE)
+			if replaceMode {
+				if existingErr != nil {
+					s.updateProgress(taskID, "running", currentPeriodProgress, fmt.Sprintf("⚠ Could not read destination values for %s/%s, nothing deleted: %v", ouName, period, existingErr))
+				} else if stale := replaceDeletions(existing, sanitizedValues, replaceElements, req.AttributeOptionComboID); len(stale) > 0 {
+					if req.DryRun {
+						s.recordReplacePreview(taskID, stale)
+					} else {
+						deleted, err := s.deleteDataValues(destClient, stale)
+						totalDeleted += deleted
+						if err != nil {
+							s.updateProgress(taskID, "running", currentPeriodProgress, fmt.Sprintf("⚠ Failed to delete stale values for %s/%s: %v", ouName, period, err))
+						}
+					}
+				}
 			}
 
-			if len(sanitizedValues) == 0 {
+			// Drop values the destination already holds unchanged
+			if req.SkipUnchanged {
+				if existingErr != nil {
+					logf(ctx, "Failed to fetch destination values for %s/%s, sending all: %v", ouName, period, existingErr)
+				} else {
+					var unchanged int
+					sanitizedValues, unchanged = filterUnchanged(sanitizedValues, existing)
+					totalUnchanged += unchanged
+					if len(sanitizedValues) == 0 {
+						continue
+					}
+				}
+			}
+
+			if req.DryRun {
+				totalWouldImport += len(sanitizedValues)
 				continue
 			}
 
 			// 4. Import to Destination
-			// Use Bulk Async for performance (chunk size 1000)
+			// Use Bulk Async for performance (chunk size and concurrency per request)
 
 			// Calculate progress range for this specific OU
 			// We map the import function's 0-100% progress to this OU's slice of the global progress
@@ -644,7 +857,7 @@ func (s *Service) performTransfer(taskID string, req TransferRequest) {
 				s.updateProgress(taskID, "running", newProgress, msg)
 			}
 
-			summaries, err := s.importDataValuesBulkAsync(destClient, sanitizedValues, 1000, onProgress)
+			summaries, err := s.importDataValuesBulkAsync(ctx, destClient, sanitizedValues, importChunkSize(req), maxConcurrentJobs(req), chunkDelay(req), req.ImportStrategy, jobRef, onProgress)
 			if err != nil {
 				s.updateProgress(taskID, "running", int(ouEndProgress), fmt.Sprintf("⚠ Import failed for %s: %v", ouName, err))
 				continue
@@ -685,6 +898,34 @@ func (s *Service) performTransfer(taskID string, req TransferRequest) {
 			totalImported, totalUpdated, len(notFoundOUs))
 	}
 
+	if req.SkipUnchanged {
+		description += fmt.Sprintf(", Skipped unchanged=%d", totalUnchanged)
+	}
+	if pipeline.duplicates > 0 {
+		description += fmt.Sprintf(", Duplicates dropped=%d", pipeline.duplicates)
+	}
+	if unassigned := s.unassignedCount(taskID); unassigned > 0 {
+		summaryStatus = "WARNING"
+		description += fmt.Sprintf(", Org units without dataset assignment=%d", unassigned)
+	}
+	if len(req.CategoryOptionCombos) > 0 {
+		description += ", Values per COC: " + s.comboCountsSummary(taskID, req.CategoryOptionCombos)
+	}
+	if pipeline.transformer != nil {
+		description += fmt.Sprintf(", Values transformed=%d", pipeline.transformed)
+		if pipeline.transformRejected > 0 {
+			summaryStatus = "WARNING"
+			description += fmt.Sprintf(", Transformed values rejected=%d", pipeline.transformRejected)
+		}
+	}
+	if cocMatcher := pipeline.cocMatcher; cocMatcher != nil {
+		pipeline.recordCOCMatches()
+		description += fmt.Sprintf(", COCs auto-matched=%d, unresolved=%d", len(cocMatcher.matched), len(cocMatcher.unresolved))
+		if len(cocMatcher.unresolved) > 0 {
+			summaryStatus = "WARNING"
+		}
+	}
+
 	summary := ImportSummary{
 		Status:      summaryStatus,
 		Description: description,
@@ -713,51 +954,34 @@ func (s *Service) performTransfer(taskID string, req TransferRequest) {
 		}
 	}
 
+	// A cancel during the last import ends the loop normally; don't go on to register completions
+	if ctx.Err() != nil || s.isCancelled(taskID) {
+		logf(ctx, "Transfer cancelled, skipping completion")
+		return
+	}
+
 	// Batch mark datasets as complete (if requested and successful transfers exist)
 	if req.MarkComplete && len(successfulTransfers) > 0 {
 		s.updateProgress(taskID, "running", 85, "Marking datasets as complete...")
 
-		// Build batched completion payload
-		completionRegs := []map[string]interface{}{}
-		now := time.Now().Format("2006-01-02") // YYYY-MM-DD format
-
+		transferKeys := make([]string, 0, len(successfulTransfers))
 		for transferKey := range successfulTransfers {
-			// Parse key: "destOUID:period"
-			parts := strings.Split(transferKey, ":")
-			if len(parts) != 2 {
-				continue
-			}
-			destOUID := parts[0]
-			period := parts[1]
-
-			completionRegs = append(completionRegs, map[string]interface{}{
-				"dataSet":          req.DestDatasetID,
-				"period":           period,
-				"organisationUnit": destOUID,
-				"completed":        true,
-				"completeDate":     now,
-				"storedBy":         "dhis2sync-desktop",
-			})
+			transferKeys = append(transferKeys, transferKey)
 		}
+		s.markComplete(ctx, taskID, destClient, req, transferKeys, 90)
+	}
 
-		if len(completionRegs) > 0 {
-			// Single batched POST for all completeness registrations
-			completionPayload := map[string]interface{}{
-				"completeDataSetRegistrations": completionRegs,
-			}
-
-			resp, err := destClient.Post("api/completeDataSetRegistrations", completionPayload)
-			if err != nil {
-				s.updateProgress(taskID, "running", 90, fmt.Sprintf("⚠ Failed to mark datasets complete: %v", err))
-				log.Printf("Completeness marking failed: %v", err)
-			} else if !resp.IsSuccess() {
-				s.updateProgress(taskID, "running", 90, fmt.Sprintf("⚠ Completion registration failed: HTTP %d", resp.StatusCode()))
-				log.Printf("Completeness marking failed: HTTP %d - %s", resp.StatusCode(), resp.String())
-			} else {
-				s.updateProgress(taskID, "running", 90, fmt.Sprintf("✓ Marked %d dataset registrations as complete", len(completionRegs)))
-				log.Printf("Successfully marked %d dataset registrations as complete", len(completionRegs))
-			}
+	if req.DryRun {
+		s.taskMu.Lock()
+		wouldDelete := 0
+		if progress, exists := s.taskStore[taskID]; exists {
+			wouldDelete = len(progress.ReplacePreview)
+			progress.CompletedAt = time.Now().Format(time.RFC3339)
 		}
+		s.taskMu.Unlock()
+
+		s.updateProgress(taskID, "completed", 100, fmt.Sprintf("Dry run complete: %d values would be imported, %d destination values would be deleted", totalWouldImport, wouldDelete))
+		return
 	}
 
 	// Check if there are unmapped values requiring user decision
@@ -767,9 +991,11 @@ func (s *Service) performTransfer(taskID string, req TransferRequest) {
 	var hasUnmapped bool
 	if progress, exists := s.taskStore[taskID]; exists {
 		progress.TotalImported = totalImported + totalUpdated
+		progress.TotalUnchanged = totalUnchanged
 
 		if len(progress.UnmappedValues) > 0 {
 			hasUnmapped = true
+			progress.request = &req
 			totalUnmapped = 0
 			for _, values := range progress.UnmappedValues {
 				totalUnmapped += len(values)
@@ -808,7 +1034,7 @@ func (s *Service) performTransfer(taskID string, req TransferRequest) {
 		// Frontend will detect "awaiting_user_decision" status and show modal
 		// User selects option, frontend calls: App.ResolveUnmappedValues(taskID, action, newMappings)
 
-		log.Printf("[%s] Transfer paused - awaiting user decision on %d unmapped values", taskID, totalUnmapped)
+		logf(ctx, "Transfer paused - awaiting user decision on %d unmapped values", totalUnmapped)
 		return // Stop here, wait for user decision
 	}
 
@@ -826,8 +1052,12 @@ func (s *Service) performTransfer(taskID string, req TransferRequest) {
 	}
 	s.updateProgress(taskID, "completed", 100, msg)
 
+	if req.SkipUnchanged {
+		s.updateProgress(taskID, "completed", 100, fmt.Sprintf("Skipped %d values already identical in destination", totalUnchanged))
+	}
+
 	if len(notFoundOUs) > 0 {
-		s.updateProgress(taskID, "completed", 100, fmt.Sprintf("Note: %d org units not found in destination: %v", len(notFoundOUs), notFoundOUs))
+		s.updateProgress(taskID, "completed", 100, fmt.Sprintf("Note: %d org units not found in destination (export them to create or map): %v", len(notFoundOUs), notFoundOUs))
 	}
 
 	// Mark completion time
@@ -844,7 +1074,7 @@ func (s *Service) performTransfer(taskID string, req TransferRequest) {
 // applyMapping applies element mapping to data values
 // Returns two slices: mapped values (with transformed IDs) and unmapped values (filtered out)
 // Unmapped values are returned separately for user review/decision
-func (s *Service) applyMapping(dataValues []DataValue, mapping map[string]string) ([]DataValue, []DataValue) {
+func (s *Service) applyMapping(ctx context.Context, dataValues []DataValue, mapping map[string]string) ([]DataValue, []DataValue) {
 	// No mapping provided → return all as mapped, none as unmapped
 	if len(mapping) == 0 {
 		return dataValues, []DataValue{}
@@ -866,16 +1096,68 @@ func (s *Service) applyMapping(dataValues []DataValue, mapping map[string]string
 	}
 
 	// Log mapping statistics for debugging
-	log.Printf("Applied element mapping: %d mapped, %d unmapped (filtered), %d total",
+	logf(ctx, "Applied element mapping: %d mapped, %d unmapped (filtered), %d total",
 		len(mapped), len(unmapped), len(dataValues))
 
 	if len(unmapped) > 0 {
-		log.Printf("WARNING: %d data values have no mapping entry and will be filtered out", len(unmapped))
+		logf(ctx, "WARNING: %d data values have no mapping entry and will be filtered out", len(unmapped))
 	}
 
 	return mapped, unmapped // Return both lists separately
 }
 
+// applyDefaultCOCMapping routes values recorded under the source's default COC,
+// or with no COC at all, to the given destination COC
+func (s *Service) applyDefaultCOCMapping(ctx context.Context, dataValues []DataValue, sourceDefaultCOC, destCOC string) []DataValue {
+	if destCOC == "" {
+		return dataValues
+	}
+
+	remapped := 0
+	for i := range dataValues {
+		coc := dataValues[i].CategoryOptionCombo
+		if coc == "" || (sourceDefaultCOC != "" && coc == sourceDefaultCOC) {
+			dataValues[i].CategoryOptionCombo = destCOC
+			remapped++
+		}
+	}
+
+	if remapped > 0 {
+		logf(ctx, "Routed %d default-COC values to destination COC %s", remapped, destCOC)
+	}
+
+	return dataValues
+}
+
+// fetchDefaultCOCID returns the ID of the "default" category option combo on a server
+func (s *Service) fetchDefaultCOCID(client *api.Client) (string, error) {
+	resp, err := client.Get("api/categoryOptionCombos", map[string]string{
+		"filter": "name:eq:default",
+		"fields": "id",
+		"paging": "false",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch default category option combo: %w", err)
+	}
+	if !resp.IsSuccess() {
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode(), resp.String())
+	}
+
+	var result struct {
+		CategoryOptionCombos []struct {
+			ID string `json:"id"`
+		} `json:"categoryOptionCombos"`
+	}
+	if err := api.DecodeJSON(resp, "api/categoryOptionCombos", &result); err != nil {
+		return "", fmt.Errorf("failed to parse category option combos: %w", err)
+	}
+	if len(result.CategoryOptionCombos) == 0 {
+		return "", nil
+	}
+
+	return result.CategoryOptionCombos[0].ID, nil
+}
+
 // applyResolutions applies user-defined resolutions (skip/map) to data values
 func (s *Service) applyResolutions(dataValues []DataValue, resolutions []Resolution) ([]DataValue, int) {
 	if len(resolutions) == 0 {
@@ -1024,7 +1306,7 @@ func (s *Service) importDataValuesChunk(client *api.Client, dataValues []DataVal
 
 	// Parse flat DHIS2 response structure
 	var summary ImportSummary
-	if err := json.Unmarshal(resp.Body(), &summary); err != nil {
+	if err := api.DecodeJSON(resp, "api/dataValueSets", &summary); err != nil {
 		return nil, fmt.Errorf("failed to parse import response: %w", err)
 	}
 
@@ -1034,7 +1316,9 @@ func (s *Service) importDataValuesChunk(client *api.Client, dataValues []DataVal
 // importDataValuesBulk sends bulk data values to DHIS2 using Format 2 (recommended)
 // This method is 300x-900x faster than Format 1 for large datasets (hundreds of org units)
 // Implements chunking and concurrent requests for optimal performance
-func (s *Service) importDataValuesBulk(client *api.Client, allDataValues []DataValue, chunkSize int, onProgress func(progress float64, message string)) ([]*ImportSummary, error) {
+// chunkDelay, when positive, is waited out between consecutive chunk requests; cancelling
+// ctx cuts the wait short and skips the chunks not yet sent.
+func (s *Service) importDataValuesBulk(ctx context.Context, client *api.Client, allDataValues []DataValue, chunkSize int, chunkDelay time.Duration, onProgress func(progress float64, message string)) ([]*ImportSummary, error) {
 	if len(allDataValues) == 0 {
 		return nil, fmt.Errorf("no data values to import")
 	}
@@ -1058,6 +1342,7 @@ func (s *Service) importDataValuesBulk(client *api.Client, allDataValues []DataV
 	sem := make(chan struct{}, maxConcurrent)
 	var wg sync.WaitGroup
 	errChan := make(chan error, numChunks)
+	var sent atomic.Int64
 
 	for chunkIdx := 0; chunkIdx < numChunks; chunkIdx++ {
 		start := chunkIdx * chunkSize
@@ -1076,6 +1361,18 @@ func (s *Service) importDataValuesBulk(client *api.Client, allDataValues []DataV
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
+			// Throttle every request after the first
+			if sent.Add(1) > 1 {
+				if err := waitChunkDelay(ctx, chunkDelay); err != nil {
+					errChan <- fmt.Errorf("chunk %d skipped: %w", chunkNum+1, err)
+					return
+				}
+			}
+			if err := ctx.Err(); err != nil {
+				errChan <- fmt.Errorf("chunk %d skipped: %w", chunkNum+1, err)
+				return
+			}
+
 			// Build bulk payload (Format 2)
 			payload := BulkDataValueSetPayload{
 				DataValues: chunkData,
@@ -1097,7 +1394,7 @@ func (s *Service) importDataValuesBulk(client *api.Client, allDataValues []DataV
 
 			// Parse response
 			var summary ImportSummary
-			if err := json.Unmarshal(resp.Body(), &summary); err != nil {
+			if err := api.DecodeJSON(resp, "api/dataValueSets", &summary); err != nil {
 				errChan <- fmt.Errorf("chunk %d parse failed: %w", chunkNum+1, err)
 				return
 			}
@@ -1142,20 +1439,26 @@ func (s *Service) importDataValuesBulk(client *api.Client, allDataValues []DataV
 // This is THE RECOMMENDED approach for large imports (>1000 values)
 // Uses async=true parameter to avoid connection timeouts during server processing
 // Returns after ALL async jobs complete successfully
-func (s *Service) importDataValuesBulkAsync(client *api.Client, allDataValues []DataValue, chunkSize int, onProgress func(progress float64, message string)) ([]*ImportSummary, error) {
+// jobRef, when non-nil, persists each submitted job so polling can be resumed after a restart.
+// chunkDelay, when positive, is waited out between consecutive job submissions.
+// importStrategy is passed to DHIS2 when set (e.g. DELETE); empty keeps its default.
+func (s *Service) importDataValuesBulkAsync(ctx context.Context, client *api.Client, allDataValues []DataValue, chunkSize, maxConcurrent int, chunkDelay time.Duration, importStrategy string, jobRef *asyncJobRef, onProgress func(progress float64, message string)) ([]*ImportSummary, error) {
 	if len(allDataValues) == 0 {
 		return nil, fmt.Errorf("no data values to import")
 	}
 
 	if chunkSize <= 0 {
-		chunkSize = 1000 // Default: 1,000 values (optimal for DHIS2 async)
+		chunkSize = defaultImportChunkSize
+	}
+	if maxConcurrent <= 0 {
+		maxConcurrent = defaultMaxConcurrentJobs
 	}
 
 	// Calculate chunks
 	totalValues := len(allDataValues)
 	numChunks := (totalValues + chunkSize - 1) / chunkSize
 
-	log.Printf("Async bulk import: %d total values, %d chunks of ~%d values each", totalValues, numChunks, chunkSize)
+	logf(ctx, "Async bulk import: %d total values, %d chunks of ~%d values each", totalValues, numChunks, chunkSize)
 	if onProgress != nil {
 		onProgress(0.0, fmt.Sprintf("Submitting %d async import jobs to DHIS2...", numChunks))
 	}
@@ -1163,6 +1466,7 @@ func (s *Service) importDataValuesBulkAsync(client *api.Client, allDataValues []
 	// Submit all chunks as async jobs (returns immediately)
 	type asyncJob struct {
 		JobID     string
+		JobType   string
 		ChunkNum  int
 		NumValues int
 	}
@@ -1170,6 +1474,8 @@ func (s *Service) importDataValuesBulkAsync(client *api.Client, allDataValues []
 	submittedJobs := []asyncJob{}
 	submissionErrors := []error{}
 
+	endpoint := withImportStrategy("api/dataValueSets?async=true&preheatCache=true", importStrategy)
+
 	for chunkIdx := 0; chunkIdx < numChunks; chunkIdx++ {
 		start := chunkIdx * chunkSize
 		end := start + chunkSize
@@ -1178,19 +1484,29 @@ func (s *Service) importDataValuesBulkAsync(client *api.Client, allDataValues []
 		}
 
 		chunk := allDataValues[start:end]
+		if importStrategy == ImportStrategyDelete {
+			chunk = markDeleted(chunk)
+		}
+
+		if chunkIdx > 0 {
+			if err := waitChunkDelay(ctx, chunkDelay); err != nil {
+				submissionErrors = append(submissionErrors, fmt.Errorf("chunk %d not submitted: %w", chunkIdx+1, err))
+				break
+			}
+		}
 
 		// Build bulk payload (Format 2)
 		payload := BulkDataValueSetPayload{
 			DataValues: chunk,
 		}
 
-		log.Printf("Submitting async job %d/%d (%d values)...", chunkIdx+1, numChunks, len(chunk))
+		logf(ctx, "Submitting async job %d/%d (%d values)...", chunkIdx+1, numChunks, len(chunk))
 
 		// POST with async=true and preheatCache=true (with retry logic)
 		var resp []byte
 
-		retryErr := retryWithBackoff("async_submit", func() error {
-			r, e := client.Post("api/dataValueSets?async=true&preheatCache=true", payload)
+		retryErr := retryWithBackoff(taskIDFrom(ctx), func() error {
+			r, e := client.Post(endpoint, payload)
 			if e != nil {
 				return e
 			}
@@ -1218,16 +1534,16 @@ func (s *Service) importDataValuesBulkAsync(client *api.Client, allDataValues []
 		// Parse async job response
 		var jobResp AsyncJobResponse
 		if err := json.Unmarshal(resp, &jobResp); err != nil {
-			log.Printf("[ERROR] Chunk %d/%d: Failed to parse job submission response. Body: %s. Error: %v",
+			logf(ctx, "[ERROR] Chunk %d/%d: Failed to parse job submission response. Body: %s. Error: %v",
 				chunkIdx+1, numChunks, string(resp), err)
 			submissionErrors = append(submissionErrors, fmt.Errorf("chunk %d parse failed: %w", chunkIdx+1, err))
 			continue
 		}
 
-		log.Printf("[DEBUG] Chunk %d/%d: Job submission response: %+v", chunkIdx+1, numChunks, jobResp)
+		logf(ctx, "[DEBUG] Chunk %d/%d: Job submission response: %+v", chunkIdx+1, numChunks, jobResp)
 
 		if jobResp.Response.ID == "" {
-			log.Printf("[ERROR] Chunk %d/%d: No job ID in response. Full response: %s",
+			logf(ctx, "[ERROR] Chunk %d/%d: No job ID in response. Full response: %s",
 				chunkIdx+1, numChunks, string(resp))
 			submissionErrors = append(submissionErrors, fmt.Errorf("chunk %d: no job ID returned", chunkIdx+1))
 			continue
@@ -1235,11 +1551,13 @@ func (s *Service) importDataValuesBulkAsync(client *api.Client, allDataValues []
 
 		submittedJobs = append(submittedJobs, asyncJob{
 			JobID:     jobResp.Response.ID,
+			JobType:   jobResp.Response.JobType,
 			ChunkNum:  chunkIdx + 1,
 			NumValues: len(chunk),
 		})
+		s.recordAsyncJob(jobRef, jobResp.Response.ID, jobResp.Response.JobType, chunkIdx+1, numChunks, len(chunk))
 
-		log.Printf("✓ Async job %d/%d submitted: jobID=%s", chunkIdx+1, numChunks, jobResp.Response.ID)
+		logf(ctx, "✓ Async job %d/%d submitted: jobID=%s", chunkIdx+1, numChunks, jobResp.Response.ID)
 	}
 
 	if len(submissionErrors) > 0 {
@@ -1256,7 +1574,6 @@ func (s *Service) importDataValuesBulkAsync(client *api.Client, allDataValues []
 	completedCount := 0
 	completedMu := sync.Mutex{}
 
-	maxConcurrent := 10 // Poll up to 10 jobs concurrently
 	sem := make(chan struct{}, maxConcurrent)
 	var wg sync.WaitGroup
 	errChan := make(chan error, len(submittedJobs))
@@ -1276,7 +1593,8 @@ func (s *Service) importDataValuesBulkAsync(client *api.Client, allDataValues []
 			}
 
 			// Poll this job until completion (with retry logic)
-			summary, err := s.pollAsyncJobWithRetry(client, j.JobID, j.ChunkNum, numChunks, onProgress)
+			summary, err := s.pollAsyncJobWithRetry(ctx, client, j.JobType, j.JobID, j.ChunkNum, numChunks, onProgress)
+			s.finishAsyncJob(jobRef, j.JobID, summary, err)
 			if err != nil {
 				errChan <- fmt.Errorf("job %d (ID=%s) failed: %w", j.ChunkNum, j.JobID, err)
 				return
@@ -1316,26 +1634,29 @@ func (s *Service) importDataValuesBulkAsync(client *api.Client, allDataValues []
 		return summaries, fmt.Errorf("async import had %d job failures: %v", len(errs), errs[0])
 	}
 
-	log.Printf("✓ All %d async jobs completed successfully", len(submittedJobs))
+	logf(ctx, "✓ All %d async jobs completed successfully", len(submittedJobs))
 	return summaries, nil
 }
 
 // pollAsyncJobWithRetry wraps pollAsyncJob with retry logic for network failures
-func (s *Service) pollAsyncJobWithRetry(client *api.Client, jobID string, chunkNum, totalChunks int, onProgress func(progress float64, message string)) (*ImportSummary, error) {
+func (s *Service) pollAsyncJobWithRetry(ctx context.Context, client *api.Client, jobType, jobID string, chunkNum, totalChunks int, onProgress func(progress float64, message string)) (*ImportSummary, error) {
 	// "Watch Football" mode: retry for a very long time (approx 8 hours if max backoff is 30s)
 	maxRetries := 1000
 	backoff := 2 * time.Second
 	maxBackoff := 30 * time.Second
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		summary, err := s.pollAsyncJob(client, jobID, chunkNum, totalChunks, onProgress)
+		summary, err := s.pollAsyncJob(ctx, client, jobType, jobID, chunkNum, totalChunks, onProgress)
 		if err == nil {
 			return summary, nil
 		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 
 		// Log retry attempt
 		if attempt < maxRetries {
-			log.Printf("Poll attempt %d/%d failed for job %d/%d (ID=%s): %v (retrying in %v...)",
+			logf(ctx, "Poll attempt %d/%d failed for job %d/%d (ID=%s): %v (retrying in %v...)",
 				attempt, maxRetries, chunkNum, totalChunks, jobID, err, backoff)
 
 			// Only update UI every 5th retry to avoid spamming
@@ -1350,7 +1671,9 @@ func (s *Service) pollAsyncJobWithRetry(client *api.Client, jobID string, chunkN
 				// We can just log it.
 			}
 
-			time.Sleep(backoff)
+			if err := waitChunkDelay(ctx, backoff); err != nil {
+				return nil, err
+			}
 
 			// Exponential backoff
 			backoff *= 2
@@ -1366,61 +1689,77 @@ func (s *Service) pollAsyncJobWithRetry(client *api.Client, jobID string, chunkN
 	return nil, fmt.Errorf("unreachable")
 }
 
-// pollAsyncJob polls a single DHIS2 async job until completion or failure
-func (s *Service) pollAsyncJob(client *api.Client, jobID string, chunkNum, totalChunks int, onProgress func(progress float64, message string)) (*ImportSummary, error) {
-	endpoint := fmt.Sprintf("api/system/tasks/DATAVALUE_IMPORT/%s", jobID)
+// defaultAsyncJobType is the task category of data value imports, assumed when DHIS2
+// doesn't report one
+const defaultAsyncJobType = "DATAVALUE_IMPORT"
+
+// asyncJobEndpoint builds the task polling endpoint for a job of the given category
+// (e.g. DATAVALUE_IMPORT, METADATA_IMPORT, TRACKER_IMPORT_JOB)
+func asyncJobEndpoint(jobType, jobID string) string {
+	if jobType == "" {
+		jobType = defaultAsyncJobType
+	}
+	return fmt.Sprintf("api/system/tasks/%s/%s", url.PathEscape(jobType), url.PathEscape(jobID))
+}
+
+// pollAsyncJob polls a single DHIS2 async job until completion or failure.
+// jobType is the category DHIS2 returned on submission; empty means DATAVALUE_IMPORT.
+func (s *Service) pollAsyncJob(ctx context.Context, client *api.Client, jobType, jobID string, chunkNum, totalChunks int, onProgress func(progress float64, message string)) (*ImportSummary, error) {
+	endpoint := asyncJobEndpoint(jobType, jobID)
 	maxAttempts := 300 // 300 × 2s = 10 minutes max per job
 	pollInterval := 2 * time.Second
 
-	log.Printf("Polling job %d/%d (ID=%s)...", chunkNum, totalChunks, jobID)
+	logf(ctx, "Polling job %d/%d (ID=%s)...", chunkNum, totalChunks, jobID)
 
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		resp, err := client.Get(endpoint, nil)
+		resp, err := client.GetWithContext(ctx, endpoint, nil)
 		if err != nil {
-			log.Printf("[DEBUG] Job %d/%d attempt %d: HTTP error: %v", chunkNum, totalChunks, attempt, err)
+			logf(ctx, "[DEBUG] Job %d/%d attempt %d: HTTP error: %v", chunkNum, totalChunks, attempt, err)
 			return nil, fmt.Errorf("polling attempt %d failed: %w", attempt, err)
 		}
 
-		log.Printf("[DEBUG] Job %d/%d attempt %d: HTTP %d, Body length: %d bytes",
+		logf(ctx, "[DEBUG] Job %d/%d attempt %d: HTTP %d, Body length: %d bytes",
 			chunkNum, totalChunks, attempt, resp.StatusCode(), len(resp.Body()))
 
 		if !resp.IsSuccess() {
-			log.Printf("[DEBUG] Job %d/%d: Non-success status. Body: %s", chunkNum, totalChunks, string(resp.Body()))
+			logf(ctx, "[DEBUG] Job %d/%d: Non-success status. Body: %s", chunkNum, totalChunks, string(resp.Body()))
 			return nil, fmt.Errorf("polling returned HTTP %d: %s", resp.StatusCode(), resp.String())
 		}
 
 		// **LOG THE RAW RESPONSE**
 		rawBody := string(resp.Body())
 		if attempt == 1 || attempt%30 == 0 || attempt == maxAttempts {
-			log.Printf("[DEBUG] Job %d/%d attempt %d raw response: %s", chunkNum, totalChunks, attempt, rawBody)
+			logf(ctx, "[DEBUG] Job %d/%d attempt %d raw response: %s", chunkNum, totalChunks, attempt, rawBody)
 		}
 
 		// Parse job status (DHIS2 returns array of status objects)
 		var statuses []JobStatus
 		if err := json.Unmarshal(resp.Body(), &statuses); err != nil {
-			log.Printf("[ERROR] Job %d/%d: JSON parse failed. Raw body: %s. Error: %v",
+			logf(ctx, "[ERROR] Job %d/%d: JSON parse failed. Raw body: %s. Error: %v",
 				chunkNum, totalChunks, rawBody, err)
 			return nil, fmt.Errorf("failed to parse job status: %w", err)
 		}
 
-		log.Printf("[DEBUG] Job %d/%d attempt %d: Parsed %d status objects", chunkNum, totalChunks, attempt, len(statuses))
+		logf(ctx, "[DEBUG] Job %d/%d attempt %d: Parsed %d status objects", chunkNum, totalChunks, attempt, len(statuses))
 
 		if len(statuses) == 0 {
 			if attempt%30 == 0 {
-				log.Printf("[WARN] Job %d/%d: Empty status array after %d attempts (%d seconds)",
+				logf(ctx, "[WARN] Job %d/%d: Empty status array after %d attempts (%d seconds)",
 					chunkNum, totalChunks, attempt, attempt*2)
 			}
-			time.Sleep(pollInterval)
+			if err := waitChunkDelay(ctx, pollInterval); err != nil {
+				return nil, err
+			}
 			continue
 		}
 
 		jobStatus := statuses[0] // Get first (latest) status
-		log.Printf("[DEBUG] Job %d/%d attempt %d: Status - completed=%v, level=%s, message=%s",
+		logf(ctx, "[DEBUG] Job %d/%d attempt %d: Status - completed=%v, level=%s, message=%s",
 			chunkNum, totalChunks, attempt, jobStatus.Completed, jobStatus.Level, jobStatus.Message)
 
 		// Check if completed
 		if jobStatus.Completed {
-			log.Printf("✓ Job %d/%d complete after %d polls: level=%s", chunkNum, totalChunks, attempt, jobStatus.Level)
+			logf(ctx, "✓ Job %d/%d complete after %d polls: level=%s", chunkNum, totalChunks, attempt, jobStatus.Level)
 
 			if jobStatus.Level == "ERROR" {
 				return nil, fmt.Errorf("job failed: %s", jobStatus.Message)
@@ -1456,7 +1795,7 @@ func (s *Service) pollAsyncJob(client *api.Client, jobID string, chunkNum, total
 		// Not complete yet, wait and retry
 		if attempt%15 == 0 { // Log every 30 seconds (15 attempts × 2s)
 			elapsedSeconds := attempt * 2
-			log.Printf("Job %d/%d still running after %d seconds...", chunkNum, totalChunks, elapsedSeconds)
+			logf(ctx, "Job %d/%d still running after %d seconds...", chunkNum, totalChunks, elapsedSeconds)
 			// Update UI to show job is still processing
 			// Update UI to show job is still processing
 			if onProgress != nil {
@@ -1464,7 +1803,9 @@ func (s *Service) pollAsyncJob(client *api.Client, jobID string, chunkNum, total
 			}
 		}
 
-		time.Sleep(pollInterval)
+		if err := waitChunkDelay(ctx, pollInterval); err != nil {
+			return nil, err
+		}
 	}
 
 	return nil, fmt.Errorf("job polling timeout after %d attempts (%d minutes)", maxAttempts, maxAttempts*2/60)
@@ -1497,25 +1838,19 @@ func (s *Service) markDatasetComplete(client *api.Client, datasetID string, peri
 
 // getAPIClient creates an API client for the specified instance (source or destination)
 func (s *Service) getAPIClient(profile *models.ConnectionProfile, sourceOrDest string) (*api.Client, error) {
-	var url, username, encPassword string
+	return api.NewProfileClient(profile, sourceOrDest)
+}
 
-	if sourceOrDest == "source" {
-		url = profile.SourceURL
-		username = profile.SourceUsername
-		encPassword = profile.SourcePasswordEnc
-	} else {
-		url = profile.DestURL
-		username = profile.DestUsername
-		encPassword = profile.DestPasswordEnc
-	}
+// defaultDiscoveryTimeout bounds discovery calls for profiles without their own timeout
+const defaultDiscoveryTimeout = 180 * time.Second
 
-	// Decrypt password
-	password, err := crypto.DecryptPassword(encPassword)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt password: %w", err)
+// discoveryTimeout is the timeout for children=true discovery calls, whose large payloads
+// need longer than most requests; a profile's own request timeout takes precedence
+func discoveryTimeout(profile *models.ConnectionProfile) time.Duration {
+	if profile.RequestTimeoutSeconds > 0 {
+		return time.Duration(profile.RequestTimeoutSeconds) * time.Second
 	}
-
-	return api.NewClient(url, username, password), nil
+	return defaultDiscoveryTimeout
 }
 
 // parseImportConflicts extracts and formats detailed conflict information from import summary
@@ -1598,17 +1933,82 @@ func retryWithBackoff(taskID string, operation func() error, maxAttempts int, ta
 	return fmt.Errorf("failed after %d attempts: %w", maxAttempts, lastErr)
 }
 
+// Bounds for TransferRequest.ChunkSize and MaxConcurrentJobs
+const (
+	defaultImportChunkSize   = 1000 // Values per async import job (optimal for DHIS2 async)
+	minImportChunkSize       = 100
+	maxImportChunkSize       = 5000
+	defaultMaxConcurrentJobs = 10 // Async import jobs polled at once
+	maxMaxConcurrentJobs     = 20
+)
+
+// importChunkSize returns the request's ChunkSize clamped to 100-5000 (default 1000)
+func importChunkSize(req TransferRequest) int {
+	switch {
+	case req.ChunkSize <= 0:
+		return defaultImportChunkSize
+	case req.ChunkSize < minImportChunkSize:
+		return minImportChunkSize
+	case req.ChunkSize > maxImportChunkSize:
+		return maxImportChunkSize
+	}
+	return req.ChunkSize
+}
+
+// maxConcurrentJobs returns the request's MaxConcurrentJobs clamped to 1-20 (default 10)
+func maxConcurrentJobs(req TransferRequest) int {
+	switch {
+	case req.MaxConcurrentJobs <= 0:
+		return defaultMaxConcurrentJobs
+	case req.MaxConcurrentJobs > maxMaxConcurrentJobs:
+		return maxMaxConcurrentJobs
+	}
+	return req.MaxConcurrentJobs
+}
+
+// chunkDelay converts the request's InterChunkDelayMs into a duration (negative is treated as 0)
+func chunkDelay(req TransferRequest) time.Duration {
+	if req.InterChunkDelayMs <= 0 {
+		return 0
+	}
+	return time.Duration(req.InterChunkDelayMs) * time.Millisecond
+}
+
+// waitChunkDelay blocks for d between import chunks or job polls, returning early if ctx is cancelled
+func waitChunkDelay(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // updateProgress updates the progress of a transfer task
 func (s *Service) updateProgress(taskID, status string, progress int, message string) {
 	// Update in-memory store and capture messages array
 	var allMessages []string
+	var previousStatus string
+	known := false
 
 	s.taskMu.Lock()
 	if p, exists := s.taskStore[taskID]; exists {
+		known = true
+		status = nextTaskStatus(p.Status, status)
+		previousStatus = p.Status
 		p.Status = status
 		p.Progress = progress
-		p.Messages = append(p.Messages, message)
+		p.appendMessage(message)
+		p.touchActivity(time.Now())
 		allMessages = p.Messages // Capture full message array
+		if status != previousStatus && isFinished(status) {
+			s.evictFinishedTasks()
+		}
 	}
 	s.taskMu.Unlock()
 
@@ -1616,19 +2016,25 @@ func (s *Service) updateProgress(taskID, status string, progress int, message st
 	db := database.GetDB()
 	var taskProgress models.TaskProgress
 	if err := db.Where("id = ?", taskID).First(&taskProgress).Error; err == nil {
+		// A task evicted from memory is judged by its persisted status
+		if !known {
+			known = true
+			status = nextTaskStatus(taskProgress.Status, status)
+			previousStatus = taskProgress.Status
+		}
 		taskProgress.Status = status
 		taskProgress.Progress = progress
 
-		// Append message
+		// Append message, keeping the persisted log as bounded as the in-memory one
 		messages := s.unmarshalMessages(taskProgress.Messages)
-		messages = append(messages, message)
+		messages = capMessages(append(messages, message))
 		taskProgress.Messages = s.marshalMessages(messages)
 
 		db.Save(&taskProgress)
 	}
 
 	// Emit event to frontend with full message array
-	runtime.EventsEmit(s.ctx, fmt.Sprintf("transfer:%s", taskID), map[string]interface{}{
+	events.EmitProgress(s.ctx, fmt.Sprintf("transfer:%s", taskID), "transfer", map[string]interface{}{
 		"task_id":  taskID,
 		"status":   status,
 		"progress": progress,
@@ -1636,15 +2042,30 @@ func (s *Service) updateProgress(taskID, status string, progress int, message st
 		"messages": allMessages, // Add full message array for scrolling log
 	})
 
+	// Only a real change notifies; TaskFinished keeps the statuses worth a notification
+	if known && status != previousStatus {
+		notifications.TaskFinished(taskID, "transfer", status, message)
+	}
+
 	log.Printf("[%s] %s (%d%%): %s", taskID, status, progress, message)
 }
 
+// nextTaskStatus returns the status a task moves to when an update asks for status.
+// Cancelled is final: a goroutine that was busy can't resurrect or complete the task.
+func nextTaskStatus(current, status string) string {
+	if current == "cancelled" {
+		return current
+	}
+	return status
+}
+
 // updateProgressOnly updates progress percentage and message without changing status
 func (s *Service) updateProgressOnly(taskID string, progress int, message string) {
 	s.taskMu.Lock()
 	if p, exists := s.taskStore[taskID]; exists {
 		p.Progress = progress
-		p.Messages = append(p.Messages, message)
+		p.appendMessage(message)
+		p.touchActivity(time.Now())
 	}
 	s.taskMu.Unlock()
 }
@@ -1706,7 +2127,7 @@ func (s *Service) ListOrganisationUnits(profileID string, sourceOrDest string, l
 		OrgUnits []OrgUnit `json:"organisationUnits"`
 	}
 
-	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+	if err := api.DecodeJSON(resp, "api/organisationUnits.json", &result); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
@@ -1749,17 +2170,70 @@ func (s *Service) GetOrgUnitChildren(profileID string, sourceOrDest string, pare
 		OrgUnits []OrgUnit `json:"organisationUnits"`
 	}
 
-	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+	if err := api.DecodeJSON(resp, "api/organisationUnits.json", &result); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	return result.OrgUnits, nil
 }
 
+const (
+	// orgUnitBatchTimeout bounds a whole GetOrgUnitsByLevelBatch call
+	orgUnitBatchTimeout = 5 * time.Minute
+
+	// orgUnitLevelTimeout bounds a single level fetch, so one slow level can't hold the batch
+	orgUnitLevelTimeout = 3 * time.Minute
+)
+
 // GetOrgUnitsByLevelBatch fetches all org units grouped by level in parallel
 // This is MUCH faster than fetching children per-parent for large hierarchies
 // Instead of 100+ sequential API calls, this makes only ~5 parallel calls (one per level)
-func (s *Service) GetOrgUnitsByLevelBatch(profileID string, sourceOrDest string, maxLevel int) (map[int][]OrgUnit, error) {
+// The whole batch is bounded by orgUnitBatchTimeout; levels that fail are reported in the
+// result's Errors. A non-empty batchID lets CancelOrgUnitBatch abort the batch.
+func (s *Service) GetOrgUnitsByLevelBatch(profileID string, sourceOrDest string, maxLevel int, batchID string) (*OrgUnitBatchResult, error) {
+	parent := s.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, orgUnitBatchTimeout)
+	defer cancel()
+
+	if batchID != "" {
+		s.batchMu.Lock()
+		if s.batchCancels == nil {
+			s.batchCancels = make(map[string]context.CancelFunc)
+		}
+		s.batchCancels[batchID] = cancel
+		s.batchMu.Unlock()
+
+		defer func() {
+			s.batchMu.Lock()
+			delete(s.batchCancels, batchID)
+			s.batchMu.Unlock()
+		}()
+	}
+
+	return s.GetOrgUnitsByLevelBatchContext(ctx, profileID, sourceOrDest, maxLevel)
+}
+
+// CancelOrgUnitBatch aborts a running GetOrgUnitsByLevelBatch; levels already fetched are
+// still returned to its caller
+func (s *Service) CancelOrgUnitBatch(batchID string) error {
+	s.batchMu.Lock()
+	cancel, exists := s.batchCancels[batchID]
+	s.batchMu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("org unit batch not found or already finished: %s", batchID)
+	}
+	cancel()
+	return nil
+}
+
+// GetOrgUnitsByLevelBatchContext is GetOrgUnitsByLevelBatch under a caller-supplied context.
+// Cancelling ctx aborts in-flight level requests; whatever levels completed are returned
+// alongside per-level errors. An error is only returned when no level could be fetched.
+func (s *Service) GetOrgUnitsByLevelBatchContext(ctx context.Context, profileID string, sourceOrDest string, maxLevel int) (*OrgUnitBatchResult, error) {
 	// Get profile from database ONCE
 	db := database.GetDB()
 	var profile models.ConnectionProfile
@@ -1767,34 +2241,60 @@ func (s *Service) GetOrgUnitsByLevelBatch(profileID string, sourceOrDest string,
 		return nil, fmt.Errorf("profile not found: %w", err)
 	}
 
-	// Create API client ONCE (uses the client's default 600s timeout)
+	// Create API client ONCE; per-level timeouts come from the context
 	client, err := s.getAPIClient(&profile, sourceOrDest)
 	if err != nil {
 		return nil, err
 	}
 
+	result := s.fetchOrgUnitLevels(ctx, client, maxLevel, orgUnitLevelTimeout)
+	if len(result.Levels) == 0 && len(result.Errors) > 0 {
+		return nil, fmt.Errorf("failed to fetch any org unit level: %s", result.firstError())
+	}
+
+	return result, nil
+}
+
+// fetchOrgUnitLevels fetches levels 1..maxLevel with a concurrency limit of 3,
+// each level bounded by levelTimeout and all of them by ctx
+func (s *Service) fetchOrgUnitLevels(ctx context.Context, client *api.Client, maxLevel int, levelTimeout time.Duration) *OrgUnitBatchResult {
 	// Default max level if not specified
 	if maxLevel <= 0 {
 		maxLevel = 10 // Most DHIS2 instances have max 6-7 levels
 	}
 
-	// Results map with mutex for concurrent access
-	results := make(map[int][]OrgUnit)
+	// Results with mutex for concurrent access
+	result := &OrgUnitBatchResult{
+		Levels: make(map[int][]OrgUnit),
+		Errors: make(map[int]string),
+	}
 	var mu sync.Mutex
+	recordError := func(lvl int, err error) {
+		log.Printf("[OrgUnitBatch] Level %d error: %v", lvl, err)
+		mu.Lock()
+		result.Errors[lvl] = err.Error()
+		mu.Unlock()
+	}
 
 	log.Printf("[OrgUnitBatch] Fetching org units for levels 1-%d", maxLevel)
 
-	// Fetch levels sequentially but with concurrency limit of 3
 	concurrency := 3
 	semaphore := make(chan struct{}, concurrency)
 	var wg sync.WaitGroup
-	var firstError error
-	var errMu sync.Mutex
 
 	for level := 1; level <= maxLevel; level++ {
-		wg.Add(1)
-		semaphore <- struct{}{} // acquire
+		// Acquire a slot, or give up on the remaining levels once the batch is cancelled
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			for lvl := level; lvl <= maxLevel; lvl++ {
+				recordError(lvl, fmt.Errorf("not fetched: %w", ctx.Err()))
+			}
+			level = maxLevel + 1
+			continue
+		}
 
+		wg.Add(1)
 		go func(lvl int) {
 			defer wg.Done()
 			defer func() { <-semaphore }() // release
@@ -1808,14 +2308,12 @@ func (s *Service) GetOrgUnitsByLevelBatch(profileID string, sourceOrDest string,
 				"order":  "displayName:asc",
 			}
 
-			resp, err := client.Get("api/organisationUnits.json", params)
+			levelCtx, cancel := context.WithTimeout(ctx, levelTimeout)
+			defer cancel()
+
+			resp, err := client.GetWithContext(levelCtx, "api/organisationUnits.json", params)
 			if err != nil {
-				log.Printf("[OrgUnitBatch] Level %d error: %v", lvl, err)
-				errMu.Lock()
-				if firstError == nil {
-					firstError = fmt.Errorf("level %d: %w", lvl, err)
-				}
-				errMu.Unlock()
+				recordError(lvl, err)
 				return
 			}
 
@@ -1830,15 +2328,15 @@ func (s *Service) GetOrgUnitsByLevelBatch(profileID string, sourceOrDest string,
 				OrgUnits []OrgUnit `json:"organisationUnits"`
 			}
 
-			if err := json.Unmarshal(resp.Body(), &r); err != nil {
-				log.Printf("[OrgUnitBatch] Level %d parse error: %v", lvl, err)
+			if err := api.DecodeJSON(resp, "api/organisationUnits.json", &r); err != nil {
+				recordError(lvl, fmt.Errorf("parse error: %w", err))
 				return
 			}
 
 			// Only store non-empty results
 			if len(r.OrgUnits) > 0 {
 				mu.Lock()
-				results[lvl] = r.OrgUnits
+				result.Levels[lvl] = r.OrgUnits
 				mu.Unlock()
 				log.Printf("[OrgUnitBatch] Level %d: fetched %d org units", lvl, len(r.OrgUnits))
 			} else {
@@ -1849,19 +2347,14 @@ func (s *Service) GetOrgUnitsByLevelBatch(profileID string, sourceOrDest string,
 
 	wg.Wait()
 
-	// If we got an error and no results, return the error
-	if firstError != nil && len(results) == 0 {
-		return nil, firstError
-	}
-
 	// Count total
 	total := 0
-	for _, units := range results {
+	for _, units := range result.Levels {
 		total += len(units)
 	}
-	log.Printf("[OrgUnitBatch] Complete: %d org units across %d levels", total, len(results))
+	log.Printf("[OrgUnitBatch] Complete: %d org units across %d levels (%d level errors)", total, len(result.Levels), len(result.Errors))
 
-	return results, nil
+	return result
 }
 
 // GetUserRootOrgUnit fetches the user's root (top-level) organization unit from /api/me
@@ -1897,7 +2390,7 @@ func (s *Service) GetUserRootOrgUnit(profileID string, sourceOrDest string) (*Or
 		OrgUnits []OrgUnit `json:"organisationUnits"`
 	}
 
-	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+	if err := api.DecodeJSON(resp, "api/me.json", &result); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
@@ -1932,71 +2425,128 @@ func (s *Service) DiscoverOrgUnitsWithData(profileID string, sourceOrDest string
 		return nil, err
 	}
 
-	// Discovery calls with children=true can return large payloads (10-100 MB for yearly data)
+	// Discovery calls with children=true can return large payloads (10-100 MB of JSON for yearly data, about half as CSV)
 	// Increase timeout to allow time for large response body download and slow server processing
-	client.SetTimeout(180 * time.Second)
+	client.SetTimeout(discoveryTimeout(&profile))
 
-	// Fetch data values for parent OU and all children
-	params := map[string]string{
+	return s.discoverOrgUnitsWithData(context.Background(), client, datasetID, period, parentOU, nil)
+}
+
+// discoverOrgUnitsWithData performs discovery with an existing client.
+// filter adds query parameters, e.g. a delta sync's lastUpdated (see deltaParams).
+// Names come through the shared org unit name cache, so a multi-period transfer
+// resolves each org unit name once.
+func (s *Service) discoverOrgUnitsWithData(ctx context.Context, client *api.Client, datasetID string, period string, parentOU string, filter map[string]string) (map[string]string, error) {
+	// Fetch data values for parent OU and all children.
+	// dataValueSets has no field selection, so request the CSV export (no repeated keys,
+	// roughly half the size of JSON) and stream it, keeping only the orgunit column.
+	params := map[string]string{
 		"dataSet":  datasetID,
 		"period":   period,
 		"orgUnit":  parentOU,
 		"children": "true",
 		"paging":   "false",
 	}
+	for key, value := range filter {
+		params[key] = value
+	}
 
-	resp, err := client.Get("api/dataValueSets", params)
+	resp, err := client.GetStream(ctx, "api/dataValueSets.csv", params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch data values: %w", err)
 	}
+	defer resp.Body.Close()
 
-	if !resp.IsSuccess() {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		// Treat any non-success response as "no data found" (match FastAPI behavior)
 		// Log the details for debugging
-		log.Printf("[DISCOVERY] HTTP %d for dataset=%s, period=%s, orgUnit=%s: %s",
-			resp.StatusCode(), datasetID, period, parentOU, string(resp.Body()))
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		logf(ctx, "[DISCOVERY] HTTP %d for dataset=%s, period=%s, orgUnit=%s: %s",
+			resp.StatusCode, datasetID, period, parentOU, string(body))
 		return make(map[string]string), nil // Empty map, not an error
 	}
 
-	var result struct {
-		DataValues []struct {
-			OrgUnit string `json:"orgUnit"`
-		} `json:"dataValues"`
+	counter := &countingReader{r: resp.Body}
+	orgUnitIDs, values, err := scanCSVOrgUnits(counter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
+	logf(ctx, "[DISCOVERY] period=%s: %d values across %d org units, %d bytes (CSV)", period, values, len(orgUnitIDs), counter.n)
 
-	if err := json.Unmarshal(resp.Body(), &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	// Fetch names for all discovered org units, batching the ones not already cached
+	ids := make([]string, 0, len(orgUnitIDs))
+	for ouID := range orgUnitIDs {
+		ids = append(ids, ouID)
+	}
+	sort.Strings(ids)
+
+	discoveredOUs, err := fetchOrgUnitNames(client, ids)
+	if err != nil {
+		// Org units without a name are skipped; the ones that were found still transfer
+		logf(ctx, "[DISCOVERY] Failed to fetch some org unit names: %v", err)
 	}
 
-	// Extract unique org unit IDs
+	return discoveredOUs, nil
+}
+
+// countingReader counts bytes read, to report discovery payload sizes
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// scanCSVOrgUnits reads a dataValueSets CSV export and returns the distinct org unit IDs
+// and the number of values. The orgunit column is located from the header row; a body
+// without one (e.g. an HTML login page) is an error.
+func scanCSVOrgUnits(r io.Reader) (map[string]bool, int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	reader.LazyQuotes = true
+
 	orgUnitIDs := make(map[string]bool)
-	for _, dv := range result.DataValues {
-		if dv.OrgUnit != "" {
-			orgUnitIDs[dv.OrgUnit] = true
-		}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return orgUnitIDs, 0, nil // No data
+	}
+	if err != nil {
+		return nil, 0, err
 	}
 
-	// Fetch names for all discovered org units
-	discoveredOUs := make(map[string]string)
-	for ouID := range orgUnitIDs {
-		// Fetch org unit details to get name
-		ouResp, err := client.Get(fmt.Sprintf("api/organisationUnits/%s.json", ouID), map[string]string{
-			"fields": "id,name,displayName",
-		})
+	column := -1
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")), "orgunit") {
+			column = i
+			break
+		}
+	}
+	if column < 0 {
+		return nil, 0, fmt.Errorf("unexpected dataValueSets CSV: no orgunit column in header %q", api.BodySnippet([]byte(strings.Join(header, ",")), 200))
+	}
 
-		if err == nil && ouResp.IsSuccess() {
-			var ouData OrgUnit
-			if err := json.Unmarshal(ouResp.Body(), &ouData); err == nil {
-				name := ouData.DisplayName
-				if name == "" {
-					name = ouData.Name
-				}
-				discoveredOUs[ouID] = name
-			}
+	values := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		values++
+		if column < len(record) && record[column] != "" {
+			orgUnitIDs[record[column]] = true
 		}
 	}
 
-	return discoveredOUs, nil
+	return orgUnitIDs, values, nil
 }
 
 // FindMatchingOrgUnit finds a matching org unit in the destination based on source org unit
@@ -2015,6 +2565,11 @@ func (s *Service) FindMatchingOrgUnit(profileID string, sourceOrgUnitID string,
 		return "", err
 	}
 
+	return s.findMatchingOrgUnit(client, sourceOrgUnitID, sourceOrgUnitName)
+}
+
+// findMatchingOrgUnit looks up the destination org unit for a source org unit using the given client
+func (s *Service) findMatchingOrgUnit(client *api.Client, sourceOrgUnitID string, sourceOrgUnitName string) (string, error) {
 	// Try exact ID match first (most common case for same-instance transfers)
 	params := map[string]string{
 		"filter": fmt.Sprintf("id:eq:%s", sourceOrgUnitID),
@@ -2054,7 +2609,7 @@ func (s *Service) FindMatchingOrgUnit(profileID string, sourceOrgUnitID string,
 		OrgUnits []OrgUnit `json:"organisationUnits"`
 	}
 
-	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+	if err := api.DecodeJSON(resp, "api/organisationUnits.json", &result); err != nil {
 		return "", fmt.Errorf("failed to parse response: %w", err)
 	}
 
@@ -2069,6 +2624,85 @@ func (s *Service) FindMatchingOrgUnit(profileID string, sourceOrgUnitID string,
 	return "", fmt.Errorf("no matching org unit found for: %s", sourceOrgUnitName)
 }
 
+// recordUnmatchedOrgUnit keeps the details of a source org unit that has no destination match,
+// fetching its code and path once per task
+func (s *Service) recordUnmatchedOrgUnit(taskID string, sourceClient *api.Client, ouID, ouName, period string) {
+	s.taskMu.Lock()
+	progress, exists := s.taskStore[taskID]
+	if !exists {
+		s.taskMu.Unlock()
+		return
+	}
+	for i := range progress.UnmatchedOUs {
+		if progress.UnmatchedOUs[i].ID == ouID {
+			progress.UnmatchedOUs[i].Periods = append(progress.UnmatchedOUs[i].Periods, period)
+			s.taskMu.Unlock()
+			return
+		}
+	}
+	s.taskMu.Unlock()
+
+	unmatched := UnmatchedOrgUnit{ID: ouID, Name: ouName, Periods: []string{period}}
+
+	resp, err := sourceClient.Get(fmt.Sprintf("api/organisationUnits/%s.json", ouID), map[string]string{
+		"fields": "id,name,code,path",
+	})
+	if err == nil && resp.IsSuccess() {
+		var ou OrganisationUnit
+		if err := json.Unmarshal(resp.Body(), &ou); err == nil {
+			unmatched.Code = ou.Code
+			unmatched.Path = ou.Path
+		}
+	} else {
+		log.Printf("[%s] Failed to fetch details for unmatched org unit %s: %v", taskID, ouID, err)
+	}
+
+	s.taskMu.Lock()
+	if progress, exists := s.taskStore[taskID]; exists {
+		progress.UnmatchedOUs = append(progress.UnmatchedOUs, unmatched)
+	}
+	s.taskMu.Unlock()
+}
+
+// ExportUnmatchedOrgUnits exports the source org units that could not be matched in the
+// destination as CSV or JSON, as a worklist for creating or mapping them
+func (s *Service) ExportUnmatchedOrgUnits(taskID, format string) (string, error) {
+	s.taskMu.RLock()
+	progress, exists := s.taskStore[taskID]
+	var unmatched []UnmatchedOrgUnit
+	if exists {
+		unmatched = append(unmatched, progress.UnmatchedOUs...)
+	}
+	s.taskMu.RUnlock()
+
+	if !exists {
+		return "", fmt.Errorf("task not found: %s", taskID)
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(unmatched, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return string(data), nil
+	}
+
+	if format == "csv" {
+		var buf strings.Builder
+		writer := csv.NewWriter(&buf)
+
+		writer.Write([]string{"sourceOrgUnitId", "name", "code", "path", "periods"})
+		for _, ou := range unmatched {
+			writer.Write([]string{ou.ID, ou.Name, ou.Code, ou.Path, strings.Join(ou.Periods, ";")})
+		}
+
+		writer.Flush()
+		return buf.String(), writer.Error()
+	}
+
+	return "", fmt.Errorf("unsupported format: %s", format)
+}
+
 // SkipUnmappedAndComplete marks the transfer as complete, skipping unmapped values
 func (s *Service) SkipUnmappedAndComplete(taskID string) error {
 	s.taskMu.Lock()
@@ -2087,8 +2721,7 @@ func (s *Service) SkipUnmappedAndComplete(taskID string) error {
 	progress.UnmappedValues = nil
 	progress.Status = "completed"
 	progress.Progress = 100
-	progress.Messages = append(progress.Messages, "✓ User chose to skip unmapped values")
-	progress.Messages = append(progress.Messages, "🎉 Transfer complete!")
+	progress.appendMessage("✓ User chose to skip unmapped values", "🎉 Transfer complete!")
 
 	now := time.Now().Format(time.RFC3339)
 	progress.CompletedAt = now
@@ -2105,49 +2738,71 @@ func (s *Service) SkipUnmappedAndComplete(taskID string) error {
 		db.Save(&taskProgress)
 	}
 
+	notifications.TaskFinished(taskID, "transfer", "completed", "Transfer complete (unmapped values skipped)")
+
 	log.Printf("[%s] Transfer completed (user skipped unmapped values)", taskID)
 	return nil
 }
 
-// CancelTransfer cancels the entire transfer operation
+// CancelTransfer stops a transfer that is starting, running, stalled or awaiting a
+// decision on unmapped values. Async import jobs already submitted can't be recalled;
+// they are left running server-side (ResumeAsyncPolling can collect their results).
 func (s *Service) CancelTransfer(taskID string) error {
 	s.taskMu.Lock()
-	defer s.taskMu.Unlock()
-
 	progress, exists := s.taskStore[taskID]
 	if !exists {
+		s.taskMu.Unlock()
 		return fmt.Errorf("task not found: %s", taskID)
 	}
 
-	if progress.Status != "awaiting_user_decision" {
-		return fmt.Errorf("task is not awaiting user decision (current status: %s)", progress.Status)
+	switch progress.Status {
+	case "starting", "running", "stalled", "awaiting_user_decision":
+	default:
+		s.taskMu.Unlock()
+		return fmt.Errorf("task cannot be cancelled (current status: %s)", progress.Status)
 	}
 
-	// Mark as cancelled
+	// Mark as cancelled before stopping the work, so its failing calls don't report an error
 	progress.Status = "cancelled"
 	progress.Error = "Transfer cancelled by user"
-	progress.Messages = append(progress.Messages, "✗ Transfer cancelled by user")
+	progress.CompletedAt = time.Now().Format(time.RFC3339)
+	if progress.cancel != nil {
+		progress.cancel()
+	}
+	s.taskMu.Unlock()
 
-	now := time.Now().Format(time.RFC3339)
-	progress.CompletedAt = now
+	message := "✗ Transfer cancelled by user"
+	db := database.GetDB()
+	var pending int64
+	if db != nil {
+		db.Model(&models.AsyncImportJob{}).Where("task_id = ? AND status = ?", taskID, "pending").Count(&pending)
+	}
+	if pending > 0 {
+		message += fmt.Sprintf("; %d submitted import job(s) left running server-side", pending)
+	}
+
+	s.taskMu.Lock()
+	progress.appendMessage(message)
+	s.taskMu.Unlock()
 
 	// Update database
-	db := database.GetDB()
 	var taskProgress models.TaskProgress
-	if err := db.Where("id = ?", taskID).First(&taskProgress).Error; err == nil {
+	if db != nil && db.Where("id = ?", taskID).First(&taskProgress).Error == nil {
 		taskProgress.Status = "cancelled"
 		taskProgress.Progress = progress.Progress
 		messages := s.unmarshalMessages(taskProgress.Messages)
-		messages = append(messages, "Transfer cancelled by user")
+		messages = append(messages, message)
 		taskProgress.Messages = s.marshalMessages(messages)
 		db.Save(&taskProgress)
 	}
 
-	log.Printf("[%s] Transfer cancelled by user", taskID)
+	log.Printf("[%s] %s", taskID, message)
 	return nil
 }
 
-// RetryWithNewMappings applies new mappings and retries import for previously unmapped values
+// RetryWithNewMappings applies new element mappings to the values a transfer parked as
+// unmapped and imports them into the destination in the background. Values whose
+// elements are still unmapped stay parked for another decision.
 func (s *Service) RetryWithNewMappings(taskID string, newMappings map[string]string) error {
 	s.taskMu.Lock()
 	progress, exists := s.taskStore[taskID]
@@ -2166,18 +2821,400 @@ func (s *Service) RetryWithNewMappings(taskID string, newMappings map[string]str
 		return fmt.Errorf("no unmapped values to retry")
 	}
 
-	// Get unmapped values before releasing lock
-	unmappedValuesCopy := make(map[string][]DataValue)
-	for key, values := range progress.UnmappedValues {
-		unmappedValuesCopy[key] = values
+	if progress.request == nil {
+		s.taskMu.Unlock()
+		return fmt.Errorf("original transfer request is not available for task %s; start a new transfer with the updated mappings", taskID)
+	}
+
+	mapped, remaining := splitByNewMappings(progress.UnmappedValues, newMappings)
+	if len(mapped) == 0 {
+		s.taskMu.Unlock()
+		return fmt.Errorf("none of the new mappings cover the unmapped data elements")
+	}
+
+	// Claim the task before unlocking so a second retry or skip can't run alongside this one
+	progress.Status = "running"
+	req := *progress.request
+	s.taskMu.Unlock()
+
+	// The value transforms find destination elements through the mapping
+	mapping := make(map[string]string, len(req.ElementMapping)+len(newMappings))
+	for srcID, destID := range req.ElementMapping {
+		mapping[srcID] = destID
+	}
+	for srcID, destID := range newMappings {
+		if destID != "" {
+			mapping[srcID] = destID
+		}
+	}
+	req.ElementMapping = mapping
+
+	s.updateProgress(taskID, "running", 95, fmt.Sprintf("Retrying import of %d values with new mappings...", len(mapped)))
+
+	go s.importRemappedValues(taskID, req, mapped, remaining)
+
+	return nil
+}
+
+// splitByNewMappings maps parked unmapped values with newMappings, returning the values
+// that now map (with destination element IDs) and those still unmapped, keyed as before
+func splitByNewMappings(unmapped map[string][]DataValue, newMappings map[string]string) ([]DataValue, map[string][]DataValue) {
+	mapped := []DataValue{}
+	var remaining map[string][]DataValue
+
+	for key, values := range unmapped {
+		for _, dv := range values {
+			if destElement, ok := newMappings[dv.DataElement]; ok && destElement != "" {
+				dv.DataElement = destElement
+				mapped = append(mapped, dv)
+				continue
+			}
+			if remaining == nil {
+				remaining = make(map[string][]DataValue)
+			}
+			remaining[key] = append(remaining[key], dv)
+		}
+	}
+
+	return mapped, remaining
+}
+
+// importRemappedValues runs values mapped by RetryWithNewMappings through the rest of the
+// transfer pipeline, imports them, marks their registrations complete when requested,
+// adds the counts to the transfer's import summary and completes the task, or parks it
+// again with the remaining values when some are still unmapped. A failed import leaves
+// the parked values untouched so the user can retry or skip.
+func (s *Service) importRemappedValues(taskID string, req TransferRequest, values []DataValue, remaining map[string][]DataValue) {
+	ctx := withTaskID(context.Background(), taskID)
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.updateProgress(taskID, "awaiting_user_decision", 95, fmt.Sprintf("⚠ Panic during retry with new mappings: %v", r))
+			logf(ctx, "Retry panic recovered: %v", r)
+		}
+	}()
+
+	db := database.GetDB()
+	var profile models.ConnectionProfile
+	if err := db.Where("id = ?", req.ProfileID).First(&profile).Error; err != nil {
+		s.updateProgress(taskID, "awaiting_user_decision", 95, fmt.Sprintf("⚠ Failed to load profile: %v", err))
+		return
+	}
+
+	sourceClient, err := s.getAPIClient(&profile, "source")
+	if err != nil {
+		s.updateProgress(taskID, "awaiting_user_decision", 95, fmt.Sprintf("⚠ Failed to create source client: %v", err))
+		return
+	}
+	destClient, err := s.getAPIClient(&profile, "destination")
+	if err != nil {
+		s.updateProgress(taskID, "awaiting_user_decision", 95, fmt.Sprintf("⚠ Failed to create destination client: %v", err))
+		return
+	}
+
+	pipeline, err := s.newValuePipeline(ctx, taskID, req, sourceClient, destClient)
+	if err != nil {
+		s.updateProgress(taskID, "awaiting_user_decision", 95, fmt.Sprintf("⚠ Invalid periods: %v", err))
+		return
+	}
+	for _, warning := range pipeline.warnings {
+		s.updateProgressOnly(taskID, 95, warning)
+	}
+	values = pipeline.resolveValues(ctx, values, "remapped values")
+
+	var retried ImportCount
+	if len(values) > 0 {
+		jobRef := &asyncJobRef{TaskID: taskID, ProfileID: req.ProfileID}
+		onProgress := func(p float64, msg string) {
+			s.updateProgressOnly(taskID, 95, msg)
+		}
+
+		summaries, err := s.importDataValuesBulkAsync(ctx, destClient, values, importChunkSize(req), maxConcurrentJobs(req), chunkDelay(req), req.ImportStrategy, jobRef, onProgress)
+		if err != nil {
+			s.updateProgress(taskID, "awaiting_user_decision", 95, fmt.Sprintf("⚠ Import with new mappings failed: %v", err))
+			return
+		}
+		for _, summary := range summaries {
+			retried.Imported += summary.ImportCount.Imported
+			retried.Updated += summary.ImportCount.Updated
+			retried.Ignored += summary.ImportCount.Ignored
+			retried.Deleted += summary.ImportCount.Deleted
+		}
+
+		if req.MarkComplete {
+			s.markComplete(ctx, taskID, destClient, req, completionKeys(values), 95)
+		}
+	}
+	pipeline.recordCOCMatches()
+
+	stillUnmapped := 0
+	for _, vals := range remaining {
+		stillUnmapped += len(vals)
+	}
+
+	s.taskMu.Lock()
+	var summary ImportSummary
+	if progress, exists := s.taskStore[taskID]; exists {
+		progress.UnmappedValues = remaining
+		progress.TotalMapped += len(values)
+		progress.TotalImported += retried.Imported + retried.Updated
+		if progress.ImportSummary == nil {
+			progress.ImportSummary = &ImportSummary{Status: "SUCCESS"}
+		}
+		progress.ImportSummary.ImportCount.Imported += retried.Imported
+		progress.ImportSummary.ImportCount.Updated += retried.Updated
+		progress.ImportSummary.ImportCount.Ignored += retried.Ignored
+		progress.ImportSummary.ImportCount.Deleted += retried.Deleted
+		progress.ImportSummary.Description += fmt.Sprintf(", Retried with new mappings: Imported=%d, Updated=%d, Already exist=%d",
+			retried.Imported, retried.Updated, retried.Ignored)
+		if stillUnmapped == 0 {
+			progress.request = nil
+			progress.CompletedAt = time.Now().Format(time.RFC3339)
+		}
+		summary = *progress.ImportSummary
 	}
 	s.taskMu.Unlock()
 
-	// Update status to running
-	s.updateProgress(taskID, "running", 95, "Retrying import with new mappings...")
+	if data, err := json.Marshal(summary); err == nil {
+		var taskProgress models.TaskProgress
+		if err := db.Where("id = ?", taskID).First(&taskProgress).Error; err == nil {
+			taskProgress.Results = string(data)
+			db.Save(&taskProgress)
+		}
+	}
+
+	msg := fmt.Sprintf("✓ Imported with new mappings: %d new, %d updated, %d already exist", retried.Imported, retried.Updated, retried.Ignored)
+	if stillUnmapped > 0 {
+		s.updateProgress(taskID, "awaiting_user_decision", 95, msg)
+		s.updateProgress(taskID, "awaiting_user_decision", 95,
+			fmt.Sprintf("⚠️ %d values are still unmapped: create more mappings, skip them, or cancel", stillUnmapped))
+		return
+	}
+
+	s.updateProgress(taskID, "completed", 100, msg)
+	s.updateProgress(taskID, "completed", 100, "🎉 Transfer complete!")
+}
+
+// Follow-up modes for TransferRequest.FollowUpMode
+const (
+	FollowUpAll     = "all"
+	FollowUpOnly    = "only"
+	FollowUpExclude = "exclude"
+)
+
+// filterFollowUp keeps values by their follow-up flag: "only" keeps flagged (suspect)
+// values, "exclude" drops them, anything else keeps all. Returns the kept values and
+// how many were dropped.
+func filterFollowUp(values []DataValue, mode string) ([]DataValue, int) {
+	if mode != FollowUpOnly && mode != FollowUpExclude {
+		return values, 0
+	}
+
+	kept := make([]DataValue, 0, len(values))
+	for _, dv := range values {
+		if dv.FollowUp == (mode == FollowUpOnly) {
+			kept = append(kept, dv)
+		}
+	}
+
+	return kept, len(values) - len(kept)
+}
+
+// filterCategoryOptionCombos keeps values recorded under one of the given source COCs.
+// Returns the kept values and how many were dropped.
+func filterCategoryOptionCombos(values []DataValue, cocIDs []string) ([]DataValue, int) {
+	if len(cocIDs) == 0 {
+		return values, 0
+	}
+
+	selected := make(map[string]bool, len(cocIDs))
+	for _, id := range cocIDs {
+		selected[id] = true
+	}
+
+	kept := make([]DataValue, 0, len(values))
+	for _, dv := range values {
+		if selected[dv.CategoryOptionCombo] {
+			kept = append(kept, dv)
+		}
+	}
+
+	return kept, len(values) - len(kept)
+}
+
+// recordComboCounts adds the values selected per source COC to the task's totals
+func (s *Service) recordComboCounts(taskID string, values []DataValue) {
+	s.taskMu.Lock()
+	defer s.taskMu.Unlock()
+
+	progress, exists := s.taskStore[taskID]
+	if !exists {
+		return
+	}
+	if progress.ComboCounts == nil {
+		progress.ComboCounts = make(map[string]int)
+	}
+	for _, dv := range values {
+		progress.ComboCounts[dv.CategoryOptionCombo]++
+	}
+}
+
+// comboCountsSummary formats the selected values per requested COC, e.g. "abc=12, def=0"
+func (s *Service) comboCountsSummary(taskID string, cocIDs []string) string {
+	s.taskMu.Lock()
+	defer s.taskMu.Unlock()
+
+	var counts map[string]int
+	if progress, exists := s.taskStore[taskID]; exists {
+		counts = progress.ComboCounts
+	}
+
+	parts := make([]string, 0, len(cocIDs))
+	for _, id := range cocIDs {
+		parts = append(parts, fmt.Sprintf("%s=%d", id, counts[id]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// dedupeDataValues keeps one value per data element, period, org unit, COC and AOC.
+// Exports can repeat a key with conflicting values, and DHIS2 keeps whichever row it
+// happens to process last. The most recently updated row wins; ties go to the greater
+// value so the outcome never depends on row order. Returns the kept values in their
+// original order and how many were dropped.
+// api/dataValueSets always includes lastUpdated (it has no fields parameter), so it's
+// available here without asking for it.
+func dedupeDataValues(values []DataValue) ([]DataValue, int) {
+	index := make(map[string]int, len(values))
+	kept := make([]DataValue, 0, len(values))
+
+	for _, dv := range values {
+		key := dv.DataElement + "|" + dv.Period + "|" + dv.OrgUnit + "|" + dv.CategoryOptionCombo + "|" + dv.AttributeOptionCombo
+		i, seen := index[key]
+		if !seen {
+			index[key] = len(kept)
+			kept = append(kept, dv)
+			continue
+		}
+
+		current := kept[i]
+		order := compareLastUpdated(dv.LastUpdated, current.LastUpdated)
+		if order > 0 || (order == 0 && dv.Value > current.Value) {
+			kept[i] = dv
+		}
+	}
+
+	return kept, len(values) - len(kept)
+}
+
+// lastUpdatedLayouts are the lastUpdated formats DHIS2 versions emit
+var lastUpdatedLayouts = []string{
+	"2006-01-02T15:04:05.000-0700",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000",
+	"2006-01-02T15:04:05",
+}
+
+// compareLastUpdated orders two lastUpdated timestamps (-1, 0, 1), treating a missing
+// one as oldest. Unparseable values fall back to comparing the strings.
+func compareLastUpdated(a, b string) int {
+	if a == b {
+		return 0
+	}
+	if a == "" {
+		return -1
+	}
+	if b == "" {
+		return 1
+	}
+
+	ta, errA := parseLastUpdated(a)
+	tb, errB := parseLastUpdated(b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	return ta.Compare(tb)
+}
+
+// parseLastUpdated parses a DHIS2 lastUpdated timestamp in any of lastUpdatedLayouts
+func parseLastUpdated(value string) (time.Time, error) {
+	var err error
+	for _, layout := range lastUpdatedLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// fetchExistingValues retrieves the destination's current values for one org unit and period
+func (s *Service) fetchExistingValues(client *api.Client, datasetID, period, orgUnit string) ([]DataValue, error) {
+	resp, err := client.Get("api/dataValueSets", map[string]string{
+		"dataSet":  datasetID,
+		"period":   period,
+		"orgUnit":  orgUnit,
+		"children": "false",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch destination values: %w", err)
+	}
+	if !resp.IsSuccess() {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), resp.String())
+	}
+
+	var payload DataValueSet
+	if err := api.DecodeJSON(resp, "api/dataValueSets", &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse destination values: %w", err)
+	}
+
+	return payload.DataValues, nil
+}
+
+// filterUnchanged drops values identical to an existing destination value for the same
+// data element, COC and AOC. A blank AOC matches the destination value of any AOC
+// (the destination reports its default explicitly). Returns the values still to send
+// and how many were skipped.
+func filterUnchanged(values, existing []DataValue) ([]DataValue, int) {
+	if len(existing) == 0 {
+		return values, 0
+	}
+
+	byFullKey := make(map[string]string, len(existing))
+	byElementCOC := make(map[string]string, len(existing))
+	for _, dv := range existing {
+		byFullKey[dv.DataElement+"|"+dv.CategoryOptionCombo+"|"+dv.AttributeOptionCombo] = dv.Value
+		byElementCOC[dv.DataElement+"|"+dv.CategoryOptionCombo] = dv.Value
+	}
+
+	changed := make([]DataValue, 0, len(values))
+	unchanged := 0
+	for _, dv := range values {
+		var current string
+		var found bool
+		if dv.AttributeOptionCombo == "" {
+			current, found = byElementCOC[dv.DataElement+"|"+dv.CategoryOptionCombo]
+		} else {
+			current, found = byFullKey[dv.DataElement+"|"+dv.CategoryOptionCombo+"|"+dv.AttributeOptionCombo]
+		}
+
+		if found && sameValue(dv.Value, current) {
+			unchanged++
+			continue
+		}
+		changed = append(changed, dv)
+	}
+
+	return changed, unchanged
+}
+
+// sameValue compares two DHIS2 values, treating numerically equal strings ("5" and "5.0") as equal
+func sameValue(a, b string) bool {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	if a == b {
+		return true
+	}
 
-	// Get profile and create API client
-	// Note: We need to extract profileID, datasetID, etc from the original request
-	// For now, return error - this needs more context from the original transfer
-	return fmt.Errorf("retry with new mappings not yet fully implemented - requires storing original transfer request")
+	af, errA := strconv.ParseFloat(a, 64)
+	bf, errB := strconv.ParseFloat(b, 64)
+	return errA == nil && errB == nil && af == bf
 }
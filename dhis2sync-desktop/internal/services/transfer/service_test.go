@@ -1,7 +1,12 @@
 package transfer
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -24,7 +29,7 @@ func TestApplyMapping(t *testing.T) {
 			"def456": "xyz222",
 		}
 
-		mapped, unmapped := service.applyMapping(dataValues, mapping)
+		mapped, unmapped := service.applyMapping(context.Background(), dataValues, mapping)
 
 		assert.Len(t, mapped, 2, "Should map 2 elements")
 		assert.Equal(t, "xyz111", mapped[0].DataElement)
@@ -46,7 +51,7 @@ func TestApplyMapping(t *testing.T) {
 		}
 
 		mapping := map[string]string{"abc123": "xyz111"}
-		mapped, _ := service.applyMapping(dataValues, mapping)
+		mapped, _ := service.applyMapping(context.Background(), dataValues, mapping)
 
 		require.Len(t, mapped, 1)
 		assert.Equal(t, "xyz111", mapped[0].DataElement)
@@ -65,7 +70,7 @@ func TestApplyMapping(t *testing.T) {
 		// Empty mapping
 		mapping := map[string]string{}
 
-		mapped, unmapped := service.applyMapping(dataValues, mapping)
+		mapped, unmapped := service.applyMapping(context.Background(), dataValues, mapping)
 
 		assert.Len(t, mapped, 2, "All values should be mapped when no mapping provided")
 		assert.Len(t, unmapped, 0, "No values should be unmapped")
@@ -75,7 +80,7 @@ func TestApplyMapping(t *testing.T) {
 		dataValues := []DataValue{}
 		mapping := map[string]string{"abc": "xyz"}
 
-		mapped, unmapped := service.applyMapping(dataValues, mapping)
+		mapped, unmapped := service.applyMapping(context.Background(), dataValues, mapping)
 
 		assert.Len(t, mapped, 0)
 		assert.Len(t, unmapped, 0)
@@ -93,7 +98,7 @@ func TestApplyMapping(t *testing.T) {
 			"xyz222": "dest2",
 		}
 
-		mapped, unmapped := service.applyMapping(dataValues, mapping)
+		mapped, unmapped := service.applyMapping(context.Background(), dataValues, mapping)
 
 		assert.Len(t, mapped, 0, "No values should be mapped")
 		assert.Len(t, unmapped, 2, "All values should be unmapped")
@@ -110,7 +115,7 @@ func TestApplyMapping(t *testing.T) {
 			"def456": "xyz222",
 		}
 
-		mapped, unmapped := service.applyMapping(dataValues, mapping)
+		mapped, unmapped := service.applyMapping(context.Background(), dataValues, mapping)
 
 		assert.Len(t, mapped, 2, "All values should be mapped")
 		assert.Len(t, unmapped, 0, "No values should be unmapped")
@@ -127,7 +132,7 @@ func TestApplyMapping(t *testing.T) {
 
 		mapping := map[string]string{"abc123": "xyz111"}
 
-		mapped, _ := service.applyMapping(dataValues, mapping)
+		mapped, _ := service.applyMapping(context.Background(), dataValues, mapping)
 
 		// Original should be unchanged
 		assert.Equal(t, "abc123", original.DataElement)
@@ -334,3 +339,656 @@ func TestImportSummary(t *testing.T) {
 		assert.Equal(t, "Data element not found", summary.Conflicts[0].Value)
 	})
 }
+
+func TestApplyDefaultCOCMapping(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(ctx)
+
+	t.Run("Should route blank and default COC values to the target COC", func(t *testing.T) {
+		dataValues := []DataValue{
+			{DataElement: "de001", CategoryOptionCombo: "", Value: "10"},
+			{DataElement: "de002", CategoryOptionCombo: "HllvX50cXC0", Value: "20"},
+			{DataElement: "de003", CategoryOptionCombo: "cocMaleU5", Value: "30"},
+		}
+
+		result := service.applyDefaultCOCMapping(context.Background(), dataValues, "HllvX50cXC0", "destCocTotal")
+
+		require.Len(t, result, 3)
+		assert.Equal(t, "destCocTotal", result[0].CategoryOptionCombo, "Blank COC should be routed")
+		assert.Equal(t, "destCocTotal", result[1].CategoryOptionCombo, "Default COC should be routed")
+		assert.Equal(t, "cocMaleU5", result[2].CategoryOptionCombo, "Disaggregated COC should be untouched")
+	})
+
+	t.Run("Should still route blank COCs when the source default is unknown", func(t *testing.T) {
+		dataValues := []DataValue{
+			{DataElement: "de001", CategoryOptionCombo: "", Value: "10"},
+			{DataElement: "de002", CategoryOptionCombo: "HllvX50cXC0", Value: "20"},
+		}
+
+		result := service.applyDefaultCOCMapping(context.Background(), dataValues, "", "destCocTotal")
+
+		assert.Equal(t, "destCocTotal", result[0].CategoryOptionCombo)
+		assert.Equal(t, "HllvX50cXC0", result[1].CategoryOptionCombo)
+	})
+
+	t.Run("Should leave values unchanged without a target COC", func(t *testing.T) {
+		dataValues := []DataValue{{DataElement: "de001", CategoryOptionCombo: "", Value: "10"}}
+
+		result := service.applyDefaultCOCMapping(context.Background(), dataValues, "HllvX50cXC0", "")
+
+		assert.Equal(t, "", result[0].CategoryOptionCombo)
+	})
+
+	t.Run("Should let resolutions still apply to routed values", func(t *testing.T) {
+		dataValues := []DataValue{
+			{DataElement: "de001", OrgUnit: "ou1", CategoryOptionCombo: "", Value: "10"},
+		}
+
+		routed := service.applyDefaultCOCMapping(context.Background(), dataValues, "HllvX50cXC0", "destCocTotal")
+		sanitized, skipped := service.applyResolutions(routed, []Resolution{
+			{ID: "ou1", Type: "orgUnit", Action: "map:ou9"},
+		})
+
+		require.Len(t, sanitized, 1)
+		assert.Equal(t, 0, skipped)
+		assert.Equal(t, "ou9", sanitized[0].OrgUnit)
+		assert.Equal(t, "destCocTotal", sanitized[0].CategoryOptionCombo)
+	})
+}
+
+func TestExportUnmatchedOrgUnits(t *testing.T) {
+	service := NewService(context.Background())
+	service.taskStore["task1"] = &TransferProgress{
+		TaskID: "task1",
+		Status: "completed",
+		UnmatchedOUs: []UnmatchedOrgUnit{
+			{ID: "ouA", Name: "Clinic A", Code: "CA01", Path: "/root/dist/ouA", Periods: []string{"202401", "202402"}},
+			{ID: "ouB", Name: "Clinic, B", Path: "/root/ouB", Periods: []string{"202401"}},
+		},
+	}
+
+	t.Run("Should export unmatched org units as CSV", func(t *testing.T) {
+		out, err := service.ExportUnmatchedOrgUnits("task1", "csv")
+
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		require.Len(t, lines, 3)
+		assert.Equal(t, "sourceOrgUnitId,name,code,path,periods", lines[0])
+		assert.Equal(t, "ouA,Clinic A,CA01,/root/dist/ouA,202401;202402", lines[1])
+		assert.Equal(t, `ouB,"Clinic, B",,/root/ouB,202401`, lines[2])
+	})
+
+	t.Run("Should export unmatched org units as JSON", func(t *testing.T) {
+		out, err := service.ExportUnmatchedOrgUnits("task1", "json")
+
+		require.NoError(t, err)
+		assert.Contains(t, out, `"code": "CA01"`)
+	})
+
+	t.Run("Should reject unknown tasks and formats", func(t *testing.T) {
+		_, err := service.ExportUnmatchedOrgUnits("missing", "csv")
+		assert.Error(t, err)
+
+		_, err = service.ExportUnmatchedOrgUnits("task1", "xml")
+		assert.Error(t, err)
+	})
+}
+
+func TestFilterUnchanged(t *testing.T) {
+	existing := []DataValue{
+		{DataElement: "de1", CategoryOptionCombo: "coc1", AttributeOptionCombo: "aocDefault", Value: "10"},
+		{DataElement: "de2", CategoryOptionCombo: "coc1", AttributeOptionCombo: "aocDefault", Value: "5.0"},
+		{DataElement: "de3", CategoryOptionCombo: "coc1", AttributeOptionCombo: "aocPartner", Value: "7"},
+	}
+
+	t.Run("Should drop identical values and keep creates and updates", func(t *testing.T) {
+		values := []DataValue{
+			{DataElement: "de1", CategoryOptionCombo: "coc1", Value: "10"},                                    // unchanged
+			{DataElement: "de2", CategoryOptionCombo: "coc1", Value: "5"},                                     // numerically unchanged
+			{DataElement: "de1", CategoryOptionCombo: "coc2", Value: "10"},                                    // create
+			{DataElement: "de3", CategoryOptionCombo: "coc1", AttributeOptionCombo: "aocPartner", Value: "8"}, // update
+		}
+
+		changed, unchanged := filterUnchanged(values, existing)
+
+		assert.Equal(t, 2, unchanged)
+		require.Len(t, changed, 2)
+		assert.Equal(t, "coc2", changed[0].CategoryOptionCombo)
+		assert.Equal(t, "8", changed[1].Value)
+	})
+
+	t.Run("Should respect an explicit attribute option combo", func(t *testing.T) {
+		values := []DataValue{
+			{DataElement: "de3", CategoryOptionCombo: "coc1", AttributeOptionCombo: "aocOther", Value: "7"},
+		}
+
+		changed, unchanged := filterUnchanged(values, existing)
+
+		assert.Equal(t, 0, unchanged)
+		assert.Len(t, changed, 1)
+	})
+
+	t.Run("Should send everything when destination is empty", func(t *testing.T) {
+		values := []DataValue{{DataElement: "de1", CategoryOptionCombo: "coc1", Value: "10"}}
+
+		changed, unchanged := filterUnchanged(values, nil)
+
+		assert.Equal(t, 0, unchanged)
+		assert.Len(t, changed, 1)
+	})
+}
+
+func TestValidateCompleteDate(t *testing.T) {
+	t.Run("Should reject a malformed completeDate", func(t *testing.T) {
+		req := &TransferRequest{
+			ProfileID:       "abcdefghij1",
+			SourceDatasetID: "abcdefghij2",
+			Periods:         []string{"202401"},
+			CompleteDate:    "05/02/2024",
+		}
+
+		err := ValidateTransferRequest(req)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "CompleteDate")
+	})
+}
+
+func TestReplaceDeletions(t *testing.T) {
+	existing := []DataValue{
+		{DataElement: "de1", CategoryOptionCombo: "coc1", AttributeOptionCombo: "aoc1", Value: "10"},
+		{DataElement: "de2", CategoryOptionCombo: "coc1", AttributeOptionCombo: "aoc1", Value: "5"},
+		{DataElement: "de2", CategoryOptionCombo: "coc2", AttributeOptionCombo: "aoc1", Value: "6"},
+		{DataElement: "deOther", CategoryOptionCombo: "coc1", AttributeOptionCombo: "aoc1", Value: "1"},
+	}
+	incoming := []DataValue{
+		{DataElement: "de1", CategoryOptionCombo: "coc1", Value: "12"},
+		{DataElement: "de2", CategoryOptionCombo: "coc1", Value: "5"},
+	}
+
+	t.Run("Should delete only stale values within the mapped element set", func(t *testing.T) {
+		elements := replaceElementSet(map[string]string{"src1": "de1", "src2": "de2"})
+
+		stale := replaceDeletions(existing, incoming, elements, "")
+
+		require.Len(t, stale, 1)
+		assert.Equal(t, "de2", stale[0].DataElement)
+		assert.Equal(t, "coc2", stale[0].CategoryOptionCombo)
+	})
+
+	t.Run("Should cover every dataset element without a mapping", func(t *testing.T) {
+		stale := replaceDeletions(existing, incoming, replaceElementSet(nil), "")
+
+		assert.Len(t, stale, 2)
+	})
+
+	t.Run("Should restrict deletions to the requested attribute option combo", func(t *testing.T) {
+		stale := replaceDeletions(existing, incoming, nil, "aocOther")
+
+		assert.Empty(t, stale)
+	})
+}
+
+func TestValidateImportMode(t *testing.T) {
+	base := func() *TransferRequest {
+		return &TransferRequest{
+			ProfileID:       "abcdefghij1",
+			SourceDatasetID: "abcdefghij2",
+			Periods:         []string{"202401"},
+			ImportMode:      ImportModeReplace,
+		}
+	}
+
+	t.Run("Should require confirmation for a REPLACE run that writes", func(t *testing.T) {
+		err := ValidateTransferRequest(base())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ImportMode")
+	})
+
+	t.Run("Should allow a REPLACE dry run without confirmation", func(t *testing.T) {
+		req := base()
+		req.DryRun = true
+
+		assert.NoError(t, ValidateTransferRequest(req))
+	})
+
+	t.Run("Should allow a confirmed REPLACE run", func(t *testing.T) {
+		req := base()
+		req.ConfirmReplace = true
+
+		assert.NoError(t, ValidateTransferRequest(req))
+	})
+
+	t.Run("Should reject an unknown mode", func(t *testing.T) {
+		req := base()
+		req.ImportMode = "OVERWRITE"
+
+		assert.Error(t, ValidateTransferRequest(req))
+	})
+
+	t.Run("Should reject REPLACE with a follow-up filter", func(t *testing.T) {
+		for _, mode := range []string{FollowUpOnly, FollowUpExclude} {
+			req := base()
+			req.DryRun = true
+			req.FollowUpMode = mode
+
+			err := ValidateTransferRequest(req)
+			require.Error(t, err, mode)
+			assert.Contains(t, err.Error(), "follow-up")
+		}
+	})
+
+	t.Run("Should allow REPLACE with every follow-up state", func(t *testing.T) {
+		req := base()
+		req.DryRun = true
+		req.FollowUpMode = FollowUpAll
+
+		assert.NoError(t, ValidateTransferRequest(req))
+	})
+
+	t.Run("Should reject REPLACE with a category option combo filter", func(t *testing.T) {
+		req := base()
+		req.DryRun = true
+		req.CategoryOptionCombos = []string{"abcdefghij3"}
+
+		err := ValidateTransferRequest(req)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "category option combo")
+	})
+
+	t.Run("Should allow filters with MERGE", func(t *testing.T) {
+		req := base()
+		req.ImportMode = ImportModeMerge
+		req.FollowUpMode = FollowUpOnly
+		req.CategoryOptionCombos = []string{"abcdefghij3"}
+
+		assert.NoError(t, ValidateTransferRequest(req))
+	})
+}
+
+func TestFilterFollowUp(t *testing.T) {
+	values := []DataValue{
+		{DataElement: "de1", Value: "1"},
+		{DataElement: "de2", Value: "999", FollowUp: true},
+		{DataElement: "de3", Value: "3"},
+	}
+
+	t.Run("Should keep everything in all mode", func(t *testing.T) {
+		kept, dropped := filterFollowUp(values, FollowUpAll)
+
+		assert.Len(t, kept, 3)
+		assert.Equal(t, 0, dropped)
+	})
+
+	t.Run("Should keep only flagged values in only mode", func(t *testing.T) {
+		kept, dropped := filterFollowUp(values, FollowUpOnly)
+
+		require.Len(t, kept, 1)
+		assert.Equal(t, "de2", kept[0].DataElement)
+		assert.Equal(t, 2, dropped)
+	})
+
+	t.Run("Should drop flagged values in exclude mode", func(t *testing.T) {
+		kept, dropped := filterFollowUp(values, FollowUpExclude)
+
+		assert.Len(t, kept, 2)
+		assert.Equal(t, 1, dropped)
+		for _, dv := range kept {
+			assert.False(t, dv.FollowUp)
+		}
+	})
+
+	t.Run("Should read the followup flag as DHIS2 exports it", func(t *testing.T) {
+		var payload DataValueSet
+		err := json.Unmarshal([]byte(`{"dataValues":[{"dataElement":"de1","value":"5","followup":true},{"dataElement":"de2","value":"6","followup":false}]}`), &payload)
+		require.NoError(t, err)
+
+		kept, _ := filterFollowUp(payload.DataValues, FollowUpOnly)
+
+		require.Len(t, kept, 1)
+		assert.Equal(t, "de1", kept[0].DataElement)
+	})
+}
+
+func TestFilterCategoryOptionCombos(t *testing.T) {
+	values := []DataValue{
+		{DataElement: "de1", CategoryOptionCombo: "cocMale0001", Value: "4"},
+		{DataElement: "de1", CategoryOptionCombo: "cocFemale001", Value: "6"},
+		{DataElement: "de2", CategoryOptionCombo: "cocMale0001", Value: "1"},
+		{DataElement: "de2", CategoryOptionCombo: "cocOther001", Value: "2"},
+	}
+
+	t.Run("Should keep only the selected combos", func(t *testing.T) {
+		kept, dropped := filterCategoryOptionCombos(values, []string{"cocMale0001", "cocFemale001"})
+
+		assert.Len(t, kept, 3)
+		assert.Equal(t, 1, dropped)
+		for _, dv := range kept {
+			assert.NotEqual(t, "cocOther001", dv.CategoryOptionCombo)
+		}
+	})
+
+	t.Run("Should keep everything without a selection", func(t *testing.T) {
+		kept, dropped := filterCategoryOptionCombos(values, nil)
+
+		assert.Len(t, kept, 4)
+		assert.Equal(t, 0, dropped)
+	})
+
+	t.Run("Should count selected values per combo", func(t *testing.T) {
+		s := &Service{taskStore: map[string]*TransferProgress{"task": {TaskID: "task"}}}
+		kept, _ := filterCategoryOptionCombos(values, []string{"cocMale0001", "cocFemale001", "cocUnused01"})
+
+		s.recordComboCounts("task", kept)
+		s.recordComboCounts("task", kept[:1])
+
+		assert.Equal(t, map[string]int{"cocMale0001": 3, "cocFemale001": 1}, s.taskStore["task"].ComboCounts)
+		assert.Equal(t, "cocMale0001=3, cocFemale001=1, cocUnused01=0",
+			s.comboCountsSummary("task", []string{"cocMale0001", "cocFemale001", "cocUnused01"}))
+	})
+}
+
+func TestDedupeDataValues(t *testing.T) {
+	t.Run("Should keep the most recently updated of conflicting rows regardless of order", func(t *testing.T) {
+		older := DataValue{DataElement: "de1", Period: "202401", OrgUnit: "ou1", CategoryOptionCombo: "coc1", Value: "10", LastUpdated: "2024-02-01T08:00:00.000"}
+		newer := DataValue{DataElement: "de1", Period: "202401", OrgUnit: "ou1", CategoryOptionCombo: "coc1", Value: "12", LastUpdated: "2024-02-03T08:00:00.000"}
+		other := DataValue{DataElement: "de2", Period: "202401", OrgUnit: "ou1", CategoryOptionCombo: "coc1", Value: "7"}
+
+		forward, dropped := dedupeDataValues([]DataValue{older, other, newer})
+		backward, _ := dedupeDataValues([]DataValue{newer, other, older})
+
+		assert.Equal(t, 1, dropped)
+		require.Len(t, forward, 2)
+		assert.Equal(t, "12", forward[0].Value)
+		assert.Equal(t, "de2", forward[1].DataElement, "Original order should be kept")
+		assert.Equal(t, forward, backward)
+	})
+
+	t.Run("Should break timestamp ties by value", func(t *testing.T) {
+		a := DataValue{DataElement: "de1", Period: "202401", OrgUnit: "ou1", Value: "3"}
+		b := DataValue{DataElement: "de1", Period: "202401", OrgUnit: "ou1", Value: "5"}
+
+		first, _ := dedupeDataValues([]DataValue{a, b})
+		second, _ := dedupeDataValues([]DataValue{b, a})
+
+		require.Len(t, first, 1)
+		assert.Equal(t, "5", first[0].Value)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("Should use lastUpdated from a source response to pick the newer duplicate", func(t *testing.T) {
+		var payload DataValueSet
+		err := json.Unmarshal([]byte(`{"dataValues":[
+			{"dataElement":"de1","period":"202401","orgUnit":"ou1","categoryOptionCombo":"coc1","value":"20","lastUpdated":"2024-03-01T09:15:00.000+0200"},
+			{"dataElement":"de1","period":"202401","orgUnit":"ou1","categoryOptionCombo":"coc1","value":"15","lastUpdated":"2024-03-01T08:30:00.000+0000"}
+		]}`), &payload)
+		require.NoError(t, err)
+		require.Len(t, payload.DataValues, 2)
+		assert.Equal(t, "2024-03-01T09:15:00.000+0200", payload.DataValues[0].LastUpdated)
+
+		kept, dropped := dedupeDataValues(payload.DataValues)
+
+		assert.Equal(t, 1, dropped)
+		require.Len(t, kept, 1)
+		assert.Equal(t, "15", kept[0].Value, "08:30 UTC is later than 09:15 at +02:00")
+	})
+
+	t.Run("Should compare mixed timestamp formats", func(t *testing.T) {
+		assert.Equal(t, 1, compareLastUpdated("2024-03-02T00:00:00", "2024-03-01T23:59:59.999"))
+		assert.Equal(t, -1, compareLastUpdated("", "2024-03-01T00:00:00"))
+		assert.Equal(t, 0, compareLastUpdated("2024-03-01T00:00:00.000Z", "2024-03-01T00:00:00"))
+	})
+
+	t.Run("Should treat different AOCs as distinct keys", func(t *testing.T) {
+		values := []DataValue{
+			{DataElement: "de1", Period: "202401", OrgUnit: "ou1", AttributeOptionCombo: "aoc1", Value: "1"},
+			{DataElement: "de1", Period: "202401", OrgUnit: "ou1", AttributeOptionCombo: "aoc2", Value: "2"},
+		}
+
+		kept, dropped := dedupeDataValues(values)
+
+		assert.Len(t, kept, 2)
+		assert.Equal(t, 0, dropped)
+	})
+}
+
+func TestLogf(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	flags := log.Flags()
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+	}()
+
+	t.Run("Should prefix lines with the task ID carried by the context", func(t *testing.T) {
+		buf.Reset()
+
+		logf(withTaskID(context.Background(), "task-123"), "Polling job %d/%d", 1, 3)
+
+		assert.Equal(t, "[task-123] Polling job 1/3\n", buf.String())
+	})
+
+	t.Run("Should log plainly without a task ID", func(t *testing.T) {
+		buf.Reset()
+
+		logf(context.Background(), "Polling job %d/%d", 1, 3)
+
+		assert.Equal(t, "Polling job 1/3\n", buf.String())
+	})
+}
+
+func TestScanCSVOrgUnits(t *testing.T) {
+	t.Run("Should locate the orgunit column from the header", func(t *testing.T) {
+		body := "\ufeffDataElement,Period,OrgUnit,CategoryOptionCombo,AttributeOptionCombo,Value\n" +
+			"de1,202401,ouA,coc1,aoc1,1\n" +
+			"de2,202401,ouB,coc1,aoc1,2\n" +
+			"de3,202401,ouA,coc1,aoc1,3\n"
+
+		orgUnits, values, err := scanCSVOrgUnits(strings.NewReader(body))
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, values)
+		assert.Equal(t, map[string]bool{"ouA": true, "ouB": true}, orgUnits)
+	})
+
+	t.Run("Should be much smaller than the JSON export of the same values", func(t *testing.T) {
+		var csvBody strings.Builder
+		csvBody.WriteString("dataelement,period,orgunit,catoptcombo,attroptcombo,value,storedby,lastupdated,comment,followup\n")
+		values := []DataValue{}
+		for i := 0; i < 500; i++ {
+			dv := DataValue{
+				DataElement: "fbfJHSPpUQD", Period: "202401", OrgUnit: "DiszpKrYNg8",
+				CategoryOptionCombo: "pq2XI5kz2BY", AttributeOptionCombo: "HllvX50cXC0",
+				Value: "12", StoredBy: "admin", LastUpdated: "2024-02-01T10:00:00.000",
+			}
+			values = append(values, dv)
+			csvBody.WriteString("fbfJHSPpUQD,202401,DiszpKrYNg8,pq2XI5kz2BY,HllvX50cXC0,12,admin,2024-02-01T10:00:00.000,,false\n")
+		}
+		jsonBody, err := json.Marshal(DataValueSet{DataValues: values})
+		require.NoError(t, err)
+
+		counter := &countingReader{r: strings.NewReader(csvBody.String())}
+		_, count, err := scanCSVOrgUnits(counter)
+		require.NoError(t, err)
+
+		assert.Equal(t, 500, count)
+		assert.Less(t, float64(counter.n), 0.6*float64(len(jsonBody)), "CSV should be well under JSON size (%d vs %d bytes)", counter.n, len(jsonBody))
+	})
+
+	t.Run("Should treat an empty body as no data", func(t *testing.T) {
+		orgUnits, values, err := scanCSVOrgUnits(strings.NewReader(""))
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, values)
+		assert.Empty(t, orgUnits)
+	})
+}
+
+func TestSplitByNewMappings(t *testing.T) {
+	unmapped := map[string][]DataValue{
+		"Clinic A:202401": {
+			{DataElement: "srcDE000001", OrgUnit: "destOU00001", Period: "202401", Value: "5"},
+			{DataElement: "srcDE000002", OrgUnit: "destOU00001", Period: "202401", Value: "7"},
+		},
+		"Clinic B:202401": {
+			{DataElement: "srcDE000002", OrgUnit: "destOU00002", Period: "202401", Value: "9"},
+		},
+	}
+
+	t.Run("Should map covered elements and keep the rest parked", func(t *testing.T) {
+		mapped, remaining := splitByNewMappings(unmapped, map[string]string{"srcDE000001": "dstDE000001"})
+
+		require.Len(t, mapped, 1)
+		assert.Equal(t, "dstDE000001", mapped[0].DataElement)
+		assert.Equal(t, "destOU00001", mapped[0].OrgUnit)
+
+		require.Len(t, remaining, 2, "Both org unit/period keys still hold unmapped values")
+		assert.Equal(t, "srcDE000002", remaining["Clinic A:202401"][0].DataElement)
+		assert.Len(t, remaining["Clinic B:202401"], 1)
+		assert.Equal(t, "srcDE000001", unmapped["Clinic A:202401"][0].DataElement, "Parked values should not be modified")
+	})
+
+	t.Run("Should leave nothing parked when every element is mapped", func(t *testing.T) {
+		mapped, remaining := splitByNewMappings(unmapped, map[string]string{
+			"srcDE000001": "dstDE000001",
+			"srcDE000002": "dstDE000002",
+		})
+
+		assert.Len(t, mapped, 3)
+		assert.Nil(t, remaining)
+	})
+
+	t.Run("Should ignore blank destination mappings", func(t *testing.T) {
+		mapped, remaining := splitByNewMappings(unmapped, map[string]string{"srcDE000002": ""})
+
+		assert.Empty(t, mapped)
+		assert.Len(t, remaining, 2)
+	})
+}
+
+func TestRetryWithNewMappingsValidation(t *testing.T) {
+	parked := func(req *TransferRequest) (*Service, string) {
+		service := NewService(context.Background())
+		service.taskStore["task-1"] = &TransferProgress{
+			TaskID: "task-1",
+			Status: "awaiting_user_decision",
+			UnmappedValues: map[string][]DataValue{
+				"Clinic A:202401": {{DataElement: "srcDE000001", Value: "5"}},
+			},
+			request: req,
+		}
+		return service, "task-1"
+	}
+
+	t.Run("Should refuse a task without the original request", func(t *testing.T) {
+		service, taskID := parked(nil)
+
+		err := service.RetryWithNewMappings(taskID, map[string]string{"srcDE000001": "dstDE000001"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "original transfer request")
+	})
+
+	t.Run("Should keep the task parked when no mapping applies", func(t *testing.T) {
+		service, taskID := parked(&TransferRequest{ProfileID: "profile-1"})
+
+		err := service.RetryWithNewMappings(taskID, map[string]string{"otherDE0001": "dstDE000001"})
+
+		require.Error(t, err)
+		progress := service.taskStore[taskID]
+		assert.Equal(t, "awaiting_user_decision", progress.Status)
+		assert.Len(t, progress.UnmappedValues["Clinic A:202401"], 1)
+	})
+}
+
+func TestCancelTransfer(t *testing.T) {
+	t.Run("Should stop a running transfer's work", func(t *testing.T) {
+		service := NewService(context.Background())
+		service.taskStore["task-1"] = &TransferProgress{TaskID: "task-1", Status: "running"}
+		ctx, cancel := service.taskContext("task-1")
+		defer cancel()
+
+		require.NoError(t, service.CancelTransfer("task-1"))
+
+		progress := service.taskStore["task-1"]
+		assert.Equal(t, "cancelled", progress.Status)
+		assert.Equal(t, "✗ Transfer cancelled by user", progress.Messages[len(progress.Messages)-1])
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+		assert.True(t, service.isCancelled("task-1"))
+	})
+
+	t.Run("Should refuse finished tasks", func(t *testing.T) {
+		service := NewService(context.Background())
+		service.taskStore["task-1"] = &TransferProgress{TaskID: "task-1", Status: "completed"}
+
+		err := service.CancelTransfer("task-1")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "current status: completed")
+	})
+}
+
+func TestNextTaskStatus(t *testing.T) {
+	t.Run("Should keep a cancelled task cancelled", func(t *testing.T) {
+		for _, status := range []string{"running", "error", "completed", "awaiting_user_decision"} {
+			assert.Equal(t, "cancelled", nextTaskStatus("cancelled", status), status)
+		}
+	})
+
+	t.Run("Should apply updates to other tasks", func(t *testing.T) {
+		assert.Equal(t, "completed", nextTaskStatus("running", "completed"))
+		assert.Equal(t, "running", nextTaskStatus("awaiting_user_decision", "running"))
+	})
+}
+
+func TestValidatePeriods(t *testing.T) {
+	cases := []struct {
+		periodType string
+		valid      []string
+		invalid    []string
+	}{
+		{"Daily", []string{"20240101", "20241231"}, []string{"202401", "20241301"}},
+		{"Weekly", []string{"2024W1", "2024W53"}, []string{"2024W0", "2024W54", "202401"}},
+		{"BiWeekly", []string{"2024BiW1", "2024BiW27"}, []string{"2024W1"}},
+		{"Monthly", []string{"202401", "202412"}, []string{"2024", "202413", "2024Q1"}},
+		{"BiMonthly", []string{"202401B", "202406B"}, []string{"202407B", "202401"}},
+		{"Quarterly", []string{"2024Q1", "2024Q4"}, []string{"2024Q5", "202401"}},
+		{"SixMonthly", []string{"2024S1", "2024S2"}, []string{"2024S3", "2024Q1"}},
+		{"Yearly", []string{"2024"}, []string{"202401", "24"}},
+		{"FinancialApril", []string{"2024April"}, []string{"2024", "2024July"}},
+		{"FinancialJuly", []string{"2024July"}, []string{"2024April"}},
+		{"FinancialOct", []string{"2024Oct"}, []string{"2024Nov"}},
+	}
+
+	for _, tc := range cases {
+		t.Run("Should check "+tc.periodType+" periods", func(t *testing.T) {
+			assert.NoError(t, validatePeriods(tc.valid, tc.periodType))
+
+			err := validatePeriods(append(append([]string{}, tc.valid...), tc.invalid...), tc.periodType)
+			require.Error(t, err)
+			for _, period := range tc.invalid {
+				assert.Contains(t, err.Error(), period)
+			}
+			assert.Contains(t, err.Error(), tc.periodType)
+		})
+	}
+
+	t.Run("Should accept any spelling of the period type", func(t *testing.T) {
+		assert.NoError(t, validatePeriods([]string{"2024S1"}, "SIX_MONTHLY"))
+		assert.NoError(t, validatePeriods([]string{"202401"}, "MONTHLY"))
+	})
+
+	t.Run("Should describe the expected format", func(t *testing.T) {
+		err := validatePeriods([]string{"2024"}, "Monthly")
+
+		require.Error(t, err)
+		assert.Equal(t, "1 period(s) don't match the dataset's Monthly period type (expected YYYYMM): 2024", err.Error())
+	})
+
+	t.Run("Should not check unknown period types", func(t *testing.T) {
+		assert.NoError(t, validatePeriods([]string{"anything"}, "WeeklyWednesday"))
+	})
+}
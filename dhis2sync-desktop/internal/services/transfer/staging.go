@@ -0,0 +1,495 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"dhis2sync-desktop/internal/database"
+	"dhis2sync-desktop/internal/models"
+	"dhis2sync-desktop/internal/writewindow"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Staged transfer statuses (models.StagedTransfer.Status)
+const (
+	StagingInProgress = "staging"
+	StagingReady      = "staged"
+	StagingPushing    = "pushing"
+	StagingPushed     = "pushed"
+	StagingFailed     = "error"
+)
+
+const (
+	defaultStagedPageSize = 100
+	maxStagedPageSize     = 1000
+	stagingBatchSize      = 500
+)
+
+// StageTransfer fetches, filters, maps and resolves a transfer's source values like
+// StartTransfer, but writes the destination-ready values to the staging table instead of
+// importing them. Progress is reported under the returned staging ID like a transfer.
+// Explicit req.OrgUnitIDs are staged under the same IDs (as in a quick transfer);
+// otherwise org units are discovered and matched in the destination by name.
+func (s *Service) StageTransfer(req TransferRequest) (string, error) {
+	if len(req.Periods) == 0 {
+		return "", fmt.Errorf("at least one period is required")
+	}
+
+	requestJSON, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	stagingID := uuid.New().String()
+	staged := &models.StagedTransfer{
+		ID:            stagingID,
+		ProfileID:     req.ProfileID,
+		DestDatasetID: req.DestDatasetID,
+		Request:       string(requestJSON),
+		Status:        StagingInProgress,
+	}
+	if err := database.GetDB().Create(staged).Error; err != nil {
+		return "", fmt.Errorf("failed to create staging record: %w", err)
+	}
+
+	if err := s.registerTask(stagingID, "transfer_staging", req, "Initializing staging..."); err != nil {
+		return "", err
+	}
+
+	s.startWatchdog()
+	go s.performStaging(stagingID, req)
+
+	return stagingID, nil
+}
+
+// performStaging extracts and transforms source values into the staging table
+func (s *Service) performStaging(stagingID string, req TransferRequest) {
+	ctx := withTaskID(context.Background(), stagingID)
+	db := database.GetDB()
+
+	fail := func(msg string) {
+		setStagingStatus(db, stagingID, StagingFailed)
+		s.updateProgress(stagingID, "error", 0, msg)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			fail(fmt.Sprintf("Panic during staging: %v", r))
+			logf(ctx, "Staging panic recovered: %v", r)
+		}
+	}()
+
+	s.updateProgress(stagingID, "running", 5, "Loading connection profile...")
+
+	var profile models.ConnectionProfile
+	if err := db.Where("id = ?", req.ProfileID).First(&profile).Error; err != nil {
+		fail(fmt.Sprintf("Failed to load profile: %v", err))
+		return
+	}
+
+	sourceClient, err := s.getAPIClient(&profile, "source")
+	if err != nil {
+		fail(fmt.Sprintf("Failed to create source client: %v", err))
+		return
+	}
+
+	destClient, err := s.getAPIClient(&profile, "destination")
+	if err != nil {
+		fail(fmt.Sprintf("Failed to create destination client: %v", err))
+		return
+	}
+
+	// Explicit org units skip discovery and name matching, as in a quick transfer
+	var selectedOUs map[string]string
+	rootID := ""
+	if len(req.OrgUnitIDs) > 0 {
+		selectedOUs, err = fetchOrgUnitNames(sourceClient, req.OrgUnitIDs)
+		if err != nil {
+			fail(fmt.Sprintf("Failed to look up org units: %v", err))
+			return
+		}
+	} else {
+		rootOU, err := s.GetUserRootOrgUnit(req.ProfileID, "source")
+		if err != nil {
+			fail(fmt.Sprintf("Failed to get root org unit: %v", err))
+			return
+		}
+		rootID = rootOU.ID
+	}
+
+	discoveryClient, err := s.getAPIClient(&profile, "source")
+	if err != nil {
+		fail(fmt.Sprintf("Failed to create discovery client: %v", err))
+		return
+	}
+	discoveryClient.SetTimeout(discoveryTimeout(&profile))
+
+	pipeline, err := s.newValuePipeline(ctx, stagingID, req, sourceClient, destClient)
+	if err != nil {
+		fail(fmt.Sprintf("Invalid periods: %v", err))
+		return
+	}
+	for _, warning := range pipeline.warnings {
+		s.updateProgress(stagingID, "running", 10, warning)
+	}
+
+	totalStaged, totalUnmapped := 0, 0
+	periodProgressChunk := 90 / len(req.Periods)
+
+	for i, period := range req.Periods {
+		if s.isCancelled(stagingID) {
+			setStagingStatus(db, stagingID, StagingFailed)
+			logf(ctx, "Staging cancelled, stopping before period %s", period)
+			return
+		}
+
+		periodProgress := 10 + i*periodProgressChunk
+		s.updateProgress(stagingID, "running", periodProgress, fmt.Sprintf("Staging period %s...", period))
+
+		ous := selectedOUs
+		if ous == nil {
+			ous, err = s.discoverOrgUnitsWithData(ctx, discoveryClient, req.SourceDatasetID, period, rootID, deltaParams(req))
+			if err != nil {
+				s.updateProgress(stagingID, "running", periodProgress, fmt.Sprintf("⚠ Failed to scan period %s: %v", period, err))
+				continue
+			}
+		}
+
+		for ouID, ouName := range ous {
+			destOUID := ouID
+			if selectedOUs == nil {
+				destOUID, err = s.findMatchingOrgUnit(destClient, ouID, ouName)
+				if err != nil {
+					logf(ctx, "No matching org unit found in destination for %s (%s): %v", ouName, ouID, err)
+					s.recordUnmatchedOrgUnit(stagingID, sourceClient, ouID, ouName, period)
+					continue
+				}
+			}
+
+			payload, err := fetchOrgUnitValues(sourceClient, req, period, ouID)
+			if err != nil {
+				logf(ctx, "Failed to fetch source data for %s/%s: %v", ouName, period, err)
+				continue
+			}
+
+			mapped, unmapped := pipeline.prepare(ctx, payload.DataValues, destOUID, ouName, period)
+			totalUnmapped += len(unmapped)
+
+			if err := stageValues(db, stagingID, mapped); err != nil {
+				fail(fmt.Sprintf("Failed to write staged values for %s/%s: %v", ouName, period, err))
+				return
+			}
+			totalStaged += len(mapped)
+		}
+	}
+
+	pipeline.recordCOCMatches()
+
+	if err := db.Model(&models.StagedTransfer{}).Where("id = ?", stagingID).
+		Updates(map[string]interface{}{"status": StagingReady, "value_count": totalStaged}).Error; err != nil {
+		logf(ctx, "⚠ Failed to update staging record: %v", err)
+	}
+
+	s.taskMu.Lock()
+	if progress, exists := s.taskStore[stagingID]; exists {
+		progress.TotalMapped = totalStaged
+		progress.CompletedAt = time.Now().Format(time.RFC3339)
+	}
+	s.taskMu.Unlock()
+
+	msg := fmt.Sprintf("✓ Staged %d values for review", totalStaged)
+	if totalUnmapped > 0 {
+		msg += fmt.Sprintf(" (%d unmapped values left out)", totalUnmapped)
+	}
+	s.updateProgress(stagingID, "completed", 100, msg)
+}
+
+// stageValues writes mapped values to the staging table in batches
+func stageValues(db *gorm.DB, stagingID string, values []DataValue) error {
+	if len(values) == 0 {
+		return nil
+	}
+	rows := make([]models.StagedDataValue, 0, len(values))
+	for _, dv := range values {
+		rows = append(rows, models.StagedDataValue{
+			StagingID:            stagingID,
+			DataElement:          dv.DataElement,
+			Period:               dv.Period,
+			OrgUnit:              dv.OrgUnit,
+			CategoryOptionCombo:  dv.CategoryOptionCombo,
+			AttributeOptionCombo: dv.AttributeOptionCombo,
+			Value:                dv.Value,
+			Comment:              dv.Comment,
+			FollowUp:             dv.FollowUp,
+		})
+	}
+	return db.CreateInBatches(rows, stagingBatchSize).Error
+}
+
+// setStagingStatus updates a staged transfer's status, ignoring failures (best effort)
+func setStagingStatus(db *gorm.DB, stagingID, status string) {
+	db.Model(&models.StagedTransfer{}).Where("id = ?", stagingID).Update("status", status)
+}
+
+// loadStagedTransfer fetches a staged transfer by ID
+func loadStagedTransfer(db *gorm.DB, stagingID string) (*models.StagedTransfer, error) {
+	var staged models.StagedTransfer
+	if err := db.Where("id = ?", stagingID).First(&staged).Error; err != nil {
+		return nil, fmt.Errorf("staged transfer not found: %w", err)
+	}
+	return &staged, nil
+}
+
+// GetStagedValues returns one page (1-based) of a staged transfer's values for review,
+// ordered by period, org unit, data element and category option combo
+func (s *Service) GetStagedValues(stagingID string, page, pageSize int) (*StagedValuesPage, error) {
+	db := database.GetDB()
+	staged, err := loadStagedTransfer(db, stagingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultStagedPageSize
+	}
+	if pageSize > maxStagedPageSize {
+		pageSize = maxStagedPageSize
+	}
+
+	query := db.Model(&models.StagedDataValue{}).Where("staging_id = ?", stagingID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count staged values: %w", err)
+	}
+
+	values := []models.StagedDataValue{}
+	if err := query.Order("period, org_unit, data_element, category_option_combo").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&values).Error; err != nil {
+		return nil, fmt.Errorf("failed to load staged values: %w", err)
+	}
+
+	return &StagedValuesPage{
+		StagingID: stagingID,
+		Status:    staged.Status,
+		Page:      page,
+		PageSize:  pageSize,
+		Total:     int(total),
+		Values:    values,
+	}, nil
+}
+
+// UpdateStagedValue corrects a staged value (and its comment) before it is pushed
+func (s *Service) UpdateStagedValue(stagingID, valueID, value, comment string) error {
+	db := database.GetDB()
+	if err := requireEditable(db, stagingID); err != nil {
+		return err
+	}
+
+	result := db.Model(&models.StagedDataValue{}).
+		Where("id = ? AND staging_id = ?", valueID, stagingID).
+		Updates(map[string]interface{}{"value": value, "comment": comment, "edited": true})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update staged value: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("staged value %s not found", valueID)
+	}
+	return nil
+}
+
+// RemoveStagedValue drops a staged value so it is not pushed
+func (s *Service) RemoveStagedValue(stagingID, valueID string) error {
+	db := database.GetDB()
+	if err := requireEditable(db, stagingID); err != nil {
+		return err
+	}
+
+	result := db.Where("id = ? AND staging_id = ?", valueID, stagingID).Delete(&models.StagedDataValue{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove staged value: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("staged value %s not found", valueID)
+	}
+	return db.Model(&models.StagedTransfer{}).Where("id = ?", stagingID).
+		Update("value_count", gorm.Expr("value_count - 1")).Error
+}
+
+// requireEditable rejects edits to a staged transfer that is still staging or already pushing/pushed
+func requireEditable(db *gorm.DB, stagingID string) error {
+	staged, err := loadStagedTransfer(db, stagingID)
+	if err != nil {
+		return err
+	}
+	if staged.Status != StagingReady {
+		return fmt.Errorf("staged transfer is %s, values can only be edited once staging has finished and before pushing", staged.Status)
+	}
+	return nil
+}
+
+// PushStaged imports a staged transfer's reviewed values into the destination in the
+// background and returns the push task ID. The staging request's MarkComplete,
+// InterChunkDelayMs and the profile's write window apply as for a normal transfer.
+func (s *Service) PushStaged(stagingID string) (string, error) {
+	db := database.GetDB()
+	staged, err := loadStagedTransfer(db, stagingID)
+	if err != nil {
+		return "", err
+	}
+	if staged.Status != StagingReady {
+		return "", fmt.Errorf("staged transfer is %s, only a finished staging can be pushed", staged.Status)
+	}
+
+	var req TransferRequest
+	if err := json.Unmarshal([]byte(staged.Request), &req); err != nil {
+		return "", fmt.Errorf("failed to decode staged request: %w", err)
+	}
+
+	var profile models.ConnectionProfile
+	if err := db.Where("id = ?", staged.ProfileID).First(&profile).Error; err != nil {
+		return "", fmt.Errorf("profile not found: %w", err)
+	}
+	if err := writewindow.Check(&profile, time.Now()); err != nil {
+		return "", err
+	}
+
+	// Claim the staging so a second push can't run concurrently
+	result := db.Model(&models.StagedTransfer{}).Where("id = ? AND status = ?", stagingID, StagingReady).Update("status", StagingPushing)
+	if result.Error != nil {
+		return "", fmt.Errorf("failed to update staging record: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return "", fmt.Errorf("staged transfer is already being pushed")
+	}
+
+	taskID := uuid.New().String()
+	if err := s.registerTask(taskID, "transfer", req, "Pushing staged values..."); err != nil {
+		setStagingStatus(db, stagingID, StagingReady)
+		return "", err
+	}
+	db.Model(&models.StagedTransfer{}).Where("id = ?", stagingID).Update("push_task_id", taskID)
+
+	s.startWatchdog()
+	go s.performPush(taskID, stagingID, req)
+
+	return taskID, nil
+}
+
+// performPush imports staged values; on failure the staging returns to "staged" so it can be pushed again
+func (s *Service) performPush(taskID, stagingID string, req TransferRequest) {
+	ctx := withTaskID(context.Background(), taskID)
+	db := database.GetDB()
+
+	fail := func(msg string) {
+		setStagingStatus(db, stagingID, StagingReady)
+		s.updateProgress(taskID, "error", 0, msg)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			fail(fmt.Sprintf("Panic during push: %v", r))
+			logf(ctx, "Push panic recovered: %v", r)
+		}
+	}()
+
+	s.updateProgress(taskID, "running", 5, "Loading staged values...")
+
+	var rows []models.StagedDataValue
+	if err := db.Where("staging_id = ?", stagingID).Order("period, org_unit, data_element").Find(&rows).Error; err != nil {
+		fail(fmt.Sprintf("Failed to load staged values: %v", err))
+		return
+	}
+	values := stagedDataValues(rows)
+	if len(values) == 0 {
+		setStagingStatus(db, stagingID, StagingPushed)
+		s.updateProgress(taskID, "completed", 100, "No staged values to push")
+		return
+	}
+
+	var profile models.ConnectionProfile
+	if err := db.Where("id = ?", req.ProfileID).First(&profile).Error; err != nil {
+		fail(fmt.Sprintf("Failed to load profile: %v", err))
+		return
+	}
+	destClient, err := s.getAPIClient(&profile, "destination")
+	if err != nil {
+		fail(fmt.Sprintf("Failed to create destination client: %v", err))
+		return
+	}
+
+	s.updateProgress(taskID, "running", 10, fmt.Sprintf("Importing %d staged values...", len(values)))
+	jobRef := &asyncJobRef{TaskID: taskID, ProfileID: req.ProfileID}
+	onProgress := func(p float64, msg string) {
+		if p < 0 {
+			p = 0.5
+		}
+		s.updateProgress(taskID, "running", 10+int(p*75), msg)
+	}
+
+	summaries, err := s.importDataValuesBulkAsync(ctx, destClient, values, importChunkSize(req), maxConcurrentJobs(req), chunkDelay(req), req.ImportStrategy, jobRef, onProgress)
+	if err != nil {
+		fail(fmt.Sprintf("Import of staged values failed: %v", err))
+		return
+	}
+
+	var counts ImportCount
+	for _, summary := range summaries {
+		counts.Imported += summary.ImportCount.Imported
+		counts.Updated += summary.ImportCount.Updated
+		counts.Ignored += summary.ImportCount.Ignored
+		counts.Deleted += summary.ImportCount.Deleted
+	}
+
+	if req.MarkComplete {
+		s.markComplete(ctx, taskID, destClient, req, completionKeys(values), 90)
+	}
+
+	summary := ImportSummary{
+		Status: "SUCCESS",
+		Description: fmt.Sprintf("Pushed staged values: Imported=%d, Updated=%d, Already exist=%d",
+			counts.Imported, counts.Updated, counts.Ignored),
+		ImportCount: counts,
+	}
+
+	s.taskMu.Lock()
+	if progress, exists := s.taskStore[taskID]; exists {
+		progress.ImportSummary = &summary
+		progress.TotalImported = counts.Imported + counts.Updated
+		progress.CompletedAt = time.Now().Format(time.RFC3339)
+	}
+	s.taskMu.Unlock()
+
+	if data, err := json.Marshal(summary); err == nil {
+		db.Model(&models.TaskProgress{}).Where("id = ?", taskID).Update("results", string(data))
+	}
+	setStagingStatus(db, stagingID, StagingPushed)
+
+	s.updateProgress(taskID, "completed", 100, fmt.Sprintf("✓ Pushed staged values: %d new, %d updated, %d already exist",
+		counts.Imported, counts.Updated, counts.Ignored))
+}
+
+// stagedDataValues converts staged rows back into import payload values
+func stagedDataValues(rows []models.StagedDataValue) []DataValue {
+	values := make([]DataValue, 0, len(rows))
+	for _, row := range rows {
+		values = append(values, DataValue{
+			DataElement:          row.DataElement,
+			Period:               row.Period,
+			OrgUnit:              row.OrgUnit,
+			CategoryOptionCombo:  row.CategoryOptionCombo,
+			AttributeOptionCombo: row.AttributeOptionCombo,
+			Value:                row.Value,
+			Comment:              row.Comment,
+			FollowUp:             row.FollowUp,
+		})
+	}
+	return values
+}
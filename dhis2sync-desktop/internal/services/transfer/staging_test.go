@@ -0,0 +1,121 @@
+package transfer
+
+import (
+	"context"
+	"testing"
+
+	"dhis2sync-desktop/internal/database"
+	"dhis2sync-desktop/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// useStagingDB points the package database at a fresh in-memory store for one test
+func useStagingDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.StagedTransfer{}, &models.StagedDataValue{}))
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+	return db
+}
+
+func TestStagedValues(t *testing.T) {
+	service := NewService(context.Background())
+	values := []DataValue{
+		{DataElement: "de2", Period: "202402", OrgUnit: "ouA", CategoryOptionCombo: "coc1", Value: "4"},
+		{DataElement: "de1", Period: "202401", OrgUnit: "ouB", CategoryOptionCombo: "coc1", Value: "2"},
+		{DataElement: "de1", Period: "202401", OrgUnit: "ouA", CategoryOptionCombo: "coc1", Value: "1", Comment: "checked"},
+	}
+
+	stage := func(t *testing.T, db *gorm.DB, status string) {
+		require.NoError(t, db.Create(&models.StagedTransfer{ID: "stg1", ProfileID: "p1", Status: status, ValueCount: len(values)}).Error)
+		require.NoError(t, stageValues(db, "stg1", values))
+	}
+
+	t.Run("Should page staged values in review order", func(t *testing.T) {
+		db := useStagingDB(t)
+		stage(t, db, StagingReady)
+
+		first, err := service.GetStagedValues("stg1", 1, 2)
+		require.NoError(t, err)
+		assert.Equal(t, 3, first.Total)
+		assert.Equal(t, StagingReady, first.Status)
+		require.Len(t, first.Values, 2)
+		assert.Equal(t, "ouA", first.Values[0].OrgUnit)
+		assert.Equal(t, "checked", first.Values[0].Comment)
+		assert.Equal(t, "ouB", first.Values[1].OrgUnit)
+
+		second, err := service.GetStagedValues("stg1", 2, 2)
+		require.NoError(t, err)
+		require.Len(t, second.Values, 1)
+		assert.Equal(t, "202402", second.Values[0].Period)
+	})
+
+	t.Run("Should apply corrections and removals before pushing", func(t *testing.T) {
+		db := useStagingDB(t)
+		stage(t, db, StagingReady)
+		page, err := service.GetStagedValues("stg1", 1, 10)
+		require.NoError(t, err)
+
+		require.NoError(t, service.UpdateStagedValue("stg1", page.Values[0].ID, "10", "fixed"))
+		require.NoError(t, service.RemoveStagedValue("stg1", page.Values[2].ID))
+
+		var rows []models.StagedDataValue
+		require.NoError(t, db.Where("staging_id = ?", "stg1").Order("period, org_unit").Find(&rows).Error)
+		require.Len(t, rows, 2)
+		assert.Equal(t, "10", rows[0].Value)
+		assert.Equal(t, "fixed", rows[0].Comment)
+		assert.True(t, rows[0].Edited)
+		assert.False(t, rows[1].Edited)
+
+		staged, err := loadStagedTransfer(db, "stg1")
+		require.NoError(t, err)
+		assert.Equal(t, 2, staged.ValueCount)
+
+		pushed := stagedDataValues(rows)
+		assert.Equal(t, DataValue{DataElement: "de1", Period: "202401", OrgUnit: "ouA", CategoryOptionCombo: "coc1", Value: "10", Comment: "fixed"}, pushed[0])
+	})
+
+	t.Run("Should refuse edits and pushes while staging is unfinished", func(t *testing.T) {
+		db := useStagingDB(t)
+		stage(t, db, StagingInProgress)
+
+		err := service.UpdateStagedValue("stg1", "any", "1", "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "staging")
+
+		_, err = service.PushStaged("stg1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only a finished staging can be pushed")
+	})
+
+	t.Run("Should report an unknown staged value", func(t *testing.T) {
+		db := useStagingDB(t)
+		stage(t, db, StagingReady)
+
+		err := service.UpdateStagedValue("stg1", "missing", "1", "")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
+
+func TestStagedCompletionKeys(t *testing.T) {
+	t.Run("Should list each org unit and period once", func(t *testing.T) {
+		keys := completionKeys([]DataValue{
+			{OrgUnit: "ouA", Period: "202401"},
+			{OrgUnit: "ouA", Period: "202401"},
+			{OrgUnit: "ouB", Period: "202401"},
+			{OrgUnit: "ouA", Period: "202402"},
+		})
+
+		assert.Equal(t, []string{"ouA:202401", "ouB:202401", "ouA:202402"}, keys)
+	})
+}
@@ -0,0 +1,116 @@
+package transfer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"dhis2sync-desktop/internal/services/audit"
+)
+
+// ValueTransform rewrites a data element's values during a transfer. The steps run in
+// order: Lookup, then Factor, Round and Min/Max clamping for numeric values (other
+// values skip the numeric steps).
+type ValueTransform struct {
+	Lookup map[string]string `json:"lookup,omitempty"` // Replaces matching values, e.g. discrete codes
+	Factor float64           `json:"factor,omitempty"` // Multiplies numeric values (0 leaves them unscaled)
+	Round  *int              `json:"round,omitempty"`  // Rounds to this many decimal places
+	Min    *float64          `json:"min,omitempty"`    // Clamps numeric values to at least Min
+	Max    *float64          `json:"max,omitempty"`    // Clamps numeric values to at most Max
+}
+
+// apply returns the transformed value
+func (t ValueTransform) apply(value string) string {
+	if mapped, ok := t.Lookup[value]; ok {
+		value = mapped
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return value
+	}
+	if t.Factor == 0 && t.Round == nil && t.Min == nil && t.Max == nil {
+		return value
+	}
+
+	if t.Factor != 0 {
+		n *= t.Factor
+	}
+	if t.Round != nil {
+		scale := math.Pow(10, float64(*t.Round))
+		n = math.Round(n*scale) / scale
+	}
+	if t.Min != nil && n < *t.Min {
+		n = *t.Min
+	}
+	if t.Max != nil && n > *t.Max {
+		n = *t.Max
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// validate checks a transform's settings
+func (t ValueTransform) validate() error {
+	if math.IsNaN(t.Factor) || math.IsInf(t.Factor, 0) {
+		return fmt.Errorf("factor must be a finite number")
+	}
+	if t.Round != nil && (*t.Round < 0 || *t.Round > 10) {
+		return fmt.Errorf("round must be between 0 and 10 decimal places")
+	}
+	if t.Min != nil && t.Max != nil && *t.Min > *t.Max {
+		return fmt.Errorf("min must not exceed max")
+	}
+	return nil
+}
+
+// valueTransformer applies TransferRequest.ValueTransforms to mapped values and drops
+// transformed values the destination element's value type wouldn't accept
+type valueTransformer struct {
+	transforms map[string]ValueTransform // Destination element ID -> transform
+	valueTypes map[string]string         // Destination element ID -> value type; nil skips the check
+}
+
+// newValueTransformer re-keys transforms from source to destination elements through
+// the element mapping, since they run on mapped values
+func newValueTransformer(transforms map[string]ValueTransform, mapping map[string]string, valueTypes map[string]string) *valueTransformer {
+	byDest := make(map[string]ValueTransform, len(transforms))
+	for srcID, t := range transforms {
+		destID := srcID
+		if mapped, ok := mapping[srcID]; ok && mapped != "" {
+			destID = mapped
+		}
+		byDest[destID] = t
+	}
+	return &valueTransformer{transforms: byDest, valueTypes: valueTypes}
+}
+
+// apply transforms the values, returning the values to send, how many were changed and
+// how many were dropped because the result is invalid for the destination value type
+func (vt *valueTransformer) apply(values []DataValue) ([]DataValue, int, int) {
+	out := make([]DataValue, 0, len(values))
+	transformed, rejected := 0, 0
+
+	for _, dv := range values {
+		t, ok := vt.transforms[dv.DataElement]
+		if !ok {
+			out = append(out, dv)
+			continue
+		}
+
+		value := t.apply(dv.Value)
+		if value == dv.Value {
+			out = append(out, dv)
+			continue
+		}
+		if audit.ValueIssue(vt.valueTypes[dv.DataElement], value) != "" {
+			rejected++
+			continue
+		}
+
+		dv.Value = value
+		out = append(out, dv)
+		transformed++
+	}
+
+	return out, transformed, rejected
+}
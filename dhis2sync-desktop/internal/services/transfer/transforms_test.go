@@ -0,0 +1,59 @@
+package transfer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueTransforms(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+	floatPtr := func(n float64) *float64 { return &n }
+
+	t.Run("Should scale, round, clamp and map values", func(t *testing.T) {
+		assert.Equal(t, "2500", ValueTransform{Factor: 1000}.apply("2.5"))
+		assert.Equal(t, "3", ValueTransform{Round: intPtr(0)}.apply("2.6"))
+		assert.Equal(t, "0.33", ValueTransform{Round: intPtr(2)}.apply("0.333333"))
+		assert.Equal(t, "100", ValueTransform{Factor: 100, Max: floatPtr(100)}.apply("1.2"))
+		assert.Equal(t, "0", ValueTransform{Min: floatPtr(0)}.apply("-4"))
+		assert.Equal(t, "true", ValueTransform{Lookup: map[string]string{"Y": "true"}}.apply("Y"))
+		assert.Equal(t, "abc", ValueTransform{Factor: 2}.apply("abc"), "Non-numeric values skip numeric steps")
+	})
+
+	t.Run("Should key transforms by destination element and drop invalid results", func(t *testing.T) {
+		vt := newValueTransformer(
+			map[string]ValueTransform{"srcRate0001": {Factor: 100}, "srcCount001": {Factor: 0.5}},
+			map[string]string{"srcRate0001": "dstRate0001", "srcCount001": "dstCount001"},
+			map[string]string{"dstRate0001": "NUMBER", "dstCount001": "INTEGER"},
+		)
+
+		out, transformed, rejected := vt.apply([]DataValue{
+			{DataElement: "dstRate0001", Value: "0.25"},
+			{DataElement: "dstCount001", Value: "4"},
+			{DataElement: "dstCount001", Value: "3"},
+			{DataElement: "dstOther001", Value: "7"},
+		})
+
+		assert.Equal(t, []DataValue{
+			{DataElement: "dstRate0001", Value: "25"},
+			{DataElement: "dstCount001", Value: "2"},
+			{DataElement: "dstOther001", Value: "7"},
+		}, out)
+		assert.Equal(t, 2, transformed)
+		assert.Equal(t, 1, rejected, "1.5 is not an INTEGER")
+	})
+
+	t.Run("Should reject invalid settings", func(t *testing.T) {
+		assert.Error(t, ValueTransform{Round: intPtr(-1)}.validate())
+		assert.Error(t, ValueTransform{Min: floatPtr(5), Max: floatPtr(1)}.validate())
+		assert.NoError(t, ValueTransform{Factor: 0.001, Round: intPtr(0)}.validate())
+
+		err := ValidateTransferRequest(&TransferRequest{
+			ProfileID:       "abcdefghij1",
+			SourceDatasetID: "abcdefghij2",
+			Periods:         []string{"202401"},
+			ValueTransforms: map[string]ValueTransform{"short": {Factor: 2}},
+		})
+		assert.Error(t, err)
+	})
+}
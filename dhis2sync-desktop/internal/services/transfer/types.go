@@ -1,5 +1,13 @@
 package transfer
 
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"dhis2sync-desktop/internal/models"
+)
+
 // TransferRequest represents a request to transfer data between DHIS2 instances
 type TransferRequest struct {
 	ProfileID              string            `json:"profile_id"`
@@ -12,6 +20,68 @@ type TransferRequest struct {
 	Resolutions            []Resolution      `json:"resolutions"`             // User-defined resolutions for missing items
 	MarkComplete           bool              `json:"mark_complete"`           // Mark dataset as complete after transfer
 	AttributeOptionComboID string            `json:"attribute_option_combo_id"`
+	DefaultCOCMapping      string            `json:"default_coc_mapping,omitempty"`   // Dest COC ID for source values under the default/blank COC
+	StallTimeoutSeconds    int               `json:"stall_timeout_seconds,omitempty"` // Mark stalled after this long without activity (default 15 min)
+	CancelOnStall          bool              `json:"cancel_on_stall,omitempty"`       // Cancel instead of only flagging a stalled transfer
+	SkipUnchanged          bool              `json:"skip_unchanged,omitempty"`        // Omit values identical to what the destination already holds
+	CompleteDate           string            `json:"complete_date,omitempty"`         // YYYY-MM-DD recorded when marking complete (default today)
+	StoredBy               string            `json:"stored_by,omitempty"`             // Recorded on completion registrations (default dhis2sync-desktop)
+	ImportMode             string            `json:"import_mode,omitempty"`           // "MERGE" (default) or "REPLACE"
+	ConfirmReplace         bool              `json:"confirm_replace,omitempty"`       // Required opt-in for a REPLACE run that writes
+	DryRun                 bool              `json:"dry_run,omitempty"`               // Preview only: nothing is deleted, imported or completed
+	FollowUpMode           string            `json:"follow_up_mode,omitempty"`        // "all" (default), "only" or "exclude" values flagged for follow-up
+
+	// CategoryOptionCombos limits the transfer to values under these source COCs
+	// (disaggregations); empty transfers all of them
+	CategoryOptionCombos []string `json:"category_option_combos,omitempty"`
+
+	// AutoAssignDataset assigns the destination dataset to target org units that lack
+	// it instead of skipping them (DHIS2 ignores values for unassigned org units)
+	AutoAssignDataset bool `json:"auto_assign_dataset,omitempty"`
+
+	// InterChunkDelayMs pauses this long between import chunks to ease load on busy
+	// destination servers (default 0: no delay)
+	InterChunkDelayMs int `json:"inter_chunk_delay_ms,omitempty"`
+
+	// ModifiedSince (RFC 3339 or YYYY-MM-DD) or LastUpdatedDuration (e.g. "12h", "7d")
+	// limits the transfer to values changed since then (delta sync); set at most one.
+	// Not allowed with REPLACE, which would delete the unchanged values.
+	ModifiedSince       string `json:"modified_since,omitempty"`
+	LastUpdatedDuration string `json:"last_updated_duration,omitempty"`
+
+	// ChunkSize is the number of values per async import job (default 1000, clamped
+	// to 100-5000); MaxConcurrentJobs bounds how many are polled at once (default 10,
+	// clamped to 1-20)
+	ChunkSize         int `json:"chunk_size,omitempty"`
+	MaxConcurrentJobs int `json:"max_concurrent_jobs,omitempty"`
+
+	// ImportStrategy is DHIS2's importStrategy for the import: CREATE_AND_UPDATE (default),
+	// CREATE, UPDATE or DELETE. DELETE removes the transferred values from the destination
+	// and needs ConfirmDelete unless DryRun is set.
+	ImportStrategy string `json:"import_strategy,omitempty"`
+	ConfirmDelete  bool   `json:"confirm_delete,omitempty"`
+
+	// ValueTransforms rewrites values per source data element (scale, round, map codes,
+	// clamp) after element mapping; see ValueTransform
+	ValueTransforms map[string]ValueTransform `json:"value_transforms,omitempty"`
+
+	// AutoMatchCOCs maps source category option combos missing from the destination to
+	// destination combos with the same category options (matched by name), as if the
+	// user had added "map:" resolutions. Explicit resolutions take precedence.
+	AutoMatchCOCs bool `json:"auto_match_cocs,omitempty"`
+}
+
+// DeleteValuesRequest selects values to remove explicitly with a DELETE import, separate
+// from a transfer's REPLACE mode
+type DeleteValuesRequest struct {
+	ProfileID string   `json:"profile_id"`
+	Instance  string   `json:"instance"` // "source" or "destination" (default)
+	DatasetID string   `json:"dataset_id"`
+	Period    string   `json:"period"`
+	OrgUnits  []string `json:"org_units"`
+	Elements  []string `json:"elements"`          // Data elements whose values are deleted; required
+	DryRun    bool     `json:"dry_run,omitempty"` // Preview only: list what would be deleted
+	Confirm   bool     `json:"confirm,omitempty"` // Required opt-in for a run that deletes
 }
 
 // Resolution represents a user decision for a missing item
@@ -42,6 +112,70 @@ type DatasetInfo struct {
 	OrganisationUnits []OrganisationUnit `json:"organisationUnits"`
 }
 
+// DatasetComparison lines up a source dataset against a destination dataset. Both
+// sides are fetched concurrently; a side that fails to load leaves its info nil with
+// the reason in SourceError/DestError, and the element lists are then partial.
+type DatasetComparison struct {
+	Source          *DatasetInfo   `json:"source,omitempty"`
+	Dest            *DatasetInfo   `json:"dest,omitempty"`
+	SourceError     string         `json:"source_error,omitempty"`
+	DestError       string         `json:"dest_error,omitempty"`
+	PeriodTypeMatch bool           `json:"period_type_match"`
+	Matched         []ElementMatch `json:"matched"`     // Elements present on both sides
+	SourceOnly      []DataElement  `json:"source_only"` // Source elements with no destination counterpart (need a mapping)
+	DestOnly        []DataElement  `json:"dest_only"`   // Destination elements the source doesn't have
+}
+
+// ValidationReport is the outcome of a pre-flight transfer check. Errors would make the
+// transfer fail or silently move nothing; warnings are worth a look but don't block it.
+type ValidationReport struct {
+	Valid    bool              `json:"valid"`
+	Errors   []ValidationIssue `json:"errors"`
+	Warnings []ValidationIssue `json:"warnings"`
+}
+
+// ValidationIssue is one finding of a pre-flight check, tied to the request field it concerns
+type ValidationIssue struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ElementPreviewResponse totals the values a transfer would send per destination data element
+type ElementPreviewResponse struct {
+	Elements       []ElementTotal `json:"elements"`
+	TotalValues    int            `json:"total_values"`
+	UnmappedValues int            `json:"unmapped_values"` // Values left out for lack of an element mapping
+	OrgUnits       int            `json:"org_units"`       // Source org units contributing values
+	Periods        int            `json:"periods"`
+}
+
+// StagedValuesPage is one page of a staged transfer's values under review
+type StagedValuesPage struct {
+	StagingID string                   `json:"staging_id"`
+	Status    string                   `json:"status"` // staging, staged, pushing, pushed, error
+	Page      int                      `json:"page"`   // 1-based
+	PageSize  int                      `json:"page_size"`
+	Total     int                      `json:"total"`
+	Values    []models.StagedDataValue `json:"values"`
+}
+
+// ElementTotal is the count and numeric sum of one destination data element's values
+type ElementTotal struct {
+	DataElement   string  `json:"data_element"`
+	Name          string  `json:"name,omitempty"`
+	Values        int     `json:"values"`
+	NumericValues int     `json:"numeric_values"` // Values that parse as numbers, and so count toward Sum
+	Sum           float64 `json:"sum"`
+}
+
+// ElementMatch pairs a source data element with its destination counterpart
+type ElementMatch struct {
+	Source    DataElement `json:"source"`
+	Dest      DataElement `json:"dest"`
+	MatchedBy string      `json:"matched_by"`       // "id" or "code"
+	TypeMatch bool        `json:"value_type_match"` // Same valueType on both sides
+}
+
 // DataElement represents a DHIS2 data element
 type DataElement struct {
 	ID          string `json:"id"`
@@ -85,6 +219,42 @@ type OrgUnitParentRef struct {
 // OrgUnit is an alias for OrganisationUnit for convenience
 type OrgUnit = OrganisationUnit
 
+// OrgUnitBatchResult holds org units fetched per level, with errors for levels that failed
+type OrgUnitBatchResult struct {
+	Levels map[int][]OrgUnit `json:"levels"`
+	Errors map[int]string    `json:"errors,omitempty"` // Level -> error (timeout, cancellation, parse failure)
+}
+
+// firstError returns the error of the lowest failed level
+func (r *OrgUnitBatchResult) firstError() string {
+	first := 0
+	for lvl := range r.Errors {
+		if first == 0 || lvl < first {
+			first = lvl
+		}
+	}
+	if first == 0 {
+		return ""
+	}
+	return fmt.Sprintf("level %d: %s", first, r.Errors[first])
+}
+
+// UnmatchedOrgUnit is a source org unit with data that could not be matched in the destination
+type UnmatchedOrgUnit struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Code    string   `json:"code"`
+	Path    string   `json:"path"`
+	Periods []string `json:"periods"` // Periods whose data was skipped for this org unit
+}
+
+// UnassignedOrgUnit is a destination org unit skipped because the destination dataset isn't assigned to it
+type UnassignedOrgUnit struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Periods []string `json:"periods"` // Periods whose data was skipped for this org unit
+}
+
 // DataValue represents a single data value in DHIS2
 type DataValue struct {
 	DataElement          string `json:"dataElement"`
@@ -97,7 +267,8 @@ type DataValue struct {
 	Created              string `json:"created,omitempty"`
 	LastUpdated          string `json:"lastUpdated,omitempty"`
 	Comment              string `json:"comment,omitempty"`
-	FollowUp             bool   `json:"followUp,omitempty"`
+	FollowUp             bool   `json:"followUp,omitempty"` // Exports spell it "followup"; decoding matches keys case-insensitively
+	Deleted              bool   `json:"deleted,omitempty"`  // Set on values sent with the DELETE import strategy
 }
 
 // DataValueSet represents a collection of data values
@@ -156,7 +327,7 @@ type JobStatus struct {
 // TransferProgress represents the progress of a transfer operation
 type TransferProgress struct {
 	TaskID        string   `json:"task_id"`
-	Status        string   `json:"status"`   // starting, running, completed, error, awaiting_user_decision
+	Status        string   `json:"status"`   // starting, running, completed, error, awaiting_user_decision, stalled, cancelled
 	Progress      int      `json:"progress"` // 0-100
 	Messages      []string `json:"messages"`
 	TotalFetched  int      `json:"total_fetched"`
@@ -164,10 +335,37 @@ type TransferProgress struct {
 	TotalImported int      `json:"total_imported"`
 	// Expose import summary as `result` to match existing frontend expectations (progress.result)
 	ImportSummary  *ImportSummary         `json:"result,omitempty"`
+	TotalUnchanged int                    `json:"total_unchanged,omitempty"` // Values skipped as identical in destination (SkipUnchanged)
 	Error          string                 `json:"error,omitempty"`
-	UnmappedValues map[string][]DataValue `json:"unmapped_values,omitempty"` // Key: "ouName:period", Value: unmapped data values
+	UnmappedValues map[string][]DataValue `json:"unmapped_values,omitempty"`     // Key: "ouName:period", Value: unmapped data values
+	UnmatchedOUs   []UnmatchedOrgUnit     `json:"unmatched_org_units,omitempty"` // Source org units with no destination match
+	ReplacePreview []DataValue            `json:"replace_preview,omitempty"`     // Destination values a REPLACE dry run would delete
+	ComboCounts    map[string]int         `json:"combo_counts,omitempty"`        // Source COC ID -> values selected by the CategoryOptionCombos filter
 	StartedAt      string                 `json:"started_at"`
 	CompletedAt    string                 `json:"completed_at,omitempty"`
+	LastActivityAt string                 `json:"last_activity_at,omitempty"` // Heartbeat, refreshed on every progress update
+
+	// UnassignedOUs are destination org units skipped because the destination dataset isn't assigned to them
+	UnassignedOUs []UnassignedOrgUnit `json:"unassigned_org_units,omitempty"`
+
+	// DeletePreview lists the values a StartDeleteValues dry run would delete
+	DeletePreview []DataValue `json:"delete_preview,omitempty"`
+
+	// AlreadyComplete counts MarkComplete registrations skipped because the destination already had them
+	AlreadyComplete int `json:"already_complete,omitempty"`
+
+	// AutoMatchedCOCs (source COC ID -> destination COC ID) and UnresolvedCOCs list what
+	// AutoMatchCOCs mapped and what it couldn't match, for review
+	AutoMatchedCOCs map[string]string `json:"auto_matched_cocs,omitempty"`
+	UnresolvedCOCs  []string          `json:"unresolved_cocs,omitempty"`
+
+	lastActivity  time.Time
+	stallTimeout  time.Duration
+	cancelOnStall bool
+	cancel        context.CancelFunc // Stops the task's work; set by taskContext
+	// request is kept while the task is parked on unmapped values so
+	// RetryWithNewMappings can import them with the original settings
+	request *TransferRequest
 }
 
 // ImportSummary represents the result of a DHIS2 import operation
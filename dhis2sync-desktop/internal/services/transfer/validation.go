@@ -4,20 +4,35 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 )
 
 var (
 	// DHIS2 UID pattern: 11 alphanumeric characters
 	uidPattern = regexp.MustCompile(`^[a-zA-Z0-9]{11}$`)
 
-	// ISO period patterns
-	periodPatterns = map[string]*regexp.Regexp{
-		"monthly":   regexp.MustCompile(`^\d{6}$`),       // 202401
-		"quarterly": regexp.MustCompile(`^\d{4}Q[1-4]$`), // 2024Q1
-		"yearly":    regexp.MustCompile(`^\d{4}$`),       // 2024
+	// Period identifier formats by dataset period type, keyed by normalizePeriodType
+	periodFormats = map[string]periodFormat{
+		"DAILY":          {regexp.MustCompile(`^\d{4}(0[1-9]|1[0-2])(0[1-9]|[12]\d|3[01])$`), "YYYYMMDD"}, // 20240115
+		"WEEKLY":         {regexp.MustCompile(`^\d{4}W([1-9]|[1-4]\d|5[0-3])$`), "YYYYWn"},                // 2024W5
+		"BIWEEKLY":       {regexp.MustCompile(`^\d{4}BiW([1-9]|1\d|2[0-7])$`), "YYYYBiWn"},                // 2024BiW3
+		"MONTHLY":        {regexp.MustCompile(`^\d{4}(0[1-9]|1[0-2])$`), "YYYYMM"},                        // 202401
+		"BIMONTHLY":      {regexp.MustCompile(`^\d{4}0[1-6]B$`), "YYYYMMB"},                               // 202401B
+		"QUARTERLY":      {regexp.MustCompile(`^\d{4}Q[1-4]$`), "YYYYQn"},                                 // 2024Q1
+		"SIXMONTHLY":     {regexp.MustCompile(`^\d{4}S[12]$`), "YYYYSn"},                                  // 2024S1
+		"YEARLY":         {regexp.MustCompile(`^\d{4}$`), "YYYY"},                                         // 2024
+		"FINANCIALAPRIL": {regexp.MustCompile(`^\d{4}April$`), "YYYYApril"},                               // 2024April
+		"FINANCIALJULY":  {regexp.MustCompile(`^\d{4}July$`), "YYYYJuly"},                                 // 2024July
+		"FINANCIALOCT":   {regexp.MustCompile(`^\d{4}Oct$`), "YYYYOct"},                                   // 2024Oct
 	}
 )
 
+// periodFormat is the identifier format of one period type
+type periodFormat struct {
+	pattern *regexp.Regexp
+	layout  string // Human-readable form for error messages, e.g. YYYYMM
+}
+
 // ValidationError represents a validation error with field context
 type ValidationError struct {
 	Field   string
@@ -93,6 +108,95 @@ func ValidateTransferRequest(req *TransferRequest) error {
 		}
 	}
 
+	// Validate CompleteDate (used for completion registrations)
+	if req.CompleteDate != "" {
+		if _, err := time.Parse("2006-01-02", req.CompleteDate); err != nil {
+			return &ValidationError{"CompleteDate", "must be a date in YYYY-MM-DD format"}
+		}
+	}
+
+	// Validate ImportMode; REPLACE deletes destination values so it needs explicit opt-in
+	switch req.ImportMode {
+	case "", ImportModeMerge:
+	case ImportModeReplace:
+		if !req.DryRun && !req.ConfirmReplace {
+			return &ValidationError{"ImportMode", "REPLACE deletes destination values: preview with dry_run, then set confirm_replace"}
+		}
+	default:
+		return &ValidationError{"ImportMode", "must be 'MERGE' or 'REPLACE'"}
+	}
+
+	// Validate ImportStrategy; DELETE removes destination values so it needs explicit opt-in
+	req.ImportStrategy = strings.ToUpper(strings.TrimSpace(req.ImportStrategy))
+	if req.ImportStrategy == "" {
+		req.ImportStrategy = ImportStrategyCreateAndUpdate
+	}
+	if !importStrategies[req.ImportStrategy] {
+		return &ValidationError{"ImportStrategy", "must be 'CREATE_AND_UPDATE', 'CREATE', 'UPDATE' or 'DELETE'"}
+	}
+	if req.ImportStrategy != ImportStrategyCreateAndUpdate && req.ImportMode == ImportModeReplace {
+		return &ValidationError{"ImportStrategy", "REPLACE requires the CREATE_AND_UPDATE import strategy"}
+	}
+	if req.ImportStrategy == ImportStrategyDelete {
+		if req.SkipUnchanged {
+			return &ValidationError{"ImportStrategy", "DELETE cannot be combined with skip_unchanged, which would keep identical values"}
+		}
+		if !req.DryRun && !req.ConfirmDelete {
+			return &ValidationError{"ImportStrategy", "DELETE removes destination values: preview with dry_run, then set confirm_delete"}
+		}
+	}
+
+	// Validate delta sync
+	if req.ModifiedSince != "" && req.LastUpdatedDuration != "" {
+		return &ValidationError{"ModifiedSince", "set either modified_since or last_updated_duration, not both"}
+	}
+	if req.ModifiedSince != "" {
+		if _, err := parseModifiedSince(req.ModifiedSince); err != nil {
+			return &ValidationError{"ModifiedSince", "must be an RFC 3339 timestamp or a YYYY-MM-DD date"}
+		}
+	}
+	if req.LastUpdatedDuration != "" && !lastUpdatedDurationPattern.MatchString(req.LastUpdatedDuration) {
+		return &ValidationError{"LastUpdatedDuration", "must be a number followed by s, m, h or d (e.g. 12h)"}
+	}
+	if deltaParams(*req) != nil && req.ImportMode == ImportModeReplace {
+		return &ValidationError{"ImportMode", "REPLACE cannot be combined with a delta sync"}
+	}
+
+	// Validate FollowUpMode
+	switch req.FollowUpMode {
+	case "", FollowUpAll, FollowUpOnly, FollowUpExclude:
+	default:
+		return &ValidationError{"FollowUpMode", "must be 'all', 'only' or 'exclude'"}
+	}
+
+	// Validate CategoryOptionCombos
+	for _, cocID := range req.CategoryOptionCombos {
+		if !uidPattern.MatchString(cocID) {
+			return &ValidationError{"CategoryOptionCombos", fmt.Sprintf("invalid UID: %s", cocID)}
+		}
+	}
+
+	// REPLACE deletes whatever the incoming values don't cover, so a partial selection
+	// would remove the values it filtered out
+	if req.ImportMode == ImportModeReplace {
+		if req.FollowUpMode != "" && req.FollowUpMode != FollowUpAll {
+			return &ValidationError{"ImportMode", "REPLACE cannot be combined with a follow-up filter"}
+		}
+		if len(req.CategoryOptionCombos) > 0 {
+			return &ValidationError{"ImportMode", "REPLACE cannot be combined with a category option combo filter"}
+		}
+	}
+
+	// Validate ValueTransforms
+	for deID, transform := range req.ValueTransforms {
+		if !uidPattern.MatchString(deID) {
+			return &ValidationError{"ValueTransforms", fmt.Sprintf("invalid UID: %s", deID)}
+		}
+		if err := transform.validate(); err != nil {
+			return &ValidationError{"ValueTransforms", fmt.Sprintf("%s: %v", deID, err)}
+		}
+	}
+
 	// Validate ElementMapping
 	if len(req.ElementMapping) > 10000 {
 		return &ValidationError{"ElementMapping", "maximum 10000 mappings allowed"}
@@ -101,15 +205,67 @@ func ValidateTransferRequest(req *TransferRequest) error {
 	return nil
 }
 
+// ValidateDeleteValuesRequest validates an explicit deletion; deleting (not previewing)
+// requires Confirm
+func ValidateDeleteValuesRequest(req *DeleteValuesRequest) error {
+	if req.ProfileID == "" {
+		return &ValidationError{"ProfileID", "required"}
+	}
+
+	if req.Instance == "" {
+		req.Instance = "destination"
+	}
+	if req.Instance != "source" && req.Instance != "destination" {
+		return &ValidationError{"Instance", "must be 'source' or 'destination'"}
+	}
+
+	if !uidPattern.MatchString(req.DatasetID) {
+		return &ValidationError{"DatasetID", "invalid DHIS2 UID format"}
+	}
+	if !isValidPeriod(req.Period) {
+		return &ValidationError{"Period", fmt.Sprintf("invalid period format: %s", req.Period)}
+	}
+
+	if len(req.OrgUnits) == 0 {
+		return &ValidationError{"OrgUnits", "at least one org unit required"}
+	}
+	for _, ouID := range req.OrgUnits {
+		if !uidPattern.MatchString(ouID) {
+			return &ValidationError{"OrgUnits", fmt.Sprintf("invalid UID: %s", ouID)}
+		}
+	}
+
+	if len(req.Elements) == 0 {
+		return &ValidationError{"Elements", "at least one data element required"}
+	}
+	for _, deID := range req.Elements {
+		if !uidPattern.MatchString(deID) {
+			return &ValidationError{"Elements", fmt.Sprintf("invalid UID: %s", deID)}
+		}
+	}
+
+	if !req.DryRun && !req.Confirm {
+		return &ValidationError{"Confirm", "deleting values is irreversible: preview with dry_run, then set confirm"}
+	}
+
+	return nil
+}
+
 // isValidPeriod checks if a period string matches DHIS2 period formats
 func isValidPeriod(period string) bool {
 	period = strings.TrimSpace(period)
 
-	for _, pattern := range periodPatterns {
-		if pattern.MatchString(period) {
+	for _, format := range periodFormats {
+		if format.pattern.MatchString(period) {
 			return true
 		}
 	}
 
 	return false
 }
+
+// normalizePeriodType folds DHIS2's period type spellings ("Monthly", "MONTHLY",
+// "SIX_MONTHLY") into the periodFormats keys
+func normalizePeriodType(periodType string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(periodType), "_", ""))
+}
@@ -0,0 +1,132 @@
+package transfer
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"dhis2sync-desktop/internal/database"
+	"dhis2sync-desktop/internal/events"
+	"dhis2sync-desktop/internal/models"
+)
+
+const (
+	// defaultStallTimeout is how long a running transfer may go without any progress update
+	// before the watchdog marks it stalled. Async polls report every few seconds, so this is generous.
+	defaultStallTimeout = 15 * time.Minute
+
+	// watchdogInterval is how often the watchdog scans the task store
+	watchdogInterval = 30 * time.Second
+)
+
+// touchActivity records a heartbeat for a task. Caller must hold taskMu.
+func (p *TransferProgress) touchActivity(now time.Time) {
+	p.lastActivity = now
+	p.LastActivityAt = now.Format(time.RFC3339)
+}
+
+// startWatchdog launches the stall watchdog once per service
+func (s *Service) startWatchdog() {
+	s.watchdogOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(watchdogInterval)
+			defer ticker.Stop()
+
+			for now := range ticker.C {
+				for _, taskID := range s.checkStalledTasks(now) {
+					s.persistStalled(taskID)
+				}
+			}
+		}()
+	})
+}
+
+// checkStalledTasks marks running tasks with no activity within their stall timeout as
+// "stalled" (or "cancelled" when CancelOnStall was requested) and returns their IDs
+func (s *Service) checkStalledTasks(now time.Time) []string {
+	s.taskMu.Lock()
+	defer s.taskMu.Unlock()
+
+	stalled := []string{}
+	for taskID, p := range s.taskStore {
+		if p.Status != "running" && p.Status != "starting" {
+			continue
+		}
+		if p.lastActivity.IsZero() {
+			continue
+		}
+
+		timeout := p.stallTimeout
+		if timeout <= 0 {
+			timeout = defaultStallTimeout
+		}
+
+		idle := now.Sub(p.lastActivity)
+		if idle < timeout {
+			continue
+		}
+
+		msg := fmt.Sprintf("⚠ No activity for %s, transfer appears stalled", idle.Round(time.Second))
+		if p.cancelOnStall {
+			p.Status = "cancelled"
+			p.Error = "Transfer cancelled after stalling"
+			if p.cancel != nil {
+				p.cancel()
+			}
+			p.CompletedAt = now.Format(time.RFC3339)
+			msg = fmt.Sprintf("✗ No activity for %s, transfer cancelled", idle.Round(time.Second))
+		} else {
+			p.Status = "stalled"
+			p.Error = "Transfer stalled"
+		}
+		p.appendMessage(msg)
+		stalled = append(stalled, taskID)
+	}
+
+	return stalled
+}
+
+// persistStalled writes a stalled/cancelled status to the database and notifies the frontend
+func (s *Service) persistStalled(taskID string) {
+	s.taskMu.RLock()
+	p, exists := s.taskStore[taskID]
+	if !exists {
+		s.taskMu.RUnlock()
+		return
+	}
+	status := p.Status
+	progress := p.Progress
+	message := p.Messages[len(p.Messages)-1]
+	allMessages := append([]string{}, p.Messages...)
+	s.taskMu.RUnlock()
+
+	if db := database.GetDB(); db != nil {
+		var taskProgress models.TaskProgress
+		if err := db.Where("id = ?", taskID).First(&taskProgress).Error; err == nil {
+			taskProgress.Status = status
+			messages := s.unmarshalMessages(taskProgress.Messages)
+			messages = append(messages, message)
+			taskProgress.Messages = s.marshalMessages(messages)
+			db.Save(&taskProgress)
+		}
+	}
+
+	events.EmitProgress(s.ctx, fmt.Sprintf("transfer:%s", taskID), "transfer", map[string]interface{}{
+		"task_id":  taskID,
+		"status":   status,
+		"progress": progress,
+		"message":  message,
+		"messages": allMessages,
+	})
+
+	log.Printf("[%s] %s: %s", taskID, status, message)
+}
+
+// isCancelled reports whether a running transfer has been cancelled (e.g. by the watchdog)
+func (s *Service) isCancelled(taskID string) bool {
+	s.taskMu.RLock()
+	defer s.taskMu.RUnlock()
+
+	p, exists := s.taskStore[taskID]
+	return exists && p.Status == "cancelled"
+}
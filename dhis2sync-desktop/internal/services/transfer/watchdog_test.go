@@ -0,0 +1,73 @@
+package transfer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckStalledTasks(t *testing.T) {
+	now := time.Now()
+
+	newTask := func(status string, idle time.Duration) *TransferProgress {
+		p := &TransferProgress{Status: status, Messages: []string{"Initializing transfer..."}}
+		p.touchActivity(now.Add(-idle))
+		return p
+	}
+
+	t.Run("Should mark running tasks idle past the default timeout as stalled", func(t *testing.T) {
+		service := NewService(context.Background())
+		service.taskStore["hung"] = newTask("running", 20*time.Minute)
+		service.taskStore["busy"] = newTask("running", time.Minute)
+		service.taskStore["done"] = newTask("completed", time.Hour)
+
+		stalled := service.checkStalledTasks(now)
+
+		assert.Equal(t, []string{"hung"}, stalled)
+		assert.Equal(t, "stalled", service.taskStore["hung"].Status)
+		assert.Contains(t, service.taskStore["hung"].Messages[1], "stalled")
+		assert.Equal(t, "running", service.taskStore["busy"].Status)
+		assert.Equal(t, "completed", service.taskStore["done"].Status)
+		assert.False(t, service.isCancelled("hung"))
+	})
+
+	t.Run("Should honour a per-task timeout and cancel when requested", func(t *testing.T) {
+		service := NewService(context.Background())
+		task := newTask("running", 2*time.Minute)
+		task.stallTimeout = time.Minute
+		task.cancelOnStall = true
+		service.taskStore["hung"] = task
+
+		stalled := service.checkStalledTasks(now)
+
+		assert.Equal(t, []string{"hung"}, stalled)
+		assert.Equal(t, "cancelled", task.Status)
+		assert.NotEmpty(t, task.CompletedAt)
+		assert.True(t, service.isCancelled("hung"))
+	})
+
+	t.Run("Should not report an already stalled task twice", func(t *testing.T) {
+		service := NewService(context.Background())
+		service.taskStore["hung"] = newTask("running", 20*time.Minute)
+
+		assert.Len(t, service.checkStalledTasks(now), 1)
+		assert.Empty(t, service.checkStalledTasks(now.Add(time.Minute)))
+	})
+}
+
+func TestUpdateProgressOnlyHeartbeat(t *testing.T) {
+	t.Run("Should refresh last activity on progress updates", func(t *testing.T) {
+		service := NewService(context.Background())
+		task := &TransferProgress{Status: "running"}
+		task.touchActivity(time.Now().Add(-time.Hour))
+		service.taskStore["task"] = task
+
+		service.updateProgressOnly("task", 50, "Halfway")
+
+		assert.WithinDuration(t, time.Now(), task.lastActivity, time.Second)
+		assert.NotEmpty(t, task.LastActivityAt)
+		assert.Empty(t, service.checkStalledTasks(time.Now()))
+	})
+}
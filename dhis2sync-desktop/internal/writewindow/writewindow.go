@@ -0,0 +1,117 @@
+// Package writewindow restricts when a profile may write to its destination instance,
+// so transfers and imports don't compete with data entry during business hours.
+package writewindow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"dhis2sync-desktop/internal/models"
+)
+
+// ErrOutsideWindow is returned (wrapped) for writes attempted outside a profile's window
+var ErrOutsideWindow = errors.New("outside the allowed write window")
+
+// Window is the span of local time in which writes are allowed. A window whose end is
+// before its start runs overnight, and belongs to the day it starts on.
+type Window struct {
+	Start string   `json:"start"`          // "HH:MM", local time
+	End   string   `json:"end"`            // "HH:MM", local time
+	Days  []string `json:"days,omitempty"` // "mon".."sun"; empty allows every day
+}
+
+// dayNames maps the accepted day names to weekdays
+var dayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Parse decodes a profile's WriteWindow setting. An empty setting means no window
+// (writes always allowed) and returns nil.
+func Parse(spec string) (*Window, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	var w Window
+	if err := json.Unmarshal([]byte(spec), &w); err != nil {
+		return nil, fmt.Errorf("invalid write window: %w", err)
+	}
+	if _, err := parseClock(w.Start); err != nil {
+		return nil, fmt.Errorf("invalid write window start: %w", err)
+	}
+	if _, err := parseClock(w.End); err != nil {
+		return nil, fmt.Errorf("invalid write window end: %w", err)
+	}
+	if w.Start == w.End {
+		return nil, fmt.Errorf("invalid write window: start and end are both %s", w.Start)
+	}
+	for _, d := range w.Days {
+		if _, ok := dayNames[strings.ToLower(d)]; !ok {
+			return nil, fmt.Errorf("invalid write window day %q (use mon, tue, ... sun)", d)
+		}
+	}
+	return &w, nil
+}
+
+// parseClock returns minutes since midnight for "HH:MM"
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Allows reports whether t falls inside the window
+func (w *Window) Allows(t time.Time) bool {
+	start, _ := parseClock(w.Start)
+	end, _ := parseClock(w.End)
+	minute := t.Hour()*60 + t.Minute()
+
+	if start < end {
+		return w.onDay(t.Weekday()) && minute >= start && minute < end
+	}
+	// Overnight: the late part belongs to today, the early part to yesterday's window
+	if minute >= start {
+		return w.onDay(t.Weekday())
+	}
+	return minute < end && w.onDay((t.Weekday()+6)%7)
+}
+
+func (w *Window) onDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if dayNames[strings.ToLower(d)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// String describes the window, e.g. "18:00-06:00 on mon, tue"
+func (w *Window) String() string {
+	s := w.Start + "-" + w.End
+	if len(w.Days) > 0 {
+		s += " on " + strings.Join(w.Days, ", ")
+	}
+	return s
+}
+
+// Check returns an error wrapping ErrOutsideWindow if profile has a write window and
+// now is outside it. A malformed window also refuses, rather than silently allowing writes.
+func Check(profile *models.ConnectionProfile, now time.Time) error {
+	w, err := Parse(profile.WriteWindow)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrOutsideWindow, err)
+	}
+	if w == nil || w.Allows(now) {
+		return nil
+	}
+	return fmt.Errorf("%w: profile %q only allows writes to the destination %s (local time)", ErrOutsideWindow, profile.Name, w)
+}
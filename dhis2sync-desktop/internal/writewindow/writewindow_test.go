@@ -0,0 +1,79 @@
+package writewindow
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"dhis2sync-desktop/internal/models"
+)
+
+// at returns a local time on the week of Monday 2024-01-01
+func at(day time.Weekday, clock string) time.Time {
+	t, _ := time.ParseInLocation("2006-01-02 15:04", "2024-01-01 "+clock, time.Local)
+	return t.AddDate(0, 0, (int(day)+6)%7)
+}
+
+func TestParse(t *testing.T) {
+	t.Run("Should treat an empty setting as no window", func(t *testing.T) {
+		w, err := Parse("  ")
+		require.NoError(t, err)
+		assert.Nil(t, w)
+	})
+
+	t.Run("Should reject malformed windows", func(t *testing.T) {
+		for _, spec := range []string{
+			`not json`,
+			`{"start":"8am","end":"17:00"}`,
+			`{"start":"08:00","end":"25:00"}`,
+			`{"start":"08:00","end":"08:00"}`,
+			`{"start":"08:00","end":"17:00","days":["funday"]}`,
+		} {
+			_, err := Parse(spec)
+			assert.Error(t, err, spec)
+		}
+	})
+}
+
+func TestAllows(t *testing.T) {
+	t.Run("Should allow writes only inside a daytime window on listed days", func(t *testing.T) {
+		w, err := Parse(`{"start":"08:00","end":"17:00","days":["mon","Tue"]}`)
+		require.NoError(t, err)
+
+		assert.True(t, w.Allows(at(time.Monday, "08:00")))
+		assert.True(t, w.Allows(at(time.Tuesday, "16:59")))
+		assert.False(t, w.Allows(at(time.Monday, "17:00")), "End is exclusive")
+		assert.False(t, w.Allows(at(time.Monday, "07:59")))
+		assert.False(t, w.Allows(at(time.Wednesday, "12:00")))
+	})
+
+	t.Run("Should carry an overnight window into the next morning", func(t *testing.T) {
+		w, err := Parse(`{"start":"18:00","end":"06:00","days":["fri"]}`)
+		require.NoError(t, err)
+
+		assert.True(t, w.Allows(at(time.Friday, "23:30")))
+		assert.True(t, w.Allows(at(time.Saturday, "05:59")), "Saturday morning belongs to Friday's window")
+		assert.False(t, w.Allows(at(time.Saturday, "18:30")))
+		assert.False(t, w.Allows(at(time.Friday, "05:00")), "Friday morning belongs to Thursday's window")
+	})
+}
+
+func TestCheck(t *testing.T) {
+	t.Run("Should allow any time without a window", func(t *testing.T) {
+		assert.NoError(t, Check(&models.ConnectionProfile{Name: "Prod"}, at(time.Monday, "10:00")))
+	})
+
+	t.Run("Should refuse writes outside the window with a clear message", func(t *testing.T) {
+		profile := &models.ConnectionProfile{Name: "Prod", WriteWindow: `{"start":"18:00","end":"06:00"}`}
+
+		err := Check(profile, at(time.Monday, "10:00"))
+
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrOutsideWindow))
+		assert.Contains(t, err.Error(), "18:00-06:00")
+		assert.NoError(t, Check(profile, at(time.Monday, "19:00")))
+	})
+}